@@ -1,37 +1,77 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	configenv "github.com/ijlik/store-app/pkg/config"
 	configdata "github.com/ijlik/store-app/pkg/config/data"
+	"github.com/ijlik/store-app/pkg/database"
 	httpmiddlewaresdk "github.com/ijlik/store-app/pkg/http/middleware"
 	"github.com/jmoiron/sqlx"
+	"strconv"
+	"strings"
 
 	// internal package
+	"github.com/ijlik/store-app/internal/adapter/payment"
 	"github.com/ijlik/store-app/internal/adapter/repository"
 	"github.com/ijlik/store-app/internal/business/port"
 	"github.com/ijlik/store-app/internal/business/service"
 	httpdelivery "github.com/ijlik/store-app/internal/handler/http"
+	schedulerdelivery "github.com/ijlik/store-app/internal/handler/scheduler"
 	_ "github.com/lib/pq"
 )
 
 var config configdata.Config
 
+// the repository run on the default pool, requests and jobs of a tenant
+// attach the tenant pool to their context with repository.TenantContext
 func getService(
 	db *sqlx.DB,
 ) port.StoreDomainService {
-	repo := repository.NewStoreRepo(db)
 	services := service.NewStoreService(
-		repo,
+		repository.NewStoreRepo(db),
 		config,
+		getPaymentGateway(),
 	)
 
 	return services
 }
 
+// PAYMENT_GATEWAY must name the provider, only the in-process fake gateway is
+// available until a provider is added. Webhooks are not verified without
+// PAYMENT_WEBHOOK_SECRET so the server does not start without it.
+func getPaymentGateway() payment.PaymentGateway {
+	secret := config.GetString("PAYMENT_WEBHOOK_SECRET")
+	if secret == "" {
+		panic(errors.New("missing config PAYMENT_WEBHOOK_SECRET"))
+	}
+
+	switch name := config.GetString("PAYMENT_GATEWAY"); name {
+	case payment.FakeProvider:
+		return payment.NewFakeGateway(
+			secret,
+			config.GetBool("PAYMENT_FAKE_AUTO_AUTHORIZE"),
+		)
+	case "":
+		panic(errors.New("missing config PAYMENT_GATEWAY"))
+	default:
+		panic(fmt.Errorf("unknown payment gateway %s", name))
+	}
+}
+
+// secret verifying the bearer tokens, tenant and user of the request are read
+// from the token claims so the server does not start without it
+func getAuthSecret() string {
+	secret := config.GetString("AUTH_TOKEN_SECRET")
+	if secret == "" {
+		panic(errors.New("missing config AUTH_TOKEN_SECRET"))
+	}
+
+	return secret
+}
+
 func getConfig() configdata.Config {
 	c := configenv.NewConfig("", 5)
 
@@ -43,29 +83,53 @@ func getConfig() configdata.Config {
 }
 
 func getDatabase() (*sqlx.DB, error) {
-	var (
-		host     = config.GetString("DB_HOST")
-		dbPort   = config.GetInt("DB_PORT")
-		user     = config.GetString("DB_USER")
-		password = config.GetString("DB_PASSWORD")
-		dbname   = config.GetString("DB_NAME")
-		timeZone = "UTC"
-	)
-
-	dsn := fmt.Sprintf("host=%s port=%d user=%s "+
-		"password=%s dbname=%s sslmode=disable TimeZone=%s",
-		host, dbPort, user, password, dbname, timeZone)
+	return database.Open(httpmiddlewaresdk.DatabaseData{
+		Host:     config.GetString("DB_HOST"),
+		Port:     config.GetInt("DB_PORT"),
+		UserName: config.GetString("DB_USER"),
+		Password: config.GetString("DB_PASSWORD"),
+		Database: config.GetString("DB_NAME"),
+	})
+}
 
-	db, err := sql.Open("postgres", dsn)
-	if err != nil {
-		return nil, err
+const tenantDatabaseKey = "DB_TENANT_"
+
+// tenant database is configured on DB_TENANT_<ID> key as json string
+// example {"host":"127.0.0.1","port":"5432","user":"acme","password":"secret","dbname":"acme"}
+func getTenantSecret() httpmiddlewaresdk.SecretData {
+	return func(ctx context.Context, id string) (httpmiddlewaresdk.DatabaseData, error) {
+		key := tenantDatabaseKey + strings.ToUpper(strings.ReplaceAll(id, "-", "_"))
+		data := config.GetMap(key)
+		if data == nil {
+			return httpmiddlewaresdk.DatabaseData{}, fmt.Errorf("missing config %s", key)
+		}
+
+		port, err := strconv.Atoi(data["port"])
+		if err != nil {
+			return httpmiddlewaresdk.DatabaseData{}, fmt.Errorf("invalid port on config %s", key)
+		}
+
+		return httpmiddlewaresdk.DatabaseData{
+			UserName: data["user"],
+			Password: data["password"],
+			Host:     data["host"],
+			Port:     port,
+			Database: data["dbname"],
+		}, nil
 	}
+}
 
-	if err != nil {
-		return nil, err
-	}
+// tenants having a database configured, DB_TENANT_ACME_CO is tenant acme-co
+func getTenants() schedulerdelivery.TenantSource {
+	return func() []string {
+		var tenants []string
+		for _, key := range config.GetKeys(tenantDatabaseKey) {
+			id := strings.TrimPrefix(key, tenantDatabaseKey)
+			tenants = append(tenants, strings.ToLower(strings.ReplaceAll(id, "_", "-")))
+		}
 
-	return sqlx.NewDb(db, "postgres"), nil
+		return tenants
+	}
 }
 
 func main() {
@@ -79,13 +143,33 @@ func main() {
 
 	defer db.Close()
 
+	registry := database.NewRegistry(db, getTenantSecret(), database.Open)
+	defer registry.Close()
+
 	router := gin.Default()
 	router.Use(
 		httpmiddlewaresdk.WithAllowedCORS(),
+		httpmiddlewaresdk.WithRequestId(),
+		httpmiddlewaresdk.WithAuth(getAuthSecret()),
+		httpmiddlewaresdk.WithTenant(
+			httpmiddlewaresdk.TenantFromClaim("tenant_id"),
+			httpmiddlewaresdk.TenantFromHeader(httpmiddlewaresdk.TenantHeader),
+			httpmiddlewaresdk.TenantFromHost(config.GetString("TENANT_BASE_DOMAIN")),
+		),
+		httpmiddlewaresdk.WithTenantContext(repository.TenantContext(registry)),
+		httpmiddlewaresdk.WithUser("user_id"),
 	)
 
 	services := getService(db)
 
+	scheduler := schedulerdelivery.HandlerScheduler(
+		config,
+		services,
+		getTenants(),
+		repository.TenantContext(registry),
+	)
+	defer scheduler.Stop()
+
 	httpdelivery.HandlerHttp(
 		router,
 		config,
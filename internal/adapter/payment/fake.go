@@ -0,0 +1,220 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+const FakeProvider = "fake"
+
+// FakeGateway is an in-process provider for local runs and tests, intents
+// live in memory and webhooks are signed with HMAC-SHA256 of the secret.
+// With autoAuthorize every intent is authorized once created as if the
+// customer paid right away, reconciliation then captures it.
+type FakeGateway struct {
+	mu            sync.Mutex
+	secret        []byte
+	autoAuthorize bool
+	intents       map[string]*Intent
+	refunds       map[string]*Refund
+}
+
+func NewFakeGateway(secret string, autoAuthorize bool) *FakeGateway {
+	return &FakeGateway{
+		secret:        []byte(secret),
+		autoAuthorize: autoAuthorize,
+		intents:       make(map[string]*Intent),
+		refunds:       make(map[string]*Refund),
+	}
+}
+
+func (f *FakeGateway) Name() string {
+	return FakeProvider
+}
+
+func (f *FakeGateway) CreateIntent(ctx context.Context, request IntentRequest) (*Intent, error) {
+	if request.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	id, err := randomId("pi")
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomId(id + "_secret")
+	if err != nil {
+		return nil, err
+	}
+
+	intent := &Intent{
+		ID:           id,
+		OrderID:      request.OrderID,
+		Amount:       request.Amount,
+		Currency:     request.Currency,
+		Status:       IntentRequiresPayment,
+		ClientSecret: secret,
+	}
+	if f.autoAuthorize {
+		intent.Status = IntentAuthorized
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.intents[id] = intent
+
+	copied := *intent
+	return &copied, nil
+}
+
+func (f *FakeGateway) GetIntent(ctx context.Context, id string) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	intent, ok := f.intents[id]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+
+	copied := *intent
+	return &copied, nil
+}
+
+func (f *FakeGateway) Capture(ctx context.Context, id string, amount float32) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	intent, ok := f.intents[id]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	switch intent.Status {
+	case IntentCaptured:
+	case IntentAuthorized:
+		if amount <= 0 || amount > intent.Amount {
+			return nil, ErrInvalidAmount
+		}
+		intent.Captured = amount
+		intent.Status = IntentCaptured
+	default:
+		return nil, ErrIntentStatus
+	}
+
+	copied := *intent
+	return &copied, nil
+}
+
+func (f *FakeGateway) Refund(ctx context.Context, id string, amount float32, reference string) (*Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if refund, ok := f.refunds[reference]; ok && reference != "" {
+		copied := *refund
+		return &copied, nil
+	}
+
+	intent, ok := f.intents[id]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if intent.Status != IntentCaptured {
+		return nil, ErrIntentStatus
+	}
+	if amount <= 0 || intent.Refunded+amount > intent.Captured {
+		return nil, ErrInvalidAmount
+	}
+
+	refundId, err := randomId("re")
+	if err != nil {
+		return nil, err
+	}
+	refund := &Refund{
+		ID:        refundId,
+		IntentID:  id,
+		Amount:    amount,
+		Reference: reference,
+	}
+	intent.Refunded += amount
+	if reference != "" {
+		f.refunds[reference] = refund
+	}
+
+	copied := *refund
+	return &copied, nil
+}
+
+func (f *FakeGateway) VerifyWebhook(payload []byte, signature string) (*Event, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, f.mac(payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("decode webhook event: %w", err)
+	}
+
+	return &event, nil
+}
+
+// Authorize simulate the customer paying the intent, it return the signed
+// webhook the provider would send
+func (f *FakeGateway) Authorize(id string) ([]byte, string, error) {
+	return f.complete(id, IntentAuthorized, EventAuthorized)
+}
+
+// Fail simulate a declined payment, it return the signed webhook the
+// provider would send
+func (f *FakeGateway) Fail(id string) ([]byte, string, error) {
+	return f.complete(id, IntentFailed, EventFailed)
+}
+
+// Sign return the signature of a webhook payload
+func (f *FakeGateway) Sign(payload []byte) string {
+	return hex.EncodeToString(f.mac(payload))
+}
+
+func (f *FakeGateway) complete(id string, status string, eventType string) ([]byte, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	intent, ok := f.intents[id]
+	if !ok {
+		return nil, "", ErrIntentNotFound
+	}
+	if intent.Status != IntentRequiresPayment {
+		return nil, "", ErrIntentStatus
+	}
+	intent.Status = status
+
+	eventId, err := randomId("evt")
+	if err != nil {
+		return nil, "", err
+	}
+	payload, err := json.Marshal(Event{ID: eventId, Type: eventType, Intent: *intent})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return payload, f.Sign(payload), nil
+}
+
+func (f *FakeGateway) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, f.secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+func randomId(prefix string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate payment id: %w", err)
+	}
+
+	return prefix + "_" + hex.EncodeToString(b), nil
+}
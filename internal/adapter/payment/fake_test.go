@@ -0,0 +1,88 @@
+package payment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeGatewayPayment(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakeGateway("secret", false)
+
+	intent, err := gateway.CreateIntent(ctx, IntentRequest{OrderID: "order-id", Amount: 36000, Currency: "IDR"})
+	assert.NoError(t, err)
+	assert.Equal(t, IntentRequiresPayment, intent.Status)
+	assert.NotEmpty(t, intent.ClientSecret)
+
+	_, err = gateway.Capture(ctx, intent.ID, 36000)
+	assert.Equal(t, ErrIntentStatus, err)
+
+	payload, signature, err := gateway.Authorize(intent.ID)
+	assert.NoError(t, err)
+
+	event, err := gateway.VerifyWebhook(payload, signature)
+	assert.NoError(t, err)
+	assert.Equal(t, EventAuthorized, event.Type)
+	assert.Equal(t, intent.ID, event.Intent.ID)
+	assert.Equal(t, IntentAuthorized, event.Intent.Status)
+
+	_, err = gateway.Capture(ctx, intent.ID, 40000)
+	assert.Equal(t, ErrInvalidAmount, err)
+
+	captured, err := gateway.Capture(ctx, intent.ID, 36000)
+	assert.NoError(t, err)
+	assert.Equal(t, IntentCaptured, captured.Status)
+	assert.Equal(t, float32(36000), captured.Captured)
+
+	// capturing again return the captured intent
+	captured, err = gateway.Capture(ctx, intent.ID, 36000)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(36000), captured.Captured)
+}
+
+func TestFakeGatewayRefund(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakeGateway("secret", true)
+
+	intent, err := gateway.CreateIntent(ctx, IntentRequest{OrderID: "order-id", Amount: 36000, Currency: "IDR"})
+	assert.NoError(t, err)
+	assert.Equal(t, IntentAuthorized, intent.Status)
+	_, err = gateway.Capture(ctx, intent.ID, 36000)
+	assert.NoError(t, err)
+
+	first, err := gateway.Refund(ctx, intent.ID, 20000, "refund-1")
+	assert.NoError(t, err)
+
+	// same reference return the first refund
+	again, err := gateway.Refund(ctx, intent.ID, 20000, "refund-1")
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID)
+
+	_, err = gateway.Refund(ctx, intent.ID, 20000, "refund-2")
+	assert.Equal(t, ErrInvalidAmount, err)
+
+	_, err = gateway.Refund(ctx, intent.ID, 16000, "refund-2")
+	assert.NoError(t, err)
+
+	current, err := gateway.GetIntent(ctx, intent.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(36000), current.Refunded)
+}
+
+func TestFakeGatewayVerifyWebhook(t *testing.T) {
+	gateway := NewFakeGateway("secret", false)
+	payload := []byte(`{"id":"evt_1","type":"payment.failed","data":{"id":"pi_1","status":"failed"}}`)
+
+	_, err := gateway.VerifyWebhook(payload, "")
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	_, err = gateway.VerifyWebhook(payload, NewFakeGateway("other", false).Sign(payload))
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	event, err := gateway.VerifyWebhook(payload, gateway.Sign(payload))
+	assert.NoError(t, err)
+	assert.Equal(t, "evt_1", event.ID)
+	assert.Equal(t, IntentFailed, event.Intent.Status)
+}
@@ -0,0 +1,81 @@
+package payment
+
+import (
+	"context"
+	"errors"
+)
+
+// status of a payment intent on the provider
+const (
+	IntentRequiresPayment = "requires_payment"
+	IntentAuthorized      = "authorized"
+	IntentCaptured        = "captured"
+	IntentFailed          = "failed"
+	IntentCancelled       = "cancelled"
+)
+
+// type of the events sent to the webhook
+const (
+	EventAuthorized = "payment.authorized"
+	EventCaptured   = "payment.captured"
+	EventFailed     = "payment.failed"
+	EventRefunded   = "payment.refunded"
+)
+
+var (
+	ErrIntentNotFound   = errors.New("payment intent not found")
+	ErrIntentStatus     = errors.New("payment intent is not in a valid status for the operation")
+	ErrInvalidAmount    = errors.New("invalid payment amount")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// PaymentGateway is implemented by every payment provider. Amounts are in the
+// currency of the intent.
+type PaymentGateway interface {
+	// Name of the provider, stored on the payment records
+	Name() string
+	// CreateIntent start a payment the customer completes with the client secret
+	CreateIntent(ctx context.Context, request IntentRequest) (*Intent, error)
+	// GetIntent return the current state of an intent, used to reconcile
+	// payments which webhook was missed
+	GetIntent(ctx context.Context, id string) (*Intent, error)
+	// Capture take amount of an authorized intent, capturing a captured intent
+	// return it unchanged
+	Capture(ctx context.Context, id string, amount float32) (*Intent, error)
+	// Refund give back amount of a captured intent, a refund with a reference
+	// already used return the first refund
+	Refund(ctx context.Context, id string, amount float32, reference string) (*Refund, error)
+	// VerifyWebhook check the signature of a webhook payload and decode it
+	VerifyWebhook(payload []byte, signature string) (*Event, error)
+}
+
+type IntentRequest struct {
+	OrderID  string
+	Amount   float32
+	Currency string
+}
+
+type Intent struct {
+	ID           string  `json:"id"`
+	OrderID      string  `json:"order_id"`
+	Amount       float32 `json:"amount"`
+	Captured     float32 `json:"captured"`
+	Refunded     float32 `json:"refunded"`
+	Currency     string  `json:"currency"`
+	Status       string  `json:"status"`
+	ClientSecret string  `json:"-"`
+}
+
+type Refund struct {
+	ID        string  `json:"id"`
+	IntentID  string  `json:"intent_id"`
+	Amount    float32 `json:"amount"`
+	Reference string  `json:"reference"`
+}
+
+// Event sent to the webhook, the intent is its state after the event
+type Event struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Intent Intent `json:"data"`
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+type AuditLog struct {
+	ID        int64          `db:"id"`
+	Entity    string         `db:"entity"`
+	EntityID  string         `db:"entity_id"`
+	Action    string         `db:"action"`
+	Actor     sql.NullString `db:"actor"`
+	RequestID sql.NullString `db:"request_id"`
+	Changes   types.JSONText `db:"changes"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func (a *AuditLog) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		a.Entity,
+		a.EntityID,
+		a.Action,
+		a.Actor,
+		a.RequestID,
+		a.Changes,
+	}
+	return data
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+)
+
+const createAuditLogQuery = `INSERT INTO audit_logs (entity, entity_id, action, actor, request_id, changes, created_at) VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)`
+
+func (r *repo) CreateAuditLog(ctx context.Context, req *AuditLog) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createAuditLogQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const countAuditLogsQuery = `SELECT count(*) FROM audit_logs WHERE entity = $1 AND entity_id = $2`
+
+func (r *repo) CountAuditLogs(ctx context.Context, entity string, entityId string) (int64, error) {
+	var count int64
+	if err := r.connFor(ctx).QueryRowContext(
+		ctx,
+		countAuditLogsQuery,
+		entity,
+		entityId,
+	).Scan(&count); err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+const listAuditLogsQuery = `SELECT id, entity, entity_id, action, actor, request_id, changes, created_at FROM audit_logs WHERE entity = $1 AND entity_id = $2 ORDER BY id DESC LIMIT $3 OFFSET $4`
+
+// list audit logs of an entity, newest first
+func (r *repo) ListAuditLogs(ctx context.Context, entity string, entityId string, limit int, offset int) ([]*AuditLog, error) {
+	var data []*AuditLog
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listAuditLogsQuery,
+		entity,
+		entityId,
+		limit,
+		offset,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAuditLog(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	log := &AuditLog{
+		Entity:    "product",
+		EntityID:  "test_product_id",
+		Action:    "update",
+		Actor:     sql.NullString{String: "test_user_id", Valid: true},
+		RequestID: sql.NullString{},
+		Changes:   []byte(`{"price":{"from":100,"to":120}}`),
+	}
+
+	createAuditLogQueryMock := "INSERT INTO audit_logs \\(entity, entity_id, action, actor, request_id, changes, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, CURRENT_TIMESTAMP\\)"
+	mock.ExpectExec(createAuditLogQueryMock).
+		WithArgs(log.Entity, log.EntityID, log.Action, log.Actor, log.RequestID, log.Changes).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	err = repo.CreateAuditLog(ctx, log)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListAuditLogs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	now := time.Now()
+	listAuditLogsQueryMock := "SELECT id, entity, entity_id, action, actor, request_id, changes, created_at FROM audit_logs WHERE entity = \\$1 AND entity_id = \\$2 ORDER BY id DESC LIMIT \\$3 OFFSET \\$4"
+	mock.ExpectQuery(listAuditLogsQueryMock).
+		WithArgs("store", "test_store_id", 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "entity", "entity_id", "action", "actor", "request_id", "changes", "created_at"}).
+			AddRow(2, "store", "test_store_id", "update", "test_user_id", "test_request_id", []byte(`{"phone":{"from":"1","to":"2"}}`), now).
+			AddRow(1, "store", "test_store_id", "create", nil, nil, []byte(`{"name":{"from":null,"to":"test"}}`), now))
+
+	ctx := context.Background()
+	result, err := repo.ListAuditLogs(ctx, "store", "test_store_id", 10, 20)
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, int64(2), result[0].ID)
+	assert.Equal(t, "test_user_id", result[0].Actor.String)
+	assert.JSONEq(t, `{"phone":{"from":"1","to":"2"}}`, result[0].Changes.String())
+	assert.False(t, result[1].Actor.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Cart struct {
+	ID        string         `db:"id"`
+	Token     sql.NullString `db:"token"`
+	UserID    sql.NullString `db:"user_id"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt sql.NullTime   `db:"updated_at"`
+	ExpiresAt time.Time      `db:"expires_at"`
+}
+
+func (c *Cart) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		c.ID,
+		c.Token,
+		c.UserID,
+		c.ExpiresAt,
+	}
+	return data
+}
+
+type CartItem struct {
+	CartID    string       `db:"cart_id"`
+	ProductID string       `db:"product_id"`
+	Quantity  int          `db:"quantity"`
+	Price     float32      `db:"price"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt sql.NullTime `db:"updated_at"`
+}
+
+func (c *CartItem) RowDataUpsert() []interface{} {
+	var data = []interface{}{
+		c.CartID,
+		c.ProductID,
+		c.Quantity,
+		c.Price,
+	}
+	return data
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const cartColumns = `id, token, user_id, created_at, updated_at, expires_at`
+
+const createCartQuery = `INSERT INTO carts (id, token, user_id, expires_at, created_at) VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)`
+
+func (r *repo) CreateCart(ctx context.Context, req *Cart) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createCartQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const getCartByTokenQuery = `SELECT ` + cartColumns + ` FROM carts WHERE token = $1 AND user_id IS NULL LIMIT 1`
+
+// get anonymous cart by its token, expired cart is returned as well
+func (r *repo) GetCartByToken(ctx context.Context, token string) (*Cart, error) {
+	return r.getCart(ctx, getCartByTokenQuery, token)
+}
+
+const getCartByUserIdQuery = `SELECT ` + cartColumns + ` FROM carts WHERE user_id = $1 LIMIT 1`
+
+// get cart of a user, expired cart is returned as well
+func (r *repo) GetCartByUserId(ctx context.Context, userId string) (*Cart, error) {
+	return r.getCart(ctx, getCartByUserIdQuery, userId)
+}
+
+func (r *repo) getCart(ctx context.Context, query string, arg string) (*Cart, error) {
+	var data Cart
+	if err := r.connFor(ctx).GetContext(
+		ctx,
+		&data,
+		query,
+		arg,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+const touchCartQuery = `UPDATE carts SET expires_at = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+// extend the cart expiry after a change
+func (r *repo) TouchCart(ctx context.Context, id string, expiresAt time.Time) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		touchCartQuery,
+		id,
+		expiresAt,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const claimCartQuery = `UPDATE carts SET user_id = $2, token = NULL, expires_at = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+// turn an anonymous cart into the cart of the user, the token is dropped
+func (r *repo) ClaimCart(ctx context.Context, id string, userId string, expiresAt time.Time) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		claimCartQuery,
+		id,
+		userId,
+		expiresAt,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const deleteCartQuery = `DELETE FROM carts WHERE id = $1`
+
+func (r *repo) DeleteCart(ctx context.Context, id string) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		deleteCartQuery,
+		id,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const deleteExpiredCartsQuery = `DELETE FROM carts WHERE id IN (SELECT id FROM carts WHERE expires_at <= $1 LIMIT $2)`
+
+// delete at most limit carts expired at now, return the deleted count
+func (r *repo) DeleteExpiredCarts(ctx context.Context, now time.Time, limit int) (int64, error) {
+	result, err := r.connFor(ctx).ExecContext(
+		ctx,
+		deleteExpiredCartsQuery,
+		now,
+		limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+const listCartItemsQuery = `SELECT cart_id, product_id, quantity, price, created_at, updated_at FROM cart_items WHERE cart_id = $1 ORDER BY created_at, product_id`
+
+// list lines of a cart, oldest first
+func (r *repo) ListCartItems(ctx context.Context, cartId string) ([]*CartItem, error) {
+	var data []*CartItem
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listCartItemsQuery,
+		cartId,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const upsertCartItemQuery = `INSERT INTO cart_items (cart_id, product_id, quantity, price, created_at) VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP) ON CONFLICT (cart_id, product_id) DO UPDATE SET quantity = EXCLUDED.quantity, price = EXCLUDED.price, updated_at = CURRENT_TIMESTAMP`
+
+// create the line or replace its quantity and price
+func (r *repo) UpsertCartItem(ctx context.Context, req *CartItem) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		upsertCartItemQuery,
+		req.RowDataUpsert()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const deleteCartItemQuery = `DELETE FROM cart_items WHERE cart_id = $1 AND product_id = $2`
+
+func (r *repo) DeleteCartItem(ctx context.Context, cartId string, productId string) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		deleteCartItemQuery,
+		cartId,
+		productId,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteExpiredCarts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	now := time.Now()
+	deleteExpiredCartsQueryMock := "DELETE FROM carts WHERE id IN \\(SELECT id FROM carts WHERE expires_at <= \\$1 LIMIT \\$2\\)"
+	mock.ExpectExec(deleteExpiredCartsQueryMock).WithArgs(now, 500).WillReturnResult(sqlmock.NewResult(0, 12))
+
+	ctx := context.Background()
+	deleted, err := repo.DeleteExpiredCarts(ctx, now, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertCartItem(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	upsertCartItemQueryMock := "INSERT INTO cart_items \\(cart_id, product_id, quantity, price, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, CURRENT_TIMESTAMP\\) ON CONFLICT \\(cart_id, product_id\\) DO UPDATE SET quantity = EXCLUDED.quantity, price = EXCLUDED.price"
+	mock.ExpectExec(upsertCartItemQueryMock).
+		WithArgs("test_cart_id", "test_product_id", 2, float32(18000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	err = repo.UpsertCartItem(ctx, &CartItem{CartID: "test_cart_id", ProductID: "test_product_id", Quantity: 2, Price: 18000})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,13 +1,25 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+
 	"github.com/jmoiron/sqlx"
 )
 
+// conn is implemented by both *sqlx.DB and *sqlx.Tx
+type conn interface {
+	sqlx.ExtContext
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
 type repo struct {
-	conn *sqlx.DB
+	conn conn
+	db   *sqlx.DB
 }
 
 func NewStoreRepo(db *sqlx.DB) StoreRepository {
-	return &repo{db}
+	return &repo{db, db}
 }
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+type DeliveryZone struct {
+	ID        string             `db:"id"`
+	StoreID   string             `db:"store_id"`
+	Name      string             `db:"name"`
+	Type      string             `db:"type"`
+	RadiusKm  sql.NullFloat64    `db:"radius_km"`
+	Polygon   types.NullJSONText `db:"polygon"`
+	Rates     types.JSONText     `db:"rates"`
+	CreatedAt time.Time          `db:"created_at"`
+}
+
+func (d *DeliveryZone) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		d.ID,
+		d.StoreID,
+		d.Name,
+		d.Type,
+		d.RadiusKm,
+		d.Polygon,
+		d.Rates,
+	}
+	return data
+}
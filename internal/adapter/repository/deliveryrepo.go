@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+)
+
+const deliveryZoneColumns = `id, store_id, name, type, radius_km, polygon, rates, created_at`
+
+const createDeliveryZoneQuery = `INSERT INTO delivery_zones (id, store_id, name, type, radius_km, polygon, rates, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)`
+
+func (r *repo) CreateDeliveryZone(ctx context.Context, req *DeliveryZone) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createDeliveryZoneQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const listDeliveryZonesQuery = `SELECT ` + deliveryZoneColumns + ` FROM delivery_zones WHERE store_id = $1 ORDER BY created_at, id`
+
+// list the delivery zones of the store, oldest first
+func (r *repo) ListDeliveryZones(ctx context.Context, storeId string) ([]*DeliveryZone, error) {
+	var data []*DeliveryZone
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listDeliveryZonesQuery,
+		storeId,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const deleteDeliveryZoneQuery = `DELETE FROM delivery_zones WHERE id = $1 AND store_id = $2`
+
+// delete the zone of the store, false when the store has no such zone
+func (r *repo) DeleteDeliveryZone(ctx context.Context, storeId string, id string) (bool, error) {
+	return r.execAffected(ctx, deleteDeliveryZoneQuery, id, storeId)
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDeliveryZones(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	now := time.Now()
+	listDeliveryZonesQueryMock := "SELECT id, store_id, name, type, radius_km, polygon, rates, created_at FROM delivery_zones WHERE store_id = \\$1 ORDER BY created_at, id"
+	mock.ExpectQuery(listDeliveryZonesQueryMock).WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "type", "radius_km", "polygon", "rates", "created_at"}).
+			AddRow("test_zone_id", "test_store_id", "Near", "radius", 3.5, nil, []byte(`[{"fee":10000}]`), now).
+			AddRow("test_polygon_zone_id", "test_store_id", "Central", "polygon", nil, []byte(`[{"lat":-6.15,"lng":106.8}]`), []byte(`[]`), now))
+
+	zones, err := repo.ListDeliveryZones(context.Background(), "test_store_id")
+	assert.NoError(t, err)
+	assert.Len(t, zones, 2)
+	assert.Equal(t, 3.5, zones[0].RadiusKm.Float64)
+	assert.False(t, zones[0].Polygon.Valid)
+	assert.False(t, zones[1].RadiusKm.Valid)
+	assert.True(t, zones[1].Polygon.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteDeliveryZoneOfAnotherStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	mock.ExpectExec("DELETE FROM delivery_zones WHERE id = \\$1 AND store_id = \\$2").WithArgs("test_zone_id", "test_store_id").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	deleted, err := repo.DeleteDeliveryZone(context.Background(), "test_store_id", "test_zone_id")
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/lib/pq"
+)
+
+// postgres error codes, https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pqStringDataRightTruncation = "22001"
+	pqInvalidTextRepresentation = "22P02"
+	pqNotNullViolation          = "23502"
+	pqForeignKeyViolation       = "23503"
+	pqUniqueViolation           = "23505"
+	pqCheckViolation            = "23514"
+	pqSerializationFailure      = "40001"
+	pqDeadlockDetected          = "40P01"
+)
+
+type constraintError struct {
+	reason string
+	msg    string
+}
+
+// user message per constraint name, constraint not listed here use the
+// default message of its error code
+var mapConstraint = map[string]constraintError{
+	"stores_url_key":            {"STORE_ALREADY_EXISTS", "store with the same name already exists"},
+	"products_url_key":          {"PRODUCT_ALREADY_EXISTS", "product with the same name already exists"},
+	"products_store_id_fkey":    {"STORE_NOT_FOUND", "store not found"},
+	"products_sku_key":          {"PRODUCT_SKU_ALREADY_EXISTS", "product with the same sku already exists"},
+	"promotions_code_key":       {"PROMOTION_CODE_ALREADY_EXISTS", "promotion with the same code already exists"},
+	"pickup_bookings_order_key": {"PICKUP_ALREADY_BOOKED", "order already has a pickup slot booked"},
+}
+
+// ErrVersionMismatch is returned by conditional update when the row has
+// been updated since it was read
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// TranslateError convert repository error into service error with a message
+// safe to show to the user, the original error is kept as cause
+func TranslateError(err error) errpkg.ErrorService {
+	if err == nil {
+		return nil
+	}
+
+	var errService errpkg.ErrorService
+	if errors.As(err, &errService) {
+		return errService
+	}
+
+	if errors.Is(err, ErrVersionMismatch) {
+		return errpkg.NewServiceError(
+			errpkg.ErrPreconditionFailed,
+			"resource has been changed by another request, reload and retry",
+			errpkg.WithReason("VERSION_MISMATCH"),
+			errpkg.WithCause(err),
+		)
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return errpkg.WrapServiceError(errpkg.ErrInternal, errpkg.GetMessage(errpkg.ErrInternal), err)
+	}
+
+	switch pqErr.Code {
+	case pqUniqueViolation:
+		return constraintServiceError(errpkg.ErrConflict, pqErr, constraintError{"ALREADY_EXISTS", "resource already exists"})
+	case pqForeignKeyViolation:
+		if _, ok := mapConstraint[pqErr.Constraint]; ok {
+			return constraintServiceError(errpkg.ErrNotFound, pqErr, constraintError{})
+		}
+		return constraintServiceError(errpkg.ErrBadRequest, pqErr, constraintError{"REFERENCE_NOT_FOUND", "referenced resource does not exist"})
+	case pqCheckViolation:
+		return constraintServiceError(errpkg.ErrBadRequest, pqErr, constraintError{"INVALID_VALUE", "invalid value"})
+	case pqNotNullViolation:
+		return errpkg.NewServiceError(
+			errpkg.ErrBadRequest,
+			"missing "+pqErr.Column,
+			errpkg.WithReason("MISSING_VALUE"),
+			errpkg.WithMetadata("field", pqErr.Column),
+			errpkg.WithField(pqErr.Column, "REQUIRED", "missing "+pqErr.Column),
+			errpkg.WithCause(err),
+		)
+	case pqStringDataRightTruncation:
+		return errpkg.NewServiceError(errpkg.ErrBadRequest, "value too long", errpkg.WithReason("VALUE_TOO_LONG"), errpkg.WithCause(err))
+	case pqInvalidTextRepresentation:
+		return errpkg.NewServiceError(errpkg.ErrBadRequest, "invalid value format", errpkg.WithReason("INVALID_FORMAT"), errpkg.WithCause(err))
+	case pqSerializationFailure, pqDeadlockDetected:
+		return errpkg.WrapServiceError(errpkg.ErrRetryable, errpkg.GetMessage(errpkg.ErrRetryable), err)
+	}
+
+	return errpkg.WrapServiceError(errpkg.ErrInternal, errpkg.GetMessage(errpkg.ErrInternal), err)
+}
+
+func constraintServiceError(code errpkg.ErrCode, pqErr *pq.Error, def constraintError) errpkg.ErrorService {
+	c, ok := mapConstraint[pqErr.Constraint]
+	if !ok {
+		c = def
+	}
+
+	return errpkg.NewServiceError(code, c.msg, errpkg.WithReason(c.reason), errpkg.WithCause(pqErr))
+}
+
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == pqSerializationFailure || pqErr.Code == pqDeadlockDetected
+}
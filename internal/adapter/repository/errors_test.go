@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode errpkg.ErrCode
+		expectedMsg  string
+	}{
+		{"unique store url", &pq.Error{Code: "23505", Constraint: "stores_url_key", Message: "duplicate key value violates unique constraint"}, errpkg.ErrConflict, "store with the same name already exists"},
+		{"unique unknown", &pq.Error{Code: "23505", Constraint: "other_key"}, errpkg.ErrConflict, "resource already exists"},
+		{"foreign key store", &pq.Error{Code: "23503", Constraint: "products_store_id_fkey"}, errpkg.ErrNotFound, "store not found"},
+		{"foreign key unknown", &pq.Error{Code: "23503", Constraint: "other_fkey"}, errpkg.ErrBadRequest, "referenced resource does not exist"},
+		{"check", &pq.Error{Code: "23514", Constraint: "price_check"}, errpkg.ErrBadRequest, "invalid value"},
+		{"invalid uuid", &pq.Error{Code: "22P02", Message: "invalid input syntax for type uuid"}, errpkg.ErrBadRequest, "invalid value format"},
+		{"serialization", &pq.Error{Code: "40001"}, errpkg.ErrRetryable, errpkg.GetMessage(errpkg.ErrRetryable)},
+		{"wrapped", fmt.Errorf("query: %w", &pq.Error{Code: "40P01"}), errpkg.ErrRetryable, errpkg.GetMessage(errpkg.ErrRetryable)},
+		{"unknown", errors.New("connection refused"), errpkg.ErrInternal, errpkg.GetMessage(errpkg.ErrInternal)},
+		{"service error", errpkg.DefaultServiceError(errpkg.ErrNotFound, "product not found"), errpkg.ErrNotFound, "product not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TranslateError(tt.err)
+			assert.Equal(t, tt.expectedCode, result.GetCode())
+			assert.Equal(t, tt.expectedMsg, result.Error())
+			assert.NotContains(t, result.Error(), "duplicate key")
+		})
+	}
+
+	assert.Nil(t, TranslateError(nil))
+
+	cause := &pq.Error{Code: "23505", Constraint: "stores_url_key"}
+	assert.Equal(t, cause, TranslateError(cause).GetCause())
+}
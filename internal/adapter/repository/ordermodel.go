@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+type Order struct {
+	ID         string         `db:"id"`
+	StoreID    string         `db:"store_id"`
+	UserID     string         `db:"user_id"`
+	Status     string         `db:"status"`
+	Subtotal   float32        `db:"subtotal"`
+	Discount   float32        `db:"discount"`
+	Total      float32        `db:"total"`
+	CouponCode sql.NullString `db:"coupon_code"`
+	ExpiresAt  sql.NullTime   `db:"expires_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+	UpdatedAt  sql.NullTime   `db:"updated_at"`
+
+	TaxIncluded float32        `db:"tax_included"`
+	TaxAdded    float32        `db:"tax_added"`
+	Taxes       types.JSONText `db:"taxes"`
+}
+
+func (o *Order) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		o.ID,
+		o.StoreID,
+		o.UserID,
+		o.Status,
+		o.Subtotal,
+		o.Discount,
+		o.Total,
+		o.CouponCode,
+		o.ExpiresAt,
+		o.TaxIncluded,
+		o.TaxAdded,
+		o.Taxes,
+	}
+	return data
+}
+
+type OrderItem struct {
+	ID         int64          `db:"id"`
+	OrderID    string         `db:"order_id"`
+	ProductID  string         `db:"product_id"`
+	Name       string         `db:"name"`
+	Sku        sql.NullString `db:"sku"`
+	UnitPrice  float32        `db:"unit_price"`
+	SalePrice  float32        `db:"sale_price"`
+	Quantity   int            `db:"quantity"`
+	Discount   float32        `db:"discount"`
+	Total      float32        `db:"total"`
+	Promotions types.JSONText `db:"promotions"`
+
+	RefundedQuantity int     `db:"refunded_quantity"`
+	RefundedAmount   float32 `db:"refunded_amount"`
+
+	TaxIncluded float32 `db:"tax_included"`
+	TaxAdded    float32 `db:"tax_added"`
+}
+
+func (o *OrderItem) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		o.OrderID,
+		o.ProductID,
+		o.Name,
+		o.Sku,
+		o.UnitPrice,
+		o.SalePrice,
+		o.Quantity,
+		o.Discount,
+		o.Total,
+		o.Promotions,
+		o.TaxIncluded,
+		o.TaxAdded,
+	}
+	return data
+}
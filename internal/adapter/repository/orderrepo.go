@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const orderColumns = `id, store_id, user_id, status, subtotal, discount, total, coupon_code, expires_at, created_at, updated_at, tax_included, tax_added, taxes`
+
+const createOrderQuery = `INSERT INTO orders (id, store_id, user_id, status, subtotal, discount, total, coupon_code, expires_at, tax_included, tax_added, taxes, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, CURRENT_TIMESTAMP)`
+
+func (r *repo) CreateOrder(ctx context.Context, req *Order) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createOrderQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const createOrderItemQuery = `INSERT INTO order_items (order_id, product_id, name, sku, unit_price, sale_price, quantity, discount, total, promotions, tax_included, tax_added) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+func (r *repo) CreateOrderItem(ctx context.Context, req *OrderItem) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createOrderItemQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const getOrderByIdQuery = `SELECT ` + orderColumns + ` FROM orders WHERE id = $1 LIMIT 1`
+
+func (r *repo) GetOrderById(ctx context.Context, id string) (*Order, error) {
+	var data Order
+	if err := r.connFor(ctx).GetContext(
+		ctx,
+		&data,
+		getOrderByIdQuery,
+		id,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+const listOrderItemsQuery = `SELECT id, order_id, product_id, name, sku, unit_price, sale_price, quantity, discount, total, promotions, refunded_quantity, refunded_amount, tax_included, tax_added FROM order_items WHERE order_id = $1 ORDER BY id`
+
+func (r *repo) ListOrderItems(ctx context.Context, orderId string) ([]*OrderItem, error) {
+	var data []*OrderItem
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listOrderItemsQuery,
+		orderId,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const countOrdersByUserQuery = `SELECT count(*) FROM orders WHERE user_id = $1 AND ($2 = '' OR status = $2)`
+
+// count orders of a user, empty status count every status
+func (r *repo) CountOrdersByUser(ctx context.Context, userId string, status string) (int64, error) {
+	return r.countOrders(ctx, countOrdersByUserQuery, userId, status)
+}
+
+const listOrdersByUserQuery = `SELECT ` + orderColumns + ` FROM orders WHERE user_id = $1 AND ($2 = '' OR status = $2) ORDER BY created_at DESC, id LIMIT $3 OFFSET $4`
+
+// list orders of a user newest first, empty status list every status
+func (r *repo) ListOrdersByUser(ctx context.Context, userId string, status string, limit int, offset int) ([]*Order, error) {
+	return r.listOrders(ctx, listOrdersByUserQuery, userId, status, limit, offset)
+}
+
+const countOrdersByStoreQuery = `SELECT count(*) FROM orders WHERE store_id = $1 AND ($2 = '' OR status = $2)`
+
+// count orders of a store, empty status count every status
+func (r *repo) CountOrdersByStore(ctx context.Context, storeId string, status string) (int64, error) {
+	return r.countOrders(ctx, countOrdersByStoreQuery, storeId, status)
+}
+
+const listOrdersByStoreQuery = `SELECT ` + orderColumns + ` FROM orders WHERE store_id = $1 AND ($2 = '' OR status = $2) ORDER BY created_at DESC, id LIMIT $3 OFFSET $4`
+
+// list orders of a store newest first, empty status list every status
+func (r *repo) ListOrdersByStore(ctx context.Context, storeId string, status string, limit int, offset int) ([]*Order, error) {
+	return r.listOrders(ctx, listOrdersByStoreQuery, storeId, status, limit, offset)
+}
+
+func (r *repo) countOrders(ctx context.Context, query string, owner string, status string) (int64, error) {
+	var count int64
+	if err := r.connFor(ctx).QueryRowContext(
+		ctx,
+		query,
+		owner,
+		status,
+	).Scan(&count); err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+func (r *repo) listOrders(ctx context.Context, query string, owner string, status string, limit int, offset int) ([]*Order, error) {
+	var data []*Order
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		query,
+		owner,
+		status,
+		limit,
+		offset,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const updateOrderStatusQuery = `UPDATE orders SET status = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND status = $2`
+
+// move the order from one status to another, ErrVersionMismatch is returned
+// when the order is no longer in the from status
+func (r *repo) UpdateOrderStatus(ctx context.Context, id string, from string, to string) error {
+	result, err := r.connFor(ctx).ExecContext(
+		ctx,
+		updateOrderStatusQuery,
+		id,
+		from,
+		to,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
+
+const listExpiredOrdersQuery = `SELECT ` + orderColumns + ` FROM orders WHERE status = 'pending' AND expires_at <= $1 ORDER BY expires_at LIMIT $2`
+
+// list pending orders not paid before now, oldest first
+func (r *repo) ListExpiredOrders(ctx context.Context, now time.Time, limit int) ([]*Order, error) {
+	var data []*Order
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listExpiredOrdersQuery,
+		now,
+		limit,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const reserveStockQuery = `UPDATE products SET stock = stock - $2, updated_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND (stock IS NULL OR stock >= $2)`
+
+// take quantity from the product stock, false when the stock is lower than
+// quantity. Product without tracked stock is always reserved. The stock is
+// part of the product so its version is bumped like any other change.
+func (r *repo) ReserveStock(ctx context.Context, productId string, quantity int) (bool, error) {
+	result, err := r.connFor(ctx).ExecContext(
+		ctx,
+		reserveStockQuery,
+		productId,
+		quantity,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+const releaseStockQuery = `UPDATE products SET stock = stock + $2, updated_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND stock IS NOT NULL`
+
+// put quantity back on the product stock, deleted product is skipped
+func (r *repo) ReleaseStock(ctx context.Context, productId string, quantity int) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		releaseStockQuery,
+		productId,
+		quantity,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
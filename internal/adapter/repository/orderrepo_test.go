@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserveStock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	reserveStockQueryMock := "UPDATE products SET stock = stock - \\$2, updated_at = CURRENT_TIMESTAMP, version = version \\+ 1 WHERE id = \\$1 AND \\(stock IS NULL OR stock >= \\$2\\)"
+	mock.ExpectExec(reserveStockQueryMock).WithArgs("test_product_id", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(reserveStockQueryMock).WithArgs("test_product_id", 5).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	reserved, err := repo.ReserveStock(ctx, "test_product_id", 2)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+
+	reserved, err = repo.ReserveStock(ctx, "test_product_id", 5)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateOrderStatusMismatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	updateOrderStatusQueryMock := "UPDATE orders SET status = \\$3, updated_at = CURRENT_TIMESTAMP WHERE id = \\$1 AND status = \\$2"
+	mock.ExpectExec(updateOrderStatusQueryMock).WithArgs("test_order_id", "pending", "paid").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.UpdateOrderStatus(context.Background(), "test_order_id", "pending", "paid")
+	assert.Equal(t, ErrVersionMismatch, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
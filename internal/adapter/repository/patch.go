@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Column is a column changed by a partial update
+type Column struct {
+	Name  string
+	Value any
+}
+
+// columns a partial update may change, names are written into the query
+var (
+	storePatchColumns = map[string]bool{
+		"name":                   true,
+		"url":                    true,
+		"address":                true,
+		"phone":                  true,
+		"operational_time_start": true,
+		"operational_time_end":   true,
+		"tax_region":             true,
+		"latitude":               true,
+		"longitude":              true,
+		"address_street":         true,
+		"address_district":       true,
+		"address_city":           true,
+		"address_province":       true,
+		"address_postal_code":    true,
+		"address_country":        true,
+	}
+	productPatchColumns = map[string]bool{
+		"store_id":    true,
+		"name":        true,
+		"url":         true,
+		"price":       true,
+		"description": true,
+		"sku":         true,
+		"was_price":   true,
+		"category":    true,
+		"stock":       true,
+		"weight":      true,
+	}
+)
+
+// buildPatchQuery build UPDATE statement of table touching only the given
+// columns, updated_at and version. id is the first parameter and version the
+// second one.
+func buildPatchQuery(table string, allowed map[string]bool, id string, version int, columns []Column) (string, []any, error) {
+	var (
+		sets   []string
+		params = []any{id, version}
+	)
+	for _, column := range columns {
+		if !allowed[column.Name] {
+			return "", nil, fmt.Errorf("%s: column %s can not be patched", table, column.Name)
+		}
+
+		params = append(params, column.Value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column.Name, len(params)))
+	}
+	sets = append(sets, "updated_at = CURRENT_TIMESTAMP", "version = version + 1")
+
+	return fmt.Sprintf("UPDATE %s SET %s WHERE id = $1 AND ($2 = 0 OR version = $2)", table, strings.Join(sets, ", ")), params, nil
+}
+
+// conditional update touching no row lost the race against another update
+func checkRowsAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+type Payment struct {
+	ID             string       `db:"id"`
+	OrderID        string       `db:"order_id"`
+	Provider       string       `db:"provider"`
+	Reference      string       `db:"reference"`
+	Amount         float32      `db:"amount"`
+	CapturedAmount float32      `db:"captured_amount"`
+	RefundedAmount float32      `db:"refunded_amount"`
+	Currency       string       `db:"currency"`
+	Status         string       `db:"status"`
+	CreatedAt      time.Time    `db:"created_at"`
+	UpdatedAt      sql.NullTime `db:"updated_at"`
+}
+
+func (p *Payment) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		p.ID,
+		p.OrderID,
+		p.Provider,
+		p.Reference,
+		p.Amount,
+		p.Currency,
+		p.Status,
+	}
+	return data
+}
+
+type PaymentEvent struct {
+	Provider  string         `db:"provider"`
+	EventID   string         `db:"event_id"`
+	Type      string         `db:"type"`
+	Reference string         `db:"reference"`
+	Payload   types.JSONText `db:"payload"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func (p *PaymentEvent) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		p.Provider,
+		p.EventID,
+		p.Type,
+		p.Reference,
+		p.Payload,
+	}
+	return data
+}
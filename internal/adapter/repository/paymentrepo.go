@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const paymentColumns = `id, order_id, provider, reference, amount, captured_amount, refunded_amount, currency, status, created_at, updated_at`
+
+const createPaymentQuery = `INSERT INTO payments (id, order_id, provider, reference, amount, currency, status, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)`
+
+func (r *repo) CreatePayment(ctx context.Context, req *Payment) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createPaymentQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const getPaymentByIdQuery = `SELECT ` + paymentColumns + ` FROM payments WHERE id = $1 LIMIT 1`
+
+func (r *repo) GetPaymentById(ctx context.Context, id string) (*Payment, error) {
+	return r.getPayment(ctx, getPaymentByIdQuery, id)
+}
+
+const getPaymentByReferenceQuery = `SELECT ` + paymentColumns + ` FROM payments WHERE provider = $1 AND reference = $2 LIMIT 1`
+
+func (r *repo) GetPaymentByReference(ctx context.Context, provider string, reference string) (*Payment, error) {
+	return r.getPayment(ctx, getPaymentByReferenceQuery, provider, reference)
+}
+
+const getOpenPaymentByOrderQuery = `SELECT ` + paymentColumns + ` FROM payments WHERE order_id = $1 AND status IN ('pending', 'authorized') LIMIT 1`
+
+// payment of the order not yet captured nor failed
+func (r *repo) GetOpenPaymentByOrder(ctx context.Context, orderId string) (*Payment, error) {
+	return r.getPayment(ctx, getOpenPaymentByOrderQuery, orderId)
+}
+
+func (r *repo) getPayment(ctx context.Context, query string, args ...any) (*Payment, error) {
+	var data Payment
+	if err := r.connFor(ctx).GetContext(
+		ctx,
+		&data,
+		query,
+		args...,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+const listPaymentsByOrderQuery = `SELECT ` + paymentColumns + ` FROM payments WHERE order_id = $1 ORDER BY created_at, id`
+
+func (r *repo) ListPaymentsByOrder(ctx context.Context, orderId string) ([]*Payment, error) {
+	var data []*Payment
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listPaymentsByOrderQuery,
+		orderId,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const listOpenPaymentsQuery = `SELECT ` + paymentColumns + ` FROM payments WHERE status IN ('pending', 'authorized') AND created_at <= $1 ORDER BY created_at LIMIT $2`
+
+// list payments not yet captured nor failed created before, oldest first
+func (r *repo) ListOpenPayments(ctx context.Context, before time.Time, limit int) ([]*Payment, error) {
+	var data []*Payment
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listOpenPaymentsQuery,
+		before,
+		limit,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const updatePaymentStatusQuery = `UPDATE payments SET status = $3, captured_amount = $4, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND status = $2`
+
+// move the payment from one status to another, ErrVersionMismatch is
+// returned when the payment is no longer in the from status
+func (r *repo) UpdatePaymentStatus(ctx context.Context, id string, from string, to string, captured float32) error {
+	result, err := r.connFor(ctx).ExecContext(
+		ctx,
+		updatePaymentStatusQuery,
+		id,
+		from,
+		to,
+		captured,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
+
+const createPaymentEventQuery = `INSERT INTO payment_events (provider, event_id, type, reference, payload, created_at) VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP) ON CONFLICT (provider, event_id) DO NOTHING`
+
+// record a webhook event, false when the event has been recorded before
+func (r *repo) CreatePaymentEvent(ctx context.Context, req *PaymentEvent) (bool, error) {
+	result, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createPaymentEventQuery,
+		req.RowDataCreate()...,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePaymentEventDuplicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	payload := types.JSONText(`{"id":"evt_1"}`)
+	createPaymentEventQueryMock := "INSERT INTO payment_events \\(provider, event_id, type, reference, payload, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, CURRENT_TIMESTAMP\\) ON CONFLICT \\(provider, event_id\\) DO NOTHING"
+	mock.ExpectExec(createPaymentEventQueryMock).WithArgs("fake", "evt_1", "payment.authorized", "pi_1", payload).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(createPaymentEventQueryMock).WithArgs("fake", "evt_1", "payment.authorized", "pi_1", payload).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	event := &PaymentEvent{Provider: "fake", EventID: "evt_1", Type: "payment.authorized", Reference: "pi_1", Payload: payload}
+	created, err := repo.CreatePaymentEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = repo.CreatePaymentEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
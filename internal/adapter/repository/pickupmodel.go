@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type PickupSettings struct {
+	StoreID     string    `db:"store_id"`
+	SlotMinutes int       `db:"slot_minutes"`
+	Capacity    int       `db:"capacity"`
+	LeadMinutes int       `db:"lead_minutes"`
+	DaysAhead   int       `db:"days_ahead"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+func (p *PickupSettings) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		p.StoreID,
+		p.SlotMinutes,
+		p.Capacity,
+		p.LeadMinutes,
+		p.DaysAhead,
+	}
+	return data
+}
+
+// PickupSlot is the booked count of a slot, slot without booking has no row
+type PickupSlot struct {
+	StoreID  string    `db:"store_id"`
+	StartsAt time.Time `db:"starts_at"`
+	Booked   int       `db:"booked"`
+}
+
+type PickupBooking struct {
+	ID        string       `db:"id"`
+	StoreID   string       `db:"store_id"`
+	OrderID   string       `db:"order_id"`
+	UserID    string       `db:"user_id"`
+	StartsAt  time.Time    `db:"starts_at"`
+	EndsAt    time.Time    `db:"ends_at"`
+	Status    string       `db:"status"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt sql.NullTime `db:"updated_at"`
+}
+
+func (p *PickupBooking) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		p.ID,
+		p.StoreID,
+		p.OrderID,
+		p.UserID,
+		p.StartsAt,
+		p.EndsAt,
+		p.Status,
+	}
+	return data
+}
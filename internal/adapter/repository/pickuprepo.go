@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const upsertPickupSettingsQuery = `INSERT INTO pickup_settings (store_id, slot_minutes, capacity, lead_minutes, days_ahead, updated_at) VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP) ON CONFLICT (store_id) DO UPDATE SET slot_minutes = EXCLUDED.slot_minutes, capacity = EXCLUDED.capacity, lead_minutes = EXCLUDED.lead_minutes, days_ahead = EXCLUDED.days_ahead, updated_at = CURRENT_TIMESTAMP`
+
+// create or replace the pickup settings of the store, slots already booked
+// keep their bookings
+func (r *repo) UpsertPickupSettings(ctx context.Context, req *PickupSettings) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		upsertPickupSettingsQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const getPickupSettingsQuery = `SELECT store_id, slot_minutes, capacity, lead_minutes, days_ahead, updated_at FROM pickup_settings WHERE store_id = $1`
+
+// return the pickup settings of the store, nil when the store does not offer
+// pickup
+func (r *repo) GetPickupSettings(ctx context.Context, storeId string) (*PickupSettings, error) {
+	var data PickupSettings
+	if err := r.connFor(ctx).GetContext(
+		ctx,
+		&data,
+		getPickupSettingsQuery,
+		storeId,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+const listPickupSlotsQuery = `SELECT store_id, starts_at, booked FROM pickup_slots WHERE store_id = $1 AND starts_at >= $2 AND starts_at < $3 ORDER BY starts_at`
+
+// list the booked count of the slots of the store starting within
+// [from, to)
+func (r *repo) ListPickupSlots(ctx context.Context, storeId string, from time.Time, to time.Time) ([]*PickupSlot, error) {
+	var data []*PickupSlot
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listPickupSlotsQuery,
+		storeId,
+		from,
+		to,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const reservePickupSlotQuery = `INSERT INTO pickup_slots (store_id, starts_at, booked) VALUES ($1, $2, 1) ON CONFLICT (store_id, starts_at) DO UPDATE SET booked = pickup_slots.booked + 1 WHERE pickup_slots.booked < $3`
+
+// count one booking on the slot, false when the slot already has capacity
+// bookings
+func (r *repo) ReservePickupSlot(ctx context.Context, storeId string, startsAt time.Time, capacity int) (bool, error) {
+	return r.execAffected(ctx, reservePickupSlotQuery, storeId, startsAt, capacity)
+}
+
+const createPickupBookingQuery = `INSERT INTO pickup_bookings (id, store_id, order_id, user_id, starts_at, ends_at, status, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)`
+
+func (r *repo) CreatePickupBooking(ctx context.Context, req *PickupBooking) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createPickupBookingQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const getPickupBookingByOrderQuery = `SELECT id, store_id, order_id, user_id, starts_at, ends_at, status, created_at, updated_at FROM pickup_bookings WHERE order_id = $1 AND status = 'booked'`
+
+// return the booking of the order, nil when the order has no booked slot
+func (r *repo) GetPickupBookingByOrder(ctx context.Context, orderId string) (*PickupBooking, error) {
+	var data PickupBooking
+	if err := r.connFor(ctx).GetContext(
+		ctx,
+		&data,
+		getPickupBookingByOrderQuery,
+		orderId,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+const releaseOrderPickupQuery = `WITH released AS (UPDATE pickup_bookings SET status = 'cancelled', updated_at = CURRENT_TIMESTAMP WHERE order_id = $1 AND status = 'booked' RETURNING store_id, starts_at) UPDATE pickup_slots s SET booked = GREATEST(s.booked - 1, 0) FROM released r WHERE s.store_id = r.store_id AND s.starts_at = r.starts_at`
+
+// cancel the booking of an order and give its place in the slot back
+func (r *repo) ReleaseOrderPickup(ctx context.Context, orderId string) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		releaseOrderPickupQuery,
+		orderId,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
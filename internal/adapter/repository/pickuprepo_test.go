@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservePickupSlotFull(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	startsAt := time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)
+	reservePickupSlotQueryMock := "INSERT INTO pickup_slots \\(store_id, starts_at, booked\\) VALUES \\(\\$1, \\$2, 1\\) ON CONFLICT \\(store_id, starts_at\\) DO UPDATE SET booked = pickup_slots.booked \\+ 1 WHERE pickup_slots.booked < \\$3"
+	mock.ExpectExec(reservePickupSlotQueryMock).WithArgs("test_store_id", startsAt, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(reservePickupSlotQueryMock).WithArgs("test_store_id", startsAt, 5).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	reserved, err := repo.ReservePickupSlot(context.Background(), "test_store_id", startsAt, 5)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+
+	reserved, err = repo.ReservePickupSlot(context.Background(), "test_store_id", startsAt, 5)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPickupSettingsNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	mock.ExpectQuery("SELECT (.+) FROM pickup_settings WHERE store_id = \\$1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows([]string{"store_id", "slot_minutes", "capacity", "lead_minutes", "days_ahead", "updated_at"}))
+
+	settings, err := repo.GetPickupSettings(context.Background(), "test_store_id")
+	assert.NoError(t, err)
+	assert.Nil(t, settings)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type PriceHistory struct {
+	ID            int64           `db:"id"`
+	ProductID     string          `db:"product_id"`
+	Price         float32         `db:"price"`
+	PreviousPrice sql.NullFloat64 `db:"previous_price"`
+	ScheduleID    sql.NullString  `db:"schedule_id"`
+	CreatedAt     time.Time       `db:"created_at"`
+}
+
+func (p *PriceHistory) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		p.ProductID,
+		p.Price,
+		p.PreviousPrice,
+		p.ScheduleID,
+	}
+	return data
+}
+
+type PriceSchedule struct {
+	ID            string          `db:"id"`
+	ProductID     string          `db:"product_id"`
+	Price         float32         `db:"price"`
+	EffectiveFrom time.Time       `db:"effective_from"`
+	EffectiveTo   sql.NullTime    `db:"effective_to"`
+	Status        string          `db:"status"`
+	PreviousPrice sql.NullFloat64 `db:"previous_price"`
+	CreatedAt     time.Time       `db:"created_at"`
+	UpdatedAt     sql.NullTime    `db:"updated_at"`
+}
+
+func (p *PriceSchedule) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		p.ID,
+		p.ProductID,
+		p.Price,
+		p.EffectiveFrom,
+		p.EffectiveTo,
+		p.Status,
+	}
+	return data
+}
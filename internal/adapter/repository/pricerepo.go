@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const createPriceHistoryQuery = `INSERT INTO product_price_histories (product_id, price, previous_price, schedule_id, created_at) VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)`
+
+func (r *repo) CreatePriceHistory(ctx context.Context, req *PriceHistory) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createPriceHistoryQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const countPriceHistoriesQuery = `SELECT count(*) FROM product_price_histories WHERE product_id = $1`
+
+func (r *repo) CountPriceHistories(ctx context.Context, productId string) (int64, error) {
+	var count int64
+	if err := r.connFor(ctx).QueryRowContext(
+		ctx,
+		countPriceHistoriesQuery,
+		productId,
+	).Scan(&count); err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+const listPriceHistoriesQuery = `SELECT id, product_id, price, previous_price, schedule_id, created_at FROM product_price_histories WHERE product_id = $1 ORDER BY id DESC LIMIT $2 OFFSET $3`
+
+// list price changes of a product, newest first
+func (r *repo) ListPriceHistories(ctx context.Context, productId string, limit int, offset int) ([]*PriceHistory, error) {
+	var data []*PriceHistory
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listPriceHistoriesQuery,
+		productId,
+		limit,
+		offset,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const createPriceScheduleQuery = `INSERT INTO product_price_schedules (id, product_id, price, effective_from, effective_to, status, created_at) VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)`
+
+func (r *repo) CreatePriceSchedule(ctx context.Context, req *PriceSchedule) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createPriceScheduleQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const getPriceScheduleByIdQuery = `SELECT id, product_id, price, effective_from, effective_to, status, previous_price, created_at, updated_at FROM product_price_schedules WHERE id = $1 LIMIT 1`
+
+func (r *repo) GetPriceScheduleById(ctx context.Context, id string) (*PriceSchedule, error) {
+	var data PriceSchedule
+	err := r.connFor(ctx).GetContext(
+		ctx,
+		&data,
+		getPriceScheduleByIdQuery,
+		id,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+const listOpenPriceSchedulesQuery = `SELECT id, product_id, price, effective_from, effective_to, status, previous_price, created_at, updated_at FROM product_price_schedules WHERE product_id = $1 AND status IN ('pending', 'active') ORDER BY effective_from`
+
+// list pending and active schedules of a product, earliest first
+func (r *repo) ListOpenPriceSchedules(ctx context.Context, productId string) ([]*PriceSchedule, error) {
+	var data []*PriceSchedule
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listOpenPriceSchedulesQuery,
+		productId,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const listStartedPriceSchedulesQuery = `SELECT id, product_id, price, effective_from, effective_to, status, previous_price, created_at, updated_at FROM product_price_schedules WHERE product_id = ANY($1) AND status IN ('pending', 'active') AND effective_from <= $2 ORDER BY effective_from`
+
+// list pending and active schedules of the products started at now, earliest
+// first
+func (r *repo) ListStartedPriceSchedules(ctx context.Context, productIds []string, now time.Time) ([]*PriceSchedule, error) {
+	var data []*PriceSchedule
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listStartedPriceSchedulesQuery,
+		pq.Array(productIds),
+		now,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const listDuePriceSchedulesQuery = `SELECT id, product_id, price, effective_from, effective_to, status, previous_price, created_at, updated_at FROM product_price_schedules WHERE (status = 'pending' AND effective_from <= $1) OR (status = 'active' AND effective_to <= $1) ORDER BY effective_from LIMIT $2`
+
+// list schedules to start or to end at now
+func (r *repo) ListDuePriceSchedules(ctx context.Context, now time.Time, limit int) ([]*PriceSchedule, error) {
+	var data []*PriceSchedule
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listDuePriceSchedulesQuery,
+		now,
+		limit,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const updatePriceScheduleStatusQuery = `UPDATE product_price_schedules SET status = $2, previous_price = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+func (r *repo) UpdatePriceScheduleStatus(ctx context.Context, id string, status string, previousPrice sql.NullFloat64) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		updatePriceScheduleStatusQuery,
+		id,
+		status,
+		previousPrice,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
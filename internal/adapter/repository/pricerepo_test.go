@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDuePriceSchedules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	now := time.Now()
+	listDuePriceSchedulesQueryMock := "SELECT id, product_id, price, effective_from, effective_to, status, previous_price, created_at, updated_at FROM product_price_schedules WHERE \\(status = 'pending' AND effective_from <= \\$1\\) OR \\(status = 'active' AND effective_to <= \\$1\\) ORDER BY effective_from LIMIT \\$2"
+	mock.ExpectQuery(listDuePriceSchedulesQueryMock).
+		WithArgs(now, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "price", "effective_from", "effective_to", "status", "previous_price", "created_at", "updated_at"}).
+			AddRow("test_schedule_id", "test_product_id", 80, now, nil, "pending", nil, now, nil).
+			AddRow("test_schedule_id_2", "test_product_id", 70, now, now, "active", 100, now, now))
+
+	ctx := context.Background()
+	result, err := repo.ListDuePriceSchedules(ctx, now, 100)
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.False(t, result[0].EffectiveTo.Valid)
+	assert.Equal(t, float32(70), result[1].Price)
+	assert.Equal(t, sql.NullFloat64{Float64: 100, Valid: true}, result[1].PreviousPrice)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListStartedPriceSchedules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	now := time.Now()
+	listStartedPriceSchedulesQueryMock := "SELECT (.+) FROM product_price_schedules WHERE product_id = ANY\\(\\$1\\) AND status IN \\('pending', 'active'\\) AND effective_from <= \\$2 ORDER BY effective_from"
+	mock.ExpectQuery(listStartedPriceSchedulesQueryMock).
+		WithArgs(sqlmock.AnyArg(), now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "price", "effective_from", "effective_to", "status", "previous_price", "created_at", "updated_at"}).
+			AddRow("test_schedule_id", "test_product_id", 80, now, now.Add(time.Hour), "pending", nil, now, nil))
+
+	ctx := context.Background()
+	result, err := repo.ListStartedPriceSchedules(ctx, []string{"test_product_id"}, now)
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "test_product_id", result[0].ProductID)
+	assert.True(t, result[0].EffectiveTo.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPriceScheduleByIdNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	getPriceScheduleByIdQueryMock := "SELECT (.+) FROM product_price_schedules WHERE id = \\$1 LIMIT 1"
+	mock.ExpectQuery(getPriceScheduleByIdQueryMock).WithArgs("test_schedule_id").WillReturnError(sql.ErrNoRows)
+
+	ctx := context.Background()
+	result, err := repo.GetPriceScheduleById(ctx, "test_schedule_id")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
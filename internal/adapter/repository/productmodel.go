@@ -8,14 +8,20 @@ import (
 )
 
 type Product struct {
-	ID          string       `db:"id"`
-	StoreID     string       `db:"store_id"`
-	Name        string       `db:"name"`
-	Url         string       `db:"url"`
-	Price       float32      `db:"price"`
-	Description string       `db:"description"`
-	CreatedAt   time.Time    `db:"created_at"`
-	UpdatedAt   sql.NullTime `db:"updated_at"`
+	ID          string          `db:"id"`
+	StoreID     string          `db:"store_id"`
+	Name        string          `db:"name"`
+	Url         string          `db:"url"`
+	Price       float32         `db:"price"`
+	Description string          `db:"description"`
+	Sku         sql.NullString  `db:"sku"`
+	CreatedAt   time.Time       `db:"created_at"`
+	UpdatedAt   sql.NullTime    `db:"updated_at"`
+	Version     int             `db:"version"`
+	WasPrice    sql.NullFloat64 `db:"was_price"`
+	Category    sql.NullString  `db:"category"`
+	Stock       sql.NullInt64   `db:"stock"`
+	Weight      int             `db:"weight"`
 }
 
 func (p *Product) RowDataIndex() []interface{} {
@@ -26,8 +32,14 @@ func (p *Product) RowDataIndex() []interface{} {
 		p.Url,
 		p.Price,
 		p.Description,
+		p.Sku,
 		p.CreatedAt,
 		p.UpdatedAt,
+		p.Version,
+		p.WasPrice,
+		p.Category,
+		p.Stock,
+		p.Weight,
 	}
 	return data
 }
@@ -43,6 +55,9 @@ func (p *Product) RowDataCreate() []interface{} {
 		p.Url,
 		p.Price,
 		p.Description,
+		p.Sku,
+		p.Category,
+		p.Weight,
 	}
 	return data
 }
@@ -55,6 +70,10 @@ func (p *Product) RowDataUpdate() []interface{} {
 		p.Url,
 		p.Price,
 		p.Description,
+		p.Sku,
+		p.Category,
+		p.Weight,
+		p.Version,
 	}
 	return data
 }
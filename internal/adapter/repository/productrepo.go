@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"fmt"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 var countProductsQuery = `SELECT count(*) FROM products`
@@ -21,7 +23,7 @@ func (r *repo) CountProduct(ctx context.Context, sfp *SearchFilterPagination) (i
 		return 0, err
 	}
 
-	if err := r.conn.QueryRowContext(
+	if err := r.connFor(ctx).QueryRowContext(
 		ctx,
 		query,
 		params...,
@@ -44,7 +46,7 @@ func (r *repo) CountProductByStoreId(ctx context.Context, sfp *SearchFilterPagin
 		return 0, err
 	}
 
-	if err := r.conn.QueryRowContext(
+	if err := r.connFor(ctx).QueryRowContext(
 		ctx,
 		query,
 		params...,
@@ -55,7 +57,7 @@ func (r *repo) CountProductByStoreId(ctx context.Context, sfp *SearchFilterPagin
 	return count, nil
 }
 
-var listProductsQuery = `SELECT id, store_id, name, url, price, description, created_at, updated_at FROM products`
+var listProductsQuery = `SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products`
 
 func (r *repo) ListProduct(ctx context.Context, sfp *SearchFilterPagination) ([]*Product, error) {
 	var (
@@ -74,7 +76,7 @@ func (r *repo) ListProduct(ctx context.Context, sfp *SearchFilterPagination) ([]
 		return nil, err
 	}
 
-	rows, err := r.conn.QueryContext(
+	rows, err := r.connFor(ctx).QueryContext(
 		ctx,
 		query,
 		params...,
@@ -93,8 +95,10 @@ func (r *repo) ListProduct(ctx context.Context, sfp *SearchFilterPagination) ([]
 			&e.Url,
 			&e.Price,
 			&e.Description,
+			&e.Sku,
 			&e.CreatedAt,
 			&e.UpdatedAt,
+			&e.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -121,7 +125,7 @@ func (r *repo) ListProductByStoreId(ctx context.Context, sfp *SearchFilterPagina
 		return nil, err
 	}
 
-	rows, err := r.conn.QueryContext(
+	rows, err := r.connFor(ctx).QueryContext(
 		ctx,
 		query,
 		params...,
@@ -140,8 +144,10 @@ func (r *repo) ListProductByStoreId(ctx context.Context, sfp *SearchFilterPagina
 			&e.Url,
 			&e.Price,
 			&e.Description,
+			&e.Sku,
 			&e.CreatedAt,
 			&e.UpdatedAt,
+			&e.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -151,11 +157,11 @@ func (r *repo) ListProductByStoreId(ctx context.Context, sfp *SearchFilterPagina
 	return data, nil
 }
 
-const createProductQuery = `INSERT INTO products (store_id, name, url, price, description, created_at) VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP) RETURNING id`
+const createProductQuery = `INSERT INTO products (store_id, name, url, price, description, sku, category, weight, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP) RETURNING id`
 
 func (r *repo) CreateProduct(ctx context.Context, req *Product) (*Product, error) {
 	var id string
-	if err := r.conn.QueryRowContext(
+	if err := r.connFor(ctx).QueryRowContext(
 		ctx,
 		createProductQuery,
 		req.RowDataCreate()...,
@@ -170,16 +176,19 @@ func (r *repo) CreateProduct(ctx context.Context, req *Product) (*Product, error
 		Url:         req.Url,
 		Price:       req.Price,
 		Description: req.Description,
+		Sku:         req.Sku,
+		Category:    req.Category,
 		CreatedAt:   time.Now().UTC(),
+		Version:     1,
 		UpdatedAt:   sql.NullTime{},
 	}, nil
 }
 
-const getProductByIdQuery = `SELECT id, store_id, name, url, price, description, created_at, updated_at FROM products WHERE id = $1 LIMIT 1`
+const getProductByIdQuery = `SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products WHERE id = $1 LIMIT 1`
 
 func (r *repo) GetProductById(ctx context.Context, id string) (*Product, error) {
 	var data Product
-	err := r.conn.GetContext(
+	err := r.connFor(ctx).GetContext(
 		ctx,
 		&data,
 		getProductByIdQuery,
@@ -196,11 +205,11 @@ func (r *repo) GetProductById(ctx context.Context, id string) (*Product, error)
 	return &data, nil
 }
 
-const getProductByUrlQuery = `SELECT id, store_id, name, url, price, description, created_at, updated_at FROM products WHERE url = $1 LIMIT 1`
+const getProductByUrlQuery = `SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products WHERE url = $1 LIMIT 1`
 
 func (r *repo) GetProductByUrl(ctx context.Context, slug string) (*Product, error) {
 	var data Product
-	err := r.conn.GetContext(
+	err := r.connFor(ctx).GetContext(
 		ctx,
 		&data,
 		getProductByUrlQuery,
@@ -217,24 +226,65 @@ func (r *repo) GetProductByUrl(ctx context.Context, slug string) (*Product, erro
 	return &data, nil
 }
 
-const updateProductQuery = `UPDATE products SET store_id = $2, name = $3, url = $4, price = $5, description = $6, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+const updateProductQuery = `UPDATE products SET store_id = $2, name = $3, url = $4, price = $5, description = $6, sku = $7, category = $8, weight = $9, updated_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND ($10 = 0 OR version = $10)`
 
+// update the product, zero Version skip the version check
 func (r *repo) UpdateProduct(ctx context.Context, req *Product) error {
-	if _, err := r.conn.ExecContext(
+	result, err := r.connFor(ctx).ExecContext(
 		ctx,
 		updateProductQuery,
 		req.RowDataUpdate()...,
-	); err != nil {
+	)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return checkRowsAffected(result)
+}
+
+const getProductBySkuQuery = `SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products WHERE sku = $1 LIMIT 1`
+
+func (r *repo) GetProductBySku(ctx context.Context, sku string) (*Product, error) {
+	var data Product
+	err := r.connFor(ctx).GetContext(
+		ctx,
+		&data,
+		getProductBySkuQuery,
+		sku,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+const listProductByIdsQuery = `SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products WHERE id = ANY($1)`
+
+// list products having one of the ids, missing ids are skipped and the order
+// is not guaranteed
+func (r *repo) ListProductByIds(ctx context.Context, ids []string) ([]*Product, error) {
+	var data []*Product
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listProductByIdsQuery,
+		pq.Array(ids),
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
 }
 
 var deleteProductQuery = `DELETE FROM products WHERE id = $1`
 
 func (r *repo) DeleteProduct(ctx context.Context, id string) error {
-	if _, err := r.conn.ExecContext(
+	if _, err := r.connFor(ctx).ExecContext(
 		ctx,
 		deleteProductQuery,
 		id,
@@ -244,3 +294,92 @@ func (r *repo) DeleteProduct(ctx context.Context, id string) error {
 
 	return nil
 }
+
+const listProductUrlsQuery = `SELECT url, id AS owner_id, created_at FROM products WHERE url = $1 OR url LIKE $2 UNION ALL SELECT url, product_id AS owner_id, created_at FROM product_url_histories WHERE url = $1 OR url LIKE $2`
+
+// list current and previous product urls equal to base or matching the LIKE pattern
+func (r *repo) ListProductUrls(ctx context.Context, base string, pattern string) ([]*Slug, error) {
+	var data []*Slug
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listProductUrlsQuery,
+		base,
+		pattern,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const getProductUrlHistoryQuery = `SELECT url, product_id AS owner_id, created_at FROM product_url_histories WHERE url = $1 LIMIT 1`
+
+func (r *repo) GetProductUrlHistory(ctx context.Context, url string) (*Slug, error) {
+	var data Slug
+	err := r.connFor(ctx).GetContext(
+		ctx,
+		&data,
+		getProductUrlHistoryQuery,
+		url,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+const createProductUrlHistoryQuery = `INSERT INTO product_url_histories (url, product_id, created_at) VALUES ($1, $2, CURRENT_TIMESTAMP) ON CONFLICT (url) DO NOTHING`
+
+func (r *repo) CreateProductUrlHistory(ctx context.Context, productId string, url string) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createProductUrlHistoryQuery,
+		url,
+		productId,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const deleteProductUrlHistoryQuery = `DELETE FROM product_url_histories WHERE url = $1 AND product_id = $2`
+
+func (r *repo) DeleteProductUrlHistory(ctx context.Context, productId string, url string) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		deleteProductUrlHistoryQuery,
+		url,
+		productId,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// update only the given columns of the product, zero version skip the version
+// check
+func (r *repo) PatchProduct(ctx context.Context, id string, version int, columns []Column) error {
+	query, params, err := buildPatchQuery("products", productPatchColumns, id, version, columns)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.connFor(ctx).ExecContext(
+		ctx,
+		query,
+		params...,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkRowsAffected(result)
+}
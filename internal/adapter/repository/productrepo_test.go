@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -86,9 +87,9 @@ func TestListProduct(t *testing.T) {
 			},
 		},
 	}
-	listProductsQueryMock := "SELECT id, store_id, name, url, price, description, created_at, updated_at FROM products"
-	mock.ExpectQuery(listProductsQueryMock).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "created_at", "updated_at"}).
-		AddRow(expectedData[0].ID, expectedData[0].StoreID, expectedData[0].Name, expectedData[0].Url, expectedData[0].Price, expectedData[0].Description, expectedData[0].CreatedAt, expectedData[0].UpdatedAt))
+	listProductsQueryMock := "SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products"
+	mock.ExpectQuery(listProductsQueryMock).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version"}).
+		AddRow(expectedData[0].ID, expectedData[0].StoreID, expectedData[0].Name, expectedData[0].Url, expectedData[0].Price, expectedData[0].Description, expectedData[0].Sku, expectedData[0].CreatedAt, expectedData[0].UpdatedAt, expectedData[0].Version))
 
 	sfp := &SearchFilterPagination{
 		Limit:         10,
@@ -128,9 +129,9 @@ func TestListProductByStoreId(t *testing.T) {
 			},
 		},
 	}
-	listProductsQueryMock := "SELECT id, store_id, name, url, price, description, created_at, updated_at FROM products"
-	mock.ExpectQuery(listProductsQueryMock).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "created_at", "updated_at"}).
-		AddRow(expectedData[0].ID, expectedData[0].StoreID, expectedData[0].Name, expectedData[0].Url, expectedData[0].Price, expectedData[0].Description, expectedData[0].CreatedAt, expectedData[0].UpdatedAt))
+	listProductsQueryMock := "SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products"
+	mock.ExpectQuery(listProductsQueryMock).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version"}).
+		AddRow(expectedData[0].ID, expectedData[0].StoreID, expectedData[0].Name, expectedData[0].Url, expectedData[0].Price, expectedData[0].Description, expectedData[0].Sku, expectedData[0].CreatedAt, expectedData[0].UpdatedAt, expectedData[0].Version))
 
 	sfp := &SearchFilterPagination{
 		Limit:         10,
@@ -168,9 +169,9 @@ func TestCreateProduct(t *testing.T) {
 		},
 	}
 
-	createProductQueryMock := "INSERT INTO products \\(store_id, name, url, price, description, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, CURRENT_TIMESTAMP\\) RETURNING id"
+	createProductQueryMock := "INSERT INTO products \\(store_id, name, url, price, description, sku, category, weight, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8, CURRENT_TIMESTAMP\\) RETURNING id"
 	mock.ExpectQuery(createProductQueryMock).
-		WithArgs(expectedData.StoreID, expectedData.Name, expectedData.Url, expectedData.Price, expectedData.Description).
+		WithArgs(expectedData.StoreID, expectedData.Name, expectedData.Url, expectedData.Price, expectedData.Description, expectedData.Sku, expectedData.Category, expectedData.Weight).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(expectedData.ID))
 
 	ctx := context.Background()
@@ -200,9 +201,9 @@ func TestGetProductById(t *testing.T) {
 			Valid: false,
 		},
 	}
-	getProductByIdQueryMock := "SELECT id, store_id, name, url, price, description, created_at, updated_at FROM products WHERE id = \\$1 LIMIT 1"
-	mock.ExpectQuery(getProductByIdQueryMock).WithArgs(expectedData.ID).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "created_at", "updated_at"}).
-		AddRow(expectedData.ID, expectedData.StoreID, expectedData.Name, expectedData.Url, expectedData.Price, expectedData.Description, expectedData.CreatedAt, expectedData.UpdatedAt))
+	getProductByIdQueryMock := "SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products WHERE id = \\$1 LIMIT 1"
+	mock.ExpectQuery(getProductByIdQueryMock).WithArgs(expectedData.ID).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version"}).
+		AddRow(expectedData.ID, expectedData.StoreID, expectedData.Name, expectedData.Url, expectedData.Price, expectedData.Description, expectedData.Sku, expectedData.CreatedAt, expectedData.UpdatedAt, expectedData.Version))
 
 	ctx := context.Background()
 	result, err := repo.GetProductById(ctx, expectedData.ID)
@@ -231,9 +232,9 @@ func TestGetProductByUrl(t *testing.T) {
 			Valid: false,
 		},
 	}
-	getProductByUrlQueryMock := "SELECT id, store_id, name, url, price, description, created_at, updated_at FROM products WHERE url = \\$1 LIMIT 1"
-	mock.ExpectQuery(getProductByUrlQueryMock).WithArgs(expectedData.Url).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "created_at", "updated_at"}).
-		AddRow(expectedData.ID, expectedData.StoreID, expectedData.Name, expectedData.Url, expectedData.Price, expectedData.Description, expectedData.CreatedAt, expectedData.UpdatedAt))
+	getProductByUrlQueryMock := "SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products WHERE url = \\$1 LIMIT 1"
+	mock.ExpectQuery(getProductByUrlQueryMock).WithArgs(expectedData.Url).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version"}).
+		AddRow(expectedData.ID, expectedData.StoreID, expectedData.Name, expectedData.Url, expectedData.Price, expectedData.Description, expectedData.Sku, expectedData.CreatedAt, expectedData.UpdatedAt, expectedData.Version))
 
 	ctx := context.Background()
 	result, err := repo.GetProductByUrl(ctx, expectedData.Url)
@@ -263,9 +264,9 @@ func TestUpdateProductById(t *testing.T) {
 		},
 	}
 
-	updateProductByIdQueryMock := "UPDATE products SET store_id = \\$2, name = \\$3, url = \\$4, price = \\$5, description = \\$6, updated_at = CURRENT_TIMESTAMP WHERE id = \\$1"
+	updateProductByIdQueryMock := "UPDATE products SET store_id = \\$2, name = \\$3, url = \\$4, price = \\$5, description = \\$6, sku = \\$7, category = \\$8, weight = \\$9, updated_at = CURRENT_TIMESTAMP, version = version \\+ 1 WHERE id = \\$1 AND \\(\\$10 = 0 OR version = \\$10\\)"
 	mock.ExpectExec(updateProductByIdQueryMock).
-		WithArgs(expectedData.ID, expectedData.StoreID, expectedData.Name, expectedData.Url, expectedData.Price, expectedData.Description).
+		WithArgs(expectedData.ID, expectedData.StoreID, expectedData.Name, expectedData.Url, expectedData.Price, expectedData.Description, expectedData.Sku, expectedData.Category, expectedData.Weight, expectedData.Version).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	ctx := context.Background()
@@ -304,3 +305,114 @@ func TestDeleteProductById(t *testing.T) {
 	err = repo.DeleteProduct(ctx, expectedData.ID)
 	assert.NoError(t, err)
 }
+
+func TestGetProductUrlHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	expectedData := &Slug{
+		Url:       "test_product_url",
+		OwnerID:   "test_product_id",
+		CreatedAt: time.Now(),
+	}
+
+	getProductUrlHistoryQueryMock := "SELECT url, product_id AS owner_id, created_at FROM product_url_histories WHERE url = \\$1 LIMIT 1"
+	mock.ExpectQuery(getProductUrlHistoryQueryMock).WithArgs(expectedData.Url).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "owner_id", "created_at"}).AddRow(expectedData.Url, expectedData.OwnerID, expectedData.CreatedAt))
+
+	ctx := context.Background()
+	result, err := repo.GetProductUrlHistory(ctx, expectedData.Url)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedData, result)
+}
+
+func TestCreateProductUrlHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	createProductUrlHistoryQueryMock := "INSERT INTO product_url_histories \\(url, product_id, created_at\\) VALUES \\(\\$1, \\$2, CURRENT_TIMESTAMP\\) ON CONFLICT \\(url\\) DO NOTHING"
+	mock.ExpectExec(createProductUrlHistoryQueryMock).
+		WithArgs("test_product_url", "test_product_id").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	err = repo.CreateProductUrlHistory(ctx, "test_product_id", "test_product_url")
+	assert.NoError(t, err)
+}
+
+func TestListProductByIds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	expectedData := []*Product{
+		{
+			ID:          "test_product_id",
+			StoreID:     "test_store_id",
+			Name:        "test_product_name",
+			Url:         "test_product_url",
+			Price:       100,
+			Description: "test_product_description",
+			Sku: sql.NullString{
+				String: "test_product_sku",
+				Valid:  true,
+			},
+			CreatedAt: time.Now(),
+		},
+	}
+	ids := []string{"test_product_id", "missing_product_id"}
+
+	listProductByIdsQueryMock := "SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products WHERE id = ANY\\(\\$1\\)"
+	mock.ExpectQuery(listProductByIdsQueryMock).WithArgs(pq.Array(ids)).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version"}).
+		AddRow(expectedData[0].ID, expectedData[0].StoreID, expectedData[0].Name, expectedData[0].Url, expectedData[0].Price, expectedData[0].Description, expectedData[0].Sku.String, expectedData[0].CreatedAt, nil, expectedData[0].Version))
+
+	ctx := context.Background()
+	result, err := repo.ListProductByIds(ctx, ids)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedData, result)
+}
+
+func TestPatchProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	patchProductQueryMock := "UPDATE products SET price = \\$3, sku = \\$4, updated_at = CURRENT_TIMESTAMP, version = version \\+ 1 WHERE id = \\$1 AND \\(\\$2 = 0 OR version = \\$2\\)"
+	mock.ExpectExec(patchProductQueryMock).
+		WithArgs("test_product_id", 2, float32(200), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	err = repo.PatchProduct(ctx, "test_product_id", 2, []Column{
+		{Name: "price", Value: float32(200)},
+		{Name: "sku", Value: nil},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectExec(patchProductQueryMock).
+		WithArgs("test_product_id", 2, float32(300), nil).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	err = repo.PatchProduct(ctx, "test_product_id", 2, []Column{
+		{Name: "price", Value: float32(300)},
+		{Name: "sku", Value: nil},
+	})
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	err = repo.PatchProduct(ctx, "test_product_id", 2, []Column{{Name: "id", Value: "other_id"}})
+	assert.Error(t, err)
+}
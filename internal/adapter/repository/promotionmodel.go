@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Promotion struct {
+	ID                string         `db:"id"`
+	Name              string         `db:"name"`
+	Code              sql.NullString `db:"code"`
+	DiscountType      string         `db:"discount_type"`
+	DiscountValue     float32        `db:"discount_value"`
+	Scope             string         `db:"scope"`
+	ScopeID           string         `db:"scope_id"`
+	StartsAt          time.Time      `db:"starts_at"`
+	EndsAt            sql.NullTime   `db:"ends_at"`
+	UsageLimit        int            `db:"usage_limit"`
+	UsageLimitPerUser int            `db:"usage_limit_per_user"`
+	Used              int            `db:"used"`
+	Stackable         bool           `db:"stackable"`
+	Priority          int            `db:"priority"`
+	Active            bool           `db:"active"`
+	CreatedAt         time.Time      `db:"created_at"`
+	UpdatedAt         sql.NullTime   `db:"updated_at"`
+}
+
+func (p *Promotion) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		p.ID,
+		p.Name,
+		p.Code,
+		p.DiscountType,
+		p.DiscountValue,
+		p.Scope,
+		p.ScopeID,
+		p.StartsAt,
+		p.EndsAt,
+		p.UsageLimit,
+		p.UsageLimitPerUser,
+		p.Stackable,
+		p.Priority,
+	}
+	return data
+}
+
+// PromotionScope list the targets of a basket, a promotion is in scope when
+// it targets one of them
+type PromotionScope struct {
+	StoreIDs   []string
+	Categories []string
+	ProductIDs []string
+}
+
+type PromotionRedemption struct {
+	ID          int64          `db:"id"`
+	PromotionID string         `db:"promotion_id"`
+	UserID      string         `db:"user_id"`
+	OrderID     sql.NullString `db:"order_id"`
+	CreatedAt   time.Time      `db:"created_at"`
+}
+
+func (p *PromotionRedemption) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		p.PromotionID,
+		p.UserID,
+		p.OrderID,
+	}
+	return data
+}
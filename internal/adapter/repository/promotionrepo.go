@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const promotionColumns = `id, name, code, discount_type, discount_value, scope, scope_id, starts_at, ends_at, usage_limit, usage_limit_per_user, used, stackable, priority, active, created_at, updated_at`
+
+const createPromotionQuery = `INSERT INTO promotions (id, name, code, discount_type, discount_value, scope, scope_id, starts_at, ends_at, usage_limit, usage_limit_per_user, stackable, priority, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, CURRENT_TIMESTAMP)`
+
+func (r *repo) CreatePromotion(ctx context.Context, req *Promotion) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createPromotionQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const getPromotionByIdQuery = `SELECT ` + promotionColumns + ` FROM promotions WHERE id = $1 LIMIT 1`
+
+func (r *repo) GetPromotionById(ctx context.Context, id string) (*Promotion, error) {
+	return r.getPromotion(ctx, getPromotionByIdQuery, id)
+}
+
+const getPromotionByCodeQuery = `SELECT ` + promotionColumns + ` FROM promotions WHERE code = $1 LIMIT 1`
+
+func (r *repo) GetPromotionByCode(ctx context.Context, code string) (*Promotion, error) {
+	return r.getPromotion(ctx, getPromotionByCodeQuery, code)
+}
+
+func (r *repo) getPromotion(ctx context.Context, query string, arg string) (*Promotion, error) {
+	var data Promotion
+	err := r.connFor(ctx).GetContext(
+		ctx,
+		&data,
+		query,
+		arg,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+const countPromotionsQuery = `SELECT count(*) FROM promotions`
+
+func (r *repo) CountPromotions(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.connFor(ctx).QueryRowContext(
+		ctx,
+		countPromotionsQuery,
+	).Scan(&count); err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+const listPromotionsQuery = `SELECT ` + promotionColumns + ` FROM promotions ORDER BY created_at DESC, id LIMIT $1 OFFSET $2`
+
+func (r *repo) ListPromotions(ctx context.Context, limit int, offset int) ([]*Promotion, error) {
+	var data []*Promotion
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listPromotionsQuery,
+		limit,
+		offset,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const listAutomaticPromotionsQuery = `SELECT ` + promotionColumns + ` FROM promotions WHERE code IS NULL AND active AND starts_at <= $1 AND (ends_at IS NULL OR ends_at > $1) AND (usage_limit = 0 OR used < usage_limit) AND ((scope = 'store' AND scope_id = ANY($2)) OR (scope = 'category' AND scope_id = ANY($3)) OR (scope = 'product' AND scope_id = ANY($4)))`
+
+// list promotions without code running at now and targeting the scope
+func (r *repo) ListAutomaticPromotions(ctx context.Context, now time.Time, scope *PromotionScope) ([]*Promotion, error) {
+	var data []*Promotion
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listAutomaticPromotionsQuery,
+		now,
+		pq.Array(scope.StoreIDs),
+		pq.Array(scope.Categories),
+		pq.Array(scope.ProductIDs),
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const deactivatePromotionQuery = `UPDATE promotions SET active = FALSE, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+func (r *repo) DeactivatePromotion(ctx context.Context, id string) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		deactivatePromotionQuery,
+		id,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const countPromotionRedemptionsByUserQuery = `SELECT count(*) FROM promotion_redemptions WHERE promotion_id = $1 AND user_id = $2`
+
+func (r *repo) CountPromotionRedemptionsByUser(ctx context.Context, promotionId string, userId string) (int64, error) {
+	var count int64
+	if err := r.connFor(ctx).QueryRowContext(
+		ctx,
+		countPromotionRedemptionsByUserQuery,
+		promotionId,
+		userId,
+	).Scan(&count); err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+const usePromotionQuery = `UPDATE promotions SET used = used + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND active AND (usage_limit = 0 OR used < usage_limit)`
+
+// count one usage of the promotion, false when it is no longer active or its
+// usage limit has been reached
+func (r *repo) UsePromotion(ctx context.Context, id string) (bool, error) {
+	result, err := r.connFor(ctx).ExecContext(
+		ctx,
+		usePromotionQuery,
+		id,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+const createPromotionRedemptionQuery = `INSERT INTO promotion_redemptions (promotion_id, user_id, order_id, created_at) VALUES ($1, $2, $3, CURRENT_TIMESTAMP)`
+
+func (r *repo) CreatePromotionRedemption(ctx context.Context, req *PromotionRedemption) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createPromotionRedemptionQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const releaseOrderRedemptionsQuery = `WITH released AS (DELETE FROM promotion_redemptions WHERE order_id = $1 RETURNING promotion_id) UPDATE promotions p SET used = GREATEST(p.used - r.count, 0), updated_at = CURRENT_TIMESTAMP FROM (SELECT promotion_id, count(*) AS count FROM released GROUP BY promotion_id) r WHERE p.id = r.promotion_id`
+
+// delete the redemptions of an order and give their usage back
+func (r *repo) ReleaseOrderRedemptions(ctx context.Context, orderId string) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		releaseOrderRedemptionsQuery,
+		orderId,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAutomaticPromotions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	now := time.Now()
+	scope := &PromotionScope{
+		StoreIDs:   []string{"test_store_id"},
+		Categories: []string{"coffee"},
+		ProductIDs: []string{"test_product_id"},
+	}
+	listAutomaticPromotionsQueryMock := "SELECT (.+) FROM promotions WHERE code IS NULL AND active AND starts_at <= \\$1 AND \\(ends_at IS NULL OR ends_at > \\$1\\) AND \\(usage_limit = 0 OR used < usage_limit\\)"
+	mock.ExpectQuery(listAutomaticPromotionsQueryMock).
+		WithArgs(now, pq.Array(scope.StoreIDs), pq.Array(scope.Categories), pq.Array(scope.ProductIDs)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "code", "discount_type", "discount_value", "scope", "scope_id", "starts_at", "ends_at", "stackable", "priority", "active"}).
+			AddRow("test_promotion_id", "Weekend", nil, "percentage", 10, "category", "coffee", now, nil, true, 1, true))
+
+	ctx := context.Background()
+	result, err := repo.ListAutomaticPromotions(ctx, now, scope)
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.False(t, result[0].Code.Valid)
+	assert.Equal(t, float32(10), result[0].DiscountValue)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPromotionByCodeNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	getPromotionByCodeQueryMock := "SELECT (.+) FROM promotions WHERE code = \\$1 LIMIT 1"
+	mock.ExpectQuery(getPromotionByCodeQueryMock).WithArgs("HEMAT10").WillReturnError(sql.ErrNoRows)
+
+	ctx := context.Background()
+	result, err := repo.GetPromotionByCode(ctx, "HEMAT10")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Refund struct {
+	ID        string         `db:"id"`
+	OrderID   string         `db:"order_id"`
+	PaymentID string         `db:"payment_id"`
+	Amount    float32        `db:"amount"`
+	Reason    string         `db:"reason"`
+	Restock   bool           `db:"restock"`
+	Status    string         `db:"status"`
+	Reference sql.NullString `db:"reference"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt sql.NullTime   `db:"updated_at"`
+}
+
+func (r *Refund) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		r.ID,
+		r.OrderID,
+		r.PaymentID,
+		r.Amount,
+		r.Reason,
+		r.Restock,
+		r.Status,
+	}
+	return data
+}
+
+type RefundItem struct {
+	RefundID    string  `db:"refund_id"`
+	OrderItemID int64   `db:"order_item_id"`
+	Quantity    int     `db:"quantity"`
+	Amount      float32 `db:"amount"`
+}
+
+func (r *RefundItem) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		r.RefundID,
+		r.OrderItemID,
+		r.Quantity,
+		r.Amount,
+	}
+	return data
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createRefundQuery = `INSERT INTO refunds (id, order_id, payment_id, amount, reason, restock, status, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)`
+
+func (r *repo) CreateRefund(ctx context.Context, req *Refund) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createRefundQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const createRefundItemQuery = `INSERT INTO refund_items (refund_id, order_item_id, quantity, amount) VALUES ($1, $2, $3, $4)`
+
+func (r *repo) CreateRefundItem(ctx context.Context, req *RefundItem) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createRefundItemQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const updateRefundStatusQuery = `UPDATE refunds SET status = $3, reference = $4, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND status = $2`
+
+// move the refund from one status to another, ErrVersionMismatch is
+// returned when the refund is no longer in the from status
+func (r *repo) UpdateRefundStatus(ctx context.Context, id string, from string, to string, reference sql.NullString) error {
+	result, err := r.connFor(ctx).ExecContext(
+		ctx,
+		updateRefundStatusQuery,
+		id,
+		from,
+		to,
+		reference,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
+
+const listRefundsByOrderQuery = `SELECT id, order_id, payment_id, amount, reason, restock, status, reference, created_at, updated_at FROM refunds WHERE order_id = $1 ORDER BY created_at, id`
+
+func (r *repo) ListRefundsByOrder(ctx context.Context, orderId string) ([]*Refund, error) {
+	var data []*Refund
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listRefundsByOrderQuery,
+		orderId,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const listRefundItemsByOrderQuery = `SELECT ri.refund_id, ri.order_item_id, ri.quantity, ri.amount FROM refund_items ri JOIN refunds r ON r.id = ri.refund_id WHERE r.order_id = $1 ORDER BY ri.refund_id, ri.order_item_id`
+
+// list the lines of every refund of an order
+func (r *repo) ListRefundItemsByOrder(ctx context.Context, orderId string) ([]*RefundItem, error) {
+	var data []*RefundItem
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listRefundItemsByOrderQuery,
+		orderId,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const addPaymentRefundQuery = `UPDATE payments SET refunded_amount = refunded_amount + $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND status = 'captured' AND refunded_amount + $2 <= captured_amount + 0.005`
+
+// add amount to the refunded amount of a captured payment, false when it
+// would exceed the captured amount. Negative amount give a failed refund back.
+func (r *repo) AddPaymentRefund(ctx context.Context, id string, amount float32) (bool, error) {
+	return r.execAffected(ctx, addPaymentRefundQuery, id, amount)
+}
+
+const addOrderItemRefundQuery = `UPDATE order_items SET refunded_quantity = refunded_quantity + $2, refunded_amount = refunded_amount + $3 WHERE id = $1 AND refunded_quantity + $2 <= quantity`
+
+// add quantity and amount to the refunded part of an order line, false when
+// more than the ordered quantity would be refunded
+func (r *repo) AddOrderItemRefund(ctx context.Context, id int64, quantity int, amount float32) (bool, error) {
+	return r.execAffected(ctx, addOrderItemRefundQuery, id, quantity, amount)
+}
+
+func (r *repo) execAffected(ctx context.Context, query string, args ...any) (bool, error) {
+	result, err := r.connFor(ctx).ExecContext(
+		ctx,
+		query,
+		args...,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -1,16 +1,41 @@
 package repository
 
-import "context"
+import (
+	"context"
+	"database/sql"
+	"time"
+)
 
 type StoreRepository interface {
 	StoreRepo
 	ProductRepo
+	AuditRepo
+	PriceRepo
+	PromotionRepo
+	CartRepo
+	OrderRepo
+	PaymentRepo
+	RefundRepo
+	TaxRepo
+	DeliveryRepo
+	PickupRepo
+	WithTx(ctx context.Context, fn func(ctx context.Context, repo StoreRepository) error) error
 }
 
 type StoreRepo interface {
 	CreateStore(ctx context.Context, req *Store) (*Store, error)
 	GetStoreById(ctx context.Context, id string) (*Store, error)
 	UpdateStore(ctx context.Context, req *Store) error
+	PatchStore(ctx context.Context, id string, version int, columns []Column) error
+	GetStoreByUrl(ctx context.Context, url string) (*Store, error)
+	ListStoreUrls(ctx context.Context, base string, pattern string) ([]*Slug, error)
+	GetStoreUrlHistory(ctx context.Context, url string) (*Slug, error)
+	CreateStoreUrlHistory(ctx context.Context, storeId string, url string) error
+	DeleteStoreUrlHistory(ctx context.Context, storeId string, url string) error
+	ListStoreByIds(ctx context.Context, ids []string) ([]*Store, error)
+	ListNearbyStores(ctx context.Context, search *NearbySearch) ([]*NearbyStore, error)
+	CountStores(ctx context.Context, city string, province string) (int64, error)
+	ListStores(ctx context.Context, city string, province string, limit int, offset int) ([]*Store, error)
 }
 
 type ProductRepo interface {
@@ -21,6 +46,117 @@ type ProductRepo interface {
 	CreateProduct(ctx context.Context, req *Product) (*Product, error)
 	GetProductById(ctx context.Context, id string) (*Product, error)
 	GetProductByUrl(ctx context.Context, url string) (*Product, error)
+	GetProductBySku(ctx context.Context, sku string) (*Product, error)
+	ListProductByIds(ctx context.Context, ids []string) ([]*Product, error)
 	UpdateProduct(ctx context.Context, req *Product) error
+	PatchProduct(ctx context.Context, id string, version int, columns []Column) error
 	DeleteProduct(ctx context.Context, id string) error
+	ListProductUrls(ctx context.Context, base string, pattern string) ([]*Slug, error)
+	GetProductUrlHistory(ctx context.Context, url string) (*Slug, error)
+	CreateProductUrlHistory(ctx context.Context, productId string, url string) error
+	DeleteProductUrlHistory(ctx context.Context, productId string, url string) error
+}
+
+type AuditRepo interface {
+	CreateAuditLog(ctx context.Context, req *AuditLog) error
+	CountAuditLogs(ctx context.Context, entity string, entityId string) (int64, error)
+	ListAuditLogs(ctx context.Context, entity string, entityId string, limit int, offset int) ([]*AuditLog, error)
+}
+
+type PriceRepo interface {
+	CreatePriceHistory(ctx context.Context, req *PriceHistory) error
+	CountPriceHistories(ctx context.Context, productId string) (int64, error)
+	ListPriceHistories(ctx context.Context, productId string, limit int, offset int) ([]*PriceHistory, error)
+	CreatePriceSchedule(ctx context.Context, req *PriceSchedule) error
+	GetPriceScheduleById(ctx context.Context, id string) (*PriceSchedule, error)
+	ListOpenPriceSchedules(ctx context.Context, productId string) ([]*PriceSchedule, error)
+	ListStartedPriceSchedules(ctx context.Context, productIds []string, now time.Time) ([]*PriceSchedule, error)
+	ListDuePriceSchedules(ctx context.Context, now time.Time, limit int) ([]*PriceSchedule, error)
+	UpdatePriceScheduleStatus(ctx context.Context, id string, status string, previousPrice sql.NullFloat64) error
+}
+
+type PromotionRepo interface {
+	CreatePromotion(ctx context.Context, req *Promotion) error
+	GetPromotionById(ctx context.Context, id string) (*Promotion, error)
+	GetPromotionByCode(ctx context.Context, code string) (*Promotion, error)
+	CountPromotions(ctx context.Context) (int64, error)
+	ListPromotions(ctx context.Context, limit int, offset int) ([]*Promotion, error)
+	ListAutomaticPromotions(ctx context.Context, now time.Time, scope *PromotionScope) ([]*Promotion, error)
+	DeactivatePromotion(ctx context.Context, id string) error
+	CountPromotionRedemptionsByUser(ctx context.Context, promotionId string, userId string) (int64, error)
+	UsePromotion(ctx context.Context, id string) (bool, error)
+	CreatePromotionRedemption(ctx context.Context, req *PromotionRedemption) error
+	ReleaseOrderRedemptions(ctx context.Context, orderId string) error
+}
+
+type CartRepo interface {
+	CreateCart(ctx context.Context, req *Cart) error
+	GetCartByToken(ctx context.Context, token string) (*Cart, error)
+	GetCartByUserId(ctx context.Context, userId string) (*Cart, error)
+	TouchCart(ctx context.Context, id string, expiresAt time.Time) error
+	ClaimCart(ctx context.Context, id string, userId string, expiresAt time.Time) error
+	DeleteCart(ctx context.Context, id string) error
+	DeleteExpiredCarts(ctx context.Context, now time.Time, limit int) (int64, error)
+	ListCartItems(ctx context.Context, cartId string) ([]*CartItem, error)
+	UpsertCartItem(ctx context.Context, req *CartItem) error
+	DeleteCartItem(ctx context.Context, cartId string, productId string) error
+}
+
+type OrderRepo interface {
+	CreateOrder(ctx context.Context, req *Order) error
+	CreateOrderItem(ctx context.Context, req *OrderItem) error
+	GetOrderById(ctx context.Context, id string) (*Order, error)
+	ListOrderItems(ctx context.Context, orderId string) ([]*OrderItem, error)
+	CountOrdersByUser(ctx context.Context, userId string, status string) (int64, error)
+	ListOrdersByUser(ctx context.Context, userId string, status string, limit int, offset int) ([]*Order, error)
+	CountOrdersByStore(ctx context.Context, storeId string, status string) (int64, error)
+	ListOrdersByStore(ctx context.Context, storeId string, status string, limit int, offset int) ([]*Order, error)
+	UpdateOrderStatus(ctx context.Context, id string, from string, to string) error
+	ListExpiredOrders(ctx context.Context, now time.Time, limit int) ([]*Order, error)
+	ReserveStock(ctx context.Context, productId string, quantity int) (bool, error)
+	ReleaseStock(ctx context.Context, productId string, quantity int) error
+}
+
+type PaymentRepo interface {
+	CreatePayment(ctx context.Context, req *Payment) error
+	GetPaymentById(ctx context.Context, id string) (*Payment, error)
+	GetPaymentByReference(ctx context.Context, provider string, reference string) (*Payment, error)
+	GetOpenPaymentByOrder(ctx context.Context, orderId string) (*Payment, error)
+	ListPaymentsByOrder(ctx context.Context, orderId string) ([]*Payment, error)
+	ListOpenPayments(ctx context.Context, before time.Time, limit int) ([]*Payment, error)
+	UpdatePaymentStatus(ctx context.Context, id string, from string, to string, captured float32) error
+	CreatePaymentEvent(ctx context.Context, req *PaymentEvent) (bool, error)
+}
+
+type RefundRepo interface {
+	CreateRefund(ctx context.Context, req *Refund) error
+	CreateRefundItem(ctx context.Context, req *RefundItem) error
+	UpdateRefundStatus(ctx context.Context, id string, from string, to string, reference sql.NullString) error
+	ListRefundsByOrder(ctx context.Context, orderId string) ([]*Refund, error)
+	ListRefundItemsByOrder(ctx context.Context, orderId string) ([]*RefundItem, error)
+	AddPaymentRefund(ctx context.Context, id string, amount float32) (bool, error)
+	AddOrderItemRefund(ctx context.Context, id int64, quantity int, amount float32) (bool, error)
+}
+
+type TaxRepo interface {
+	CreateTaxRule(ctx context.Context, req *TaxRule) error
+	ListTaxRules(ctx context.Context, storeId string) ([]*TaxRule, error)
+	ListTaxRulesForStore(ctx context.Context, storeId string, region string) ([]*TaxRule, error)
+	DeleteTaxRule(ctx context.Context, id string) (bool, error)
+}
+
+type DeliveryRepo interface {
+	CreateDeliveryZone(ctx context.Context, req *DeliveryZone) error
+	ListDeliveryZones(ctx context.Context, storeId string) ([]*DeliveryZone, error)
+	DeleteDeliveryZone(ctx context.Context, storeId string, id string) (bool, error)
+}
+
+type PickupRepo interface {
+	UpsertPickupSettings(ctx context.Context, req *PickupSettings) error
+	GetPickupSettings(ctx context.Context, storeId string) (*PickupSettings, error)
+	ListPickupSlots(ctx context.Context, storeId string, from time.Time, to time.Time) ([]*PickupSlot, error)
+	ReservePickupSlot(ctx context.Context, storeId string, startsAt time.Time, capacity int) (bool, error)
+	CreatePickupBooking(ctx context.Context, req *PickupBooking) error
+	GetPickupBookingByOrder(ctx context.Context, orderId string) (*PickupBooking, error)
+	ReleaseOrderPickup(ctx context.Context, orderId string) error
 }
@@ -0,0 +1,10 @@
+package repository
+
+import "time"
+
+// Slug is an url used by a store or product, either current or previous
+type Slug struct {
+	Url       string    `db:"url"`
+	OwnerID   string    `db:"owner_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -6,15 +6,25 @@ import (
 )
 
 type Store struct {
-	ID                   string       `db:"id"`
-	Name                 string       `db:"name"`
-	Url                  string       `db:"url"`
-	Address              string       `db:"address"`
-	Phone                string       `db:"phone"`
-	OperationalTimeStart int          `db:"operational_time_start"`
-	OperationalTimeEnd   int          `db:"operational_time_end"`
-	CreatedAt            time.Time    `db:"created_at"`
-	UpdatedAt            sql.NullTime `db:"updated_at"`
+	ID                   string          `db:"id"`
+	Name                 string          `db:"name"`
+	Url                  string          `db:"url"`
+	Address              string          `db:"address"`
+	Phone                string          `db:"phone"`
+	OperationalTimeStart int             `db:"operational_time_start"`
+	OperationalTimeEnd   int             `db:"operational_time_end"`
+	CreatedAt            time.Time       `db:"created_at"`
+	UpdatedAt            sql.NullTime    `db:"updated_at"`
+	Version              int             `db:"version"`
+	TaxRegion            sql.NullString  `db:"tax_region"`
+	Latitude             sql.NullFloat64 `db:"latitude"`
+	Longitude            sql.NullFloat64 `db:"longitude"`
+	AddressStreet        string          `db:"address_street"`
+	AddressDistrict      string          `db:"address_district"`
+	AddressCity          string          `db:"address_city"`
+	AddressProvince      string          `db:"address_province"`
+	AddressPostalCode    string          `db:"address_postal_code"`
+	AddressCountry       string          `db:"address_country"`
 }
 
 func (s *Store) RowDataIndex() []interface{} {
@@ -28,6 +38,16 @@ func (s *Store) RowDataIndex() []interface{} {
 		s.OperationalTimeEnd,
 		s.CreatedAt,
 		s.UpdatedAt,
+		s.Version,
+		s.TaxRegion,
+		s.Latitude,
+		s.Longitude,
+		s.AddressStreet,
+		s.AddressDistrict,
+		s.AddressCity,
+		s.AddressProvince,
+		s.AddressPostalCode,
+		s.AddressCountry,
 	}
 	return data
 }
@@ -44,6 +64,15 @@ func (s *Store) RowDataCreate() []interface{} {
 		s.Phone,
 		s.OperationalTimeStart,
 		s.OperationalTimeEnd,
+		s.TaxRegion,
+		s.Latitude,
+		s.Longitude,
+		s.AddressStreet,
+		s.AddressDistrict,
+		s.AddressCity,
+		s.AddressProvince,
+		s.AddressPostalCode,
+		s.AddressCountry,
 	}
 	return data
 }
@@ -57,6 +86,34 @@ func (s *Store) RowDataUpdate() []interface{} {
 		s.Phone,
 		s.OperationalTimeStart,
 		s.OperationalTimeEnd,
+		s.TaxRegion,
+		s.Latitude,
+		s.Longitude,
+		s.AddressStreet,
+		s.AddressDistrict,
+		s.AddressCity,
+		s.AddressProvince,
+		s.AddressPostalCode,
+		s.AddressCountry,
+		s.Version,
 	}
 	return data
 }
+
+// NearbyStore is a store with its distance in km from the searched point
+type NearbyStore struct {
+	Store
+	DistanceKm float64 `db:"distance_km"`
+}
+
+// NearbySearch find the stores within RadiusKm of Lat and Lng. LatDelta and
+// LngDelta are the half sides in degrees of the box enclosing the circle,
+// the box is matched on the location index before the exact distance.
+type NearbySearch struct {
+	Lat      float64
+	Lng      float64
+	RadiusKm float64
+	LatDelta float64
+	LngDelta float64
+	Limit    int
+}
@@ -4,13 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
-const createStoreQuery = `INSERT INTO stores (name, url, address, phone, operational_time_start, operational_time_end, created_at) VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP) RETURNING id`
+const storeColumns = `id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at, version, tax_region, latitude, longitude, address_street, address_district, address_city, address_province, address_postal_code, address_country`
+
+const createStoreQuery = `INSERT INTO stores (name, url, address, phone, operational_time_start, operational_time_end, tax_region, latitude, longitude, address_street, address_district, address_city, address_province, address_postal_code, address_country, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, CURRENT_TIMESTAMP) RETURNING id`
 
 func (r *repo) CreateStore(ctx context.Context, req *Store) (*Store, error) {
 	var id string
-	if err := r.conn.QueryRowContext(
+	if err := r.connFor(ctx).QueryRowContext(
 		ctx,
 		createStoreQuery,
 		req.RowDataCreate()...,
@@ -26,16 +30,26 @@ func (r *repo) CreateStore(ctx context.Context, req *Store) (*Store, error) {
 		Phone:                req.Phone,
 		OperationalTimeStart: req.OperationalTimeStart,
 		OperationalTimeEnd:   req.OperationalTimeEnd,
+		TaxRegion:            req.TaxRegion,
+		Latitude:             req.Latitude,
+		Longitude:            req.Longitude,
+		AddressStreet:        req.AddressStreet,
+		AddressDistrict:      req.AddressDistrict,
+		AddressCity:          req.AddressCity,
+		AddressProvince:      req.AddressProvince,
+		AddressPostalCode:    req.AddressPostalCode,
+		AddressCountry:       req.AddressCountry,
 		CreatedAt:            time.Now().UTC(),
+		Version:              1,
 		UpdatedAt:            sql.NullTime{},
 	}, nil
 }
 
-const getStoreByIdQuery = `SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at FROM stores WHERE id = $1 LIMIT 1`
+const getStoreByIdQuery = `SELECT ` + storeColumns + ` FROM stores WHERE id = $1 LIMIT 1`
 
 func (r *repo) GetStoreById(ctx context.Context, id string) (*Store, error) {
 	var data Store
-	err := r.conn.GetContext(
+	err := r.connFor(ctx).GetContext(
 		ctx,
 		&data,
 		getStoreByIdQuery,
@@ -52,16 +66,211 @@ func (r *repo) GetStoreById(ctx context.Context, id string) (*Store, error) {
 	return &data, nil
 }
 
-const updateStoreQuery = `UPDATE stores SET name = $2, url = $3, address = $4, phone = $5, operational_time_start = $6, operational_time_end = $7, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+const updateStoreQuery = `UPDATE stores SET name = $2, url = $3, address = $4, phone = $5, operational_time_start = $6, operational_time_end = $7, tax_region = $8, latitude = $9, longitude = $10, address_street = $11, address_district = $12, address_city = $13, address_province = $14, address_postal_code = $15, address_country = $16, updated_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND ($17 = 0 OR version = $17)`
 
+// update the store, zero Version skip the version check
 func (r *repo) UpdateStore(ctx context.Context, req *Store) error {
-	if _, err := r.conn.ExecContext(
+	result, err := r.connFor(ctx).ExecContext(
 		ctx,
 		updateStoreQuery,
 		req.RowDataUpdate()...,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkRowsAffected(result)
+}
+
+const getStoreByUrlQuery = `SELECT ` + storeColumns + ` FROM stores WHERE url = $1 LIMIT 1`
+
+func (r *repo) GetStoreByUrl(ctx context.Context, url string) (*Store, error) {
+	var data Store
+	err := r.connFor(ctx).GetContext(
+		ctx,
+		&data,
+		getStoreByUrlQuery,
+		url,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// haversine distance in km from ($1, $2) with the mean earth radius of
+// domain.EarthRadiusKm
+const storeDistanceColumn = `2 * 6371 * asin(least(1, sqrt(power(sin(radians(latitude - $1::float8) / 2), 2) + cos(radians($1::float8)) * cos(radians(latitude)) * power(sin(radians(longitude - $2::float8) / 2), 2)))) AS distance_km`
+
+const listNearbyStoresQuery = `SELECT * FROM (SELECT ` + storeColumns + `, ` + storeDistanceColumn + ` FROM stores WHERE latitude BETWEEN $1::float8 - $4::float8 AND $1::float8 + $4::float8 AND longitude BETWEEN $2::float8 - $5::float8 AND $2::float8 + $5::float8) nearby WHERE distance_km <= $3 ORDER BY distance_km, id LIMIT $6`
+
+// list the stores within the radius of the point, nearest first. Store
+// without location is never found.
+func (r *repo) ListNearbyStores(ctx context.Context, search *NearbySearch) ([]*NearbyStore, error) {
+	var data []*NearbyStore
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listNearbyStoresQuery,
+		search.Lat,
+		search.Lng,
+		search.RadiusKm,
+		search.LatDelta,
+		search.LngDelta,
+		search.Limit,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const listStoreUrlsQuery = `SELECT url, id AS owner_id, created_at FROM stores WHERE url = $1 OR url LIKE $2 UNION ALL SELECT url, store_id AS owner_id, created_at FROM store_url_histories WHERE url = $1 OR url LIKE $2`
+
+// list current and previous store urls equal to base or matching the LIKE pattern
+func (r *repo) ListStoreUrls(ctx context.Context, base string, pattern string) ([]*Slug, error) {
+	var data []*Slug
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listStoreUrlsQuery,
+		base,
+		pattern,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const getStoreUrlHistoryQuery = `SELECT url, store_id AS owner_id, created_at FROM store_url_histories WHERE url = $1 LIMIT 1`
+
+func (r *repo) GetStoreUrlHistory(ctx context.Context, url string) (*Slug, error) {
+	var data Slug
+	err := r.connFor(ctx).GetContext(
+		ctx,
+		&data,
+		getStoreUrlHistoryQuery,
+		url,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+const createStoreUrlHistoryQuery = `INSERT INTO store_url_histories (url, store_id, created_at) VALUES ($1, $2, CURRENT_TIMESTAMP) ON CONFLICT (url) DO NOTHING`
+
+func (r *repo) CreateStoreUrlHistory(ctx context.Context, storeId string, url string) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createStoreUrlHistoryQuery,
+		url,
+		storeId,
 	); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+const deleteStoreUrlHistoryQuery = `DELETE FROM store_url_histories WHERE url = $1 AND store_id = $2`
+
+func (r *repo) DeleteStoreUrlHistory(ctx context.Context, storeId string, url string) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		deleteStoreUrlHistoryQuery,
+		url,
+		storeId,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const listStoreByIdsQuery = `SELECT ` + storeColumns + ` FROM stores WHERE id = ANY($1)`
+
+// list stores having one of the ids, missing ids are skipped and the order
+// is not guaranteed
+func (r *repo) ListStoreByIds(ctx context.Context, ids []string) ([]*Store, error) {
+	var data []*Store
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listStoreByIdsQuery,
+		pq.Array(ids),
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const countStoresQuery = `SELECT COUNT(id) FROM stores WHERE ($1 = '' OR lower(address_city) = lower($1)) AND ($2 = '' OR lower(address_province) = lower($2))`
+
+// count stores of the city and province, empty filter match every store
+func (r *repo) CountStores(ctx context.Context, city string, province string) (int64, error) {
+	var count int64
+	if err := r.connFor(ctx).QueryRowContext(
+		ctx,
+		countStoresQuery,
+		city,
+		province,
+	).Scan(&count); err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+const listStoresQuery = `SELECT ` + storeColumns + ` FROM stores WHERE ($1 = '' OR lower(address_city) = lower($1)) AND ($2 = '' OR lower(address_province) = lower($2)) ORDER BY name, id LIMIT $3 OFFSET $4`
+
+// list stores of the city and province by name, city and province are
+// matched regardless of case and empty filter match every store
+func (r *repo) ListStores(ctx context.Context, city string, province string, limit int, offset int) ([]*Store, error) {
+	var data []*Store
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listStoresQuery,
+		city,
+		province,
+		limit,
+		offset,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// update only the given columns of the store, zero version skip the version
+// check
+func (r *repo) PatchStore(ctx context.Context, id string, version int, columns []Column) error {
+	query, params, err := buildPatchQuery("stores", storePatchColumns, id, version, columns)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.connFor(ctx).ExecContext(
+		ctx,
+		query,
+		params...,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkRowsAffected(result)
+}
@@ -33,9 +33,9 @@ func TestCreateStore(t *testing.T) {
 		},
 	}
 
-	createStoreQueryMock := "INSERT INTO stores \\(name, url, address, phone, operational_time_start, operational_time_end, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, CURRENT_TIMESTAMP\\) RETURNING id"
+	createStoreQueryMock := "INSERT INTO stores \\(name, url, address, phone, operational_time_start, operational_time_end, tax_region, latitude, longitude, address_street, address_district, address_city, address_province, address_postal_code, address_country, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8, \\$9, \\$10, \\$11, \\$12, \\$13, \\$14, \\$15, CURRENT_TIMESTAMP\\) RETURNING id"
 	mock.ExpectQuery(createStoreQueryMock).
-		WithArgs(expectedData.Name, expectedData.Url, expectedData.Address, expectedData.Phone, expectedData.OperationalTimeStart, expectedData.OperationalTimeEnd).
+		WithArgs(expectedData.Name, expectedData.Url, expectedData.Address, expectedData.Phone, expectedData.OperationalTimeStart, expectedData.OperationalTimeEnd, expectedData.TaxRegion, expectedData.Latitude, expectedData.Longitude, expectedData.AddressStreet, expectedData.AddressDistrict, expectedData.AddressCity, expectedData.AddressProvince, expectedData.AddressPostalCode, expectedData.AddressCountry).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(expectedData.ID))
 
 	ctx := context.Background()
@@ -67,9 +67,9 @@ func TestGetStoreById(t *testing.T) {
 		},
 	}
 
-	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at FROM stores WHERE id = \\$1 LIMIT 1"
-	rows := sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at"}).
-		AddRow(expectedData.ID, expectedData.Name, expectedData.Url, expectedData.Address, expectedData.Phone, expectedData.OperationalTimeStart, expectedData.OperationalTimeEnd, expectedData.CreatedAt, nil)
+	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at, version, tax_region, latitude, longitude, address_street, address_district, address_city, address_province, address_postal_code, address_country FROM stores WHERE id = \\$1 LIMIT 1"
+	rows := sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at", "version"}).
+		AddRow(expectedData.ID, expectedData.Name, expectedData.Url, expectedData.Address, expectedData.Phone, expectedData.OperationalTimeStart, expectedData.OperationalTimeEnd, expectedData.CreatedAt, nil, expectedData.Version)
 	mock.ExpectQuery(getStoreByIdQueryMock).WithArgs(expectedData.ID).WillReturnRows(rows)
 
 	ctx := context.Background()
@@ -101,12 +101,114 @@ func TestUpdateStore(t *testing.T) {
 		},
 	}
 
-	updateStoreQueryMock := "UPDATE stores SET name = \\$2, url = \\$3, address = \\$4, phone = \\$5, operational_time_start = \\$6, operational_time_end = \\$7, updated_at = CURRENT_TIMESTAMP WHERE id = \\$1"
+	updateStoreQueryMock := "UPDATE stores SET name = \\$2, url = \\$3, address = \\$4, phone = \\$5, operational_time_start = \\$6, operational_time_end = \\$7, tax_region = \\$8, latitude = \\$9, longitude = \\$10, address_street = \\$11, address_district = \\$12, address_city = \\$13, address_province = \\$14, address_postal_code = \\$15, address_country = \\$16, updated_at = CURRENT_TIMESTAMP, version = version \\+ 1 WHERE id = \\$1 AND \\(\\$17 = 0 OR version = \\$17\\)"
 	mock.ExpectExec(updateStoreQueryMock).
-		WithArgs(expectedData.ID, expectedData.Name, expectedData.Url, expectedData.Address, expectedData.Phone, expectedData.OperationalTimeStart, expectedData.OperationalTimeEnd).
+		WithArgs(expectedData.ID, expectedData.Name, expectedData.Url, expectedData.Address, expectedData.Phone, expectedData.OperationalTimeStart, expectedData.OperationalTimeEnd, expectedData.TaxRegion, expectedData.Latitude, expectedData.Longitude, expectedData.AddressStreet, expectedData.AddressDistrict, expectedData.AddressCity, expectedData.AddressProvince, expectedData.AddressPostalCode, expectedData.AddressCountry, expectedData.Version).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	ctx := context.Background()
 	err = repo.UpdateStore(ctx, expectedData)
 	assert.NoError(t, err)
 }
+
+func TestGetStoreByUrlNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	getStoreByUrlQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at, version, tax_region, latitude, longitude, address_street, address_district, address_city, address_province, address_postal_code, address_country FROM stores WHERE url = \\$1 LIMIT 1"
+	mock.ExpectQuery(getStoreByUrlQueryMock).WithArgs("test_store_url").WillReturnError(sql.ErrNoRows)
+
+	ctx := context.Background()
+	result, err := repo.GetStoreByUrl(ctx, "test_store_url")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestListStoreUrls(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	now := time.Now()
+	expectedData := []*Slug{
+		{Url: "kopi", OwnerID: "test_store_id", CreatedAt: now},
+		{Url: "kopi-2", OwnerID: "other_store_id", CreatedAt: now},
+	}
+
+	listStoreUrlsQueryMock := "SELECT url, id AS owner_id, created_at FROM stores WHERE url = \\$1 OR url LIKE \\$2 UNION ALL"
+	mock.ExpectQuery(listStoreUrlsQueryMock).WithArgs("kopi", "kopi-%").
+		WillReturnRows(sqlmock.NewRows([]string{"url", "owner_id", "created_at"}).
+			AddRow(expectedData[0].Url, expectedData[0].OwnerID, now).
+			AddRow(expectedData[1].Url, expectedData[1].OwnerID, now))
+
+	ctx := context.Background()
+	result, err := repo.ListStoreUrls(ctx, "kopi", "kopi-%")
+	assert.NoError(t, err)
+	assert.Equal(t, expectedData, result)
+}
+
+func TestListNearbyStores(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	now := time.Now()
+	listNearbyStoresQueryMock := "SELECT \\* FROM \\(SELECT (.+), 2 \\* 6371 \\* asin\\((.+)\\) AS distance_km FROM stores WHERE latitude BETWEEN (.+) AND longitude BETWEEN (.+)\\) nearby WHERE distance_km <= \\$3 ORDER BY distance_km, id LIMIT \\$6"
+	mock.ExpectQuery(listNearbyStoresQueryMock).WithArgs(-6.1754, 106.8272, 5.0, 0.045, 0.045, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at", "version", "tax_region", "latitude", "longitude", "distance_km"}).
+			AddRow("test_store_id", "Kopi Kenangan", "kopi-kenangan", "Jakarta", "+6281234567890", 8, 22, now, nil, 1, nil, -6.1950, 106.8230, 2.2312))
+
+	stores, err := repo.ListNearbyStores(context.Background(), &NearbySearch{
+		Lat:      -6.1754,
+		Lng:      106.8272,
+		RadiusKm: 5,
+		LatDelta: 0.045,
+		LngDelta: 0.045,
+		Limit:    10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, stores, 1)
+	assert.Equal(t, "test_store_id", stores[0].ID)
+	assert.Equal(t, -6.1950, stores[0].Latitude.Float64)
+	assert.Equal(t, 2.2312, stores[0].DistanceKm)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListStores(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	now := time.Now()
+	listStoresQueryMock := "SELECT (.+) FROM stores WHERE \\(\\$1 = '' OR lower\\(address_city\\) = lower\\(\\$1\\)\\) AND \\(\\$2 = '' OR lower\\(address_province\\) = lower\\(\\$2\\)\\) ORDER BY name, id LIMIT \\$3 OFFSET \\$4"
+	mock.ExpectQuery(listStoresQueryMock).WithArgs("bandung", "", 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at", "version", "address_street", "address_city", "address_province"}).
+			AddRow("test_store_id", "Kopi Kenangan", "kopi-kenangan", "Jl. Braga 10, Bandung, Jawa Barat", "+6281234567890", 8, 22, now, nil, 1, "Jl. Braga 10", "Bandung", "Jawa Barat"))
+
+	stores, err := repo.ListStores(context.Background(), "bandung", "", 10, 20)
+	assert.NoError(t, err)
+	assert.Len(t, stores, 1)
+	assert.Equal(t, "Bandung", stores[0].AddressCity)
+	assert.Equal(t, "Jawa Barat", stores[0].AddressProvince)
+
+	countStoresQueryMock := "SELECT COUNT\\(id\\) FROM stores WHERE (.+) lower\\(address_city\\) = lower\\(\\$1\\)"
+	mock.ExpectQuery(countStoresQueryMock).WithArgs("bandung", "").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+
+	count, err := repo.CountStores(context.Background(), "bandung", "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(21), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
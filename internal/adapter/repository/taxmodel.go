@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type TaxRule struct {
+	ID        string         `db:"id"`
+	Name      string         `db:"name"`
+	StoreID   sql.NullString `db:"store_id"`
+	Region    sql.NullString `db:"region"`
+	Category  sql.NullString `db:"category"`
+	Rate      float32        `db:"rate"`
+	Inclusive bool           `db:"inclusive"`
+	Rounding  string         `db:"rounding"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func (t *TaxRule) RowDataCreate() []interface{} {
+	var data = []interface{}{
+		t.ID,
+		t.Name,
+		t.StoreID,
+		t.Region,
+		t.Category,
+		t.Rate,
+		t.Inclusive,
+		t.Rounding,
+	}
+	return data
+}
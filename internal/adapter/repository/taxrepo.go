@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+)
+
+const taxRuleColumns = `id, name, store_id, region, category, rate, inclusive, rounding, created_at`
+
+const createTaxRuleQuery = `INSERT INTO tax_rules (id, name, store_id, region, category, rate, inclusive, rounding, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP)`
+
+func (r *repo) CreateTaxRule(ctx context.Context, req *TaxRule) error {
+	if _, err := r.connFor(ctx).ExecContext(
+		ctx,
+		createTaxRuleQuery,
+		req.RowDataCreate()...,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const listTaxRulesQuery = `SELECT ` + taxRuleColumns + ` FROM tax_rules WHERE ($1 = '' OR store_id::text = $1) ORDER BY created_at, id`
+
+// list every tax rule, only the rules of the store when storeId is not
+// empty
+func (r *repo) ListTaxRules(ctx context.Context, storeId string) ([]*TaxRule, error) {
+	var data []*TaxRule
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listTaxRulesQuery,
+		storeId,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const listTaxRulesForStoreQuery = `SELECT ` + taxRuleColumns + ` FROM tax_rules WHERE store_id = $1 OR (store_id IS NULL AND (region IS NULL OR region = $2)) ORDER BY created_at, id`
+
+// list the rules which may tax the store, rules of the store, of its region
+// and the default rules, oldest first
+func (r *repo) ListTaxRulesForStore(ctx context.Context, storeId string, region string) ([]*TaxRule, error) {
+	var data []*TaxRule
+	if err := r.connFor(ctx).SelectContext(
+		ctx,
+		&data,
+		listTaxRulesForStoreQuery,
+		storeId,
+		region,
+	); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+const deleteTaxRuleQuery = `DELETE FROM tax_rules WHERE id = $1`
+
+// delete the rule, false when it does not exist
+func (r *repo) DeleteTaxRule(ctx context.Context, id string) (bool, error) {
+	return r.execAffected(ctx, deleteTaxRuleQuery, id)
+}
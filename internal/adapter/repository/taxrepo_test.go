@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTaxRulesForStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	now := time.Now()
+	listTaxRulesForStoreQueryMock := "SELECT id, name, store_id, region, category, rate, inclusive, rounding, created_at FROM tax_rules WHERE store_id = \\$1 OR \\(store_id IS NULL AND \\(region IS NULL OR region = \\$2\\)\\) ORDER BY created_at, id"
+	mock.ExpectQuery(listTaxRulesForStoreQueryMock).WithArgs("test_store_id", "ID-JK").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "store_id", "region", "category", "rate", "inclusive", "rounding", "created_at"}).
+			AddRow("test_rule_id", "VAT", nil, "ID-JK", nil, 11, false, "line", now).
+			AddRow("test_food_rule_id", "Food", "test_store_id", nil, "food", 10, true, "total", now))
+
+	rules, err := repo.ListTaxRulesForStore(context.Background(), "test_store_id", "ID-JK")
+	assert.NoError(t, err)
+	assert.Len(t, rules, 2)
+	assert.Equal(t, "ID-JK", rules[0].Region.String)
+	assert.False(t, rules[0].StoreID.Valid)
+	assert.Equal(t, "food", rules[1].Category.String)
+	assert.True(t, rules[1].Inclusive)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteTaxRuleNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	mock.ExpectExec("DELETE FROM tax_rules WHERE id = \\$1").WithArgs("test_rule_id").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	deleted, err := repo.DeleteTaxRule(context.Background(), "test_rule_id")
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	"github.com/ijlik/store-app/pkg/database"
+	"github.com/jmoiron/sqlx"
+)
+
+type dbKey struct{}
+
+// ContextWithDB attach db to ctx so every repository called with it run on db
+// instead of the pool it was created with
+func ContextWithDB(ctx context.Context, db *sqlx.DB) context.Context {
+	return context.WithValue(ctx, dbKey{}, db)
+}
+
+// TenantContext return a function attaching the connection pool of the
+// tenant set on the context with ContextWithDB, context without tenant get
+// the default pool. It is run once per request and per job run so the pool is
+// not looked up again on every query.
+func TenantContext(registry *database.Registry) func(ctx context.Context) (context.Context, error) {
+	return func(ctx context.Context) (context.Context, error) {
+		db, err := registry.Get(ctx, pkgcontext.GetString(ctx, pkgcontext.TENANT_ID))
+		if err != nil {
+			return nil, err
+		}
+
+		return ContextWithDB(ctx, db), nil
+	}
+}
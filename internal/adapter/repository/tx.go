@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const maxTxAttempts = 3
+
+type txKey struct{}
+
+// WithTx run fn inside a serializable transaction, commit when fn return nil
+// and rollback otherwise. Transaction failing on serialization or deadlock
+// is retried up to maxTxAttempts. The transaction is started on the pool
+// attached with ContextWithDB when there is one. The context given to fn
+// carry the transaction, so every repository called with it run inside the
+// transaction. Calling WithTx on the repository given to fn, or with a
+// context carrying a transaction, join the running transaction.
+func (r *repo) WithTx(ctx context.Context, fn func(ctx context.Context, repo StoreRepository) error) error {
+	if tx, ok := r.conn.(*sqlx.Tx); ok {
+		return fn(ContextWithTx(ctx, tx), r)
+	}
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx, &repo{conn: tx, db: r.dbFor(ctx)})
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = r.runTx(ctx, fn)
+		if err == nil || !isSerializationFailure(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt*attempt) * 10 * time.Millisecond):
+		}
+	}
+
+	return err
+}
+
+func (r *repo) runTx(ctx context.Context, fn func(ctx context.Context, repo StoreRepository) error) (err error) {
+	db := r.dbFor(ctx)
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = fn(ContextWithTx(ctx, tx), &repo{conn: tx, db: db}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ContextWithTx attach tx to ctx so WithTx join it instead of starting a new
+// transaction
+func ContextWithTx(ctx context.Context, tx *sqlx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// connection to run a query on, the transaction carried by ctx when there
+// is one, then the pool attached to ctx
+func (r *repo) connFor(ctx context.Context) conn {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	if _, ok := r.conn.(*sqlx.Tx); ok {
+		return r.conn
+	}
+	if db, ok := ctx.Value(dbKey{}).(*sqlx.DB); ok {
+		return db
+	}
+
+	return r.conn
+}
+
+// pool to start a transaction on, the one attached to ctx when there is one
+func (r *repo) dbFor(ctx context.Context) *sqlx.DB {
+	if db, ok := ctx.Value(dbKey{}).(*sqlx.DB); ok {
+		return db
+	}
+
+	return r.db
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTxCommit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	deleteProductQueryMock := "DELETE FROM products WHERE id = \\$1"
+	mock.ExpectBegin()
+	mock.ExpectExec(deleteProductQueryMock).WithArgs("first").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(deleteProductQueryMock).WithArgs("second").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	err = repo.WithTx(ctx, func(ctx context.Context, txRepo StoreRepository) error {
+		if err := txRepo.DeleteProduct(ctx, "first"); err != nil {
+			return err
+		}
+
+		// nested transaction join the running one
+		return txRepo.WithTx(ctx, func(ctx context.Context, nested StoreRepository) error {
+			return nested.DeleteProduct(ctx, "second")
+		})
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTxRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	expectedErr := errors.New("failed")
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM products WHERE id = \\$1").WithArgs("first").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	err = repo.WithTx(ctx, func(ctx context.Context, txRepo StoreRepository) error {
+		if err := txRepo.DeleteProduct(ctx, "first"); err != nil {
+			return err
+		}
+		return expectedErr
+	})
+	assert.Equal(t, expectedErr, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTxRetrySerializationFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	deleteProductQueryMock := "DELETE FROM products WHERE id = \\$1"
+	mock.ExpectBegin()
+	mock.ExpectExec(deleteProductQueryMock).WithArgs("first").WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(deleteProductQueryMock).WithArgs("first").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	attempts := 0
+	ctx := context.Background()
+	err = repo.WithTx(ctx, func(ctx context.Context, txRepo StoreRepository) error {
+		attempts++
+		return txRepo.DeleteProduct(ctx, "first")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTxFromContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	repo := NewStoreRepo(dbx)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM products WHERE id = \\$1").WithArgs("first").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	tx, err := dbx.Beginx()
+	assert.NoError(t, err)
+
+	ctx := ContextWithTx(context.Background(), tx)
+	err = repo.WithTx(ctx, func(ctx context.Context, txRepo StoreRepository) error {
+		return txRepo.DeleteProduct(ctx, "first")
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTxContextCarryTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	outside := NewStoreRepo(dbx).(*repo)
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	assert.Equal(t, conn(dbx), outside.connFor(ctx))
+	err = NewStoreRepo(dbx).WithTx(ctx, func(ctx context.Context, txRepo StoreRepository) error {
+		// another repository called with the context of fn run in the transaction
+		tx, ok := outside.connFor(ctx).(*sqlx.Tx)
+		assert.True(t, ok)
+		assert.Equal(t, txRepo.(*repo).conn, tx)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestContextWithDB(t *testing.T) {
+	def, defMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer def.Close()
+	tenant, tenantMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer tenant.Close()
+
+	tenantDb := sqlx.NewDb(tenant, "postgres")
+	repo := NewStoreRepo(sqlx.NewDb(def, "postgres"))
+
+	// queries and transactions run on the pool attached to the context
+	tenantMock.ExpectExec("DELETE FROM products WHERE id = \\$1").WithArgs("first").WillReturnResult(sqlmock.NewResult(0, 1))
+	tenantMock.ExpectBegin()
+	tenantMock.ExpectExec("DELETE FROM products WHERE id = \\$1").WithArgs("second").WillReturnResult(sqlmock.NewResult(0, 1))
+	tenantMock.ExpectCommit()
+
+	ctx := ContextWithDB(context.Background(), tenantDb)
+	assert.NoError(t, repo.DeleteProduct(ctx, "first"))
+	err = repo.WithTx(ctx, func(ctx context.Context, txRepo StoreRepository) error {
+		return txRepo.DeleteProduct(ctx, "second")
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, tenantMock.ExpectationsWereMet())
+	assert.NoError(t, defMock.ExpectationsWereMet())
+}
@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// Address is the postal address of a store. District and postal code are
+// optional, country is an ISO 3166-1 alpha-2 code.
+type Address struct {
+	Street     string `json:"street"`
+	District   string `json:"district"`
+	City       string `json:"city"`
+	Province   string `json:"province"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+// Normalize trim every part and upper case the country
+func (a *Address) Normalize() {
+	a.Street = strings.TrimSpace(a.Street)
+	a.District = strings.TrimSpace(a.District)
+	a.City = strings.TrimSpace(a.City)
+	a.Province = strings.TrimSpace(a.Province)
+	a.PostalCode = strings.TrimSpace(a.PostalCode)
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+}
+
+// Validate check the address on v, field is the prefix of the part fields
+func (a Address) Validate(v *validation.Validator, field string) {
+	v.Required(field+".street", a.Street).
+		MaxLength(field+".street", a.Street, MaxAddressStreetLength).
+		MaxLength(field+".district", a.District, MaxAddressPartLength).
+		Required(field+".city", a.City).
+		MaxLength(field+".city", a.City, MaxAddressPartLength).
+		Required(field+".province", a.Province).
+		MaxLength(field+".province", a.Province, MaxAddressPartLength).
+		Required(field+".country", a.Country).
+		Country(field+".country", a.Country)
+	if a.PostalCode != "" {
+		v.PostalCode(field+".postal_code", a.PostalCode)
+	}
+}
+
+// Format render the address on a single line, example "Jl. Sudirman 1,
+// Menteng, Jakarta Pusat, DKI Jakarta 10310". Empty parts are skipped and
+// the country is left out so the line is read back by ParseAddress.
+func (a Address) Format() string {
+	parts := make([]string, 0, 4)
+	for _, part := range []string{a.Street, a.District, a.City, a.Province} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	line := strings.Join(parts, ", ")
+	if a.PostalCode != "" {
+		if line != "" {
+			line += " "
+		}
+		line += a.PostalCode
+	}
+
+	return line
+}
+
+// five digits number ending the line, with the separator before it
+var postalCodeSuffix = regexp.MustCompile(`[\s,]*\b(\d{5})$`)
+
+// ParseAddress split a single line address on best effort, the rules are
+// the ones of the store addresses migration. A trailing five digits number
+// is the postal code, the last three comma separated parts are district,
+// city and province and the rest is the street. Shorter lines fill street,
+// city and province first. The country is never guessed.
+func ParseAddress(line string) Address {
+	var address Address
+
+	line = strings.TrimSpace(line)
+	if match := postalCodeSuffix.FindStringSubmatchIndex(line); match != nil {
+		address.PostalCode = line[match[2]:match[3]]
+		line = line[:match[0]]
+	}
+
+	var parts []string
+	for _, part := range strings.Split(line, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	switch n := len(parts); {
+	case n >= 4:
+		address.Street = strings.Join(parts[:n-3], ", ")
+		address.District = parts[n-3]
+		address.City = parts[n-2]
+		address.Province = parts[n-1]
+	case n == 3:
+		address.Province = parts[2]
+		fallthrough
+	case n == 2:
+		address.City = parts[1]
+		fallthrough
+	case n == 1:
+		address.Street = parts[0]
+	}
+
+	return address
+}
+
+// AddressPatch is a merge patch of an address, nil part is not changed and
+// empty District or PostalCode remove the part
+type AddressPatch struct {
+	Street     *string
+	District   *string
+	City       *string
+	Province   *string
+	PostalCode *string
+	Country    *string
+}
+
+// Validate check the patched parts on v, field is the prefix of the part
+// fields
+func (p *AddressPatch) Validate(v *validation.Validator, field string) {
+	trim := func(val *string) {
+		if val != nil {
+			*val = strings.TrimSpace(*val)
+		}
+	}
+	trim(p.Street)
+	trim(p.District)
+	trim(p.City)
+	trim(p.Province)
+	trim(p.PostalCode)
+	if p.Country != nil {
+		*p.Country = strings.ToUpper(strings.TrimSpace(*p.Country))
+	}
+
+	if p.Street != nil {
+		v.Required(field+".street", *p.Street).
+			MaxLength(field+".street", *p.Street, MaxAddressStreetLength)
+	}
+	if p.District != nil {
+		v.MaxLength(field+".district", *p.District, MaxAddressPartLength)
+	}
+	if p.City != nil {
+		v.Required(field+".city", *p.City).
+			MaxLength(field+".city", *p.City, MaxAddressPartLength)
+	}
+	if p.Province != nil {
+		v.Required(field+".province", *p.Province).
+			MaxLength(field+".province", *p.Province, MaxAddressPartLength)
+	}
+	if p.PostalCode != nil && *p.PostalCode != "" {
+		v.PostalCode(field+".postal_code", *p.PostalCode)
+	}
+	if p.Country != nil {
+		v.Required(field+".country", *p.Country).
+			Country(field+".country", *p.Country)
+	}
+}
+
+// Apply return address with the patched parts
+func (p *AddressPatch) Apply(address Address) Address {
+	set := func(dst *string, val *string) {
+		if val != nil {
+			*dst = *val
+		}
+	}
+	set(&address.Street, p.Street)
+	set(&address.District, p.District)
+	set(&address.City, p.City)
+	set(&address.Province, p.Province)
+	set(&address.PostalCode, p.PostalCode)
+	set(&address.Country, p.Country)
+
+	return address
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ijlik/store-app/pkg/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Address
+	}{
+		{"Jl. Sudirman 1, RT 01/RW 02, Menteng, Jakarta Pusat, DKI Jakarta 10310", Address{Street: "Jl. Sudirman 1, RT 01/RW 02", District: "Menteng", City: "Jakarta Pusat", Province: "DKI Jakarta", PostalCode: "10310"}},
+		{"Jl. Braga 10, Bandung, Jawa Barat, 40111", Address{Street: "Jl. Braga 10", City: "Bandung", Province: "Jawa Barat", PostalCode: "40111"}},
+		{" Jl. Malioboro 5 ,Yogyakarta ", Address{Street: "Jl. Malioboro 5", City: "Yogyakarta"}},
+		{"Jakarta", Address{Street: "Jakarta"}},
+		{"Ruko 12345A", Address{Street: "Ruko 12345A"}},
+		{"", Address{}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ParseAddress(tt.line), tt.line)
+	}
+}
+
+func TestAddressFormat(t *testing.T) {
+	address := Address{Street: "Jl. Sudirman 1", District: "Menteng", City: "Jakarta Pusat", Province: "DKI Jakarta", PostalCode: "10310", Country: "ID"}
+	assert.Equal(t, "Jl. Sudirman 1, Menteng, Jakarta Pusat, DKI Jakarta 10310", address.Format())
+
+	address.Country = ""
+	assert.Equal(t, address, ParseAddress(address.Format()))
+
+	assert.Equal(t, "Jl. Braga 10, Bandung, Jawa Barat", Address{Street: "Jl. Braga 10", City: "Bandung", Province: "Jawa Barat"}.Format())
+}
+
+func TestAddressValidate(t *testing.T) {
+	address := Address{Street: " Jl. Sudirman 1 ", City: "Jakarta Pusat", Province: "DKI Jakarta", PostalCode: "10310", Country: " id"}
+	address.Normalize()
+	v := validation.New()
+	address.Validate(v, "postal_address")
+	assert.Nil(t, v.Error())
+	assert.Equal(t, "Jl. Sudirman 1", address.Street)
+	assert.Equal(t, "ID", address.Country)
+
+	v = validation.New()
+	Address{District: strings.Repeat("a", MaxAddressPartLength+1), City: "Jakarta", PostalCode: "1", Country: "IDN"}.Validate(v, "postal_address")
+	var fields []string
+	for _, field := range v.Error().GetFields() {
+		fields = append(fields, field.Field+":"+field.Reason)
+	}
+
+	assert.Equal(t, []string{
+		"postal_address.street:REQUIRED",
+		"postal_address.district:MAX_LENGTH",
+		"postal_address.province:REQUIRED",
+		"postal_address.country:INVALID_COUNTRY",
+		"postal_address.postal_code:INVALID_POSTAL_CODE",
+	}, fields)
+}
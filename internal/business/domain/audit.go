@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// audited entities, stored on audit_logs.entity
+const (
+	AuditEntityStore   = "store"
+	AuditEntityProduct = "product"
+	AuditEntityOrder   = "order"
+	AuditEntityRefund  = "refund"
+)
+
+// audited actions, stored on audit_logs.action
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+type AuditLog struct {
+	ID        int64           `json:"id"`
+	Action    string          `json:"action"`
+	Actor     string          `json:"actor,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Changes   json.RawMessage `json:"changes"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// AuditChange is the value of a field before and after a write, From is nil
+// on create and To is nil on delete
+type AuditChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// AuditFields is a snapshot of the audited fields of an entity, values must
+// be comparable
+type AuditFields map[string]any
+
+// AuditDiff return the fields whose value differs between before and after,
+// a nil snapshot stands for a missing entity
+func AuditDiff(before, after AuditFields) map[string]AuditChange {
+	diff := make(map[string]AuditChange)
+	for field, from := range before {
+		if to, ok := after[field]; !ok || to != from {
+			diff[field] = AuditChange{From: from, To: after[field]}
+		}
+	}
+	for field, to := range after {
+		if _, ok := before[field]; !ok {
+			diff[field] = AuditChange{From: nil, To: to}
+		}
+	}
+
+	return diff
+}
+
+type HttpHistoryQuery struct {
+	Limit int `form:"limit"`
+	Page  int `form:"page"`
+}
+
+func (h *HttpHistoryQuery) Validate() errpkg.ErrorService {
+	if h.Limit <= 0 {
+		h.Limit = 10
+	}
+	if h.Limit > MaxHistoryLimit {
+		h.Limit = MaxHistoryLimit
+	}
+	if h.Page <= 0 {
+		h.Page = 1
+	}
+
+	return nil
+}
+
+// id of the product on GET routes nested under a product, the GET tree
+// already name that segment url, see ShowProduct
+type HttpProductHistoryParams struct {
+	ID string `uri:"url"`
+}
+
+// Validate check the segment is a product id and not a product url, the
+// route match both
+func (h *HttpProductHistoryParams) Validate() errpkg.ErrorService {
+	return validation.New().
+		UUID("id", h.ID).
+		Error()
+}
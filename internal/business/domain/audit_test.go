@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditDiff(t *testing.T) {
+	fields := AuditFields{"name": "Kopi Susu", "price": float32(18000), "sku": nil}
+
+	tests := []struct {
+		name     string
+		before   AuditFields
+		after    AuditFields
+		expected map[string]AuditChange
+	}{
+		{
+			name:   "create",
+			before: nil,
+			after:  fields,
+			expected: map[string]AuditChange{
+				"name":  {nil, "Kopi Susu"},
+				"price": {nil, float32(18000)},
+				"sku":   {nil, nil},
+			},
+		},
+		{
+			name:   "update",
+			before: fields,
+			after:  AuditFields{"name": "Kopi Susu", "price": float32(20000), "sku": "KS-001"},
+			expected: map[string]AuditChange{
+				"price": {float32(18000), float32(20000)},
+				"sku":   {nil, "KS-001"},
+			},
+		},
+		{
+			name:     "unchanged",
+			before:   fields,
+			after:    AuditFields{"name": "Kopi Susu", "price": float32(18000), "sku": nil},
+			expected: map[string]AuditChange{},
+		},
+		{
+			name:   "delete",
+			before: fields,
+			after:  nil,
+			expected: map[string]AuditChange{
+				"name":  {"Kopi Susu", nil},
+				"price": {float32(18000), nil},
+				"sku":   {nil, nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, AuditDiff(tt.before, tt.after))
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"time"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// a cart expires after this long without change, anonymous cart is kept
+// shorter than the cart of a signed in user
+const (
+	AnonymousCartTTL = 7 * 24 * time.Hour
+	UserCartTTL      = 30 * 24 * time.Hour
+)
+
+// max length of the anonymous cart token
+const MaxCartTokenLength = 64
+
+// CartOwner identify the cart of a request, the user when signed in and the
+// token of an anonymous cart. Both are set right after sign in, the
+// anonymous cart is then merged into the cart of the user.
+type CartOwner struct {
+	UserID string
+	Token  string
+}
+
+// TTL return how long the cart of the owner is kept
+func (o CartOwner) TTL() time.Duration {
+	if o.UserID != "" {
+		return UserCartTTL
+	}
+
+	return AnonymousCartTTL
+}
+
+// Cart lines are split per store, every store is checked out as its own
+// order. Token is only set on anonymous cart, the client send it back on
+// X-Cart-Token header.
+type Cart struct {
+	ID           string      `json:"id"`
+	Token        string      `json:"token,omitempty"`
+	Stores       []CartStore `json:"stores"`
+	ItemCount    int         `json:"item_count"`
+	Subtotal     float32     `json:"subtotal"`
+	Discount     float32     `json:"discount"`
+	TaxIncluded  float32     `json:"tax_included"`
+	TaxAdded     float32     `json:"tax_added"`
+	Total        float32     `json:"total"`
+	PriceChanged bool        `json:"price_changed"`
+	ExpiresAt    time.Time   `json:"expires_at"`
+}
+
+// CartStore total include the tax added on top of the lines, the same way
+// the order of the store is totalled at checkout
+type CartStore struct {
+	StoreID   string        `json:"store_id"`
+	StoreName string        `json:"store_name"`
+	Lines     []CartLine    `json:"lines"`
+	Subtotal  float32       `json:"subtotal"`
+	Discount  float32       `json:"discount"`
+	Tax       *TaxBreakdown `json:"tax"`
+	Total     float32       `json:"total"`
+}
+
+// CartLine price is the current product price, added price the price when
+// the line was last written
+type CartLine struct {
+	ProductID    string             `json:"product_id"`
+	Name         string             `json:"name"`
+	Url          string             `json:"url"`
+	Quantity     int                `json:"quantity"`
+	AddedPrice   float32            `json:"added_price"`
+	Price        float32            `json:"price"`
+	PriceChanged bool               `json:"price_changed"`
+	SalePrice    float32            `json:"sale_price"`
+	Discount     float32            `json:"discount"`
+	Total        float32            `json:"total"`
+	Promotions   []AppliedPromotion `json:"promotions"`
+}
+
+type CartItemRequest struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+func (c *CartItemRequest) Validate() errpkg.ErrorService {
+	return validation.New().
+		UUID("product_id", c.ProductID).
+		Range("quantity", float64(c.Quantity), 1, MaxItemQuantity).
+		Error()
+}
+
+type CartQuantityRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+func (c *CartQuantityRequest) Validate() errpkg.ErrorService {
+	return validation.New().
+		Range("quantity", float64(c.Quantity), 1, MaxItemQuantity).
+		Error()
+}
+
+type HttpCartItemParams struct {
+	ProductID string `uri:"productId"`
+}
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// shape of a delivery zone, a radius around the store location or a polygon
+const (
+	DeliveryZoneRadius  = "radius"
+	DeliveryZonePolygon = "polygon"
+)
+
+// DeliveryZone is an area the store deliver to, Rates is its rate table
+type DeliveryZone struct {
+	ID        string         `json:"id"`
+	StoreID   string         `json:"store_id"`
+	Name      string         `json:"name"`
+	Type      string         `json:"type"`
+	RadiusKm  float64        `json:"radius_km,omitempty"`
+	Polygon   []GeoPoint     `json:"polygon,omitempty"`
+	Rates     []DeliveryRate `json:"rates"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Contains report whether point is inside the zone of a store located at
+// origin
+func (z *DeliveryZone) Contains(origin GeoPoint, point GeoPoint) bool {
+	switch z.Type {
+	case DeliveryZoneRadius:
+		return DistanceKm(origin, point) <= z.RadiusKm
+	case DeliveryZonePolygon:
+		return InPolygon(point, z.Polygon)
+	}
+
+	return false
+}
+
+// DeliveryRate charge fee on deliveries within the bounds, lower bounds are
+// inclusive and upper bounds exclusive. Zero upper bound is unbounded.
+// Weight is in grams and order total is the discounted total of the items.
+type DeliveryRate struct {
+	MinDistanceKm float64 `json:"min_distance_km"`
+	MaxDistanceKm float64 `json:"max_distance_km"`
+	MinWeight     int     `json:"min_weight"`
+	MaxWeight     int     `json:"max_weight"`
+	MinOrderTotal float32 `json:"min_order_total"`
+	MaxOrderTotal float32 `json:"max_order_total"`
+	Fee           float32 `json:"fee"`
+}
+
+// Matches report whether the rate applies to a delivery
+func (r *DeliveryRate) Matches(distanceKm float64, weight int, orderTotal float32) bool {
+	return distanceKm >= r.MinDistanceKm && (r.MaxDistanceKm == 0 || distanceKm < r.MaxDistanceKm) &&
+		weight >= r.MinWeight && (r.MaxWeight == 0 || weight < r.MaxWeight) &&
+		orderTotal >= r.MinOrderTotal && (r.MaxOrderTotal == 0 || orderTotal < r.MaxOrderTotal)
+}
+
+type DeliveryQuote struct {
+	ZoneID     string  `json:"zone_id"`
+	ZoneName   string  `json:"zone_name"`
+	DistanceKm float64 `json:"distance_km"`
+	Weight     int     `json:"weight"`
+	OrderTotal float32 `json:"order_total"`
+	Fee        float32 `json:"fee"`
+}
+
+// QuoteDelivery return the cheapest delivery from a store located at origin
+// to point, nil when no zone contains the point or no rate of the zones
+// matches. Ties go to the first zone and the first rate.
+func QuoteDelivery(zones []*DeliveryZone, origin GeoPoint, point GeoPoint, weight int, orderTotal float32) *DeliveryQuote {
+	var (
+		best     *DeliveryQuote
+		distance = DistanceKm(origin, point)
+	)
+	for _, zone := range zones {
+		if !zone.Contains(origin, point) {
+			continue
+		}
+		for _, rate := range zone.Rates {
+			if !rate.Matches(distance, weight, orderTotal) || (best != nil && rate.Fee >= best.Fee) {
+				continue
+			}
+			best = &DeliveryQuote{
+				ZoneID:     zone.ID,
+				ZoneName:   zone.Name,
+				DistanceKm: RoundDistanceKm(distance),
+				Weight:     weight,
+				OrderTotal: orderTotal,
+				Fee:        rate.Fee,
+			}
+		}
+	}
+
+	return best
+}
+
+type DeliveryZoneRequest struct {
+	Name     string         `json:"name"`
+	Type     string         `json:"type"`
+	RadiusKm float64        `json:"radius_km"`
+	Polygon  []GeoPoint     `json:"polygon"`
+	Rates    []DeliveryRate `json:"rates"`
+}
+
+// Validate the request, the field of the other shape is cleared
+func (d *DeliveryZoneRequest) Validate() errpkg.ErrorService {
+	v := validation.New().
+		Required("name", d.Name).
+		MaxLength("name", d.Name, MaxDeliveryZoneNameLength).
+		OneOf("type", d.Type, DeliveryZoneRadius, DeliveryZonePolygon).
+		Check(len(d.Rates) > 0, "rates", validation.ReasonRequired, "missing rates", nil).
+		Check(len(d.Rates) <= MaxDeliveryRates, "rates", validation.ReasonMax, fmt.Sprintf("rates must be at most %d", MaxDeliveryRates), map[string]any{"max": MaxDeliveryRates})
+
+	switch d.Type {
+	case DeliveryZoneRadius:
+		d.Polygon = nil
+		v.Check(d.RadiusKm > 0, "radius_km", validation.ReasonMin, "radius km must be more than 0", map[string]any{"min": 0}).
+			Max("radius_km", d.RadiusKm, MaxDeliveryRadiusKm)
+	case DeliveryZonePolygon:
+		d.RadiusKm = 0
+		v.Check(len(d.Polygon) >= 3, "polygon", validation.ReasonMin, "polygon must have at least 3 points", map[string]any{"min": 3}).
+			Check(len(d.Polygon) <= MaxPolygonPoints, "polygon", validation.ReasonMax, fmt.Sprintf("polygon must have at most %d points", MaxPolygonPoints), map[string]any{"max": MaxPolygonPoints})
+		for i, point := range d.Polygon {
+			point.Validate(v, fmt.Sprintf("polygon[%d]", i))
+		}
+	}
+
+	for i, rate := range d.Rates {
+		field := fmt.Sprintf("rates[%d]", i)
+		v.Range(field+".fee", float64(rate.Fee), 0, MaxProductPrice).
+			Min(field+".min_distance_km", rate.MinDistanceKm, 0).
+			Min(field+".min_weight", float64(rate.MinWeight), 0).
+			Min(field+".min_order_total", float64(rate.MinOrderTotal), 0)
+		if rate.MaxDistanceKm != 0 {
+			v.Check(rate.MaxDistanceKm > rate.MinDistanceKm, field+".max_distance_km", validation.ReasonMin, "max distance km must be more than min distance km", map[string]any{"min": rate.MinDistanceKm})
+		}
+		if rate.MaxWeight != 0 {
+			v.Check(rate.MaxWeight > rate.MinWeight, field+".max_weight", validation.ReasonMin, "max weight must be more than min weight", map[string]any{"min": rate.MinWeight})
+		}
+		if rate.MaxOrderTotal != 0 {
+			v.Check(rate.MaxOrderTotal > rate.MinOrderTotal, field+".max_order_total", validation.ReasonMin, "max order total must be more than min order total", map[string]any{"min": rate.MinOrderTotal})
+		}
+	}
+
+	return v.Error()
+}
+
+// DeliveryQuoteRequest price the delivery of the items of the store to
+// location
+type DeliveryQuoteRequest struct {
+	Location *GeoPoint    `json:"location"`
+	Items    []BasketItem `json:"items"`
+}
+
+// Validate the request, quantities of a product given more than once are
+// added up
+func (d *DeliveryQuoteRequest) Validate() errpkg.ErrorService {
+	v := validation.New().
+		Check(d.Location != nil, "location", validation.ReasonRequired, "missing location", nil).
+		Check(len(d.Items) > 0, "items", validation.ReasonRequired, "missing items", nil).
+		Check(len(d.Items) <= MaxBasketItems, "items", validation.ReasonMax, fmt.Sprintf("items must be at most %d", MaxBasketItems), map[string]any{"max": MaxBasketItems})
+	if d.Location != nil {
+		d.Location.Validate(v, "location")
+	}
+	for i, item := range d.Items {
+		v.UUID(fmt.Sprintf("items[%d].product_id", i), item.ProductID).
+			Range(fmt.Sprintf("items[%d].quantity", i), float64(item.Quantity), 1, MaxItemQuantity)
+	}
+	if err := v.Error(); err != nil {
+		return err
+	}
+
+	d.Items = MergeBasketItems(d.Items)
+
+	return nil
+}
+
+type HttpDeliveryZoneParams struct {
+	StoreID string `uri:"id"`
+	ZoneID  string `uri:"zoneId"`
+}
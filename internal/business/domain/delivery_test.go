@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryRateMatches(t *testing.T) {
+	rate := DeliveryRate{MinDistanceKm: 2, MaxDistanceKm: 5, MaxWeight: 1000, MinOrderTotal: 50000, Fee: 8000}
+
+	assert.True(t, rate.Matches(2, 0, 50000))
+	assert.True(t, rate.Matches(4.99, 999, 1000000))
+	assert.False(t, rate.Matches(1.99, 0, 50000))
+	assert.False(t, rate.Matches(5, 0, 50000))
+	assert.False(t, rate.Matches(3, 1000, 50000))
+	assert.False(t, rate.Matches(3, 0, 49999))
+}
+
+func TestQuoteDelivery(t *testing.T) {
+	zones := []*DeliveryZone{
+		{ID: "near", Name: "Near", Type: DeliveryZoneRadius, RadiusKm: 3, Rates: []DeliveryRate{
+			{Fee: 10000},
+			{MinOrderTotal: 100000, Fee: 0},
+		}},
+		{ID: "central", Name: "Central", Type: DeliveryZonePolygon, Polygon: centralArea, Rates: []DeliveryRate{
+			{MaxWeight: 5000, Fee: 7000},
+		}},
+	}
+
+	tests := []struct {
+		name       string
+		point      GeoPoint
+		weight     int
+		orderTotal float32
+		zoneId     string
+		fee        float32
+	}{
+		{"cheapest zone", bundaranHI, 1000, 30000, "central", 7000},
+		{"cheapest rate", bundaranHI, 1000, 150000, "near", 0},
+		{"heavy order", bundaranHI, 6000, 30000, "near", 10000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := QuoteDelivery(zones, monas, tt.point, tt.weight, tt.orderTotal)
+			assert.Equal(t, tt.zoneId, quote.ZoneID)
+			assert.Equal(t, tt.fee, quote.Fee)
+			assert.Equal(t, 2.23, quote.DistanceKm)
+		})
+	}
+
+	assert.Nil(t, QuoteDelivery(zones, monas, bandung, 1000, 30000))
+}
+
+func TestDeliveryZoneRequestValidate(t *testing.T) {
+	request := DeliveryZoneRequest{
+		Name:     "Central",
+		Type:     DeliveryZoneRadius,
+		RadiusKm: 5,
+		Polygon:  centralArea,
+		Rates:    []DeliveryRate{{MaxDistanceKm: 3, Fee: 10000}},
+	}
+	assert.Nil(t, request.Validate())
+	assert.Nil(t, request.Polygon)
+
+	request = DeliveryZoneRequest{
+		Name:  "Central",
+		Type:  DeliveryZonePolygon,
+		Rates: []DeliveryRate{{MinWeight: 1000, MaxWeight: 500, Fee: 10000}},
+	}
+	err := request.Validate()
+	assert.NotNil(t, err)
+	fields := []string{}
+	for _, field := range err.GetFields() {
+		fields = append(fields, field.Field)
+	}
+	assert.Equal(t, []string{"polygon", "rates[0].max_weight"}, fields)
+}
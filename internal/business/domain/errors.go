@@ -0,0 +1,46 @@
+package domain
+
+import errpkg "github.com/ijlik/store-app/pkg/error"
+
+var (
+	ErrStoreNotFound         = errpkg.NewServiceError(errpkg.ErrNotFound, "store not found", errpkg.WithReason("STORE_NOT_FOUND"))
+	ErrProductNotFound       = errpkg.NewServiceError(errpkg.ErrNotFound, "product not found", errpkg.WithReason("PRODUCT_NOT_FOUND"))
+	ErrVersionMismatch       = errpkg.NewServiceError(errpkg.ErrPreconditionFailed, "resource has been changed by another request, reload and retry", errpkg.WithReason("VERSION_MISMATCH"))
+	ErrMissingIfMatch        = errpkg.NewServiceError(errpkg.ErrPreconditionRequired, "If-Match header is required", errpkg.WithReason("MISSING_IF_MATCH"))
+	ErrMergePatchMediaType   = errpkg.NewServiceError(errpkg.ErrUnsupportedMediaType, "patch must be sent as application/merge-patch+json", errpkg.WithReason("UNSUPPORTED_PATCH_MEDIA_TYPE"))
+	ErrPriceScheduleNotFound = errpkg.NewServiceError(errpkg.ErrNotFound, "price schedule not found", errpkg.WithReason("PRICE_SCHEDULE_NOT_FOUND"))
+	ErrPriceScheduleConflict = errpkg.NewServiceError(errpkg.ErrConflict, "price schedule overlaps another schedule of the product", errpkg.WithReason("PRICE_SCHEDULE_CONFLICT"))
+	ErrPriceScheduleClosed   = errpkg.NewServiceError(errpkg.ErrConflict, "price schedule has already ended", errpkg.WithReason("PRICE_SCHEDULE_CLOSED"))
+	ErrPromotionNotFound     = errpkg.NewServiceError(errpkg.ErrNotFound, "promotion not found", errpkg.WithReason("PROMOTION_NOT_FOUND"))
+	ErrCouponNotFound        = errpkg.NewServiceError(errpkg.ErrNotFound, "coupon not found", errpkg.WithReason("COUPON_NOT_FOUND"))
+	ErrCouponExpired         = errpkg.NewServiceError(errpkg.ErrUnprocessableEntity, "coupon is not valid at this time", errpkg.WithReason("COUPON_EXPIRED"))
+	ErrCouponUsageExceeded   = errpkg.NewServiceError(errpkg.ErrUnprocessableEntity, "coupon usage limit has been reached", errpkg.WithReason("COUPON_USAGE_EXCEEDED"))
+	ErrCouponNotApplicable   = errpkg.NewServiceError(errpkg.ErrUnprocessableEntity, "coupon does not apply to the items", errpkg.WithReason("COUPON_NOT_APPLICABLE"))
+	ErrCartNotFound          = errpkg.NewServiceError(errpkg.ErrNotFound, "cart not found", errpkg.WithReason("CART_NOT_FOUND"))
+	ErrCartItemNotFound      = errpkg.NewServiceError(errpkg.ErrNotFound, "product is not in the cart", errpkg.WithReason("CART_ITEM_NOT_FOUND"))
+	ErrCartFull              = errpkg.NewServiceError(errpkg.ErrUnprocessableEntity, "cart has reached the maximum number of products", errpkg.WithReason("CART_FULL"))
+	ErrCartEmpty             = errpkg.NewServiceError(errpkg.ErrUnprocessableEntity, "cart has no product of the store", errpkg.WithReason("CART_EMPTY"))
+	ErrCartPriceChanged      = errpkg.NewServiceError(errpkg.ErrConflict, "price of products in the cart has changed, review the cart and retry", errpkg.WithReason("CART_PRICE_CHANGED"))
+	ErrSignInRequired        = errpkg.NewServiceError(errpkg.ErrUnauthorize, "sign in to continue", errpkg.WithReason("SIGN_IN_REQUIRED"))
+	ErrStoreClosed           = errpkg.NewServiceError(errpkg.ErrUnprocessableEntity, "store is closed at this time", errpkg.WithReason("STORE_CLOSED"))
+	ErrOrderNotFound         = errpkg.NewServiceError(errpkg.ErrNotFound, "order not found", errpkg.WithReason("ORDER_NOT_FOUND"))
+	ErrOrderStatus           = errpkg.NewServiceError(errpkg.ErrConflict, "order can not move to this status", errpkg.WithReason("INVALID_STATUS_TRANSITION"))
+	ErrOrderNotPayable       = errpkg.NewServiceError(errpkg.ErrConflict, "order is not waiting for payment", errpkg.WithReason("ORDER_NOT_PAYABLE"))
+	ErrInvalidSignature      = errpkg.NewServiceError(errpkg.ErrInvalidToken, "invalid webhook signature", errpkg.WithReason("INVALID_SIGNATURE"))
+	ErrPaymentUnavailable    = errpkg.NewServiceError(errpkg.ErrRetryable, "payment provider is unavailable, retry later", errpkg.WithReason("PAYMENT_UNAVAILABLE"))
+	ErrOrderNotRefundable    = errpkg.NewServiceError(errpkg.ErrConflict, "order has no captured payment left to refund", errpkg.WithReason("ORDER_NOT_REFUNDABLE"))
+	ErrOrderItemNotFound     = errpkg.NewServiceError(errpkg.ErrNotFound, "order item not found", errpkg.WithReason("ORDER_ITEM_NOT_FOUND"))
+	ErrRefundQuantity        = errpkg.NewServiceError(errpkg.ErrUnprocessableEntity, "refund quantity exceeds the quantity left on the order item", errpkg.WithReason("REFUND_QUANTITY_EXCEEDED"))
+	ErrRefundExceedsCaptured = errpkg.NewServiceError(errpkg.ErrUnprocessableEntity, "refund exceeds the captured amount left on the payment", errpkg.WithReason("REFUND_EXCEEDS_CAPTURED"))
+	ErrTaxRuleNotFound       = errpkg.NewServiceError(errpkg.ErrNotFound, "tax rule not found", errpkg.WithReason("TAX_RULE_NOT_FOUND"))
+	ErrDeliveryZoneNotFound  = errpkg.NewServiceError(errpkg.ErrNotFound, "delivery zone not found", errpkg.WithReason("DELIVERY_ZONE_NOT_FOUND"))
+	ErrDeliveryUnavailable   = errpkg.NewServiceError(errpkg.ErrUnprocessableEntity, "store does not deliver to this location", errpkg.WithReason("DELIVERY_UNAVAILABLE"))
+	ErrPickupUnavailable     = errpkg.NewServiceError(errpkg.ErrUnprocessableEntity, "store does not offer pickup", errpkg.WithReason("PICKUP_UNAVAILABLE"))
+	ErrPickupSlotInvalid     = errpkg.NewServiceError(errpkg.ErrUnprocessableEntity, "pickup slot is not offered", errpkg.WithReason("PICKUP_SLOT_INVALID"))
+	ErrPickupSlotFull        = errpkg.NewServiceError(errpkg.ErrConflict, "pickup slot is fully booked", errpkg.WithReason("PICKUP_SLOT_FULL"))
+)
+
+// ErrOutOfStock tell which product has not enough stock for the order
+func ErrOutOfStock(productId string) errpkg.ErrorService {
+	return errpkg.NewServiceError(errpkg.ErrConflict, "product is out of stock", errpkg.WithReason("OUT_OF_STOCK"), errpkg.WithMetadata("product_id", productId))
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"math"
+
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// mean radius of the earth used by the haversine distance
+const EarthRadiusKm = 6371.0
+
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Validate check the point on v, field is the prefix of the lat and lng
+// fields
+func (p GeoPoint) Validate(v *validation.Validator, field string) {
+	v.Range(field+".lat", p.Lat, -90, 90).
+		Range(field+".lng", p.Lng, -180, 180)
+}
+
+// NullGeoPoint is a point which may be removed, invalid point is null
+type NullGeoPoint struct {
+	GeoPoint
+	Valid bool
+}
+
+// DistanceKm return the great circle distance between a and b with the
+// haversine formula
+func DistanceKm(a GeoPoint, b GeoPoint) float64 {
+	var (
+		lat1 = a.Lat * math.Pi / 180
+		lat2 = b.Lat * math.Pi / 180
+		dLat = (b.Lat - a.Lat) * math.Pi / 180
+		dLng = (b.Lng - a.Lng) * math.Pi / 180
+	)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// RoundDistanceKm round a distance to two decimals, ten meters
+func RoundDistanceKm(distanceKm float64) float64 {
+	return math.Round(distanceKm*100) / 100
+}
+
+// BoundingBox return the latitude and longitude distance in degrees of the
+// box around center enclosing the circle of radiusKm, a cheap indexed filter
+// before the exact distance. Longitude distance of a box reaching a pole or
+// crossing the antimeridian is 360 so every longitude is kept.
+func BoundingBox(center GeoPoint, radiusKm float64) (float64, float64) {
+	latDelta := radiusKm / (EarthRadiusKm * math.Pi / 180)
+	if math.Abs(center.Lat)+latDelta >= 90 {
+		return latDelta, 360
+	}
+
+	lngDelta := latDelta / math.Cos(center.Lat*math.Pi/180)
+	if center.Lng-lngDelta < -180 || center.Lng+lngDelta > 180 {
+		return latDelta, 360
+	}
+
+	return latDelta, lngDelta
+}
+
+// InPolygon report whether the point is inside the polygon with the ray
+// casting rule, longitude is taken as x and latitude as y which is precise
+// enough for zones of a city
+func InPolygon(point GeoPoint, polygon []GeoPoint) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > point.Lat) != (b.Lat > point.Lat) &&
+			point.Lng < (b.Lng-a.Lng)*(point.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	monas       = GeoPoint{Lat: -6.1754, Lng: 106.8272}
+	bundaranHI  = GeoPoint{Lat: -6.1950, Lng: 106.8230}
+	bandung     = GeoPoint{Lat: -6.9175, Lng: 107.6191}
+	centralArea = []GeoPoint{
+		{Lat: -6.15, Lng: 106.80},
+		{Lat: -6.15, Lng: 106.85},
+		{Lat: -6.21, Lng: 106.85},
+		{Lat: -6.21, Lng: 106.80},
+	}
+)
+
+func TestDistanceKm(t *testing.T) {
+	assert.InDelta(t, 2.23, DistanceKm(monas, bundaranHI), 0.01)
+	assert.InDelta(t, 120.26, DistanceKm(monas, bandung), 0.01)
+	assert.Equal(t, 0.0, DistanceKm(monas, monas))
+}
+
+func TestInPolygon(t *testing.T) {
+	assert.True(t, InPolygon(monas, centralArea))
+	assert.True(t, InPolygon(bundaranHI, centralArea))
+	assert.False(t, InPolygon(bandung, centralArea))
+	assert.False(t, InPolygon(GeoPoint{Lat: -6.18, Lng: 106.86}, centralArea))
+}
+
+func TestBoundingBox(t *testing.T) {
+	latDelta, lngDelta := BoundingBox(monas, 10)
+	assert.InDelta(t, 0.0899, latDelta, 0.0001)
+	assert.InDelta(t, 0.0905, lngDelta, 0.0001)
+
+	// every point of the circle is inside the box
+	assert.InDelta(t, 10, DistanceKm(monas, GeoPoint{Lat: monas.Lat + latDelta, Lng: monas.Lng}), 0.001)
+	assert.InDelta(t, 10, DistanceKm(monas, GeoPoint{Lat: monas.Lat, Lng: monas.Lng + lngDelta}), 0.01)
+
+	_, lngDelta = BoundingBox(GeoPoint{Lat: 89.95, Lng: 0}, 10)
+	assert.Equal(t, 360.0, lngDelta)
+	_, lngDelta = BoundingBox(GeoPoint{Lat: -17.7, Lng: 179.99}, 10)
+	assert.Equal(t, 360.0, lngDelta)
+}
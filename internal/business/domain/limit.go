@@ -0,0 +1,77 @@
+package domain
+
+// column length of stores and products table, see migration folder
+const (
+	MaxStoreNameLength   = 50
+	MaxProductNameLength = 50
+	MaxUrlLength         = 100
+	MaxPhoneLength       = 16
+	MaxSkuLength         = 64
+	MaxCategoryLength    = 50
+
+	MaxPromotionNameLength = 100
+	MaxCouponCodeLength    = 32
+
+	MaxTaxRuleNameLength = 100
+	MaxTaxRegionLength   = 50
+
+	MaxDeliveryZoneNameLength = 100
+
+	MaxAddressStreetLength = 255
+	MaxAddressPartLength   = 100
+)
+
+// max ids of one batch get request
+const MaxBatchGetIds = 100
+
+// upper bound of product price
+const MaxProductPrice = 1_000_000_000
+
+// upper bound of product weight in grams
+const MaxProductWeight = 1_000_000
+
+// max audit logs of one history page
+const MaxHistoryLimit = 100
+
+// max distinct products of one basket and max quantity of one product
+const (
+	MaxBasketItems  = 100
+	MaxItemQuantity = 999
+)
+
+// max promotions of one list page
+const MaxPromotionLimit = 100
+
+// max stores of one list page
+const MaxStoreLimit = 100
+
+// max orders of one list page
+const MaxOrderLimit = 100
+
+// max length of the reason given on a status change
+const MaxReasonLength = 255
+
+// limits of a delivery zone, radius in km and rates of its rate table
+const (
+	MaxDeliveryRadiusKm = 100
+	MaxPolygonPoints    = 100
+	MaxDeliveryRates    = 50
+)
+
+// limits of pickup settings, slot length and lead time in minutes
+const (
+	MinPickupSlotMinutes = 5
+	MaxPickupSlotMinutes = 240
+	MaxPickupCapacity    = 1000
+	MaxPickupLeadMinutes = 24 * 60
+	MaxPickupDaysAhead   = 14
+)
+
+// search radius in km and max stores of a nearby search, default when not
+// given
+const (
+	DefaultNearbyRadiusKm = 5
+	MaxNearbyRadiusKm     = 100
+	DefaultNearbyLimit    = 10
+	MaxNearbyLimit        = 100
+)
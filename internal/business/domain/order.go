@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"time"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// status of an order
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderPreparing = "preparing"
+	OrderReady     = "ready"
+	OrderCompleted = "completed"
+	OrderCancelled = "cancelled"
+	OrderRefunded  = "refunded"
+
+	OrderPartiallyRefunded = "partially_refunded"
+)
+
+// pending order not paid in time is cancelled and its stock released
+const OrderPaymentTTL = 30 * time.Minute
+
+// next statuses allowed from each status, cancelled and refunded are final.
+// Only an unpaid order is cancelled, a paid order is refunded so the money
+// goes back with the stock. Partially refunded order goes on with the lines
+// left.
+var orderTransitions = map[string][]string{
+	OrderPending:           {OrderPaid, OrderCancelled},
+	OrderPaid:              {OrderPreparing, OrderPartiallyRefunded, OrderRefunded},
+	OrderPreparing:         {OrderReady, OrderPartiallyRefunded, OrderRefunded},
+	OrderReady:             {OrderCompleted, OrderPartiallyRefunded, OrderRefunded},
+	OrderCompleted:         {OrderPartiallyRefunded, OrderRefunded},
+	OrderPartiallyRefunded: {OrderPreparing, OrderReady, OrderCompleted, OrderRefunded},
+}
+
+// CanTransition report whether an order may move from one status to another
+func CanTransition(from string, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CustomerCanCancel report whether the customer may cancel the order
+// themselves, once paid only the store can refund it
+func CustomerCanCancel(status string) bool {
+	return status == OrderPending
+}
+
+// StoreOpen report whether a store opening at start hour and closing at end
+// hour is open at t. Closing hour before the opening hour is open past
+// midnight, the same hour is open all day.
+func StoreOpen(start int, end int, t time.Time) bool {
+	hour := t.Hour()
+	switch {
+	case start == end:
+		return true
+	case start < end:
+		return hour >= start && hour < end
+	default:
+		return hour >= start || hour < end
+	}
+}
+
+// Order total is what the user pays, the discounted lines plus the tax added
+// on top of them
+type Order struct {
+	ID         string       `json:"id"`
+	StoreID    string       `json:"store_id"`
+	UserID     string       `json:"user_id"`
+	Status     string       `json:"status"`
+	Items      []OrderItem  `json:"items,omitempty"`
+	Subtotal   float32      `json:"subtotal"`
+	Discount   float32      `json:"discount"`
+	Tax        TaxBreakdown `json:"tax"`
+	Total      float32      `json:"total"`
+	CouponCode string       `json:"coupon_code,omitempty"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  *time.Time   `json:"updated_at,omitempty"`
+}
+
+// OrderItem is a snapshot of the product when the order was placed
+type OrderItem struct {
+	ID         int64              `json:"id"`
+	ProductID  string             `json:"product_id"`
+	Name       string             `json:"name"`
+	Sku        string             `json:"sku,omitempty"`
+	UnitPrice  float32            `json:"unit_price"`
+	SalePrice  float32            `json:"sale_price"`
+	Quantity   int                `json:"quantity"`
+	Discount   float32            `json:"discount"`
+	Total      float32            `json:"total"`
+	Promotions []AppliedPromotion `json:"promotions"`
+
+	RefundedQuantity int     `json:"refunded_quantity"`
+	RefundedAmount   float32 `json:"refunded_amount"`
+
+	TaxIncluded float32 `json:"tax_included"`
+	TaxAdded    float32 `json:"tax_added"`
+}
+
+// CheckoutRequest check out the cart lines of one store
+type CheckoutRequest struct {
+	StoreID    string `json:"store_id"`
+	CouponCode string `json:"coupon_code"`
+}
+
+func (c *CheckoutRequest) Validate() errpkg.ErrorService {
+	c.CouponCode = NormalizeCouponCode(c.CouponCode)
+
+	return validation.New().
+		UUID("store_id", c.StoreID).
+		MaxLength("coupon_code", c.CouponCode, MaxCouponCodeLength).
+		Error()
+}
+
+// OrderStatusRequest move an order by hand, refunds go through RefundRequest
+// so the money follows the status and only a captured payment mark an order
+// paid
+type OrderStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+func (o *OrderStatusRequest) Validate() errpkg.ErrorService {
+	return validation.New().
+		OneOf("status", o.Status, OrderPreparing, OrderReady, OrderCompleted, OrderCancelled).
+		MaxLength("reason", o.Reason, MaxReasonLength).
+		Error()
+}
+
+type OrderCancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (o *OrderCancelRequest) Validate() errpkg.ErrorService {
+	return validation.New().
+		MaxLength("reason", o.Reason, MaxReasonLength).
+		Error()
+}
+
+type ProductStockRequest struct {
+	Stock *int `json:"stock"`
+}
+
+// Validate the request, null stock stop tracking the stock of the product
+func (p *ProductStockRequest) Validate() errpkg.ErrorService {
+	v := validation.New()
+	if p.Stock != nil {
+		v.Min("stock", float64(*p.Stock), 0)
+	}
+
+	return v.Error()
+}
+
+type HttpOrderIdParams struct {
+	ID string `uri:"id"`
+}
+
+type HttpStoreOrderParams struct {
+	StoreID string `uri:"id"`
+	OrderID string `uri:"orderId"`
+}
+
+type HttpOrderQuery struct {
+	Status string `form:"status"`
+	Limit  int    `form:"limit"`
+	Page   int    `form:"page"`
+}
+
+func (h *HttpOrderQuery) Validate() errpkg.ErrorService {
+	if h.Status != "" {
+		if err := validation.New().
+			OneOf("status", h.Status, OrderPending, OrderPaid, OrderPreparing, OrderReady, OrderCompleted, OrderCancelled, OrderPartiallyRefunded, OrderRefunded).
+			Error(); err != nil {
+			return err
+		}
+	}
+	if h.Limit <= 0 {
+		h.Limit = 10
+	}
+	if h.Limit > MaxOrderLimit {
+		h.Limit = MaxOrderLimit
+	}
+	if h.Page <= 0 {
+		h.Page = 1
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from     string
+		to       string
+		expected bool
+	}{
+		{OrderPending, OrderPaid, true},
+		{OrderPending, OrderCancelled, true},
+		{OrderPending, OrderPreparing, false},
+		{OrderPaid, OrderPreparing, true},
+		{OrderPaid, OrderRefunded, true},
+		{OrderPaid, OrderCancelled, false},
+		{OrderPreparing, OrderCancelled, false},
+		{OrderPreparing, OrderReady, true},
+		{OrderReady, OrderCompleted, true},
+		{OrderReady, OrderCancelled, false},
+		{OrderCompleted, OrderRefunded, true},
+		{OrderCompleted, OrderPending, false},
+		{OrderCancelled, OrderPaid, false},
+		{OrderRefunded, OrderCompleted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CanTransition(tt.from, tt.to))
+		})
+	}
+}
+
+func TestStoreOpen(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2024, 1, 1, hour, 30, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		start    int
+		end      int
+		hour     int
+		expected bool
+	}{
+		{"within hours", 8, 22, 12, true},
+		{"on opening hour", 8, 22, 8, true},
+		{"on closing hour", 8, 22, 22, false},
+		{"before opening", 8, 22, 7, false},
+		{"all day", 0, 0, 3, true},
+		{"overnight before midnight", 20, 4, 23, true},
+		{"overnight after midnight", 20, 4, 2, true},
+		{"overnight closed", 20, 4, 12, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, StoreOpen(tt.start, tt.end, at(tt.hour)))
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/mergepatch"
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// patchReader decode members of a merge patch, every failing member is
+// collected on the validator together with members the patch does not know.
+// Prefix is the path of a nested patch, prepended to the failing fields.
+type patchReader struct {
+	doc    mergepatch.Document
+	v      *validation.Validator
+	known  map[string]bool
+	prefix string
+}
+
+func newPatchReader(doc mergepatch.Document) *patchReader {
+	return &patchReader{
+		doc:   doc,
+		v:     validation.New(),
+		known: make(map[string]bool),
+	}
+}
+
+// decode member into val, return false when the member is missing or
+// invalid. Null is accepted only on nullable member and leave val empty.
+func (r *patchReader) decode(field string, nullable bool, val any) bool {
+	r.known[field] = true
+	if !r.doc.Has(field) {
+		return false
+	}
+
+	path := r.prefix + field
+	if r.doc.IsNull(field) {
+		r.v.Check(nullable, path, validation.ReasonRequired, fmt.Sprintf("missing %s", fieldLabel(path)), nil)
+		return nullable
+	}
+
+	if err := r.doc.Decode(field, val); err != nil {
+		r.v.Check(false, path, validation.ReasonInvalidType, fmt.Sprintf("%s has an invalid type", fieldLabel(path)), nil)
+		return false
+	}
+
+	return true
+}
+
+func (r *patchReader) String(field string, nullable bool) *string {
+	var val string
+	if !r.decode(field, nullable, &val) {
+		return nil
+	}
+	return &val
+}
+
+func (r *patchReader) Int(field string) *int {
+	var val int
+	if !r.decode(field, false, &val) {
+		return nil
+	}
+	return &val
+}
+
+func (r *patchReader) Float(field string) *float32 {
+	var val float32
+	if !r.decode(field, false, &val) {
+		return nil
+	}
+	return &val
+}
+
+// GeoPoint decode a nullable point, null is returned as an invalid point
+func (r *patchReader) GeoPoint(field string) *NullGeoPoint {
+	var val *GeoPoint
+	if !r.decode(field, true, &val) {
+		return nil
+	}
+	if val == nil {
+		return &NullGeoPoint{}
+	}
+	return &NullGeoPoint{GeoPoint: *val, Valid: true}
+}
+
+// Address decode a nested patch of address, null district and postal code
+// remove the part
+func (r *patchReader) Address(field string) *AddressPatch {
+	var doc mergepatch.Document
+	if !r.decode(field, false, &doc) {
+		return nil
+	}
+
+	nested := &patchReader{
+		doc:    doc,
+		v:      r.v,
+		known:  make(map[string]bool),
+		prefix: r.prefix + field + ".",
+	}
+	p := &AddressPatch{
+		Street:     nested.String("street", false),
+		District:   nested.String("district", true),
+		City:       nested.String("city", false),
+		Province:   nested.String("province", false),
+		PostalCode: nested.String("postal_code", true),
+		Country:    nested.String("country", false),
+	}
+	nested.unknown()
+
+	return p
+}
+
+// unknown report the members the patch does not know
+func (r *patchReader) unknown() {
+	for _, field := range r.doc.Fields() {
+		path := r.prefix + field
+		r.v.Check(r.known[field], path, validation.ReasonUnknownField, fmt.Sprintf("%s is not a known field", fieldLabel(path)), nil)
+	}
+}
+
+// Error report invalid and unknown members
+func (r *patchReader) Error() errpkg.ErrorService {
+	r.unknown()
+
+	return r.v.Error()
+}
+
+func fieldLabel(field string) string {
+	return strings.ReplaceAll(field, "_", " ")
+}
+
+// StorePatch is a validated merge patch of store, nil field is not changed,
+// empty TaxRegion remove the tax region and invalid Location the location.
+// PostalAddress is merged on the current address and take over Address when
+// both are given.
+type StorePatch struct {
+	Name                 *string
+	Url                  *string
+	Address              *string
+	PostalAddress        *AddressPatch
+	Phone                *string
+	OperationalTimeStart *int
+	OperationalTimeEnd   *int
+	TaxRegion            *string
+	Location             *NullGeoPoint
+	Version              int
+}
+
+func NewStorePatch(doc mergepatch.Document) (*StorePatch, errpkg.ErrorService) {
+	r := newPatchReader(doc)
+	p := &StorePatch{
+		Name:                 r.String("name", false),
+		Address:              r.String("address", false),
+		PostalAddress:        r.Address("postal_address"),
+		Phone:                r.String("phone", false),
+		OperationalTimeStart: r.Int("operational_time_start"),
+		OperationalTimeEnd:   r.Int("operational_time_end"),
+		TaxRegion:            r.String("tax_region", true),
+		Location:             r.GeoPoint("location"),
+	}
+
+	if p.Name != nil {
+		r.v.Required("name", *p.Name).
+			MaxLength("name", *p.Name, MaxStoreNameLength)
+	}
+	if p.Address != nil {
+		r.v.Required("address", *p.Address)
+	}
+	if p.PostalAddress != nil {
+		p.PostalAddress.Validate(r.v, "postal_address")
+	}
+	if p.Phone != nil {
+		*p.Phone = validation.NormalizePhone(*p.Phone)
+		r.v.Required("phone", *p.Phone).
+			MaxLength("phone", *p.Phone, MaxPhoneLength).
+			Phone("phone", *p.Phone)
+	}
+	if p.OperationalTimeStart != nil {
+		r.v.Range("operational_time_start", float64(*p.OperationalTimeStart), 0, 23)
+	}
+	if p.OperationalTimeEnd != nil {
+		r.v.Range("operational_time_end", float64(*p.OperationalTimeEnd), 0, 23)
+	}
+	if p.TaxRegion != nil {
+		region := NormalizeTaxRegion(*p.TaxRegion)
+		p.TaxRegion = &region
+		r.v.MaxLength("tax_region", region, MaxTaxRegionLength)
+	}
+	if p.Location != nil && p.Location.Valid {
+		p.Location.Validate(r.v, "location")
+	}
+
+	if err := r.Error(); err != nil {
+		return nil, err
+	}
+	if p.Name != nil {
+		url := CreateSlug(*p.Name)
+		p.Url = &url
+	}
+
+	return p, nil
+}
+
+// ProductPatch is a validated merge patch of product, nil field is not
+// changed, empty Sku remove the sku and empty Category the category
+type ProductPatch struct {
+	Name        *string
+	Url         *string
+	Price       *float32
+	Description *string
+	Sku         *string
+	Category    *string
+	Weight      *int
+	StoreID     *string
+	Version     int
+}
+
+func NewProductPatch(doc mergepatch.Document) (*ProductPatch, errpkg.ErrorService) {
+	r := newPatchReader(doc)
+	p := &ProductPatch{
+		Name:        r.String("name", false),
+		Price:       r.Float("price"),
+		Description: r.String("description", false),
+		Sku:         r.String("sku", true),
+		Category:    r.String("category", true),
+		Weight:      r.Int("weight"),
+		StoreID:     r.String("store_id", false),
+	}
+
+	if p.Name != nil {
+		r.v.Required("name", *p.Name).
+			MaxLength("name", *p.Name, MaxProductNameLength)
+	}
+	if p.Price != nil {
+		r.v.Min("price", float64(*p.Price), 0).
+			Max("price", float64(*p.Price), MaxProductPrice)
+	}
+	if p.Description != nil {
+		r.v.Required("description", *p.Description)
+	}
+	if p.Sku != nil {
+		r.v.MaxLength("sku", *p.Sku, MaxSkuLength)
+	}
+	if p.Category != nil {
+		category := NormalizeCategory(*p.Category)
+		p.Category = &category
+		r.v.MaxLength("category", category, MaxCategoryLength)
+	}
+	if p.Weight != nil {
+		r.v.Range("weight", float64(*p.Weight), 0, MaxProductWeight)
+	}
+	if p.StoreID != nil {
+		r.v.Required("store_id", *p.StoreID).
+			UUID("store_id", *p.StoreID)
+	}
+
+	if err := r.Error(); err != nil {
+		return nil, err
+	}
+	if p.Name != nil {
+		url := CreateSlug(*p.Name)
+		p.Url = &url
+	}
+
+	return p, nil
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/ijlik/store-app/pkg/mergepatch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStorePatch(t *testing.T) {
+	doc, err := mergepatch.Parse([]byte(`{"name":"Kopi Kenangan","phone":"+62 812-3456-7890"}`))
+	assert.NoError(t, err)
+
+	patch, errPatch := NewStorePatch(doc)
+	assert.Nil(t, errPatch)
+	assert.Equal(t, "Kopi Kenangan", *patch.Name)
+	assert.Equal(t, "kopi-kenangan", *patch.Url)
+	assert.Equal(t, "+6281234567890", *patch.Phone)
+	assert.Nil(t, patch.Address)
+	assert.Nil(t, patch.OperationalTimeStart)
+}
+
+func TestNewStorePatchEveryField(t *testing.T) {
+	doc, err := mergepatch.Parse([]byte(`{"name":null,"address":"","operational_time_start":"8","operational_time_end":24,"url":"kopi"}`))
+	assert.NoError(t, err)
+
+	_, errPatch := NewStorePatch(doc)
+	var fields []string
+	for _, field := range errPatch.GetFields() {
+		fields = append(fields, field.Field+":"+field.Reason)
+	}
+
+	assert.Equal(t, []string{
+		"name:REQUIRED",
+		"operational_time_start:INVALID_TYPE",
+		"address:REQUIRED",
+		"operational_time_end:OUT_OF_RANGE",
+		"url:UNKNOWN_FIELD",
+	}, fields)
+}
+
+func TestNewStorePatchPostalAddress(t *testing.T) {
+	doc, err := mergepatch.Parse([]byte(`{"postal_address":{"city":" Bandung ","district":null,"country":"id"}}`))
+	assert.NoError(t, err)
+
+	patch, errPatch := NewStorePatch(doc)
+	assert.Nil(t, errPatch)
+	assert.Equal(t, "", *patch.PostalAddress.District)
+	assert.Nil(t, patch.PostalAddress.Street)
+	assert.Equal(t, Address{Street: "Jl. Braga 10", City: "Bandung", Province: "Jawa Barat", Country: "ID"},
+		patch.PostalAddress.Apply(Address{Street: "Jl. Braga 10", District: "Sumur Bandung", City: "Jakarta", Province: "Jawa Barat"}))
+
+	doc, err = mergepatch.Parse([]byte(`{"postal_address":{"street":null,"city":"","postal_code":"#1","zip":"40111"}}`))
+	assert.NoError(t, err)
+
+	_, errPatch = NewStorePatch(doc)
+	var fields []string
+	for _, field := range errPatch.GetFields() {
+		fields = append(fields, field.Field+":"+field.Reason)
+	}
+
+	assert.Equal(t, []string{
+		"postal_address.street:REQUIRED",
+		"postal_address.zip:UNKNOWN_FIELD",
+		"postal_address.city:REQUIRED",
+		"postal_address.postal_code:INVALID_POSTAL_CODE",
+	}, fields)
+
+	doc, err = mergepatch.Parse([]byte(`{"postal_address":"Bandung"}`))
+	assert.NoError(t, err)
+
+	_, errPatch = NewStorePatch(doc)
+	assert.Equal(t, "postal_address", errPatch.GetFields()[0].Field)
+	assert.Equal(t, "INVALID_TYPE", errPatch.GetFields()[0].Reason)
+}
+
+func TestNewProductPatch(t *testing.T) {
+	doc, err := mergepatch.Parse([]byte(`{"price":20000,"sku":null}`))
+	assert.NoError(t, err)
+
+	patch, errPatch := NewProductPatch(doc)
+	assert.Nil(t, errPatch)
+	assert.Equal(t, float32(20000), *patch.Price)
+	assert.Equal(t, "", *patch.Sku)
+	assert.Nil(t, patch.Name)
+	assert.Nil(t, patch.Url)
+	assert.Nil(t, patch.StoreID)
+}
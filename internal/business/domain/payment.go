@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// status of a payment
+const (
+	PaymentPending    = "pending"
+	PaymentAuthorized = "authorized"
+	PaymentCaptured   = "captured"
+	PaymentFailed     = "failed"
+)
+
+// open payment younger than this is left to its webhook before it is
+// reconciled against the provider
+const PaymentReconcileDelay = 10 * time.Minute
+
+type Payment struct {
+	ID             string     `json:"id"`
+	OrderID        string     `json:"order_id"`
+	Provider       string     `json:"provider"`
+	Reference      string     `json:"reference"`
+	Amount         float32    `json:"amount"`
+	CapturedAmount float32    `json:"captured_amount"`
+	RefundedAmount float32    `json:"refunded_amount"`
+	Currency       string     `json:"currency"`
+	Status         string     `json:"status"`
+	ClientSecret   string     `json:"client_secret,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
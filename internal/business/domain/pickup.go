@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"time"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// status of a pickup booking, a cancelled booking give its place back
+const (
+	PickupBooked    = "booked"
+	PickupCancelled = "cancelled"
+)
+
+// PickupSettings slice the opening hours of the store into slots of
+// SlotMinutes taking at most Capacity bookings each. Slots starting within
+// LeadMinutes from now are not offered and slots are offered DaysAhead days
+// ahead, today included.
+type PickupSettings struct {
+	StoreID     string `json:"store_id"`
+	SlotMinutes int    `json:"slot_minutes"`
+	Capacity    int    `json:"capacity"`
+	LeadMinutes int    `json:"lead_minutes"`
+	DaysAhead   int    `json:"days_ahead"`
+}
+
+type PickupSlot struct {
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Capacity  int       `json:"capacity"`
+	Booked    int       `json:"booked"`
+	Available int       `json:"available"`
+}
+
+// PickupSlots generate the slots of a store opening at start hour and
+// closing at end hour, following StoreOpen. Now is in the time zone of the
+// store and the slots are in the same time zone; a slot never runs past the
+// closing hour. Opening of the day before is included so the slots of a
+// store open past midnight are offered until it closes.
+func PickupSlots(settings PickupSettings, start int, end int, now time.Time) []PickupSlot {
+	var (
+		slots            = []PickupSlot{}
+		length           = time.Duration(settings.SlotMinutes) * time.Minute
+		earliest         = now.Add(time.Duration(settings.LeadMinutes) * time.Minute)
+		year, month, day = now.Date()
+	)
+	if length <= 0 {
+		return slots
+	}
+
+	for d := -1; d < settings.DaysAhead; d++ {
+		opens := time.Date(year, month, day+d, start, 0, 0, 0, now.Location())
+		closes := time.Date(year, month, day+d, end, 0, 0, 0, now.Location())
+		if !closes.After(opens) {
+			closes = time.Date(year, month, day+d+1, end, 0, 0, 0, now.Location())
+		}
+
+		for at := opens; !at.Add(length).After(closes); at = at.Add(length) {
+			if at.Before(earliest) {
+				continue
+			}
+			slots = append(slots, PickupSlot{
+				StartsAt:  at,
+				EndsAt:    at.Add(length),
+				Capacity:  settings.Capacity,
+				Available: settings.Capacity,
+			})
+		}
+	}
+
+	return slots
+}
+
+// FindPickupSlot return the slot starting at startsAt, nil when the slot is
+// not offered
+func FindPickupSlot(slots []PickupSlot, startsAt time.Time) *PickupSlot {
+	for i := range slots {
+		if slots[i].StartsAt.Equal(startsAt) {
+			return &slots[i]
+		}
+	}
+
+	return nil
+}
+
+type PickupSettingsRequest struct {
+	SlotMinutes int `json:"slot_minutes"`
+	Capacity    int `json:"capacity"`
+	LeadMinutes int `json:"lead_minutes"`
+	DaysAhead   int `json:"days_ahead"`
+}
+
+// Validate the request, empty days ahead offer the slots of today only
+func (p *PickupSettingsRequest) Validate() errpkg.ErrorService {
+	if p.DaysAhead == 0 {
+		p.DaysAhead = 1
+	}
+
+	return validation.New().
+		Range("slot_minutes", float64(p.SlotMinutes), MinPickupSlotMinutes, MaxPickupSlotMinutes).
+		Range("capacity", float64(p.Capacity), 1, MaxPickupCapacity).
+		Range("lead_minutes", float64(p.LeadMinutes), 0, MaxPickupLeadMinutes).
+		Range("days_ahead", float64(p.DaysAhead), 1, MaxPickupDaysAhead).
+		Error()
+}
+
+type PickupBooking struct {
+	ID        string     `json:"id"`
+	StoreID   string     `json:"store_id"`
+	OrderID   string     `json:"order_id"`
+	StartsAt  time.Time  `json:"starts_at"`
+	EndsAt    time.Time  `json:"ends_at"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// PickupBookingRequest book the slot starting at StartsAt for the order, an
+// order already booked is moved to the new slot
+type PickupBookingRequest struct {
+	OrderID  string    `json:"order_id"`
+	StartsAt time.Time `json:"starts_at"`
+}
+
+func (p *PickupBookingRequest) Validate() errpkg.ErrorService {
+	return validation.New().
+		UUID("order_id", p.OrderID).
+		Check(!p.StartsAt.IsZero(), "starts_at", validation.ReasonRequired, "missing starts_at", nil).
+		Error()
+}
+
+// PickupBookable report whether an order in status may book a pickup slot
+func PickupBookable(status string) bool {
+	switch status {
+	case OrderPending, OrderPaid, OrderPreparing, OrderReady:
+		return true
+	}
+
+	return false
+}
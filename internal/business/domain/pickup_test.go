@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickupSlots(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+
+	tests := []struct {
+		name     string
+		settings PickupSettings
+		start    int
+		end      int
+		now      time.Time
+		count    int
+		first    time.Time
+		last     time.Time
+	}{
+		{
+			"lead time skip the early slots",
+			PickupSettings{SlotMinutes: 60, Capacity: 5, LeadMinutes: 30, DaysAhead: 2},
+			8, 22,
+			time.Date(2024, 1, 1, 10, 30, 0, 0, wib),
+			25,
+			time.Date(2024, 1, 1, 11, 0, 0, 0, wib),
+			time.Date(2024, 1, 2, 21, 0, 0, 0, wib),
+		},
+		{
+			"open past midnight",
+			PickupSettings{SlotMinutes: 60, Capacity: 5, DaysAhead: 1},
+			20, 2,
+			time.Date(2024, 1, 2, 0, 30, 0, 0, wib),
+			7,
+			time.Date(2024, 1, 2, 1, 0, 0, 0, wib),
+			time.Date(2024, 1, 3, 1, 0, 0, 0, wib),
+		},
+		{
+			"slot never runs past closing",
+			PickupSettings{SlotMinutes: 45, Capacity: 5, DaysAhead: 1},
+			8, 10,
+			time.Date(2024, 1, 1, 7, 0, 0, 0, wib),
+			2,
+			time.Date(2024, 1, 1, 8, 0, 0, 0, wib),
+			time.Date(2024, 1, 1, 8, 45, 0, 0, wib),
+		},
+		{
+			"open all day",
+			PickupSettings{SlotMinutes: 240, Capacity: 5, DaysAhead: 1},
+			0, 0,
+			time.Date(2024, 1, 1, 0, 0, 0, 0, wib),
+			6,
+			time.Date(2024, 1, 1, 0, 0, 0, 0, wib),
+			time.Date(2024, 1, 1, 20, 0, 0, 0, wib),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slots := PickupSlots(tt.settings, tt.start, tt.end, tt.now)
+			assert.Len(t, slots, tt.count)
+			assert.Equal(t, tt.first, slots[0].StartsAt)
+			assert.Equal(t, tt.last, slots[len(slots)-1].StartsAt)
+			assert.Equal(t, tt.settings.Capacity, slots[0].Available)
+			assert.Equal(t, time.Duration(tt.settings.SlotMinutes)*time.Minute, slots[0].EndsAt.Sub(slots[0].StartsAt))
+		})
+	}
+}
+
+func TestFindPickupSlot(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	slots := PickupSlots(PickupSettings{SlotMinutes: 30, Capacity: 5, DaysAhead: 1}, 8, 10, time.Date(2024, 1, 1, 7, 0, 0, 0, wib))
+
+	slot := FindPickupSlot(slots, time.Date(2024, 1, 1, 2, 30, 0, 0, time.UTC))
+	assert.NotNil(t, slot)
+	assert.Equal(t, time.Date(2024, 1, 1, 9, 30, 0, 0, wib), slot.StartsAt)
+
+	assert.Nil(t, FindPickupSlot(slots, time.Date(2024, 1, 1, 9, 15, 0, 0, wib)))
+	assert.Nil(t, FindPickupSlot(slots, time.Date(2024, 1, 1, 10, 0, 0, 0, wib)))
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"time"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// status of a price schedule, pending until effective_from, active until
+// effective_to, schedule without effective_to is done once applied
+const (
+	PriceSchedulePending   = "pending"
+	PriceScheduleActive    = "active"
+	PriceScheduleDone      = "done"
+	PriceScheduleCancelled = "cancelled"
+)
+
+type PriceHistory struct {
+	ID            int64     `json:"id"`
+	Price         float32   `json:"price"`
+	PreviousPrice *float32  `json:"previous_price"`
+	ScheduleID    string    `json:"schedule_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type PriceSchedule struct {
+	ID            string     `json:"id"`
+	Price         float32    `json:"price"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type PriceScheduleRequest struct {
+	Price         float32    `json:"price"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+}
+
+// Validate the request at now, effective_from in the past start the schedule
+// right away
+func (p *PriceScheduleRequest) Validate(now time.Time) errpkg.ErrorService {
+	v := validation.New().
+		Min("price", float64(p.Price), 0).
+		Max("price", float64(p.Price), MaxProductPrice).
+		Check(!p.EffectiveFrom.IsZero(), "effective_from", validation.ReasonRequired, "missing effective_from", nil)
+	if p.EffectiveTo != nil {
+		after := now
+		if p.EffectiveFrom.After(after) {
+			after = p.EffectiveFrom
+		}
+		v.After("effective_to", *p.EffectiveTo, after)
+	}
+	if err := v.Error(); err != nil {
+		return err
+	}
+
+	p.EffectiveFrom = p.EffectiveFrom.UTC()
+	if p.EffectiveTo != nil {
+		to := p.EffectiveTo.UTC()
+		p.EffectiveTo = &to
+	}
+
+	return nil
+}
+
+type HttpPriceScheduleParams struct {
+	ProductID  string `uri:"id"`
+	ScheduleID string `uri:"scheduleId"`
+}
+
+// PricePeriod is the time a schedule set the product price, a period without
+// To is a permanent change happening at From
+type PricePeriod struct {
+	From time.Time
+	To   *time.Time
+}
+
+// Conflicts report whether both periods can not be scheduled on the same
+// product: temporary periods must not overlap and a permanent change must not
+// happen inside a temporary period, it would be reverted at its end
+func (p PricePeriod) Conflicts(other PricePeriod) bool {
+	switch {
+	case p.To == nil && other.To == nil:
+		return p.From.Equal(other.From)
+	case p.To == nil:
+		return other.contains(p.From)
+	case other.To == nil:
+		return p.contains(other.From)
+	}
+
+	return p.From.Before(*other.To) && other.From.Before(*p.To)
+}
+
+func (p PricePeriod) contains(t time.Time) bool {
+	return !t.Before(p.From) && t.Before(*p.To)
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricePeriodConflicts(t *testing.T) {
+	at := func(day int) time.Time {
+		return time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)
+	}
+	until := func(day int) *time.Time {
+		t := at(day)
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		period   PricePeriod
+		other    PricePeriod
+		expected bool
+	}{
+		{"overlapping windows", PricePeriod{at(1), until(10)}, PricePeriod{at(5), until(15)}, true},
+		{"adjacent windows", PricePeriod{at(1), until(10)}, PricePeriod{at(10), until(15)}, false},
+		{"disjoint windows", PricePeriod{at(1), until(5)}, PricePeriod{at(6), until(15)}, false},
+		{"nested window", PricePeriod{at(1), until(20)}, PricePeriod{at(5), until(6)}, true},
+		{"permanent inside window", PricePeriod{at(5), nil}, PricePeriod{at(1), until(10)}, true},
+		{"window inside permanent", PricePeriod{at(1), until(10)}, PricePeriod{at(5), nil}, true},
+		{"permanent at window end", PricePeriod{at(10), nil}, PricePeriod{at(1), until(10)}, false},
+		{"permanent before window", PricePeriod{at(1), nil}, PricePeriod{at(5), until(10)}, false},
+		{"permanent at same time", PricePeriod{at(1), nil}, PricePeriod{at(1), nil}, true},
+		{"permanent at different time", PricePeriod{at(1), nil}, PricePeriod{at(2), nil}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.period.Conflicts(tt.other))
+		})
+	}
+}
+
+func TestPriceScheduleRequestValidate(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		request PriceScheduleRequest
+		fields  []string
+	}{
+		{"valid window", PriceScheduleRequest{Price: 100, EffectiveFrom: now, EffectiveTo: &future}, nil},
+		{"valid permanent", PriceScheduleRequest{Price: 100, EffectiveFrom: future}, nil},
+		{"missing from", PriceScheduleRequest{Price: 100}, []string{"effective_from"}},
+		{"negative price", PriceScheduleRequest{Price: -1, EffectiveFrom: now}, []string{"price"}},
+		{"to before from", PriceScheduleRequest{Price: 100, EffectiveFrom: future.Add(time.Hour), EffectiveTo: &future}, []string{"effective_to"}},
+		{"to in the past", PriceScheduleRequest{Price: 100, EffectiveFrom: past.Add(-time.Hour), EffectiveTo: &past}, []string{"effective_to"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate(now)
+			if tt.fields == nil {
+				assert.Nil(t, err)
+				return
+			}
+
+			var fields []string
+			for _, f := range err.GetFields() {
+				fields = append(fields, f.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
@@ -3,7 +3,7 @@ package domain
 import (
 	"fmt"
 	errpkg "github.com/ijlik/store-app/pkg/error"
-	"regexp"
+	"github.com/ijlik/store-app/pkg/validation"
 	"strings"
 	"time"
 )
@@ -13,9 +13,16 @@ type Product struct {
 	Name        string    `json:"name"`
 	Url         string    `json:"url"`
 	Price       float32   `json:"price"`
+	WasPrice    *float32  `json:"was_price,omitempty"`
 	Description string    `json:"description"`
+	Sku         string    `json:"sku,omitempty"`
+	Category    string    `json:"category,omitempty"`
+	SalePrice   *float32  `json:"sale_price,omitempty"`
+	Stock       *int      `json:"stock"`
+	Weight      int       `json:"weight"`
 	CreatedAt   time.Time `json:"created_at"`
 	Store       *Store    `json:"store"`
+	Version     int       `json:"-"`
 }
 
 type ProductRequest struct {
@@ -23,40 +30,40 @@ type ProductRequest struct {
 	Url         string  `json:"-"`
 	Price       float32 `json:"price"`
 	Description string  `json:"description"`
+	Sku         string  `json:"sku"`
+	Category    string  `json:"category"`
+	Weight      int     `json:"weight"`
 	StoreID     string  `json:"store_id"`
+	Version     int     `json:"-"`
 }
 
 func (p *ProductRequest) Validate() errpkg.ErrorService {
-	if p.Name == "" {
-		return errpkg.DefaultServiceError(errpkg.ErrBadRequest, "missing name")
+	p.Category = NormalizeCategory(p.Category)
+
+	err := validation.New().
+		Required("name", p.Name).
+		MaxLength("name", p.Name, MaxProductNameLength).
+		Min("price", float64(p.Price), 0).
+		Max("price", float64(p.Price), MaxProductPrice).
+		Required("description", p.Description).
+		MaxLength("sku", p.Sku, MaxSkuLength).
+		MaxLength("category", p.Category, MaxCategoryLength).
+		Range("weight", float64(p.Weight), 0, MaxProductWeight).
+		Required("store_id", p.StoreID).
+		UUID("store_id", p.StoreID).
+		Error()
+	if err != nil {
+		return err
 	}
-	if p.Price < 0 {
-		return errpkg.DefaultServiceError(errpkg.ErrBadRequest, "missing price")
-	}
-	if p.Description == "" {
-		return errpkg.DefaultServiceError(errpkg.ErrBadRequest, "missing description")
-	}
-	if p.StoreID == "" {
-		return errpkg.DefaultServiceError(errpkg.ErrBadRequest, "missing store id")
-	}
-	p.Url = CreateSlug(p.Name, true)
+	p.Url = CreateSlug(p.Name)
 
 	return nil
 }
 
-func CreateSlug(input string, isUnique bool) string {
-	input = strings.ToLower(input)
-
-	re := regexp.MustCompile("[^a-z0-9]+")
-	input = re.ReplaceAllString(input, "-")
-	input = strings.Trim(input, "-")
-
-	if isUnique {
-		unixCurrentTime := time.Now().Unix()
-		return fmt.Sprintf("%s-%d", input, unixCurrentTime)
-	} else {
-		return input
-	}
+// NormalizeCategory trim and lower case the category so promotions scoped to
+// a category match it regardless of the case given by the merchant
+func NormalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
 }
 
 type HttpProductUrlParams struct {
@@ -67,6 +74,39 @@ type HttpProductIdParams struct {
 	ID string `uri:"id"`
 }
 
+type HttpProductSkuParams struct {
+	Sku string `uri:"sku"`
+}
+
+type ProductBatchGetRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// Validate check every id and remove duplicate ids
+func (p *ProductBatchGetRequest) Validate() errpkg.ErrorService {
+	v := validation.New().
+		Check(len(p.IDs) > 0, "ids", validation.ReasonRequired, "missing ids", nil).
+		Check(len(p.IDs) <= MaxBatchGetIds, "ids", validation.ReasonMax, fmt.Sprintf("ids must be at most %d", MaxBatchGetIds), map[string]any{"max": MaxBatchGetIds})
+
+	var (
+		ids  []string
+		seen = make(map[string]bool, len(p.IDs))
+	)
+	for i, id := range p.IDs {
+		v.UUID(fmt.Sprintf("ids[%d]", i), id)
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if err := v.Error(); err != nil {
+		return err
+	}
+	p.IDs = ids
+
+	return nil
+}
+
 type SearchAndFilterProduct struct {
 	Limit         int    `form:"limit"`
 	Page          int    `form:"page"`
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProductRequestValidateEveryField(t *testing.T) {
+	request := &ProductRequest{
+		Price:   MaxProductPrice * 2,
+		StoreID: "store-id",
+	}
+
+	err := request.Validate()
+	var fields []string
+	for _, field := range err.GetFields() {
+		fields = append(fields, field.Field+":"+field.Reason)
+	}
+
+	assert.Equal(t, []string{
+		"name:REQUIRED",
+		"price:MAX",
+		"description:REQUIRED",
+		"store_id:INVALID_UUID",
+	}, fields)
+}
+
+func TestProductBatchGetRequestValidate(t *testing.T) {
+	id := "0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11"
+	request := &ProductBatchGetRequest{IDs: []string{id, id}}
+
+	assert.Nil(t, request.Validate())
+	assert.Equal(t, []string{id}, request.IDs)
+
+	request = &ProductBatchGetRequest{IDs: []string{id, "abc"}}
+	err := request.Validate()
+	assert.Equal(t, "ids[1]", err.GetFields()[0].Field)
+
+	request = &ProductBatchGetRequest{IDs: make([]string, MaxBatchGetIds+1)}
+	err = request.Validate()
+	assert.Equal(t, "ids", err.GetFields()[0].Field)
+	assert.Equal(t, "MAX", err.GetFields()[0].Reason)
+}
@@ -0,0 +1,402 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// discount type of a promotion, percentage of the unit price or fixed amount
+// off the unit price
+const (
+	DiscountPercentage = "percentage"
+	DiscountFixed      = "fixed"
+)
+
+// scope of a promotion, scope id is the store id, the category or the
+// product id
+const (
+	PromotionScopeStore    = "store"
+	PromotionScopeCategory = "category"
+	PromotionScopeProduct  = "product"
+)
+
+type Promotion struct {
+	ID                string     `json:"id"`
+	Name              string     `json:"name"`
+	Code              string     `json:"code,omitempty"`
+	DiscountType      string     `json:"discount_type"`
+	DiscountValue     float32    `json:"discount_value"`
+	Scope             string     `json:"scope"`
+	ScopeID           string     `json:"scope_id"`
+	StartsAt          time.Time  `json:"starts_at"`
+	EndsAt            *time.Time `json:"ends_at"`
+	UsageLimit        int        `json:"usage_limit"`
+	UsageLimitPerUser int        `json:"usage_limit_per_user"`
+	Used              int        `json:"used"`
+	Stackable         bool       `json:"stackable"`
+	Priority          int        `json:"priority"`
+	Active            bool       `json:"active"`
+	CreatedAt         time.Time  `json:"created_at"`
+
+	// redemptions of the user evaluating the basket
+	UsedByUser int `json:"-"`
+}
+
+// Available report whether the promotion can be used at now, zero usage
+// limit is unlimited
+func (p *Promotion) Available(now time.Time) bool {
+	return p.Active &&
+		!now.Before(p.StartsAt) &&
+		(p.EndsAt == nil || now.Before(*p.EndsAt)) &&
+		(p.UsageLimit == 0 || p.Used < p.UsageLimit) &&
+		(p.UsageLimitPerUser == 0 || p.UsedByUser < p.UsageLimitPerUser)
+}
+
+// Applies report whether the line is in the scope of the promotion
+func (p *Promotion) Applies(line BasketLine) bool {
+	switch p.Scope {
+	case PromotionScopeStore:
+		return p.ScopeID == line.StoreID
+	case PromotionScopeCategory:
+		return line.Category != "" && p.ScopeID == line.Category
+	case PromotionScopeProduct:
+		return p.ScopeID == line.ProductID
+	}
+
+	return false
+}
+
+// discount off unitPrice, never more than unitPrice
+func (p *Promotion) discount(unitPrice float32) float32 {
+	var amount float32
+	switch p.DiscountType {
+	case DiscountPercentage:
+		amount = RoundPrice(unitPrice * p.DiscountValue / 100)
+	case DiscountFixed:
+		amount = p.DiscountValue
+	}
+	if amount > unitPrice {
+		return unitPrice
+	}
+
+	return amount
+}
+
+type BasketLine struct {
+	ProductID string
+	StoreID   string
+	Category  string
+	Price     float32
+	Quantity  int
+	Weight    int
+}
+
+type AppliedPromotion struct {
+	PromotionID string  `json:"promotion_id"`
+	Name        string  `json:"name"`
+	Code        string  `json:"code,omitempty"`
+	Amount      float32 `json:"amount"`
+}
+
+type LineEvaluation struct {
+	ProductID  string             `json:"product_id"`
+	StoreID    string             `json:"store_id"`
+	Quantity   int                `json:"quantity"`
+	UnitPrice  float32            `json:"unit_price"`
+	SalePrice  float32            `json:"sale_price"`
+	Discount   float32            `json:"discount"`
+	Total      float32            `json:"total"`
+	Promotions []AppliedPromotion `json:"promotions"`
+}
+
+type BasketEvaluation struct {
+	Lines    []LineEvaluation `json:"lines"`
+	Subtotal float32          `json:"subtotal"`
+	Discount float32          `json:"discount"`
+	Total    float32          `json:"total"`
+}
+
+// Applied report whether the promotion discount at least one line
+func (b *BasketEvaluation) Applied(promotionId string) bool {
+	for _, line := range b.Lines {
+		for _, applied := range line.Promotions {
+			if applied.PromotionID == promotionId {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// EvaluateBasket compute the discounted price of every line at now. On each
+// line stackable promotions are combined, applied one after another on the
+// remaining price by priority, while a non stackable promotion apply alone;
+// the option giving the largest discount wins, ties go to the higher
+// priority. Promotions which are not available or not in scope are ignored.
+func EvaluateBasket(lines []BasketLine, promotions []*Promotion, now time.Time) *BasketEvaluation {
+	evaluation := &BasketEvaluation{Lines: make([]LineEvaluation, 0, len(lines))}
+
+	for _, line := range lines {
+		var candidates []*Promotion
+		for _, promotion := range promotions {
+			if promotion.Available(now) && promotion.Applies(line) {
+				candidates = append(candidates, promotion)
+			}
+		}
+
+		unitDiscount, applied := bestDiscount(line.Price, candidates)
+
+		result := LineEvaluation{
+			ProductID:  line.ProductID,
+			StoreID:    line.StoreID,
+			Quantity:   line.Quantity,
+			UnitPrice:  line.Price,
+			SalePrice:  RoundPrice(line.Price - unitDiscount),
+			Discount:   RoundPrice(unitDiscount * float32(line.Quantity)),
+			Promotions: []AppliedPromotion{},
+		}
+		result.Total = RoundPrice(result.SalePrice * float32(line.Quantity))
+		for _, a := range applied {
+			a.Amount = RoundPrice(a.Amount * float32(line.Quantity))
+			result.Promotions = append(result.Promotions, a)
+		}
+
+		evaluation.Lines = append(evaluation.Lines, result)
+		evaluation.Subtotal += RoundPrice(line.Price * float32(line.Quantity))
+		evaluation.Discount += result.Discount
+		evaluation.Total += result.Total
+	}
+
+	evaluation.Subtotal = RoundPrice(evaluation.Subtotal)
+	evaluation.Discount = RoundPrice(evaluation.Discount)
+	evaluation.Total = RoundPrice(evaluation.Total)
+
+	return evaluation
+}
+
+// SalePrice return the discounted unit price of a single product, false when
+// no promotion applies
+func SalePrice(line BasketLine, promotions []*Promotion, now time.Time) (float32, bool) {
+	line.Quantity = 1
+	evaluation := EvaluateBasket([]BasketLine{line}, promotions, now)
+	if evaluation.Discount <= 0 {
+		return 0, false
+	}
+
+	return evaluation.Lines[0].SalePrice, true
+}
+
+// return the unit discount of the best option and its promotions, amount of
+// the promotions is per unit
+func bestDiscount(unitPrice float32, candidates []*Promotion) (float32, []AppliedPromotion) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Priority > candidates[j].Priority
+	})
+
+	var (
+		best         float32
+		bestPriority = math.MinInt
+		bestApplied  []AppliedPromotion
+	)
+	choose := func(amount float32, priority int, applied []AppliedPromotion) {
+		if amount > best || (amount == best && amount > 0 && priority > bestPriority) {
+			best, bestPriority, bestApplied = amount, priority, applied
+		}
+	}
+
+	var (
+		stacked         float32
+		stackedPriority = math.MinInt
+		stackedApplied  []AppliedPromotion
+	)
+	for _, promotion := range candidates {
+		if !promotion.Stackable {
+			choose(promotion.discount(unitPrice), promotion.Priority, []AppliedPromotion{appliedPromotion(promotion, promotion.discount(unitPrice))})
+			continue
+		}
+
+		amount := promotion.discount(unitPrice - stacked)
+		if amount <= 0 {
+			continue
+		}
+		stacked += amount
+		stackedApplied = append(stackedApplied, appliedPromotion(promotion, amount))
+		if promotion.Priority > stackedPriority {
+			stackedPriority = promotion.Priority
+		}
+	}
+	if len(stackedApplied) > 0 {
+		choose(stacked, stackedPriority, stackedApplied)
+	}
+
+	return best, bestApplied
+}
+
+func appliedPromotion(promotion *Promotion, amount float32) AppliedPromotion {
+	return AppliedPromotion{
+		PromotionID: promotion.ID,
+		Name:        promotion.Name,
+		Code:        promotion.Code,
+		Amount:      amount,
+	}
+}
+
+// RoundPrice round price to two decimals
+func RoundPrice(price float32) float32 {
+	return float32(math.Round(float64(price)*100) / 100)
+}
+
+type PromotionRequest struct {
+	Name              string     `json:"name"`
+	Code              string     `json:"code"`
+	DiscountType      string     `json:"discount_type"`
+	DiscountValue     float32    `json:"discount_value"`
+	Scope             string     `json:"scope"`
+	ScopeID           string     `json:"scope_id"`
+	StartsAt          time.Time  `json:"starts_at"`
+	EndsAt            *time.Time `json:"ends_at"`
+	UsageLimit        int        `json:"usage_limit"`
+	UsageLimitPerUser int        `json:"usage_limit_per_user"`
+	Stackable         bool       `json:"stackable"`
+	Priority          int        `json:"priority"`
+}
+
+// Validate the request, code is upper cased and empty code create a
+// promotion applied without coupon
+func (p *PromotionRequest) Validate() errpkg.ErrorService {
+	p.Code = NormalizeCouponCode(p.Code)
+	if p.Scope == PromotionScopeCategory {
+		p.ScopeID = NormalizeCategory(p.ScopeID)
+	}
+
+	v := validation.New().
+		Required("name", p.Name).
+		MaxLength("name", p.Name, MaxPromotionNameLength).
+		MaxLength("code", p.Code, MaxCouponCodeLength).
+		OneOf("discount_type", p.DiscountType, DiscountPercentage, DiscountFixed).
+		Check(p.DiscountValue > 0, "discount_value", validation.ReasonMin, "discount value must be more than 0", map[string]any{"min": 0}).
+		OneOf("scope", p.Scope, PromotionScopeStore, PromotionScopeCategory, PromotionScopeProduct).
+		Required("scope_id", p.ScopeID).
+		Check(!p.StartsAt.IsZero(), "starts_at", validation.ReasonRequired, "missing starts_at", nil).
+		Min("usage_limit", float64(p.UsageLimit), 0).
+		Min("usage_limit_per_user", float64(p.UsageLimitPerUser), 0)
+
+	switch p.DiscountType {
+	case DiscountPercentage:
+		v.Max("discount_value", float64(p.DiscountValue), 100)
+	case DiscountFixed:
+		v.Max("discount_value", float64(p.DiscountValue), MaxProductPrice)
+	}
+	switch p.Scope {
+	case PromotionScopeStore, PromotionScopeProduct:
+		v.UUID("scope_id", p.ScopeID)
+	case PromotionScopeCategory:
+		v.MaxLength("scope_id", p.ScopeID, MaxCategoryLength)
+	}
+	if p.EndsAt != nil {
+		v.After("ends_at", *p.EndsAt, p.StartsAt)
+	}
+	if err := v.Error(); err != nil {
+		return err
+	}
+
+	p.StartsAt = p.StartsAt.UTC()
+	if p.EndsAt != nil {
+		ends := p.EndsAt.UTC()
+		p.EndsAt = &ends
+	}
+
+	return nil
+}
+
+// NormalizeCouponCode trim and upper case the code, codes are matched case
+// insensitively
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+type BasketItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type CouponValidateRequest struct {
+	Code  string       `json:"code"`
+	Items []BasketItem `json:"items"`
+}
+
+// Validate the request, quantities of a product given more than once are
+// added up
+func (c *CouponValidateRequest) Validate() errpkg.ErrorService {
+	c.Code = NormalizeCouponCode(c.Code)
+
+	v := validation.New().
+		Required("code", c.Code).
+		Check(len(c.Items) > 0, "items", validation.ReasonRequired, "missing items", nil).
+		Check(len(c.Items) <= MaxBasketItems, "items", validation.ReasonMax, fmt.Sprintf("items must be at most %d", MaxBasketItems), map[string]any{"max": MaxBasketItems})
+	for i, item := range c.Items {
+		v.UUID(fmt.Sprintf("items[%d].product_id", i), item.ProductID).
+			Range(fmt.Sprintf("items[%d].quantity", i), float64(item.Quantity), 1, MaxItemQuantity)
+	}
+	if err := v.Error(); err != nil {
+		return err
+	}
+
+	c.Items = MergeBasketItems(c.Items)
+
+	return nil
+}
+
+// MergeBasketItems add up the quantities of a product given more than once,
+// keeping the order of the first occurrence
+func MergeBasketItems(items []BasketItem) []BasketItem {
+	var (
+		merged []BasketItem
+		index  = make(map[string]int, len(items))
+	)
+	for _, item := range items {
+		if i, ok := index[item.ProductID]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.ProductID] = len(merged)
+		merged = append(merged, item)
+	}
+
+	return merged
+}
+
+type CouponValidation struct {
+	Promotion  *Promotion        `json:"promotion"`
+	Evaluation *BasketEvaluation `json:"evaluation"`
+}
+
+type HttpPromotionIdParams struct {
+	ID string `uri:"id"`
+}
+
+type HttpPromotionQuery struct {
+	Limit int `form:"limit"`
+	Page  int `form:"page"`
+}
+
+func (h *HttpPromotionQuery) Validate() errpkg.ErrorService {
+	if h.Limit <= 0 {
+		h.Limit = 10
+	}
+	if h.Limit > MaxPromotionLimit {
+		h.Limit = MaxPromotionLimit
+	}
+	if h.Page <= 0 {
+		h.Page = 1
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateBasket(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	line := BasketLine{ProductID: "product-1", StoreID: "store-1", Category: "coffee", Price: 20000, Quantity: 2}
+	promotion := func(id string, discountType string, value float32, scope string, scopeId string, stackable bool, priority int) *Promotion {
+		return &Promotion{ID: id, DiscountType: discountType, DiscountValue: value, Scope: scope, ScopeID: scopeId, StartsAt: now.Add(-time.Hour), Stackable: stackable, Priority: priority, Active: true}
+	}
+
+	tests := []struct {
+		name           string
+		promotions     []*Promotion
+		expectedSale   float32
+		expectedIds    []string
+		expectedTotal  float32
+		expectedAmount float32
+	}{
+		{
+			name:          "no promotion",
+			expectedSale:  20000,
+			expectedTotal: 40000,
+		},
+		{
+			name:           "percentage of category",
+			promotions:     []*Promotion{promotion("p1", DiscountPercentage, 10, PromotionScopeCategory, "coffee", false, 0)},
+			expectedSale:   18000,
+			expectedIds:    []string{"p1"},
+			expectedTotal:  36000,
+			expectedAmount: 4000,
+		},
+		{
+			name: "stackable combined on remaining price by priority",
+			promotions: []*Promotion{
+				promotion("p1", DiscountPercentage, 10, PromotionScopeStore, "store-1", true, 1),
+				promotion("p2", DiscountFixed, 2000, PromotionScopeProduct, "product-1", true, 2),
+			},
+			expectedSale:   16200,
+			expectedIds:    []string{"p2", "p1"},
+			expectedTotal:  32400,
+			expectedAmount: 7600,
+		},
+		{
+			name: "non stackable wins when larger",
+			promotions: []*Promotion{
+				promotion("p1", DiscountPercentage, 5, PromotionScopeStore, "store-1", true, 1),
+				promotion("p2", DiscountFixed, 1000, PromotionScopeProduct, "product-1", true, 1),
+				promotion("p3", DiscountPercentage, 25, PromotionScopeCategory, "coffee", false, 0),
+			},
+			expectedSale:   15000,
+			expectedIds:    []string{"p3"},
+			expectedTotal:  30000,
+			expectedAmount: 10000,
+		},
+		{
+			name: "tie goes to higher priority",
+			promotions: []*Promotion{
+				promotion("p1", DiscountFixed, 3000, PromotionScopeStore, "store-1", false, 1),
+				promotion("p2", DiscountPercentage, 15, PromotionScopeProduct, "product-1", false, 5),
+			},
+			expectedSale:   17000,
+			expectedIds:    []string{"p2"},
+			expectedTotal:  34000,
+			expectedAmount: 6000,
+		},
+		{
+			name:           "discount capped at price",
+			promotions:     []*Promotion{promotion("p1", DiscountFixed, 50000, PromotionScopeProduct, "product-1", false, 0)},
+			expectedSale:   0,
+			expectedIds:    []string{"p1"},
+			expectedTotal:  0,
+			expectedAmount: 40000,
+		},
+		{
+			name: "unavailable and out of scope ignored",
+			promotions: []*Promotion{
+				promotion("p1", DiscountFixed, 1000, PromotionScopeStore, "store-2", false, 0),
+				promotion("p2", DiscountFixed, 1000, PromotionScopeCategory, "tea", false, 0),
+				{ID: "p3", DiscountType: DiscountFixed, DiscountValue: 1000, Scope: PromotionScopeStore, ScopeID: "store-1", StartsAt: later, Active: true},
+				{ID: "p4", DiscountType: DiscountFixed, DiscountValue: 1000, Scope: PromotionScopeStore, ScopeID: "store-1", StartsAt: now.Add(-time.Hour), EndsAt: &now, Active: true},
+				{ID: "p5", DiscountType: DiscountFixed, DiscountValue: 1000, Scope: PromotionScopeStore, ScopeID: "store-1", StartsAt: now.Add(-time.Hour), UsageLimit: 5, Used: 5, Active: true},
+				{ID: "p6", DiscountType: DiscountFixed, DiscountValue: 1000, Scope: PromotionScopeStore, ScopeID: "store-1", StartsAt: now.Add(-time.Hour), UsageLimitPerUser: 1, UsedByUser: 1, Active: true},
+				{ID: "p7", DiscountType: DiscountFixed, DiscountValue: 1000, Scope: PromotionScopeStore, ScopeID: "store-1", StartsAt: now.Add(-time.Hour)},
+			},
+			expectedSale:  20000,
+			expectedTotal: 40000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluation := EvaluateBasket([]BasketLine{line}, tt.promotions, now)
+			assert.Len(t, evaluation.Lines, 1)
+
+			result := evaluation.Lines[0]
+			assert.Equal(t, tt.expectedSale, result.SalePrice)
+			assert.Equal(t, tt.expectedTotal, result.Total)
+			assert.Equal(t, tt.expectedAmount, result.Discount)
+			assert.Equal(t, float32(40000), evaluation.Subtotal)
+			assert.Equal(t, tt.expectedTotal, evaluation.Total)
+
+			var ids []string
+			for _, applied := range result.Promotions {
+				ids = append(ids, applied.PromotionID)
+			}
+			assert.Equal(t, tt.expectedIds, ids)
+		})
+	}
+}
+
+func TestEvaluateBasketRounding(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	promotions := []*Promotion{{ID: "p1", DiscountType: DiscountPercentage, DiscountValue: 15, Scope: PromotionScopeStore, ScopeID: "store-1", StartsAt: now, Active: true}}
+
+	evaluation := EvaluateBasket([]BasketLine{{ProductID: "product-1", StoreID: "store-1", Price: 9.99, Quantity: 3}}, promotions, now)
+	assert.Equal(t, float32(8.49), evaluation.Lines[0].SalePrice)
+	assert.Equal(t, float32(4.5), evaluation.Discount)
+	assert.Equal(t, float32(25.47), evaluation.Total)
+	assert.True(t, evaluation.Applied("p1"))
+	assert.False(t, evaluation.Applied("p2"))
+}
+
+func TestSalePrice(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	line := BasketLine{ProductID: "product-1", StoreID: "store-1", Price: 20000, Quantity: 4}
+	promotions := []*Promotion{{ID: "p1", DiscountType: DiscountFixed, DiscountValue: 2500, Scope: PromotionScopeProduct, ScopeID: "product-1", StartsAt: now, Active: true}}
+
+	price, ok := SalePrice(line, promotions, now)
+	assert.True(t, ok)
+	assert.Equal(t, float32(17500), price)
+
+	_, ok = SalePrice(line, nil, now)
+	assert.False(t, ok)
+}
+
+func TestPromotionRequestValidate(t *testing.T) {
+	starts := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := starts.Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		request       PromotionRequest
+		expectedField string
+	}{
+		{"valid", PromotionRequest{Name: "Weekend", Code: " hemat10 ", DiscountType: DiscountPercentage, DiscountValue: 10, Scope: PromotionScopeCategory, ScopeID: " Coffee ", StartsAt: starts}, ""},
+		{"missing name", PromotionRequest{DiscountType: DiscountFixed, DiscountValue: 10, Scope: PromotionScopeCategory, ScopeID: "coffee", StartsAt: starts}, "name"},
+		{"unknown discount type", PromotionRequest{Name: "Weekend", DiscountType: "free", DiscountValue: 10, Scope: PromotionScopeCategory, ScopeID: "coffee", StartsAt: starts}, "discount_type"},
+		{"percentage over 100", PromotionRequest{Name: "Weekend", DiscountType: DiscountPercentage, DiscountValue: 120, Scope: PromotionScopeCategory, ScopeID: "coffee", StartsAt: starts}, "discount_value"},
+		{"store scope not uuid", PromotionRequest{Name: "Weekend", DiscountType: DiscountFixed, DiscountValue: 10, Scope: PromotionScopeStore, ScopeID: "store-1", StartsAt: starts}, "scope_id"},
+		{"missing starts at", PromotionRequest{Name: "Weekend", DiscountType: DiscountFixed, DiscountValue: 10, Scope: PromotionScopeCategory, ScopeID: "coffee"}, "starts_at"},
+		{"ends before starts", PromotionRequest{Name: "Weekend", DiscountType: DiscountFixed, DiscountValue: 10, Scope: PromotionScopeCategory, ScopeID: "coffee", StartsAt: starts, EndsAt: &before}, "ends_at"},
+		{"negative usage limit", PromotionRequest{Name: "Weekend", DiscountType: DiscountFixed, DiscountValue: 10, Scope: PromotionScopeCategory, ScopeID: "coffee", StartsAt: starts, UsageLimit: -1}, "usage_limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.expectedField == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, "HEMAT10", tt.request.Code)
+				assert.Equal(t, "coffee", tt.request.ScopeID)
+				return
+			}
+			assert.NotNil(t, err)
+			assert.Equal(t, tt.expectedField, err.GetFields()[0].Field)
+		})
+	}
+}
+
+func TestCouponValidateRequestMergeItems(t *testing.T) {
+	request := CouponValidateRequest{
+		Code: "hemat10",
+		Items: []BasketItem{
+			{ProductID: "0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11", Quantity: 1},
+			{ProductID: "1c8f4d9f-9a64-4b64-8a4d-6b4d2a3a7e22", Quantity: 2},
+			{ProductID: "0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11", Quantity: 3},
+		},
+	}
+
+	assert.Nil(t, request.Validate())
+	assert.Equal(t, "HEMAT10", request.Code)
+	assert.Equal(t, []BasketItem{
+		{ProductID: "0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11", Quantity: 4},
+		{ProductID: "1c8f4d9f-9a64-4b64-8a4d-6b4d2a3a7e22", Quantity: 2},
+	}, request.Items)
+}
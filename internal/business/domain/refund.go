@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// status of a refund, pending until the provider accepts it
+const (
+	RefundPending   = "pending"
+	RefundSucceeded = "succeeded"
+	RefundFailed    = "failed"
+)
+
+// UnpaidRefundReason is the reason of the refund giving back a payment
+// captured after its order closed or short of the order total
+const UnpaidRefundReason = "payment does not pay its order"
+
+// OrderRefundable report whether money of an order in status may be given
+// back
+func OrderRefundable(status string) bool {
+	return CanTransition(status, OrderRefunded)
+}
+
+// OrderUnpaid report whether an order in status was never paid, a payment
+// captured for it is given back whole
+func OrderUnpaid(status string) bool {
+	return status == OrderPending || status == OrderCancelled
+}
+
+// RefundLineAmount return the amount of refunding quantity units of a line
+// which total is paid for ordered units and refunded units have been given
+// refundedAmount back. The last units take what is left of the line so the
+// refunds add up to its total.
+func RefundLineAmount(total float32, ordered int, refunded int, refundedAmount float32, quantity int) float32 {
+	if refunded+quantity >= ordered {
+		return RoundPrice(total - refundedAmount)
+	}
+
+	return RoundPrice(total * float32(quantity) / float32(ordered))
+}
+
+type Refund struct {
+	ID        string       `json:"id"`
+	OrderID   string       `json:"order_id"`
+	PaymentID string       `json:"payment_id"`
+	Amount    float32      `json:"amount"`
+	Reason    string       `json:"reason"`
+	Restock   bool         `json:"restock"`
+	Status    string       `json:"status"`
+	Items     []RefundItem `json:"items"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt *time.Time   `json:"updated_at,omitempty"`
+}
+
+type RefundItem struct {
+	OrderItemID int64   `json:"order_item_id"`
+	Quantity    int     `json:"quantity"`
+	Amount      float32 `json:"amount"`
+}
+
+// RefundRequest give back the items, no item refund everything left on the
+// order. Restock put the refunded quantities back on the product stock.
+type RefundRequest struct {
+	Items   []RefundItemRequest `json:"items"`
+	Restock bool                `json:"restock"`
+	Reason  string              `json:"reason"`
+}
+
+type RefundItemRequest struct {
+	OrderItemID int64 `json:"order_item_id"`
+	Quantity    int   `json:"quantity"`
+}
+
+// Validate the request, quantities of a line given more than once are
+// added up
+func (r *RefundRequest) Validate() errpkg.ErrorService {
+	v := validation.New().
+		Required("reason", r.Reason).
+		MaxLength("reason", r.Reason, MaxReasonLength).
+		Check(len(r.Items) <= MaxBasketItems, "items", validation.ReasonMax, fmt.Sprintf("items must be at most %d", MaxBasketItems), map[string]any{"max": MaxBasketItems})
+	for i, item := range r.Items {
+		v.Min(fmt.Sprintf("items[%d].order_item_id", i), float64(item.OrderItemID), 1).
+			Range(fmt.Sprintf("items[%d].quantity", i), float64(item.Quantity), 1, MaxItemQuantity)
+	}
+	if err := v.Error(); err != nil {
+		return err
+	}
+
+	var (
+		merged []RefundItemRequest
+		index  = make(map[int64]int, len(r.Items))
+	)
+	for _, item := range r.Items {
+		if i, ok := index[item.OrderItemID]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.OrderItemID] = len(merged)
+		merged = append(merged, item)
+	}
+	r.Items = merged
+
+	return nil
+}
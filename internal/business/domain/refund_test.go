@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefundLineAmount(t *testing.T) {
+	tests := []struct {
+		name           string
+		total          float32
+		ordered        int
+		refunded       int
+		refundedAmount float32
+		quantity       int
+		expected       float32
+	}{
+		{"one of three", 10000, 3, 0, 0, 1, 3333.33},
+		{"second of three", 10000, 3, 1, 3333.33, 1, 3333.33},
+		{"last unit take the rest", 10000, 3, 2, 6666.66, 1, 3333.34},
+		{"whole line", 36000, 2, 0, 0, 2, 36000},
+		{"discounted line", 15300, 2, 0, 0, 1, 7650},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RefundLineAmount(tt.total, tt.ordered, tt.refunded, tt.refundedAmount, tt.quantity))
+		})
+	}
+}
+
+func TestOrderRefundable(t *testing.T) {
+	assert.False(t, OrderRefundable(OrderPending))
+	assert.True(t, OrderRefundable(OrderPaid))
+	assert.True(t, OrderRefundable(OrderCompleted))
+	assert.True(t, OrderRefundable(OrderPartiallyRefunded))
+	assert.False(t, OrderRefundable(OrderCancelled))
+	assert.False(t, OrderRefundable(OrderRefunded))
+}
+
+func TestRefundRequestValidateMergeItems(t *testing.T) {
+	request := RefundRequest{
+		Reason: "wrong item",
+		Items: []RefundItemRequest{
+			{OrderItemID: 1, Quantity: 1},
+			{OrderItemID: 2, Quantity: 1},
+			{OrderItemID: 1, Quantity: 2},
+		},
+	}
+
+	assert.Nil(t, request.Validate())
+	assert.Equal(t, []RefundItemRequest{{OrderItemID: 1, Quantity: 3}, {OrderItemID: 2, Quantity: 1}}, request.Items)
+
+	missing := RefundRequest{Items: []RefundItemRequest{{OrderItemID: 0, Quantity: 1}}}
+	assert.NotNil(t, missing.Validate())
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ijlik/store-app/pkg/slug"
+)
+
+// slugs kept for pages of the clients, route segments are added by
+// ReserveSlugs when their route is registered
+var reservedSlugs = []string{"new", "edit"}
+
+var slugger = newSlugger(reservedSlugs)
+
+func newSlugger(reserved []string) *slug.Slugger {
+	return slug.New(
+		slug.WithMaxLength(MaxUrlLength),
+		slug.WithReserved(reserved...),
+	)
+}
+
+// ReserveSlugs reserve words used as route segment next to the url of store
+// or product, it must be called while registering the routes, before
+// serving
+func ReserveSlugs(words ...string) {
+	reservedSlugs = append(reservedSlugs, words...)
+	slugger = newSlugger(reservedSlugs)
+}
+
+// CreateSlug build url friendly base slug from name, example Café Ümlaut
+// become cafe-umlaut. The slug is not unique, use NextSlug to allocate a
+// free one.
+func CreateSlug(input string) string {
+	return slugger.Make(input)
+}
+
+// RandomSlug report whether CreateSlug give name a random base, a new one on
+// every call
+func RandomSlug(name string) bool {
+	return slugger.Fallback(name)
+}
+
+// NextSlug return base when it is not taken, otherwise base followed by the
+// smallest free counter starting from 2, example kopi-kenangan-2
+func NextSlug(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, url := range taken {
+		used[url] = true
+	}
+
+	if !used[base] {
+		return base
+	}
+
+	for i := 2; ; i++ {
+		url := withCounter(base, i)
+		if !used[url] {
+			return url
+		}
+	}
+}
+
+// base followed by the counter, base is truncated to keep the url fit on
+// the url column
+func withCounter(base string, i int) string {
+	suffix := fmt.Sprintf("-%d", i)
+	return slug.Truncate(base, MaxUrlLength-len(suffix)) + suffix
+}
+
+// longest counter suffix expected on a url, used to look up urls having a
+// truncated base
+const maxCounterSuffix = len("-999999")
+
+// SlugPattern return LIKE pattern matching every url NextSlug may produce
+// from base
+func SlugPattern(base string) string {
+	if len(base)+maxCounterSuffix <= MaxUrlLength {
+		return base + "-%"
+	}
+
+	return slug.Truncate(base, MaxUrlLength-maxCounterSuffix) + "%"
+}
+
+// SlugOf report whether url is base or base followed by a counter, so a
+// rename resulting in the same base keep its url
+func SlugOf(url, base string) bool {
+	if url == base {
+		return true
+	}
+
+	i := strings.LastIndexByte(url, '-')
+	if i < 0 {
+		return false
+	}
+
+	n, err := strconv.Atoi(url[i+1:])
+	return err == nil && n >= 2 && withCounter(base, n) == url
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextSlug(t *testing.T) {
+	assert.Equal(t, "kopi", NextSlug("kopi", nil))
+	assert.Equal(t, "kopi", NextSlug("kopi", []string{"kopi-2"}))
+	assert.Equal(t, "kopi-2", NextSlug("kopi", []string{"kopi"}))
+	assert.Equal(t, "kopi-3", NextSlug("kopi", []string{"kopi", "kopi-2", "kopi-susu"}))
+	assert.Equal(t, "kopi-2", NextSlug("kopi", []string{"kopi", "kopi-3"}))
+}
+
+func TestSlugOf(t *testing.T) {
+	assert.True(t, SlugOf("kopi", "kopi"))
+	assert.True(t, SlugOf("kopi-2", "kopi"))
+	assert.True(t, SlugOf("kopi-12", "kopi"))
+	assert.False(t, SlugOf("kopi-1", "kopi"))
+	assert.False(t, SlugOf("kopi-02", "kopi"))
+	assert.False(t, SlugOf("kopi-susu", "kopi"))
+	assert.False(t, SlugOf("teh", "kopi"))
+}
+
+func TestCreateSlug(t *testing.T) {
+	assert.Equal(t, "kopi-kenangan-jakarta", CreateSlug("Kopi Kenangan – Jakarta"))
+	assert.Equal(t, "cafe-umlaut", CreateSlug("Café Ümlaut"))
+	assert.Equal(t, "strasse", CreateSlug("Straße"))
+	assert.Equal(t, "es-teh-manis", CreateSlug("  Es Teh, Manis!  "))
+}
+
+func TestNextSlugMaxLength(t *testing.T) {
+	base := CreateSlug(strings.Repeat("kopi ", 30))
+	url := NextSlug(base, []string{base})
+
+	assert.LessOrEqual(t, len(url), MaxUrlLength)
+	assert.True(t, strings.HasSuffix(url, "-2"))
+	assert.True(t, SlugOf(url, base))
+	assert.True(t, strings.HasPrefix(url, strings.TrimSuffix(SlugPattern(base), "%")))
+	assert.Equal(t, "kopi-%", SlugPattern("kopi"))
+}
+
+func TestCreateSlugReserved(t *testing.T) {
+	assert.Equal(t, "new-1", CreateSlug("New"))
+	assert.Regexp(t, "^[a-z0-9]{8}$", CreateSlug("咖啡店"))
+}
@@ -2,6 +2,8 @@ package domain
 
 import (
 	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/validation"
+	"strings"
 	"time"
 )
 
@@ -10,38 +12,64 @@ type Store struct {
 	Name                 string    `json:"name"`
 	Url                  string    `json:"url"`
 	Address              string    `json:"address"`
+	PostalAddress        Address   `json:"postal_address"`
 	Phone                string    `json:"phone"`
 	OperationalTimeStart int       `json:"operational_time_start"`
 	OperationalTimeEnd   int       `json:"operational_time_end"`
+	TaxRegion            string    `json:"tax_region,omitempty"`
+	Location             *GeoPoint `json:"location"`
 	CreatedAt            time.Time `json:"created_at"`
+	Version              int       `json:"-"`
 }
 
+// StoreRequest take the address as PostalAddress, Address is then its
+// formatted line. A request with the single line Address only is still
+// accepted and its postal address parsed from the line.
 type StoreRequest struct {
-	Name                 string `json:"name"`
-	Url                  string `json:"-"`
-	Address              string `json:"address"`
-	Phone                string `json:"phone"`
-	OperationalTimeStart int    `json:"operational_time_start"`
-	OperationalTimeEnd   int    `json:"operational_time_end"`
+	Name                 string    `json:"name"`
+	Url                  string    `json:"-"`
+	Address              string    `json:"address"`
+	PostalAddress        *Address  `json:"postal_address"`
+	Phone                string    `json:"phone"`
+	OperationalTimeStart int       `json:"operational_time_start"`
+	OperationalTimeEnd   int       `json:"operational_time_end"`
+	TaxRegion            string    `json:"tax_region"`
+	Location             *GeoPoint `json:"location"`
+	Version              int       `json:"-"`
 }
 
 func (s *StoreRequest) Validate() errpkg.ErrorService {
-	if s.Name == "" {
-		return errpkg.DefaultServiceError(errpkg.ErrBadRequest, "missing name")
-	}
-	if s.Address == "" {
-		return errpkg.DefaultServiceError(errpkg.ErrBadRequest, "missing address")
+	s.Phone = validation.NormalizePhone(s.Phone)
+	s.TaxRegion = NormalizeTaxRegion(s.TaxRegion)
+
+	v := validation.New().
+		Required("name", s.Name).
+		MaxLength("name", s.Name, MaxStoreNameLength)
+	if s.PostalAddress != nil {
+		s.PostalAddress.Normalize()
+		s.PostalAddress.Validate(v, "postal_address")
+	} else {
+		v.Required("address", s.Address)
 	}
-	if s.Phone == "" {
-		return errpkg.DefaultServiceError(errpkg.ErrBadRequest, "missing phone")
+	v.Required("phone", s.Phone).
+		MaxLength("phone", s.Phone, MaxPhoneLength).
+		Phone("phone", s.Phone).
+		Range("operational_time_start", float64(s.OperationalTimeStart), 0, 23).
+		Range("operational_time_end", float64(s.OperationalTimeEnd), 0, 23).
+		MaxLength("tax_region", s.TaxRegion, MaxTaxRegionLength)
+	if s.Location != nil {
+		s.Location.Validate(v, "location")
 	}
-	if s.OperationalTimeStart > 23 || s.OperationalTimeStart < 0 {
-		return errpkg.DefaultServiceError(errpkg.ErrBadRequest, "missing operational time start (0-23)")
+	if err := v.Error(); err != nil {
+		return err
 	}
-	if s.OperationalTimeEnd > 23 || s.OperationalTimeEnd < 0 {
-		return errpkg.DefaultServiceError(errpkg.ErrBadRequest, "missing operational time end (0-23)")
+	s.Url = CreateSlug(s.Name)
+	if s.PostalAddress != nil {
+		s.Address = s.PostalAddress.Format()
+	} else {
+		address := ParseAddress(s.Address)
+		s.PostalAddress = &address
 	}
-	s.Url = CreateSlug(s.Name, false)
 
 	return nil
 }
@@ -49,3 +77,81 @@ func (s *StoreRequest) Validate() errpkg.ErrorService {
 type HttpStoreIdParams struct {
 	ID string `uri:"id"`
 }
+
+type HttpStoreUrlParams struct {
+	Url string `uri:"url"`
+}
+
+// NearbyStore is a store found around a point, DistanceKm is the distance
+// from the point rounded to two decimals
+type NearbyStore struct {
+	Store
+	DistanceKm float64 `json:"distance_km"`
+}
+
+type HttpNearbyStoreQuery struct {
+	Lat      *float64 `form:"lat"`
+	Lng      *float64 `form:"lng"`
+	RadiusKm float64  `form:"radiusKm"`
+	Limit    int      `form:"limit"`
+}
+
+// Validate the query, empty radius and limit take their default
+func (h *HttpNearbyStoreQuery) Validate() errpkg.ErrorService {
+	if h.RadiusKm == 0 {
+		h.RadiusKm = DefaultNearbyRadiusKm
+	}
+	if h.Limit == 0 {
+		h.Limit = DefaultNearbyLimit
+	}
+
+	v := validation.New().
+		Check(h.Lat != nil, "lat", validation.ReasonRequired, "missing lat", nil).
+		Check(h.Lng != nil, "lng", validation.ReasonRequired, "missing lng", nil).
+		Check(h.RadiusKm > 0, "radiusKm", validation.ReasonMin, "radius km must be more than 0", map[string]any{"min": 0}).
+		Max("radiusKm", h.RadiusKm, MaxNearbyRadiusKm).
+		Range("limit", float64(h.Limit), 1, MaxNearbyLimit)
+	if h.Lat != nil {
+		v.Range("lat", *h.Lat, -90, 90)
+	}
+	if h.Lng != nil {
+		v.Range("lng", *h.Lng, -180, 180)
+	}
+
+	return v.Error()
+}
+
+// Point return the center of the search, only valid after Validate
+func (h *HttpNearbyStoreQuery) Point() GeoPoint {
+	return GeoPoint{Lat: *h.Lat, Lng: *h.Lng}
+}
+
+type HttpStoreQuery struct {
+	City     string `form:"city"`
+	Province string `form:"province"`
+	Limit    int    `form:"limit"`
+	Page     int    `form:"page"`
+}
+
+// Validate the query, city and province are matched regardless of case
+func (h *HttpStoreQuery) Validate() errpkg.ErrorService {
+	h.City = strings.TrimSpace(h.City)
+	h.Province = strings.TrimSpace(h.Province)
+	if err := validation.New().
+		MaxLength("city", h.City, MaxAddressPartLength).
+		MaxLength("province", h.Province, MaxAddressPartLength).
+		Error(); err != nil {
+		return err
+	}
+	if h.Limit <= 0 {
+		h.Limit = 10
+	}
+	if h.Limit > MaxStoreLimit {
+		h.Limit = MaxStoreLimit
+	}
+	if h.Page <= 0 {
+		h.Page = 1
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreRequestValidate(t *testing.T) {
+	request := &StoreRequest{
+		Name:                 "Kopi Kenangan",
+		Address:              "Jakarta",
+		Phone:                "+62 812-3456-7890",
+		OperationalTimeStart: 8,
+		OperationalTimeEnd:   22,
+	}
+
+	assert.Nil(t, request.Validate())
+	assert.Equal(t, "+6281234567890", request.Phone)
+	assert.Equal(t, "kopi-kenangan", request.Url)
+}
+
+func TestStoreRequestValidatePostalAddress(t *testing.T) {
+	request := &StoreRequest{
+		Name:          "Kopi Kenangan",
+		Address:       "ignored",
+		PostalAddress: &Address{Street: "Jl. Sudirman 1", City: "Jakarta Pusat", Province: "DKI Jakarta", PostalCode: "10310", Country: "id"},
+		Phone:         "+6281234567890",
+	}
+
+	assert.Nil(t, request.Validate())
+	assert.Equal(t, "Jl. Sudirman 1, Jakarta Pusat, DKI Jakarta 10310", request.Address)
+	assert.Equal(t, "ID", request.PostalAddress.Country)
+
+	request = &StoreRequest{
+		Name:    "Kopi Kenangan",
+		Address: "Jl. Braga 10, Bandung, Jawa Barat 40111",
+		Phone:   "+6281234567890",
+	}
+
+	assert.Nil(t, request.Validate())
+	assert.Equal(t, &Address{Street: "Jl. Braga 10", City: "Bandung", Province: "Jawa Barat", PostalCode: "40111"}, request.PostalAddress)
+
+	request = &StoreRequest{
+		Name:          "Kopi Kenangan",
+		PostalAddress: &Address{Street: "Jl. Sudirman 1", Province: "DKI Jakarta", Country: "ID"},
+		Phone:         "+6281234567890",
+	}
+
+	err := request.Validate()
+	assert.Equal(t, "postal_address.city", err.GetFields()[0].Field)
+}
+
+func TestStoreRequestValidateEveryField(t *testing.T) {
+	request := &StoreRequest{
+		Name:                 strings.Repeat("a", MaxStoreNameLength+1),
+		Phone:                "0812345678901234567",
+		OperationalTimeStart: -1,
+		OperationalTimeEnd:   24,
+	}
+
+	err := request.Validate()
+	var fields []string
+	for _, field := range err.GetFields() {
+		fields = append(fields, field.Field+":"+field.Reason)
+	}
+
+	assert.Equal(t, []string{
+		"name:MAX_LENGTH",
+		"address:REQUIRED",
+		"phone:MAX_LENGTH",
+		"operational_time_start:OUT_OF_RANGE",
+		"operational_time_end:OUT_OF_RANGE",
+	}, fields)
+}
+
+func TestHttpNearbyStoreQueryValidate(t *testing.T) {
+	lat, lng := -6.1754, 106.8272
+	query := &HttpNearbyStoreQuery{Lat: &lat, Lng: &lng}
+
+	assert.Nil(t, query.Validate())
+	assert.Equal(t, float64(DefaultNearbyRadiusKm), query.RadiusKm)
+	assert.Equal(t, DefaultNearbyLimit, query.Limit)
+	assert.Equal(t, GeoPoint{Lat: lat, Lng: lng}, query.Point())
+
+	lat = -91
+	query = &HttpNearbyStoreQuery{Lat: &lat, RadiusKm: -1}
+	err := query.Validate()
+	var fields []string
+	for _, field := range err.GetFields() {
+		fields = append(fields, field.Field+":"+field.Reason)
+	}
+
+	assert.Equal(t, []string{
+		"lng:REQUIRED",
+		"radiusKm:MIN",
+		"lat:OUT_OF_RANGE",
+	}, fields)
+}
+
+func TestHttpStoreQueryValidate(t *testing.T) {
+	query := &HttpStoreQuery{City: " Bandung ", Limit: 500}
+
+	assert.Nil(t, query.Validate())
+	assert.Equal(t, "Bandung", query.City)
+	assert.Equal(t, MaxStoreLimit, query.Limit)
+	assert.Equal(t, 1, query.Page)
+
+	query = &HttpStoreQuery{Province: strings.Repeat("a", MaxAddressPartLength+1)}
+	err := query.Validate()
+	assert.Equal(t, "province", err.GetFields()[0].Field)
+}
@@ -0,0 +1,300 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// rounding of a tax rule, every line rounded on its own or the sum of the
+// lines rounded once
+const (
+	TaxRoundingLine  = "line"
+	TaxRoundingTotal = "total"
+)
+
+// TaxRule apply rate percent on lines of the store, or of every store in the
+// region when store is empty, and of the category when set. Rule without
+// store nor region is the default of every store. Inclusive rate is already
+// part of the price, exclusive rate is added on top.
+type TaxRule struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	StoreID   string    `json:"store_id,omitempty"`
+	Region    string    `json:"region,omitempty"`
+	Category  string    `json:"category,omitempty"`
+	Rate      float32   `json:"rate"`
+	Inclusive bool      `json:"inclusive"`
+	Rounding  string    `json:"rounding"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// specificity of the rule for a line, false when the rule does not apply
+func (t *TaxRule) specificity(storeId string, region string, category string) (int, bool) {
+	score := 0
+	switch {
+	case t.StoreID != "":
+		if t.StoreID != storeId {
+			return 0, false
+		}
+		score += 4
+	case t.Region != "":
+		if t.Region != region {
+			return 0, false
+		}
+		score += 2
+	}
+	if t.Category != "" {
+		if t.Category != category {
+			return 0, false
+		}
+		score++
+	}
+
+	return score, true
+}
+
+// MatchTaxRule return the rule taxing a line of category sold by the store
+// in region, nil when no rule applies. Store rule is preferred over region
+// rule, region rule over default rule and within the same level category
+// rule over rule of every category; ties go to the first rule.
+func MatchTaxRule(rules []*TaxRule, storeId string, region string, category string) *TaxRule {
+	var (
+		best      *TaxRule
+		bestScore = -1
+	)
+	for _, rule := range rules {
+		if score, ok := rule.specificity(storeId, region, category); ok && score > bestScore {
+			best, bestScore = rule, score
+		}
+	}
+
+	return best
+}
+
+type TaxLine struct {
+	Category string
+	Amount   float32
+}
+
+// TaxAmount is the tax of one rule, taxable is the amount before tax
+type TaxAmount struct {
+	RuleID    string  `json:"rule_id"`
+	Name      string  `json:"name"`
+	Rate      float32 `json:"rate"`
+	Inclusive bool    `json:"inclusive"`
+	Taxable   float32 `json:"taxable"`
+	Amount    float32 `json:"amount"`
+}
+
+// LineTax is the tax of one line, included is already in the line amount
+// and added comes on top of it
+type LineTax struct {
+	Included float32
+	Added    float32
+}
+
+// TaxBreakdown list the tax of every rule in the order the rules first
+// applied, included is the tax inside the prices and added the tax charged
+// on top. Lines follow the order of the calculated lines.
+type TaxBreakdown struct {
+	Taxes    []TaxAmount `json:"taxes"`
+	Included float32     `json:"included"`
+	Added    float32     `json:"added"`
+	Lines    []LineTax   `json:"-"`
+}
+
+// CalculateTax compute the tax of the lines of the store in region. A rule
+// rounding per line round the tax of every line to two decimals, a rule
+// rounding per total round the sum of its lines once and spread the cents
+// over its lines with spreadTax, so the tax of the lines always add up to the
+// tax of the rule. Half a cent is rounded away from zero.
+func CalculateTax(rules []*TaxRule, storeId string, region string, lines []TaxLine) *TaxBreakdown {
+	breakdown := &TaxBreakdown{
+		Taxes: []TaxAmount{},
+		Lines: make([]LineTax, len(lines)),
+	}
+
+	var (
+		index   = make(map[string]int)
+		applied = make(map[string][]int)
+		exact   = make([]float64, len(lines))
+		matched = make([]*TaxRule, len(lines))
+	)
+	for i, line := range lines {
+		rule := MatchTaxRule(rules, storeId, region, line.Category)
+		if rule == nil || line.Amount <= 0 {
+			continue
+		}
+		matched[i] = rule
+		if _, ok := index[rule.ID]; !ok {
+			index[rule.ID] = len(breakdown.Taxes)
+			breakdown.Taxes = append(breakdown.Taxes, TaxAmount{
+				RuleID:    rule.ID,
+				Name:      rule.Name,
+				Rate:      rule.Rate,
+				Inclusive: rule.Inclusive,
+			})
+		}
+		applied[rule.ID] = append(applied[rule.ID], i)
+
+		amount, rate := float64(line.Amount), float64(rule.Rate)
+		if rule.Inclusive {
+			exact[i] = amount * rate / (100 + rate)
+		} else {
+			exact[i] = amount * rate / 100
+		}
+	}
+
+	for k, tax := range breakdown.Taxes {
+		var (
+			rule     = matched[applied[tax.RuleID][0]]
+			lineIds  = applied[tax.RuleID]
+			taxes    = make([]float64, len(lineIds))
+			sumExact float64
+			sumTaxes float64
+			taxable  float64
+		)
+		for j, i := range lineIds {
+			taxes[j] = roundTax(exact[i])
+			sumExact += exact[i]
+			sumTaxes += taxes[j]
+		}
+		if rule.Rounding == TaxRoundingTotal {
+			lineExact := make([]float64, len(lineIds))
+			for j, i := range lineIds {
+				lineExact[j] = exact[i]
+			}
+			taxes = spreadTax(lineExact)
+			sumTaxes = roundTax(sumExact)
+		}
+
+		for j, i := range lineIds {
+			amount := float32(taxes[j])
+			if rule.Inclusive {
+				breakdown.Lines[i].Included = amount
+				taxable += float64(lines[i].Amount) - taxes[j]
+			} else {
+				breakdown.Lines[i].Added = amount
+				taxable += float64(lines[i].Amount)
+			}
+		}
+
+		breakdown.Taxes[k].Taxable = float32(roundTax(taxable))
+		breakdown.Taxes[k].Amount = float32(roundTax(sumTaxes))
+		if rule.Inclusive {
+			breakdown.Included = float32(roundTax(float64(breakdown.Included) + sumTaxes))
+		} else {
+			breakdown.Added = float32(roundTax(float64(breakdown.Added) + sumTaxes))
+		}
+	}
+
+	return breakdown
+}
+
+// spreadTax round the exact taxes of lines so they add up to the rounded sum.
+// Every line gets its tax rounded down, never below zero, then the cents left
+// go one by one to the lines with the largest remainder, the first line first
+// on a tie.
+func spreadTax(exact []float64) []float64 {
+	var (
+		cents     = make([]float64, len(exact))
+		remainder = make([]float64, len(exact))
+		order     = make([]int, len(exact))
+		sumExact  float64
+		sumCents  float64
+	)
+	for j, amount := range exact {
+		value := math.Round(amount*1e6) / 1e4
+		cents[j] = math.Max(0, math.Floor(value))
+		remainder[j] = value - cents[j]
+		order[j] = j
+		sumExact += amount
+		sumCents += cents[j]
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainder[order[a]] > remainder[order[b]]
+	})
+
+	left := int(math.Round(roundTax(sumExact)*100 - sumCents))
+	for k := 0; k < left && k < len(order); k++ {
+		cents[order[k]]++
+	}
+
+	taxes := make([]float64, len(exact))
+	for j := range cents {
+		taxes[j] = cents[j] / 100
+	}
+
+	return taxes
+}
+
+// round to two decimals, the amount is first rounded to six decimals so
+// binary error of float32 amounts do not move half a cent down
+func roundTax(amount float64) float64 {
+	return math.Round(math.Round(amount*1e6)/1e4) / 100
+}
+
+type TaxRuleRequest struct {
+	Name      string  `json:"name"`
+	StoreID   string  `json:"store_id"`
+	Region    string  `json:"region"`
+	Category  string  `json:"category"`
+	Rate      float32 `json:"rate"`
+	Inclusive bool    `json:"inclusive"`
+	Rounding  string  `json:"rounding"`
+}
+
+// Validate the request, region of a store rule is dropped since the store
+// already has its region and empty rounding round per line
+func (t *TaxRuleRequest) Validate() errpkg.ErrorService {
+	t.Region = NormalizeTaxRegion(t.Region)
+	if t.StoreID != "" {
+		t.Region = ""
+	}
+	t.Category = NormalizeCategory(t.Category)
+	if t.Rounding == "" {
+		t.Rounding = TaxRoundingLine
+	}
+
+	v := validation.New().
+		Required("name", t.Name).
+		MaxLength("name", t.Name, MaxTaxRuleNameLength).
+		MaxLength("region", t.Region, MaxTaxRegionLength).
+		MaxLength("category", t.Category, MaxCategoryLength).
+		Range("rate", float64(t.Rate), 0, 100).
+		OneOf("rounding", t.Rounding, TaxRoundingLine, TaxRoundingTotal)
+	if t.StoreID != "" {
+		v.UUID("store_id", t.StoreID)
+	}
+
+	return v.Error()
+}
+
+// NormalizeTaxRegion trim and upper case the region, regions are matched
+// case insensitively
+func NormalizeTaxRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
+
+type HttpTaxRuleIdParams struct {
+	ID string `uri:"id"`
+}
+
+type HttpTaxRuleQuery struct {
+	StoreID string `form:"store_id"`
+}
+
+func (h *HttpTaxRuleQuery) Validate() errpkg.ErrorService {
+	if h.StoreID == "" {
+		return nil
+	}
+
+	return validation.New().
+		UUID("store_id", h.StoreID).
+		Error()
+}
@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchTaxRule(t *testing.T) {
+	var (
+		storeId = "8c3f1bc4-5c54-4b0b-9b9a-2d7d3c2e9f10"
+		rules   = []*TaxRule{
+			{ID: "default"},
+			{ID: "region", Region: "ID-JK"},
+			{ID: "region-food", Region: "ID-JK", Category: "food"},
+			{ID: "store", StoreID: storeId},
+			{ID: "other-store", StoreID: "0f6bdf4e-6c3b-4ab4-8d43-7b1f1d3c2a55", Category: "drink"},
+		}
+	)
+
+	tests := []struct {
+		name     string
+		storeId  string
+		region   string
+		category string
+		expected string
+	}{
+		{"store rule first", storeId, "ID-JK", "food", "store"},
+		{"region category rule", "", "ID-JK", "food", "region-food"},
+		{"region rule", "", "ID-JK", "drink", "region"},
+		{"default rule", "", "ID-BA", "drink", "default"},
+		{"rule of another store", "", "", "drink", "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MatchTaxRule(rules, tt.storeId, tt.region, tt.category).ID)
+		})
+	}
+
+	assert.Nil(t, MatchTaxRule([]*TaxRule{{ID: "food", Category: "food"}}, storeId, "", "drink"))
+}
+
+func TestCalculateTaxRounding(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     TaxRule
+		amounts  []float32
+		lines    []LineTax
+		included float32
+		added    float32
+	}{
+		{
+			name:    "exclusive per line round each line up",
+			rule:    TaxRule{Rate: 10, Rounding: TaxRoundingLine},
+			amounts: []float32{0.05, 0.05, 0.05},
+			lines:   []LineTax{{Added: 0.01}, {Added: 0.01}, {Added: 0.01}},
+			added:   0.03,
+		},
+		{
+			name:    "exclusive per total round the sum once",
+			rule:    TaxRule{Rate: 10, Rounding: TaxRoundingTotal},
+			amounts: []float32{0.05, 0.05, 0.05},
+			lines:   []LineTax{{Added: 0.01}, {Added: 0.01}, {Added: 0}},
+			added:   0.02,
+		},
+		{
+			name:    "exclusive per total never take a cent from a line",
+			rule:    TaxRule{Rate: 10, Rounding: TaxRoundingTotal},
+			amounts: []float32{0.05, 0.05, 0.05, 0.01},
+			lines:   []LineTax{{Added: 0.01}, {Added: 0.01}, {Added: 0}, {Added: 0}},
+			added:   0.02,
+		},
+		{
+			name:    "exclusive per total give the cents to the largest remainders",
+			rule:    TaxRule{Rate: 10, Rounding: TaxRoundingTotal},
+			amounts: []float32{0.04, 0.08, 0.05},
+			lines:   []LineTax{{Added: 0}, {Added: 0.01}, {Added: 0.01}},
+			added:   0.02,
+		},
+		{
+			name:    "half a cent round away from zero",
+			rule:    TaxRule{Rate: 5, Rounding: TaxRoundingLine},
+			amounts: []float32{10.1},
+			lines:   []LineTax{{Added: 0.51}},
+			added:   0.51,
+		},
+		{
+			name:    "float32 error does not move half a cent down",
+			rule:    TaxRule{Rate: 10, Rounding: TaxRoundingLine},
+			amounts: []float32{1.15},
+			lines:   []LineTax{{Added: 0.12}},
+			added:   0.12,
+		},
+		{
+			name:     "inclusive per line keep the cents",
+			rule:     TaxRule{Rate: 11, Inclusive: true, Rounding: TaxRoundingLine},
+			amounts:  []float32{10000, 333},
+			lines:    []LineTax{{Included: 990.99}, {Included: 33}},
+			included: 1023.99,
+		},
+		{
+			name:     "inclusive per line round each line up",
+			rule:     TaxRule{Rate: 5, Inclusive: true, Rounding: TaxRoundingLine},
+			amounts:  []float32{0.15, 0.15, 0.15},
+			lines:    []LineTax{{Included: 0.01}, {Included: 0.01}, {Included: 0.01}},
+			included: 0.03,
+		},
+		{
+			name:     "inclusive per total spread the cents on the first lines of a tie",
+			rule:     TaxRule{Rate: 5, Inclusive: true, Rounding: TaxRoundingTotal},
+			amounts:  []float32{0.15, 0.15, 0.15},
+			lines:    []LineTax{{Included: 0.01}, {Included: 0.01}, {Included: 0}},
+			included: 0.02,
+		},
+		{
+			name:    "zero rate",
+			rule:    TaxRule{Rate: 0, Rounding: TaxRoundingTotal},
+			amounts: []float32{19.99},
+			lines:   []LineTax{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			rule.ID = "rule"
+
+			lines := make([]TaxLine, 0, len(tt.amounts))
+			for _, amount := range tt.amounts {
+				lines = append(lines, TaxLine{Amount: amount})
+			}
+
+			breakdown := CalculateTax([]*TaxRule{&rule}, "", "", lines)
+			assert.InDeltaSlice(t, lineAmounts(tt.lines), lineAmounts(breakdown.Lines), 0.0001)
+			assert.Equal(t, tt.included, breakdown.Included)
+			assert.Equal(t, tt.added, breakdown.Added)
+			assert.Len(t, breakdown.Taxes, 1)
+			assert.Equal(t, tt.included+tt.added, breakdown.Taxes[0].Amount)
+		})
+	}
+}
+
+func TestCalculateTaxBreakdown(t *testing.T) {
+	rules := []*TaxRule{
+		{ID: "vat", Name: "VAT", Rate: 11, Rounding: TaxRoundingLine},
+		{ID: "food", Name: "Food", Category: "food", Rate: 10, Inclusive: true, Rounding: TaxRoundingTotal},
+	}
+	lines := []TaxLine{
+		{Category: "food", Amount: 11000},
+		{Category: "drink", Amount: 5000},
+		{Category: "food", Amount: 0},
+	}
+
+	breakdown := CalculateTax(rules, "", "", lines)
+
+	assert.Equal(t, []TaxAmount{
+		{RuleID: "food", Name: "Food", Rate: 10, Inclusive: true, Taxable: 10000, Amount: 1000},
+		{RuleID: "vat", Name: "VAT", Rate: 11, Taxable: 5000, Amount: 550},
+	}, breakdown.Taxes)
+	assert.Equal(t, []LineTax{{Included: 1000}, {Added: 550}, {}}, breakdown.Lines)
+	assert.Equal(t, float32(1000), breakdown.Included)
+	assert.Equal(t, float32(550), breakdown.Added)
+}
+
+func TestTaxRuleRequestValidate(t *testing.T) {
+	request := TaxRuleRequest{Name: "VAT", Region: " id-jk ", Category: " Food ", Rate: 11}
+
+	assert.Nil(t, request.Validate())
+	assert.Equal(t, "ID-JK", request.Region)
+	assert.Equal(t, "food", request.Category)
+	assert.Equal(t, TaxRoundingLine, request.Rounding)
+
+	rate := TaxRuleRequest{Name: "VAT", Rate: 101}
+	assert.NotNil(t, rate.Validate())
+
+	rounding := TaxRuleRequest{Name: "VAT", Rate: 11, Rounding: "half"}
+	assert.NotNil(t, rounding.Validate())
+}
+
+func lineAmounts(lines []LineTax) []float64 {
+	amounts := make([]float64, 0, len(lines)*2)
+	for _, line := range lines {
+		amounts = append(amounts, float64(line.Included), float64(line.Added))
+	}
+
+	return amounts
+}
@@ -0,0 +1,14 @@
+package domain
+
+import errpkg "github.com/ijlik/store-app/pkg/error"
+
+// CheckVersion compare the version expected by the client, taken from
+// If-Match header, with the current version of the resource. Zero expected
+// version, If-Match: *, skip the check.
+func CheckVersion(expected, current int) errpkg.ErrorService {
+	if expected != 0 && expected != current {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
@@ -5,17 +5,79 @@ import (
 	"github.com/ijlik/store-app/internal/business/domain"
 	errpkg "github.com/ijlik/store-app/pkg/error"
 	httppagination "github.com/ijlik/store-app/pkg/http/pagination"
+	"time"
 )
 
 type StoreDomainService interface {
 	CreateStore(ctx context.Context, request *domain.StoreRequest) (*domain.Store, errpkg.ErrorService)
 	GetStoreById(ctx context.Context, id string) (*domain.Store, errpkg.ErrorService)
+	GetStoreByUrl(ctx context.Context, url string) (*domain.Store, errpkg.ErrorService)
+	ListNearbyStores(ctx context.Context, query *domain.HttpNearbyStoreQuery) ([]*domain.NearbyStore, errpkg.ErrorService)
+	ShowStores(ctx context.Context, pagination *httppagination.Pagination, query *domain.HttpStoreQuery) errpkg.ErrorService
 	UpdateStore(ctx context.Context, request *domain.StoreRequest, id string) errpkg.ErrorService
+	PatchStore(ctx context.Context, patch *domain.StorePatch, id string) errpkg.ErrorService
 	ShowStoreProducts(ctx context.Context, pagination *httppagination.Pagination, searchAndFilter *domain.SearchAndFilterProduct, id string) errpkg.ErrorService
+	ShowStoreHistory(ctx context.Context, pagination *httppagination.Pagination, id string) errpkg.ErrorService
 
 	ShowProducts(ctx context.Context, pagination *httppagination.Pagination, searchAndFilter *domain.SearchAndFilterProduct) errpkg.ErrorService
 	CreateProduct(ctx context.Context, request *domain.ProductRequest) (*domain.Product, errpkg.ErrorService)
 	GetProductByUrl(ctx context.Context, url string) (*domain.Product, errpkg.ErrorService)
+	GetProductById(ctx context.Context, id string) (*domain.Product, errpkg.ErrorService)
+	GetProductBySku(ctx context.Context, sku string) (*domain.Product, errpkg.ErrorService)
+	GetProductsByIds(ctx context.Context, request *domain.ProductBatchGetRequest) ([]*domain.Product, errpkg.ErrorService)
 	UpdateProduct(ctx context.Context, request *domain.ProductRequest, id string) errpkg.ErrorService
-	DeleteProduct(ctx context.Context, id string) errpkg.ErrorService
+	PatchProduct(ctx context.Context, patch *domain.ProductPatch, id string) errpkg.ErrorService
+	DeleteProduct(ctx context.Context, id string, version int) errpkg.ErrorService
+	ShowProductHistory(ctx context.Context, pagination *httppagination.Pagination, id string) errpkg.ErrorService
+
+	ShowPriceHistory(ctx context.Context, pagination *httppagination.Pagination, productId string) errpkg.ErrorService
+	ShowPriceSchedules(ctx context.Context, productId string) ([]*domain.PriceSchedule, errpkg.ErrorService)
+	CreatePriceSchedule(ctx context.Context, request *domain.PriceScheduleRequest, productId string) (*domain.PriceSchedule, errpkg.ErrorService)
+	CancelPriceSchedule(ctx context.Context, productId string, scheduleId string) errpkg.ErrorService
+	ApplyPriceSchedules(ctx context.Context, now time.Time) errpkg.ErrorService
+
+	CreatePromotion(ctx context.Context, request *domain.PromotionRequest) (*domain.Promotion, errpkg.ErrorService)
+	GetPromotionById(ctx context.Context, id string) (*domain.Promotion, errpkg.ErrorService)
+	ShowPromotions(ctx context.Context, pagination *httppagination.Pagination) errpkg.ErrorService
+	DeactivatePromotion(ctx context.Context, id string) errpkg.ErrorService
+	ValidateCoupon(ctx context.Context, request *domain.CouponValidateRequest) (*domain.CouponValidation, errpkg.ErrorService)
+
+	GetCart(ctx context.Context, owner domain.CartOwner) (*domain.Cart, errpkg.ErrorService)
+	AddCartItem(ctx context.Context, owner domain.CartOwner, request *domain.CartItemRequest) (*domain.Cart, errpkg.ErrorService)
+	UpdateCartItem(ctx context.Context, owner domain.CartOwner, productId string, request *domain.CartQuantityRequest) (*domain.Cart, errpkg.ErrorService)
+	RemoveCartItem(ctx context.Context, owner domain.CartOwner, productId string) (*domain.Cart, errpkg.ErrorService)
+	ClearCart(ctx context.Context, owner domain.CartOwner) errpkg.ErrorService
+	RevalidateCart(ctx context.Context, owner domain.CartOwner) (*domain.Cart, errpkg.ErrorService)
+	ExpireCarts(ctx context.Context, now time.Time) errpkg.ErrorService
+
+	Checkout(ctx context.Context, owner domain.CartOwner, request *domain.CheckoutRequest) (*domain.Order, errpkg.ErrorService)
+	GetOrder(ctx context.Context, userId string, id string) (*domain.Order, errpkg.ErrorService)
+	ShowOrders(ctx context.Context, pagination *httppagination.Pagination, userId string, status string) errpkg.ErrorService
+	CancelOrder(ctx context.Context, userId string, id string, request *domain.OrderCancelRequest) (*domain.Order, errpkg.ErrorService)
+	GetStoreOrder(ctx context.Context, storeId string, id string) (*domain.Order, errpkg.ErrorService)
+	ShowStoreOrders(ctx context.Context, pagination *httppagination.Pagination, storeId string, status string) errpkg.ErrorService
+	UpdateOrderStatus(ctx context.Context, storeId string, id string, request *domain.OrderStatusRequest) (*domain.Order, errpkg.ErrorService)
+	CancelExpiredOrders(ctx context.Context, now time.Time) errpkg.ErrorService
+	SetProductStock(ctx context.Context, id string, request *domain.ProductStockRequest) errpkg.ErrorService
+
+	CreatePayment(ctx context.Context, userId string, orderId string) (*domain.Payment, errpkg.ErrorService)
+	HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) errpkg.ErrorService
+	ReconcilePayments(ctx context.Context, now time.Time) errpkg.ErrorService
+
+	RefundOrder(ctx context.Context, storeId string, orderId string, request *domain.RefundRequest) (*domain.Refund, errpkg.ErrorService)
+	ListRefunds(ctx context.Context, storeId string, orderId string) ([]*domain.Refund, errpkg.ErrorService)
+
+	CreateTaxRule(ctx context.Context, request *domain.TaxRuleRequest) (*domain.TaxRule, errpkg.ErrorService)
+	ListTaxRules(ctx context.Context, storeId string) ([]*domain.TaxRule, errpkg.ErrorService)
+	DeleteTaxRule(ctx context.Context, id string) errpkg.ErrorService
+
+	CreateDeliveryZone(ctx context.Context, storeId string, request *domain.DeliveryZoneRequest) (*domain.DeliveryZone, errpkg.ErrorService)
+	ListDeliveryZones(ctx context.Context, storeId string) ([]*domain.DeliveryZone, errpkg.ErrorService)
+	DeleteDeliveryZone(ctx context.Context, storeId string, id string) errpkg.ErrorService
+	QuoteDelivery(ctx context.Context, storeId string, request *domain.DeliveryQuoteRequest) (*domain.DeliveryQuote, errpkg.ErrorService)
+
+	UpdatePickupSettings(ctx context.Context, storeId string, request *domain.PickupSettingsRequest) (*domain.PickupSettings, errpkg.ErrorService)
+	GetPickupSettings(ctx context.Context, storeId string) (*domain.PickupSettings, errpkg.ErrorService)
+	ListPickupSlots(ctx context.Context, storeId string) ([]domain.PickupSlot, errpkg.ErrorService)
+	BookPickupSlot(ctx context.Context, userId string, storeId string, request *domain.PickupBookingRequest) (*domain.PickupBooking, errpkg.ErrorService)
 }
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppagination "github.com/ijlik/store-app/pkg/http/pagination"
+)
+
+// ShowStoreHistory list the audit logs of a store, newest first
+func (s *service) ShowStoreHistory(ctx context.Context, pagination *httppagination.Pagination, id string) errpkg.ErrorService {
+	return s.showHistory(ctx, pagination, domain.AuditEntityStore, id)
+}
+
+// ShowProductHistory list the audit logs of a product, newest first, the
+// history of a deleted product is kept
+func (s *service) ShowProductHistory(ctx context.Context, pagination *httppagination.Pagination, id string) errpkg.ErrorService {
+	return s.showHistory(ctx, pagination, domain.AuditEntityProduct, id)
+}
+
+func (s *service) showHistory(ctx context.Context, pagination *httppagination.Pagination, entity string, id string) errpkg.ErrorService {
+	var (
+		g           sync.WaitGroup
+		int64Atomic atomic.Int64
+		arrayAtomic atomic.Value
+		errAtomic   atomic.Value
+		result      = []*domain.AuditLog{}
+	)
+
+	g.Add(1)
+	go func() {
+		defer g.Done()
+		logs, err := s.repo.ListAuditLogs(ctx, entity, id, pagination.Limit, pagination.Offset)
+		if err != nil {
+			errAtomic.Store(err)
+		} else {
+			arrayAtomic.Store(logs)
+		}
+	}()
+
+	g.Add(1)
+	go func() {
+		defer g.Done()
+		count, err := s.repo.CountAuditLogs(ctx, entity, id)
+		if err != nil {
+			errAtomic.Store(err)
+		} else {
+			int64Atomic.Store(count)
+		}
+	}()
+	g.Wait()
+
+	if err, ok := errAtomic.Load().(error); ok {
+		return repository.TranslateError(err)
+	}
+
+	if logs, ok := arrayAtomic.Load().([]*repository.AuditLog); !ok {
+		return errpkg.DefaultServiceError(errpkg.ErrInternal, "")
+	} else {
+		for _, log := range logs {
+			result = append(result, AuditLogRes(log))
+		}
+	}
+
+	pagination.SetData(result, int64Atomic.Load())
+	return nil
+}
+
+// recordAudit write the diff of before and after using repo, it must be the
+// repository of the transaction doing the write so both commit together.
+// Writes changing nothing are not recorded.
+func recordAudit(ctx context.Context, repo repository.StoreRepository, entity string, id string, action string, before, after domain.AuditFields) error {
+	diff := domain.AuditDiff(before, after)
+	if len(diff) == 0 {
+		return nil
+	}
+
+	changes, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+
+	return repo.CreateAuditLog(ctx, &repository.AuditLog{
+		Entity:    entity,
+		EntityID:  id,
+		Action:    action,
+		Actor:     nullString(pkgcontext.GetString(ctx, pkgcontext.USER_ID)),
+		RequestID: nullString(pkgcontext.GetString(ctx, pkgcontext.REQUEST_ID)),
+		Changes:   changes,
+	})
+}
+
+func storeAuditFields(store *repository.Store) domain.AuditFields {
+	return domain.AuditFields{
+		"name":                   store.Name,
+		"url":                    store.Url,
+		"address":                store.Address,
+		"phone":                  store.Phone,
+		"operational_time_start": store.OperationalTimeStart,
+		"operational_time_end":   store.OperationalTimeEnd,
+		"tax_region":             auditValue(store.TaxRegion),
+		"latitude":               auditValue(store.Latitude),
+		"longitude":              auditValue(store.Longitude),
+		"address_street":         store.AddressStreet,
+		"address_district":       store.AddressDistrict,
+		"address_city":           store.AddressCity,
+		"address_province":       store.AddressProvince,
+		"address_postal_code":    store.AddressPostalCode,
+		"address_country":        store.AddressCountry,
+	}
+}
+
+func productAuditFields(product *repository.Product) domain.AuditFields {
+	return domain.AuditFields{
+		"store_id":    product.StoreID,
+		"name":        product.Name,
+		"url":         product.Url,
+		"price":       product.Price,
+		"description": product.Description,
+		"sku":         auditValue(product.Sku),
+		"category":    auditValue(product.Category),
+		"weight":      product.Weight,
+	}
+}
+
+// patchedAuditFields return a copy of fields with the patched columns applied,
+// columns which are not audited are skipped
+func patchedAuditFields(fields domain.AuditFields, columns []repository.Column) domain.AuditFields {
+	patched := make(domain.AuditFields, len(fields))
+	for field, value := range fields {
+		patched[field] = value
+	}
+	for _, column := range columns {
+		if _, ok := fields[column.Name]; ok {
+			patched[column.Name] = auditValue(column.Value)
+		}
+	}
+
+	return patched
+}
+
+// NULL column is audited as nil
+func auditValue(value any) any {
+	if s, ok := value.(sql.NullString); ok {
+		if !s.Valid {
+			return nil
+		}
+		return s.String
+	}
+	if n, ok := value.(sql.NullInt64); ok {
+		if !n.Valid {
+			return nil
+		}
+		return n.Int64
+	}
+	if f, ok := value.(sql.NullFloat64); ok {
+		if !f.Valid {
+			return nil
+		}
+		return f.Float64
+	}
+
+	return value
+}
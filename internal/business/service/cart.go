@@ -0,0 +1,609 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+// carts deleted per statement of the expiry job
+const cartExpiryBatch = 500
+
+// GetCart return the cart of the owner with the current prices. A signed in
+// user sending the token of an anonymous cart get it merged into their cart.
+func (s *service) GetCart(ctx context.Context, owner domain.CartOwner) (*domain.Cart, errpkg.ErrorService) {
+	var cart *repository.Cart
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		var err error
+		cart, err = resolveCart(ctx, repo, owner, time.Now().UTC())
+		return err
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if cart == nil {
+		return nil, domain.ErrCartNotFound
+	}
+
+	return s.cartRes(ctx, cart)
+}
+
+// AddCartItem add quantity of a product, the cart is created on the first
+// item. Anonymous request without a known token get a new cart and token.
+func (s *service) AddCartItem(ctx context.Context, owner domain.CartOwner, request *domain.CartItemRequest) (*domain.Cart, errpkg.ErrorService) {
+	now := time.Now().UTC()
+
+	var cart *repository.Cart
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		var err error
+		cart, err = ownCart(ctx, repo, owner, now)
+		if err != nil {
+			return err
+		}
+
+		product, err := repo.GetProductById(ctx, request.ProductID)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return errProductIdNotFound()
+		}
+		if err := resolvePriceSchedules(ctx, repo, now, product); err != nil {
+			return err
+		}
+
+		items, err := repo.ListCartItems(ctx, cart.ID)
+		if err != nil {
+			return err
+		}
+
+		quantity := request.Quantity
+		if item := findCartItem(items, product.ID); item != nil {
+			quantity += item.Quantity
+		} else if len(items) >= domain.MaxBasketItems {
+			return domain.ErrCartFull
+		}
+		if err := validation.New().Max("quantity", float64(quantity), domain.MaxItemQuantity).Error(); err != nil {
+			return err
+		}
+
+		if err := repo.UpsertCartItem(ctx, &repository.CartItem{
+			CartID:    cart.ID,
+			ProductID: product.ID,
+			Quantity:  quantity,
+			Price:     product.Price,
+		}); err != nil {
+			return err
+		}
+
+		return touchCart(ctx, repo, cart, owner, now)
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return s.cartRes(ctx, cart)
+}
+
+// UpdateCartItem set the quantity of a product already in the cart, the
+// line take the current price of the product
+func (s *service) UpdateCartItem(ctx context.Context, owner domain.CartOwner, productId string, request *domain.CartQuantityRequest) (*domain.Cart, errpkg.ErrorService) {
+	now := time.Now().UTC()
+
+	var cart *repository.Cart
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		var err error
+		cart, err = existingCart(ctx, repo, owner, now)
+		if err != nil {
+			return err
+		}
+
+		items, err := repo.ListCartItems(ctx, cart.ID)
+		if err != nil {
+			return err
+		}
+		if findCartItem(items, productId) == nil {
+			return domain.ErrCartItemNotFound
+		}
+
+		product, err := repo.GetProductById(ctx, productId)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return domain.ErrCartItemNotFound
+		}
+		if err := resolvePriceSchedules(ctx, repo, now, product); err != nil {
+			return err
+		}
+
+		if err := repo.UpsertCartItem(ctx, &repository.CartItem{
+			CartID:    cart.ID,
+			ProductID: product.ID,
+			Quantity:  request.Quantity,
+			Price:     product.Price,
+		}); err != nil {
+			return err
+		}
+
+		return touchCart(ctx, repo, cart, owner, now)
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return s.cartRes(ctx, cart)
+}
+
+func (s *service) RemoveCartItem(ctx context.Context, owner domain.CartOwner, productId string) (*domain.Cart, errpkg.ErrorService) {
+	now := time.Now().UTC()
+
+	var cart *repository.Cart
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		var err error
+		cart, err = existingCart(ctx, repo, owner, now)
+		if err != nil {
+			return err
+		}
+
+		items, err := repo.ListCartItems(ctx, cart.ID)
+		if err != nil {
+			return err
+		}
+		if findCartItem(items, productId) == nil {
+			return domain.ErrCartItemNotFound
+		}
+
+		if err := repo.DeleteCartItem(ctx, cart.ID, productId); err != nil {
+			return err
+		}
+
+		return touchCart(ctx, repo, cart, owner, now)
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return s.cartRes(ctx, cart)
+}
+
+// ClearCart delete the cart with its lines
+func (s *service) ClearCart(ctx context.Context, owner domain.CartOwner) errpkg.ErrorService {
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		cart, err := existingCart(ctx, repo, owner, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		return repo.DeleteCart(ctx, cart.ID)
+	})
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+
+	return nil
+}
+
+// RevalidateCart accept the current product prices, lines with a changed
+// price take the current price so the cart no longer report the change
+func (s *service) RevalidateCart(ctx context.Context, owner domain.CartOwner) (*domain.Cart, errpkg.ErrorService) {
+	now := time.Now().UTC()
+
+	var cart *repository.Cart
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		var err error
+		cart, err = existingCart(ctx, repo, owner, now)
+		if err != nil {
+			return err
+		}
+
+		items, err := repo.ListCartItems(ctx, cart.ID)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		products, err := repo.ListProductByIds(ctx, cartProductIds(items))
+		if err != nil {
+			return err
+		}
+		if err := resolvePriceSchedules(ctx, repo, now, products...); err != nil {
+			return err
+		}
+		prices := make(map[string]float32, len(products))
+		for _, product := range products {
+			prices[product.ID] = product.Price
+		}
+
+		for _, item := range items {
+			price, ok := prices[item.ProductID]
+			if !ok || price == item.Price {
+				continue
+			}
+			item.Price = price
+			if err := repo.UpsertCartItem(ctx, item); err != nil {
+				return err
+			}
+		}
+
+		return touchCart(ctx, repo, cart, owner, now)
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return s.cartRes(ctx, cart)
+}
+
+// ExpireCarts delete the carts expired at now
+func (s *service) ExpireCarts(ctx context.Context, now time.Time) errpkg.ErrorService {
+	for {
+		deleted, err := s.repo.DeleteExpiredCarts(ctx, now, cartExpiryBatch)
+		if err != nil {
+			return repository.TranslateError(err)
+		}
+		if deleted < cartExpiryBatch {
+			return nil
+		}
+	}
+}
+
+// find the cart of the owner, nil when there is none. Expired cart waiting
+// for the expiry job is deleted, the anonymous cart of a signed in user is
+// merged into the user cart.
+func resolveCart(ctx context.Context, repo repository.StoreRepository, owner domain.CartOwner, now time.Time) (*repository.Cart, error) {
+	var userCart, tokenCart *repository.Cart
+	if owner.UserID != "" {
+		cart, err := repo.GetCartByUserId(ctx, owner.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if userCart, err = liveCart(ctx, repo, cart, now); err != nil {
+			return nil, err
+		}
+	}
+	if owner.Token != "" {
+		cart, err := repo.GetCartByToken(ctx, owner.Token)
+		if err != nil {
+			return nil, err
+		}
+		if tokenCart, err = liveCart(ctx, repo, cart, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if tokenCart == nil {
+		return userCart, nil
+	}
+	if owner.UserID == "" {
+		return tokenCart, nil
+	}
+
+	return mergeCart(ctx, repo, owner.UserID, userCart, tokenCart, now)
+}
+
+// return the cart when it has not expired, expired cart is deleted
+func liveCart(ctx context.Context, repo repository.StoreRepository, cart *repository.Cart, now time.Time) (*repository.Cart, error) {
+	if cart == nil || cart.ExpiresAt.After(now) {
+		return cart, nil
+	}
+
+	if err := repo.DeleteCart(ctx, cart.ID); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// move the anonymous cart into the cart of the user, the anonymous cart
+// become the user cart when the user has none
+func mergeCart(ctx context.Context, repo repository.StoreRepository, userId string, userCart *repository.Cart, tokenCart *repository.Cart, now time.Time) (*repository.Cart, error) {
+	expiresAt := now.Add(domain.UserCartTTL)
+
+	if userCart == nil {
+		if err := repo.ClaimCart(ctx, tokenCart.ID, userId, expiresAt); err != nil {
+			return nil, err
+		}
+		tokenCart.UserID = nullString(userId)
+		tokenCart.Token = sql.NullString{}
+		tokenCart.ExpiresAt = expiresAt
+
+		return tokenCart, nil
+	}
+
+	userItems, err := repo.ListCartItems(ctx, userCart.ID)
+	if err != nil {
+		return nil, err
+	}
+	tokenItems, err := repo.ListCartItems(ctx, tokenCart.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range mergeCartItems(userItems, tokenItems) {
+		item.CartID = userCart.ID
+		if err := repo.UpsertCartItem(ctx, item); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := repo.DeleteCart(ctx, tokenCart.ID); err != nil {
+		return nil, err
+	}
+	if err := repo.TouchCart(ctx, userCart.ID, expiresAt); err != nil {
+		return nil, err
+	}
+	userCart.ExpiresAt = expiresAt
+
+	return userCart, nil
+}
+
+// return the lines of the user cart to write after merging the anonymous
+// lines: quantities of the same product are added up to the max quantity,
+// products beyond the max of a cart are dropped
+func mergeCartItems(userItems []*repository.CartItem, tokenItems []*repository.CartItem) []*repository.CartItem {
+	var (
+		changed []*repository.CartItem
+		count   = len(userItems)
+	)
+	for _, item := range tokenItems {
+		if existing := findCartItem(userItems, item.ProductID); existing != nil {
+			quantity := existing.Quantity + item.Quantity
+			if quantity > domain.MaxItemQuantity {
+				quantity = domain.MaxItemQuantity
+			}
+			if quantity == existing.Quantity {
+				continue
+			}
+			merged := *existing
+			merged.Quantity = quantity
+			changed = append(changed, &merged)
+			continue
+		}
+
+		if count >= domain.MaxBasketItems {
+			continue
+		}
+		count++
+		added := *item
+		changed = append(changed, &added)
+	}
+
+	return changed
+}
+
+// resolve the cart of the owner or create one
+func ownCart(ctx context.Context, repo repository.StoreRepository, owner domain.CartOwner, now time.Time) (*repository.Cart, error) {
+	cart, err := resolveCart(ctx, repo, owner, now)
+	if err != nil || cart != nil {
+		return cart, err
+	}
+
+	cart = &repository.Cart{
+		ID:        uuid.New().String(),
+		UserID:    nullString(owner.UserID),
+		CreatedAt: now,
+		ExpiresAt: now.Add(owner.TTL()),
+	}
+	if owner.UserID == "" {
+		token, err := newCartToken()
+		if err != nil {
+			return nil, err
+		}
+		cart.Token = nullString(token)
+	}
+
+	if err := repo.CreateCart(ctx, cart); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+// resolve the cart of the owner, missing cart is an error
+func existingCart(ctx context.Context, repo repository.StoreRepository, owner domain.CartOwner, now time.Time) (*repository.Cart, error) {
+	cart, err := resolveCart(ctx, repo, owner, now)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return nil, domain.ErrCartNotFound
+	}
+
+	return cart, nil
+}
+
+// extend the cart expiry after a change
+func touchCart(ctx context.Context, repo repository.StoreRepository, cart *repository.Cart, owner domain.CartOwner, now time.Time) error {
+	cart.ExpiresAt = now.Add(owner.TTL())
+
+	return repo.TouchCart(ctx, cart.ID, cart.ExpiresAt)
+}
+
+// random url safe token of an anonymous cart
+func newCartToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate cart token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func findCartItem(items []*repository.CartItem, productId string) *repository.CartItem {
+	for _, item := range items {
+		if item.ProductID == productId {
+			return item
+		}
+	}
+
+	return nil
+}
+
+func cartProductIds(items []*repository.CartItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	return ids
+}
+
+// build the cart response priced with the current product prices and the
+// promotions running without coupon, lines are grouped per store in the
+// order they were added and every store is taxed with its own rules
+func (s *service) cartRes(ctx context.Context, cart *repository.Cart) (*domain.Cart, errpkg.ErrorService) {
+	now := time.Now().UTC()
+
+	res := &domain.Cart{
+		ID:        cart.ID,
+		Token:     cart.Token.String,
+		Stores:    []domain.CartStore{},
+		ExpiresAt: cart.ExpiresAt,
+	}
+
+	items, err := s.repo.ListCartItems(ctx, cart.ID)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if len(items) == 0 {
+		return res, nil
+	}
+
+	products, err := s.repo.ListProductByIds(ctx, cartProductIds(items))
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if err := resolvePriceSchedules(ctx, s.repo, now, products...); err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	var (
+		byId       = make(map[string]*repository.Product, len(products))
+		storeIds   []string
+		seenStores = make(map[string]bool)
+	)
+	for _, product := range products {
+		byId[product.ID] = product
+		if !seenStores[product.StoreID] {
+			seenStores[product.StoreID] = true
+			storeIds = append(storeIds, product.StoreID)
+		}
+	}
+
+	storesById := make(map[string]*repository.Store, len(storeIds))
+	stores, err := s.repo.ListStoreByIds(ctx, storeIds)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	for _, store := range stores {
+		storesById[store.ID] = store
+	}
+
+	var (
+		lines = make([]domain.BasketLine, 0, len(items))
+		kept  = make([]*repository.CartItem, 0, len(items))
+	)
+	for _, item := range items {
+		product, ok := byId[item.ProductID]
+		if !ok {
+			continue
+		}
+		lines = append(lines, basketLine(product, item.Quantity))
+		kept = append(kept, item)
+	}
+
+	promotions, err := automaticPromotions(ctx, s.repo, lines, now)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	evaluation := domain.EvaluateBasket(lines, promotions, now)
+
+	var (
+		groups   = make(map[string]int)
+		taxLines [][]domain.TaxLine
+	)
+	for i, item := range kept {
+		var (
+			product    = byId[item.ProductID]
+			evaluated  = evaluation.Lines[i]
+			storeIndex int
+			ok         bool
+		)
+		if storeIndex, ok = groups[product.StoreID]; !ok {
+			storeIndex = len(res.Stores)
+			groups[product.StoreID] = storeIndex
+			res.Stores = append(res.Stores, domain.CartStore{
+				StoreID: product.StoreID,
+				Lines:   []domain.CartLine{},
+			})
+			taxLines = append(taxLines, nil)
+		}
+
+		line := domain.CartLine{
+			ProductID:    product.ID,
+			Name:         product.Name,
+			Url:          product.Url,
+			Quantity:     item.Quantity,
+			AddedPrice:   item.Price,
+			Price:        product.Price,
+			PriceChanged: item.Price != product.Price,
+			SalePrice:    evaluated.SalePrice,
+			Discount:     evaluated.Discount,
+			Total:        evaluated.Total,
+			Promotions:   evaluated.Promotions,
+		}
+
+		group := &res.Stores[storeIndex]
+		group.Lines = append(group.Lines, line)
+		group.Subtotal = domain.RoundPrice(group.Subtotal + product.Price*float32(item.Quantity))
+		group.Discount = domain.RoundPrice(group.Discount + line.Discount)
+		group.Total = domain.RoundPrice(group.Total + line.Total)
+		taxLines[storeIndex] = append(taxLines[storeIndex], domain.TaxLine{Category: product.Category.String, Amount: line.Total})
+
+		res.ItemCount += item.Quantity
+		res.PriceChanged = res.PriceChanged || line.PriceChanged
+	}
+	res.Subtotal = evaluation.Subtotal
+	res.Discount = evaluation.Discount
+	res.Total = evaluation.Total
+
+	for i := range res.Stores {
+		group := &res.Stores[i]
+		store, ok := storesById[group.StoreID]
+		if !ok {
+			store = &repository.Store{ID: group.StoreID}
+		}
+		group.StoreName = store.Name
+
+		tax, err := storeTax(ctx, s.repo, store, taxLines[i])
+		if err != nil {
+			return nil, repository.TranslateError(err)
+		}
+		group.Tax = tax
+		group.Total = domain.RoundPrice(group.Total + tax.Added)
+
+		res.TaxIncluded = domain.RoundPrice(res.TaxIncluded + tax.Included)
+		res.TaxAdded = domain.RoundPrice(res.TaxAdded + tax.Added)
+		res.Total = domain.RoundPrice(res.Total + tax.Added)
+	}
+
+	return res, nil
+}
+
+// validation error of cart request referring to a missing product
+func errProductIdNotFound() errpkg.ErrorService {
+	return validation.New().
+		Check(false, "product_id", validation.ReasonNotFound, "product id not found", nil).
+		Error()
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	cartColumns     = []string{"id", "token", "user_id", "created_at", "updated_at", "expires_at"}
+	cartItemColumns = []string{"cart_id", "product_id", "quantity", "price", "created_at", "updated_at"}
+)
+
+func TestMergeCartItems(t *testing.T) {
+	userItems := []*repository.CartItem{
+		{CartID: "user_cart", ProductID: "product-1", Quantity: 2, Price: 100},
+		{CartID: "user_cart", ProductID: "product-2", Quantity: 990, Price: 200},
+	}
+	tokenItems := []*repository.CartItem{
+		{CartID: "token_cart", ProductID: "product-1", Quantity: 3, Price: 90},
+		{CartID: "token_cart", ProductID: "product-2", Quantity: 20, Price: 200},
+		{CartID: "token_cart", ProductID: "product-3", Quantity: 1, Price: 300},
+	}
+
+	merged := mergeCartItems(userItems, tokenItems)
+	assert.Equal(t, []*repository.CartItem{
+		{CartID: "user_cart", ProductID: "product-1", Quantity: 5, Price: 100},
+		{CartID: "user_cart", ProductID: "product-2", Quantity: domain.MaxItemQuantity, Price: 200},
+		{CartID: "token_cart", ProductID: "product-3", Quantity: 1, Price: 300},
+	}, merged)
+	// lines of the user cart are left untouched
+	assert.Equal(t, 2, userItems[0].Quantity)
+
+	full := make([]*repository.CartItem, domain.MaxBasketItems)
+	for i := range full {
+		full[i] = &repository.CartItem{ProductID: "other"}
+	}
+	assert.Empty(t, mergeCartItems(full, tokenItems[2:]))
+}
+
+func TestGetCartMergeAnonymousCart(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	now := time.Now().UTC()
+	future := now.Add(time.Hour)
+
+	getCartByUserIdQueryMock := "SELECT (.+) FROM carts WHERE user_id = \\$1 LIMIT 1"
+	getCartByTokenQueryMock := "SELECT (.+) FROM carts WHERE token = \\$1 AND user_id IS NULL LIMIT 1"
+	listCartItemsQueryMock := "SELECT (.+) FROM cart_items WHERE cart_id = \\$1"
+	upsertCartItemQueryMock := "INSERT INTO cart_items (.+) ON CONFLICT \\(cart_id, product_id\\) DO UPDATE"
+	deleteCartQueryMock := "DELETE FROM carts WHERE id = \\$1"
+	touchCartQueryMock := "UPDATE carts SET expires_at = \\$2"
+	listProductByIdsQueryMock := "SELECT (.+) FROM products WHERE id = ANY\\(\\$1\\)"
+	listStartedPriceSchedulesQueryMock := "SELECT (.+) FROM product_price_schedules WHERE product_id = ANY\\(\\$1\\)"
+	listStoreByIdsQueryMock := "SELECT (.+) FROM stores WHERE id = ANY\\(\\$1\\)"
+	listAutomaticPromotionsQueryMock := "SELECT (.+) FROM promotions WHERE code IS NULL"
+	listTaxRulesForStoreQueryMock := "SELECT (.+) FROM tax_rules WHERE store_id = \\$1"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(getCartByUserIdQueryMock).WithArgs("test_user_id").
+		WillReturnRows(sqlmock.NewRows(cartColumns).AddRow("test_user_cart", nil, "test_user_id", now, nil, future))
+	mock.ExpectQuery(getCartByTokenQueryMock).WithArgs("test_token").
+		WillReturnRows(sqlmock.NewRows(cartColumns).AddRow("test_token_cart", "test_token", nil, now, nil, future))
+	mock.ExpectQuery(listCartItemsQueryMock).WithArgs("test_user_cart").
+		WillReturnRows(sqlmock.NewRows(cartItemColumns).AddRow("test_user_cart", "test_product_1", 1, 18000, now, nil))
+	mock.ExpectQuery(listCartItemsQueryMock).WithArgs("test_token_cart").
+		WillReturnRows(sqlmock.NewRows(cartItemColumns).
+			AddRow("test_token_cart", "test_product_1", 2, 18000, now, nil).
+			AddRow("test_token_cart", "test_product_2", 1, 25000, now, nil))
+	mock.ExpectExec(upsertCartItemQueryMock).WithArgs("test_user_cart", "test_product_1", 3, float32(18000)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(upsertCartItemQueryMock).WithArgs("test_user_cart", "test_product_2", 1, float32(25000)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(deleteCartQueryMock).WithArgs("test_token_cart").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(touchCartQueryMock).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectQuery(listCartItemsQueryMock).WithArgs("test_user_cart").
+		WillReturnRows(sqlmock.NewRows(cartItemColumns).
+			AddRow("test_user_cart", "test_product_1", 3, 18000, now, nil).
+			AddRow("test_user_cart", "test_product_2", 1, 25000, now, nil))
+	mock.ExpectQuery(listProductByIdsQueryMock).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version", "was_price", "category"}).
+			AddRow("test_product_1", "test_store_1", "Kopi Susu", "kopi-susu", 20000, "Es kopi susu", nil, now, nil, 2, nil, nil).
+			AddRow("test_product_2", "test_store_2", "Roti Bakar", "roti-bakar", 25000, "Roti bakar coklat", nil, now, nil, 1, nil, nil))
+	mock.ExpectQuery(listStartedPriceSchedulesQueryMock).WillReturnRows(sqlmock.NewRows(priceScheduleColumns))
+	mock.ExpectQuery(listStoreByIdsQueryMock).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "tax_region"}).
+			AddRow("test_store_1", "Kopi Kenangan", "ID-JK").
+			AddRow("test_store_2", "Roti O", nil))
+	mock.ExpectQuery(listAutomaticPromotionsQueryMock).WillReturnRows(sqlmock.NewRows(promotionColumns))
+	mock.ExpectQuery(listTaxRulesForStoreQueryMock).WithArgs("test_store_1", "ID-JK").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "store_id", "region", "category", "rate", "inclusive", "rounding", "created_at"}).
+			AddRow("test_rule_id", "VAT", nil, "ID-JK", nil, 11, false, "line", now))
+	mock.ExpectQuery(listTaxRulesForStoreQueryMock).WithArgs("test_store_2", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	ctx := context.Background()
+	cart, errSvc := svc.GetCart(ctx, domain.CartOwner{UserID: "test_user_id", Token: "test_token"})
+	assert.Nil(t, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, "test_user_cart", cart.ID)
+	assert.Empty(t, cart.Token)
+	assert.Equal(t, 4, cart.ItemCount)
+	assert.True(t, cart.PriceChanged)
+	assert.Len(t, cart.Stores, 2)
+	assert.Equal(t, "Kopi Kenangan", cart.Stores[0].StoreName)
+	assert.Equal(t, float32(18000), cart.Stores[0].Lines[0].AddedPrice)
+	assert.Equal(t, float32(20000), cart.Stores[0].Lines[0].Price)
+	assert.Equal(t, float32(6600), cart.Stores[0].Tax.Added)
+	assert.Equal(t, float32(66600), cart.Stores[0].Total)
+	assert.Empty(t, cart.Stores[1].Tax.Taxes)
+	assert.Equal(t, float32(25000), cart.Stores[1].Total)
+	assert.Equal(t, float32(6600), cart.TaxAdded)
+	assert.Equal(t, float32(91600), cart.Total)
+}
+
+func TestGetCartExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	past := time.Now().UTC().Add(-time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM carts WHERE token = \\$1").WithArgs("test_token").
+		WillReturnRows(sqlmock.NewRows(cartColumns).AddRow("test_token_cart", "test_token", nil, past, nil, past))
+	mock.ExpectExec("DELETE FROM carts WHERE id = \\$1").WithArgs("test_token_cart").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, errSvc := svc.GetCart(context.Background(), domain.CartOwner{Token: "test_token"})
+	assert.Equal(t, domain.ErrCartNotFound, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
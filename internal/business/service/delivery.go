@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/validation"
+	"github.com/jmoiron/sqlx/types"
+)
+
+func (s *service) CreateDeliveryZone(ctx context.Context, storeId string, request *domain.DeliveryZoneRequest) (*domain.DeliveryZone, errpkg.ErrorService) {
+	store, err := s.repo.GetStoreById(ctx, storeId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if store == nil {
+		return nil, domain.ErrStoreNotFound
+	}
+
+	rates, err := json.Marshal(request.Rates)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	zone := &repository.DeliveryZone{
+		ID:        uuid.New().String(),
+		StoreID:   store.ID,
+		Name:      request.Name,
+		Type:      request.Type,
+		Rates:     types.JSONText(rates),
+		CreatedAt: time.Now().UTC(),
+	}
+	switch request.Type {
+	case domain.DeliveryZoneRadius:
+		zone.RadiusKm = sql.NullFloat64{Float64: request.RadiusKm, Valid: true}
+	case domain.DeliveryZonePolygon:
+		polygon, err := json.Marshal(request.Polygon)
+		if err != nil {
+			return nil, repository.TranslateError(err)
+		}
+		zone.Polygon = types.NullJSONText{JSONText: polygon, Valid: true}
+	}
+	if err := s.repo.CreateDeliveryZone(ctx, zone); err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return DeliveryZoneRes(zone), nil
+}
+
+// ListDeliveryZones list the delivery zones of the store, oldest first
+func (s *service) ListDeliveryZones(ctx context.Context, storeId string) ([]*domain.DeliveryZone, errpkg.ErrorService) {
+	zones, err := s.repo.ListDeliveryZones(ctx, storeId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return DeliveryZonesRes(zones), nil
+}
+
+func (s *service) DeleteDeliveryZone(ctx context.Context, storeId string, id string) errpkg.ErrorService {
+	deleted, err := s.repo.DeleteDeliveryZone(ctx, storeId, id)
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+	if !deleted {
+		return domain.ErrDeliveryZoneNotFound
+	}
+
+	return nil
+}
+
+// QuoteDelivery price the delivery of the items of the store to the
+// location. The rate tables are matched on the distance from the store, the
+// weight of the items and their total after automatic promotions.
+func (s *service) QuoteDelivery(ctx context.Context, storeId string, request *domain.DeliveryQuoteRequest) (*domain.DeliveryQuote, errpkg.ErrorService) {
+	store, err := s.repo.GetStoreById(ctx, storeId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if store == nil {
+		return nil, domain.ErrStoreNotFound
+	}
+	origin := storeLocation(store)
+	if origin == nil {
+		return nil, domain.ErrDeliveryUnavailable
+	}
+
+	lines, errSvc := s.basketLines(ctx, request.Items)
+	if errSvc != nil {
+		return nil, errSvc
+	}
+	var (
+		weight int
+		v      = validation.New()
+	)
+	for i, line := range lines {
+		v.Check(line.StoreID == store.ID, fmt.Sprintf("items[%d].product_id", i), validation.ReasonNotFound, "product not found", nil)
+		weight += line.Weight * line.Quantity
+	}
+	if err := v.Error(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	promotions, err := automaticPromotions(ctx, s.repo, lines, now)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	evaluation := domain.EvaluateBasket(lines, promotions, now)
+
+	zones, err := s.repo.ListDeliveryZones(ctx, store.ID)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	quote := domain.QuoteDelivery(DeliveryZonesRes(zones), *origin, *request.Location, weight, evaluation.Total)
+	if quote == nil {
+		return nil, domain.ErrDeliveryUnavailable
+	}
+
+	return quote, nil
+}
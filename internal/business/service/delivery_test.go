@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuoteDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM stores WHERE id = \\$1 LIMIT 1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "latitude", "longitude"}).AddRow("test_store_id", "Kopi Kenangan", -6.1754, 106.8272))
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = ANY\\(\\$1\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version", "was_price", "category", "stock", "weight"}).
+			AddRow("test_product_id", "test_store_id", "Kopi Susu", "kopi-susu", 18000, "Es kopi susu", nil, now, nil, 1, nil, nil, nil, 400))
+	mock.ExpectQuery("SELECT (.+) FROM product_price_schedules WHERE product_id = ANY\\(\\$1\\)").WillReturnRows(sqlmock.NewRows(priceScheduleColumns))
+	mock.ExpectQuery("SELECT (.+) FROM promotions WHERE code IS NULL").WillReturnRows(sqlmock.NewRows(promotionColumns))
+	mock.ExpectQuery("SELECT (.+) FROM delivery_zones WHERE store_id = \\$1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "type", "radius_km", "polygon", "rates", "created_at"}).
+			AddRow("test_zone_id", "test_store_id", "Near", "radius", 5, nil, []byte(`[{"max_weight":1000,"fee":8000},{"fee":12000}]`), now))
+
+	quote, errSvc := svc.QuoteDelivery(context.Background(), "test_store_id", &domain.DeliveryQuoteRequest{
+		Location: &domain.GeoPoint{Lat: -6.1950, Lng: 106.8230},
+		Items:    []domain.BasketItem{{ProductID: "test_product_id", Quantity: 3}},
+	})
+	assert.Nil(t, errSvc)
+	assert.Equal(t, "test_zone_id", quote.ZoneID)
+	assert.Equal(t, 1200, quote.Weight)
+	assert.Equal(t, float32(54000), quote.OrderTotal)
+	assert.Equal(t, float32(12000), quote.Fee)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuoteDeliveryStoreWithoutLocation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM stores WHERE id = \\$1 LIMIT 1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "latitude", "longitude"}).AddRow("test_store_id", "Kopi Kenangan", nil, nil))
+
+	_, errSvc := svc.QuoteDelivery(context.Background(), "test_store_id", &domain.DeliveryQuoteRequest{
+		Location: &domain.GeoPoint{Lat: -6.1950, Lng: 106.8230},
+		Items:    []domain.BasketItem{{ProductID: "test_product_id", Quantity: 1}},
+	})
+	assert.Equal(t, domain.ErrDeliveryUnavailable, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
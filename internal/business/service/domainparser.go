@@ -1,6 +1,9 @@
 package service
 
 import (
+	"encoding/json"
+	"log"
+
 	"github.com/ijlik/store-app/internal/adapter/repository"
 	"github.com/ijlik/store-app/internal/business/domain"
 )
@@ -11,9 +14,15 @@ func ProductRes(product *repository.Product, store *repository.Store) *domain.Pr
 		Name:        product.Name,
 		Url:         product.Url,
 		Price:       product.Price,
+		WasPrice:    wasPrice(product),
 		Description: product.Description,
+		Sku:         product.Sku.String,
+		Category:    product.Category.String,
+		Stock:       stock(product),
+		Weight:      product.Weight,
 		Store:       StoreRes(store),
 		CreatedAt:   product.CreatedAt,
+		Version:     product.Version,
 	}
 }
 
@@ -23,10 +32,321 @@ func StoreRes(store *repository.Store) *domain.Store {
 		Name:                 store.Name,
 		Url:                  store.Url,
 		Address:              store.Address,
+		PostalAddress:        storeAddress(store),
 		Phone:                store.Phone,
 		OperationalTimeStart: store.OperationalTimeStart,
 		OperationalTimeEnd:   store.OperationalTimeEnd,
+		TaxRegion:            store.TaxRegion.String,
+		Location:             storeLocation(store),
 		CreatedAt:            store.CreatedAt,
+		Version:              store.Version,
+	}
+
+}
+
+func storeAddress(store *repository.Store) domain.Address {
+	return domain.Address{
+		Street:     store.AddressStreet,
+		District:   store.AddressDistrict,
+		City:       store.AddressCity,
+		Province:   store.AddressProvince,
+		PostalCode: store.AddressPostalCode,
+		Country:    store.AddressCountry,
+	}
+}
+
+// location of the store, nil when it is not set
+func storeLocation(store *repository.Store) *domain.GeoPoint {
+	if !store.Latitude.Valid || !store.Longitude.Valid {
+		return nil
+	}
+
+	return &domain.GeoPoint{Lat: store.Latitude.Float64, Lng: store.Longitude.Float64}
+}
+
+// price before the running price schedule, only shown while it is higher
+// than the current price
+func wasPrice(product *repository.Product) *float32 {
+	if !product.WasPrice.Valid || float32(product.WasPrice.Float64) <= product.Price {
+		return nil
+	}
+
+	was := float32(product.WasPrice.Float64)
+	return &was
+}
+
+// stock of the product, nil when the stock is not tracked
+func stock(product *repository.Product) *int {
+	if !product.Stock.Valid {
+		return nil
+	}
+
+	stock := int(product.Stock.Int64)
+	return &stock
+}
+
+func PriceHistoryRes(history *repository.PriceHistory) *domain.PriceHistory {
+	res := &domain.PriceHistory{
+		ID:         history.ID,
+		Price:      history.Price,
+		ScheduleID: history.ScheduleID.String,
+		CreatedAt:  history.CreatedAt,
+	}
+	if history.PreviousPrice.Valid {
+		previous := float32(history.PreviousPrice.Float64)
+		res.PreviousPrice = &previous
+	}
+
+	return res
+}
+
+func PriceScheduleRes(schedule *repository.PriceSchedule) *domain.PriceSchedule {
+	res := &domain.PriceSchedule{
+		ID:            schedule.ID,
+		Price:         schedule.Price,
+		EffectiveFrom: schedule.EffectiveFrom,
+		Status:        schedule.Status,
+		CreatedAt:     schedule.CreatedAt,
+	}
+	if schedule.EffectiveTo.Valid {
+		to := schedule.EffectiveTo.Time
+		res.EffectiveTo = &to
+	}
+
+	return res
+}
+
+func AuditLogRes(log *repository.AuditLog) *domain.AuditLog {
+	return &domain.AuditLog{
+		ID:        log.ID,
+		Action:    log.Action,
+		Actor:     log.Actor.String,
+		RequestID: log.RequestID.String,
+		Changes:   json.RawMessage(log.Changes),
+		CreatedAt: log.CreatedAt,
+	}
+}
+
+func PromotionRes(promotion *repository.Promotion) *domain.Promotion {
+	res := &domain.Promotion{
+		ID:                promotion.ID,
+		Name:              promotion.Name,
+		Code:              promotion.Code.String,
+		DiscountType:      promotion.DiscountType,
+		DiscountValue:     promotion.DiscountValue,
+		Scope:             promotion.Scope,
+		ScopeID:           promotion.ScopeID,
+		StartsAt:          promotion.StartsAt,
+		UsageLimit:        promotion.UsageLimit,
+		UsageLimitPerUser: promotion.UsageLimitPerUser,
+		Used:              promotion.Used,
+		Stackable:         promotion.Stackable,
+		Priority:          promotion.Priority,
+		Active:            promotion.Active,
+		CreatedAt:         promotion.CreatedAt,
+	}
+	if promotion.EndsAt.Valid {
+		ends := promotion.EndsAt.Time
+		res.EndsAt = &ends
+	}
+
+	return res
+}
+
+func OrderRes(order *repository.Order, items []*repository.OrderItem) *domain.Order {
+	res := &domain.Order{
+		ID:       order.ID,
+		StoreID:  order.StoreID,
+		UserID:   order.UserID,
+		Status:   order.Status,
+		Subtotal: order.Subtotal,
+		Discount: order.Discount,
+		Tax: domain.TaxBreakdown{
+			Taxes:    []domain.TaxAmount{},
+			Included: order.TaxIncluded,
+			Added:    order.TaxAdded,
+		},
+		Total:      order.Total,
+		CouponCode: order.CouponCode.String,
+		CreatedAt:  order.CreatedAt,
+	}
+	if len(order.Taxes) > 0 {
+		if err := json.Unmarshal(order.Taxes, &res.Tax.Taxes); err != nil {
+			log.Println("invalid order taxes: ", err)
+		}
+	}
+	if order.ExpiresAt.Valid && order.Status == domain.OrderPending {
+		expires := order.ExpiresAt.Time
+		res.ExpiresAt = &expires
+	}
+	if order.UpdatedAt.Valid {
+		updated := order.UpdatedAt.Time
+		res.UpdatedAt = &updated
+	}
+	for _, item := range items {
+		res.Items = append(res.Items, OrderItemRes(item))
+	}
+
+	return res
+}
+
+func OrderItemRes(item *repository.OrderItem) domain.OrderItem {
+	res := domain.OrderItem{
+		ID:         item.ID,
+		ProductID:  item.ProductID,
+		Name:       item.Name,
+		Sku:        item.Sku.String,
+		UnitPrice:  item.UnitPrice,
+		SalePrice:  item.SalePrice,
+		Quantity:   item.Quantity,
+		Discount:   item.Discount,
+		Total:      item.Total,
+		Promotions: []domain.AppliedPromotion{},
+
+		RefundedQuantity: item.RefundedQuantity,
+		RefundedAmount:   item.RefundedAmount,
+
+		TaxIncluded: item.TaxIncluded,
+		TaxAdded:    item.TaxAdded,
+	}
+	if len(item.Promotions) > 0 {
+		if err := json.Unmarshal(item.Promotions, &res.Promotions); err != nil {
+			log.Println("invalid order item promotions: ", err)
+		}
+	}
+
+	return res
+}
+
+func TaxRuleRes(rule *repository.TaxRule) *domain.TaxRule {
+	return &domain.TaxRule{
+		ID:        rule.ID,
+		Name:      rule.Name,
+		StoreID:   rule.StoreID.String,
+		Region:    rule.Region.String,
+		Category:  rule.Category.String,
+		Rate:      rule.Rate,
+		Inclusive: rule.Inclusive,
+		Rounding:  rule.Rounding,
+		CreatedAt: rule.CreatedAt,
+	}
+}
+
+func TaxRulesRes(rules []*repository.TaxRule) []*domain.TaxRule {
+	res := make([]*domain.TaxRule, 0, len(rules))
+	for _, rule := range rules {
+		res = append(res, TaxRuleRes(rule))
+	}
+
+	return res
+}
+
+func PaymentRes(payment *repository.Payment, clientSecret string) *domain.Payment {
+	res := &domain.Payment{
+		ID:             payment.ID,
+		OrderID:        payment.OrderID,
+		Provider:       payment.Provider,
+		Reference:      payment.Reference,
+		Amount:         payment.Amount,
+		CapturedAmount: payment.CapturedAmount,
+		RefundedAmount: payment.RefundedAmount,
+		Currency:       payment.Currency,
+		Status:         payment.Status,
+		ClientSecret:   clientSecret,
+		CreatedAt:      payment.CreatedAt,
+	}
+	if payment.UpdatedAt.Valid {
+		updated := payment.UpdatedAt.Time
+		res.UpdatedAt = &updated
+	}
+
+	return res
+}
+
+func RefundRes(refund *repository.Refund, items []*repository.RefundItem) *domain.Refund {
+	res := &domain.Refund{
+		ID:        refund.ID,
+		OrderID:   refund.OrderID,
+		PaymentID: refund.PaymentID,
+		Amount:    refund.Amount,
+		Reason:    refund.Reason,
+		Restock:   refund.Restock,
+		Status:    refund.Status,
+		Items:     []domain.RefundItem{},
+		CreatedAt: refund.CreatedAt,
+	}
+	if refund.UpdatedAt.Valid {
+		updated := refund.UpdatedAt.Time
+		res.UpdatedAt = &updated
+	}
+	for _, item := range items {
+		res.Items = append(res.Items, domain.RefundItem{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+			Amount:      item.Amount,
+		})
+	}
+
+	return res
+}
+
+func DeliveryZoneRes(zone *repository.DeliveryZone) *domain.DeliveryZone {
+	res := &domain.DeliveryZone{
+		ID:        zone.ID,
+		StoreID:   zone.StoreID,
+		Name:      zone.Name,
+		Type:      zone.Type,
+		RadiusKm:  zone.RadiusKm.Float64,
+		Rates:     []domain.DeliveryRate{},
+		CreatedAt: zone.CreatedAt,
+	}
+	if zone.Polygon.Valid {
+		if err := json.Unmarshal(zone.Polygon.JSONText, &res.Polygon); err != nil {
+			log.Println("invalid delivery zone polygon: ", err)
+		}
+	}
+	if len(zone.Rates) > 0 {
+		if err := json.Unmarshal(zone.Rates, &res.Rates); err != nil {
+			log.Println("invalid delivery zone rates: ", err)
+		}
+	}
+
+	return res
+}
+
+func DeliveryZonesRes(zones []*repository.DeliveryZone) []*domain.DeliveryZone {
+	res := make([]*domain.DeliveryZone, 0, len(zones))
+	for _, zone := range zones {
+		res = append(res, DeliveryZoneRes(zone))
+	}
+
+	return res
+}
+
+func PickupSettingsRes(settings *repository.PickupSettings) *domain.PickupSettings {
+	return &domain.PickupSettings{
+		StoreID:     settings.StoreID,
+		SlotMinutes: settings.SlotMinutes,
+		Capacity:    settings.Capacity,
+		LeadMinutes: settings.LeadMinutes,
+		DaysAhead:   settings.DaysAhead,
+	}
+}
+
+func PickupBookingRes(booking *repository.PickupBooking) *domain.PickupBooking {
+	res := &domain.PickupBooking{
+		ID:        booking.ID,
+		StoreID:   booking.StoreID,
+		OrderID:   booking.OrderID,
+		StartsAt:  booking.StartsAt,
+		EndsAt:    booking.EndsAt,
+		Status:    booking.Status,
+		CreatedAt: booking.CreatedAt,
+	}
+	if booking.UpdatedAt.Valid {
+		updated := booking.UpdatedAt.Time
+		res.UpdatedAt = &updated
 	}
 
+	return res
 }
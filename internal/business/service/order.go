@@ -0,0 +1,536 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppagination "github.com/ijlik/store-app/pkg/http/pagination"
+)
+
+// pending orders cancelled per run of the expiry job
+const orderExpiryBatch = 100
+
+// Checkout place an order with the cart lines of one store, the lines are
+// removed from the cart. Lines must carry the current product price, the
+// store must be open and the stock is reserved until the order is paid or
+// cancelled.
+func (s *service) Checkout(ctx context.Context, owner domain.CartOwner, request *domain.CheckoutRequest) (*domain.Order, errpkg.ErrorService) {
+	if owner.UserID == "" {
+		return nil, domain.ErrSignInRequired
+	}
+
+	var (
+		now   = time.Now().UTC()
+		order *repository.Order
+		items []*repository.OrderItem
+	)
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		items = nil
+
+		cart, err := existingCart(ctx, repo, owner, now)
+		if err != nil {
+			return err
+		}
+
+		cartItems, err := repo.ListCartItems(ctx, cart.ID)
+		if err != nil {
+			return err
+		}
+		if len(cartItems) == 0 {
+			return domain.ErrCartEmpty
+		}
+
+		products, err := repo.ListProductByIds(ctx, cartProductIds(cartItems))
+		if err != nil {
+			return err
+		}
+		if err := resolvePriceSchedules(ctx, repo, now, products...); err != nil {
+			return err
+		}
+		byId := make(map[string]*repository.Product, len(products))
+		for _, product := range products {
+			byId[product.ID] = product
+		}
+
+		var (
+			lines   []domain.BasketLine
+			ordered []*repository.Product
+		)
+		for _, item := range cartItems {
+			product, ok := byId[item.ProductID]
+			if !ok || product.StoreID != request.StoreID {
+				continue
+			}
+			if item.Price != product.Price {
+				return domain.ErrCartPriceChanged
+			}
+			lines = append(lines, basketLine(product, item.Quantity))
+			ordered = append(ordered, product)
+		}
+		if len(lines) == 0 {
+			return domain.ErrCartEmpty
+		}
+
+		store, err := repo.GetStoreById(ctx, request.StoreID)
+		if err != nil {
+			return err
+		}
+		if store == nil {
+			return domain.ErrStoreNotFound
+		}
+		if !domain.StoreOpen(store.OperationalTimeStart, store.OperationalTimeEnd, s.storeTime(now)) {
+			return domain.ErrStoreClosed
+		}
+
+		promotions, err := automaticPromotions(ctx, repo, lines, now)
+		if err != nil {
+			return err
+		}
+		if err := loadUserRedemptions(ctx, repo, promotions, owner.UserID); err != nil {
+			return err
+		}
+		var coupon *domain.Promotion
+		if request.CouponCode != "" {
+			if coupon, err = availableCoupon(ctx, repo, request.CouponCode, owner.UserID, now); err != nil {
+				return err
+			}
+			promotions = append(promotions, coupon)
+		}
+
+		evaluation := domain.EvaluateBasket(lines, promotions, now)
+		if coupon != nil && !evaluation.Applied(coupon.ID) {
+			return domain.ErrCouponNotApplicable
+		}
+
+		taxLines := make([]domain.TaxLine, 0, len(ordered))
+		for i, product := range ordered {
+			taxLines = append(taxLines, domain.TaxLine{Category: product.Category.String, Amount: evaluation.Lines[i].Total})
+		}
+		tax, err := storeTax(ctx, repo, store, taxLines)
+		if err != nil {
+			return err
+		}
+		taxes, err := json.Marshal(tax.Taxes)
+		if err != nil {
+			return err
+		}
+
+		order = &repository.Order{
+			ID:          uuid.New().String(),
+			StoreID:     store.ID,
+			UserID:      owner.UserID,
+			Status:      domain.OrderPending,
+			Subtotal:    evaluation.Subtotal,
+			Discount:    evaluation.Discount,
+			Total:       domain.RoundPrice(evaluation.Total + tax.Added),
+			CouponCode:  nullString(request.CouponCode),
+			ExpiresAt:   sql.NullTime{Time: now.Add(domain.OrderPaymentTTL), Valid: true},
+			CreatedAt:   now,
+			TaxIncluded: tax.Included,
+			TaxAdded:    tax.Added,
+			Taxes:       taxes,
+		}
+		if err := repo.CreateOrder(ctx, order); err != nil {
+			return err
+		}
+
+		for i, product := range ordered {
+			line := evaluation.Lines[i]
+
+			reserved, err := repo.ReserveStock(ctx, product.ID, line.Quantity)
+			if err != nil {
+				return err
+			}
+			if !reserved {
+				return domain.ErrOutOfStock(product.ID)
+			}
+
+			applied, err := json.Marshal(line.Promotions)
+			if err != nil {
+				return err
+			}
+			item := &repository.OrderItem{
+				OrderID:    order.ID,
+				ProductID:  product.ID,
+				Name:       product.Name,
+				Sku:        product.Sku,
+				UnitPrice:  line.UnitPrice,
+				SalePrice:  line.SalePrice,
+				Quantity:   line.Quantity,
+				Discount:   line.Discount,
+				Total:      line.Total,
+				Promotions: applied,
+
+				TaxIncluded: tax.Lines[i].Included,
+				TaxAdded:    tax.Lines[i].Added,
+			}
+			if err := repo.CreateOrderItem(ctx, item); err != nil {
+				return err
+			}
+			items = append(items, item)
+
+			if err := repo.DeleteCartItem(ctx, cart.ID, product.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := redeemPromotions(ctx, repo, evaluation, coupon, order); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repo, domain.AuditEntityOrder, order.ID, domain.AuditActionCreate, nil, orderAuditFields(order))
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return OrderRes(order, items), nil
+}
+
+// GetOrder return an order of the user
+func (s *service) GetOrder(ctx context.Context, userId string, id string) (*domain.Order, errpkg.ErrorService) {
+	if userId == "" {
+		return nil, domain.ErrSignInRequired
+	}
+
+	order, err := s.repo.GetOrderById(ctx, id)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if order == nil || order.UserID != userId {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	return s.orderWithItems(ctx, order)
+}
+
+// GetStoreOrder return an order placed on the store
+func (s *service) GetStoreOrder(ctx context.Context, storeId string, id string) (*domain.Order, errpkg.ErrorService) {
+	order, err := s.repo.GetOrderById(ctx, id)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if order == nil || order.StoreID != storeId {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	return s.orderWithItems(ctx, order)
+}
+
+// ShowOrders list orders of the user, newest first
+func (s *service) ShowOrders(ctx context.Context, pagination *httppagination.Pagination, userId string, status string) errpkg.ErrorService {
+	if userId == "" {
+		return domain.ErrSignInRequired
+	}
+
+	return s.showOrders(ctx, pagination, func() ([]*repository.Order, error) {
+		return s.repo.ListOrdersByUser(ctx, userId, status, pagination.Limit, pagination.Offset)
+	}, func() (int64, error) {
+		return s.repo.CountOrdersByUser(ctx, userId, status)
+	})
+}
+
+// ShowStoreOrders list orders placed on the store, newest first
+func (s *service) ShowStoreOrders(ctx context.Context, pagination *httppagination.Pagination, storeId string, status string) errpkg.ErrorService {
+	store, err := s.repo.GetStoreById(ctx, storeId)
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+	if store == nil {
+		return domain.ErrStoreNotFound
+	}
+
+	return s.showOrders(ctx, pagination, func() ([]*repository.Order, error) {
+		return s.repo.ListOrdersByStore(ctx, storeId, status, pagination.Limit, pagination.Offset)
+	}, func() (int64, error) {
+		return s.repo.CountOrdersByStore(ctx, storeId, status)
+	})
+}
+
+func (s *service) showOrders(ctx context.Context, pagination *httppagination.Pagination, list func() ([]*repository.Order, error), count func() (int64, error)) errpkg.ErrorService {
+	var (
+		g           sync.WaitGroup
+		int64Atomic atomic.Int64
+		arrayAtomic atomic.Value
+		errAtomic   atomic.Value
+		result      = []*domain.Order{}
+	)
+
+	g.Add(1)
+	go func() {
+		defer g.Done()
+		orders, err := list()
+		if err != nil {
+			errAtomic.Store(err)
+		} else {
+			arrayAtomic.Store(orders)
+		}
+	}()
+
+	g.Add(1)
+	go func() {
+		defer g.Done()
+		total, err := count()
+		if err != nil {
+			errAtomic.Store(err)
+		} else {
+			int64Atomic.Store(total)
+		}
+	}()
+	g.Wait()
+
+	if err, ok := errAtomic.Load().(error); ok {
+		return repository.TranslateError(err)
+	}
+
+	if orders, ok := arrayAtomic.Load().([]*repository.Order); !ok {
+		return errpkg.DefaultServiceError(errpkg.ErrInternal, "")
+	} else {
+		for _, order := range orders {
+			result = append(result, OrderRes(order, nil))
+		}
+	}
+
+	pagination.SetData(result, int64Atomic.Load())
+	return nil
+}
+
+// CancelOrder cancel an order of the user which has not been paid
+func (s *service) CancelOrder(ctx context.Context, userId string, id string, request *domain.OrderCancelRequest) (*domain.Order, errpkg.ErrorService) {
+	if userId == "" {
+		return nil, domain.ErrSignInRequired
+	}
+
+	var order *repository.Order
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		var err error
+		order, err = repo.GetOrderById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if order == nil || order.UserID != userId {
+			return domain.ErrOrderNotFound
+		}
+		if !domain.CustomerCanCancel(order.Status) {
+			return domain.ErrOrderStatus
+		}
+
+		return transitionOrder(ctx, repo, order, domain.OrderCancelled, request.Reason)
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return s.orderWithItems(ctx, order)
+}
+
+// UpdateOrderStatus move an order of the store to the next status
+func (s *service) UpdateOrderStatus(ctx context.Context, storeId string, id string, request *domain.OrderStatusRequest) (*domain.Order, errpkg.ErrorService) {
+	var order *repository.Order
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		var err error
+		order, err = repo.GetOrderById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if order == nil || order.StoreID != storeId {
+			return domain.ErrOrderNotFound
+		}
+
+		return transitionOrder(ctx, repo, order, request.Status, request.Reason)
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return s.orderWithItems(ctx, order)
+}
+
+// CancelExpiredOrders cancel pending orders not paid before now, every
+// order is cancelled on its own transaction and the first error is returned
+func (s *service) CancelExpiredOrders(ctx context.Context, now time.Time) errpkg.ErrorService {
+	orders, err := s.repo.ListExpiredOrders(ctx, now, orderExpiryBatch)
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+
+	var errSvc errpkg.ErrorService
+	for _, expired := range orders {
+		err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+			order, err := repo.GetOrderById(ctx, expired.ID)
+			if err != nil {
+				return err
+			}
+			// paid or cancelled since it was listed
+			if order == nil || order.Status != domain.OrderPending || order.ExpiresAt.Time.After(now) {
+				return nil
+			}
+
+			return transitionOrder(ctx, repo, order, domain.OrderCancelled, "payment expired")
+		})
+		if err != nil && errSvc == nil {
+			errSvc = repository.TranslateError(err)
+		}
+	}
+
+	return errSvc
+}
+
+// SetProductStock set the stock of a product, nil stop tracking it
+func (s *service) SetProductStock(ctx context.Context, id string, request *domain.ProductStockRequest) errpkg.ErrorService {
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		product, err := repo.GetProductById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return domain.ErrProductNotFound
+		}
+
+		var stock sql.NullInt64
+		if request.Stock != nil {
+			stock = sql.NullInt64{Int64: int64(*request.Stock), Valid: true}
+		}
+		if err := repo.PatchProduct(ctx, id, 0, []repository.Column{{Name: "stock", Value: stock}}); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repo, domain.AuditEntityProduct, id, domain.AuditActionUpdate,
+			domain.AuditFields{"stock": auditValue(product.Stock)},
+			domain.AuditFields{"stock": auditValue(stock)})
+	})
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+
+	return nil
+}
+
+// move the order to status to, a cancelled order release its stock and the
+// usage of its promotions
+func transitionOrder(ctx context.Context, repo repository.StoreRepository, order *repository.Order, to string, reason string) error {
+	if !domain.CanTransition(order.Status, to) {
+		return domain.ErrOrderStatus
+	}
+
+	from := order.Status
+	if err := repo.UpdateOrderStatus(ctx, order.ID, from, to); err != nil {
+		return err
+	}
+	order.Status = to
+
+	if to == domain.OrderCancelled {
+		if err := releaseOrder(ctx, repo, order.ID); err != nil {
+			return err
+		}
+	}
+
+	after := domain.AuditFields{"status": to}
+	if reason != "" {
+		after["reason"] = reason
+	}
+
+	return recordAudit(ctx, repo, domain.AuditEntityOrder, order.ID, domain.AuditActionUpdate, domain.AuditFields{"status": from}, after)
+}
+
+// put the stock of the order lines back, release its redemptions and its
+// pickup slot
+func releaseOrder(ctx context.Context, repo repository.StoreRepository, orderId string) error {
+	items, err := repo.ListOrderItems(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := repo.ReleaseStock(ctx, item.ProductID, item.Quantity); err != nil {
+			return err
+		}
+	}
+
+	if err := repo.ReleaseOrderRedemptions(ctx, orderId); err != nil {
+		return err
+	}
+
+	return repo.ReleaseOrderPickup(ctx, orderId)
+}
+
+// count a usage of every promotion applied on the order. A promotion which
+// ran out since it was evaluated fail the checkout.
+func redeemPromotions(ctx context.Context, repo repository.StoreRepository, evaluation *domain.BasketEvaluation, coupon *domain.Promotion, order *repository.Order) error {
+	seen := make(map[string]bool)
+	for _, line := range evaluation.Lines {
+		for _, applied := range line.Promotions {
+			if seen[applied.PromotionID] {
+				continue
+			}
+			seen[applied.PromotionID] = true
+
+			used, err := repo.UsePromotion(ctx, applied.PromotionID)
+			if err != nil {
+				return err
+			}
+			if !used {
+				if coupon != nil && applied.PromotionID == coupon.ID {
+					return domain.ErrCouponUsageExceeded
+				}
+				return domain.ErrCartPriceChanged
+			}
+
+			if err := repo.CreatePromotionRedemption(ctx, &repository.PromotionRedemption{
+				PromotionID: applied.PromotionID,
+				UserID:      order.UserID,
+				OrderID:     nullString(order.ID),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *service) orderWithItems(ctx context.Context, order *repository.Order) (*domain.Order, errpkg.ErrorService) {
+	items, err := s.repo.ListOrderItems(ctx, order.ID)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return OrderRes(order, items), nil
+}
+
+// now on the time zone of the store hours, STORE_TIMEZONE or UTC
+func (s *service) storeTime(now time.Time) time.Time {
+	if s.config == nil {
+		return now
+	}
+	name := s.config.GetString("STORE_TIMEZONE")
+	if name == "" {
+		return now
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		log.Println("invalid STORE_TIMEZONE: ", err)
+		return now
+	}
+
+	return now.In(location)
+}
+
+func orderAuditFields(order *repository.Order) domain.AuditFields {
+	return domain.AuditFields{
+		"store_id":    order.StoreID,
+		"user_id":     order.UserID,
+		"status":      order.Status,
+		"total":       order.Total,
+		"coupon_code": auditValue(order.CouponCode),
+	}
+}
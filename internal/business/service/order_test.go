@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	orderColumns     = []string{"id", "store_id", "user_id", "status", "subtotal", "discount", "total", "coupon_code", "expires_at", "created_at", "updated_at"}
+	orderItemColumns = []string{"id", "order_id", "product_id", "name", "sku", "unit_price", "sale_price", "quantity", "discount", "total", "promotions"}
+)
+
+func TestCheckoutPriceChanged(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	future := time.Now().UTC().Add(time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM carts WHERE user_id = \\$1 LIMIT 1").WithArgs("test_user_id").
+		WillReturnRows(sqlmock.NewRows(cartColumns).AddRow("test_cart_id", nil, "test_user_id", time.Now(), nil, future))
+	mock.ExpectQuery("SELECT (.+) FROM cart_items WHERE cart_id = \\$1").WithArgs("test_cart_id").
+		WillReturnRows(sqlmock.NewRows(cartItemColumns).AddRow("test_cart_id", "test_product_id", 2, 15000, time.Now(), nil))
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = ANY\\(\\$1\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version", "was_price", "category", "stock"}).
+			AddRow("test_product_id", "test_store_id", "Kopi Susu", "kopi-susu", 18000, "Es kopi susu", nil, time.Now(), nil, 1, nil, nil, nil))
+	mock.ExpectQuery("SELECT (.+) FROM product_price_schedules WHERE product_id = ANY\\(\\$1\\)").WillReturnRows(sqlmock.NewRows(priceScheduleColumns))
+	mock.ExpectRollback()
+
+	order, errSvc := svc.Checkout(context.Background(), domain.CartOwner{UserID: "test_user_id"}, &domain.CheckoutRequest{StoreID: "test_store_id"})
+	assert.Nil(t, order)
+	assert.Equal(t, domain.ErrCartPriceChanged, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckoutSignInRequired(t *testing.T) {
+	svc := NewStoreService(nil, nil, nil)
+
+	_, errSvc := svc.Checkout(context.Background(), domain.CartOwner{Token: "test_token"}, &domain.CheckoutRequest{StoreID: "test_store_id"})
+	assert.Equal(t, domain.ErrSignInRequired, errSvc)
+}
+
+func TestCancelOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	now := time.Now().UTC()
+	order := sqlmock.NewRows(orderColumns).
+		AddRow("test_order_id", "test_store_id", "test_user_id", "pending", 36000, 0, 36000, nil, now.Add(time.Minute), now, nil)
+	items := func() *sqlmock.Rows {
+		return sqlmock.NewRows(orderItemColumns).
+			AddRow(1, "test_order_id", "test_product_id", "Kopi Susu", nil, 18000, 18000, 2, 0, 36000, []byte(`[]`))
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").WillReturnRows(order)
+	mock.ExpectExec("UPDATE orders SET status = \\$3").WithArgs("test_order_id", "pending", "cancelled").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT (.+) FROM order_items WHERE order_id = \\$1").WithArgs("test_order_id").WillReturnRows(items())
+	mock.ExpectExec("UPDATE products SET stock = stock \\+ \\$2").WithArgs("test_product_id", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("WITH released AS \\(DELETE FROM promotion_redemptions").WithArgs("test_order_id").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("WITH released AS \\(UPDATE pickup_bookings SET status = 'cancelled'").WithArgs("test_order_id").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO audit_logs").
+		WithArgs("order", "test_order_id", "update", sqlmock.AnyArg(), sqlmock.AnyArg(), []byte(`{"reason":{"from":null,"to":"changed my mind"},"status":{"from":"pending","to":"cancelled"}}`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT (.+) FROM order_items WHERE order_id = \\$1").WithArgs("test_order_id").WillReturnRows(items())
+
+	res, errSvc := svc.CancelOrder(context.Background(), "test_user_id", "test_order_id", &domain.OrderCancelRequest{Reason: "changed my mind"})
+	assert.Nil(t, errSvc)
+	assert.Equal(t, domain.OrderCancelled, res.Status)
+	assert.Nil(t, res.ExpiresAt)
+	assert.Len(t, res.Items, 1)
+	assert.Equal(t, []domain.AppliedPromotion{}, res.Items[0].Promotions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelOrderPaid(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	now := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(orderColumns).
+			AddRow("test_order_id", "test_store_id", "test_user_id", "paid", 36000, 0, 36000, nil, now, now, nil))
+	mock.ExpectRollback()
+
+	_, errSvc := svc.CancelOrder(context.Background(), "test_user_id", "test_order_id", &domain.OrderCancelRequest{})
+	assert.Equal(t, domain.ErrOrderStatus, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateOrderStatusCancelPaid(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	// a paid order is refunded, cancelling it would keep the money
+	now := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(orderColumns).
+			AddRow("test_order_id", "test_store_id", "test_user_id", "paid", 36000, 0, 36000, nil, now, now, nil))
+	mock.ExpectRollback()
+
+	_, errSvc := svc.UpdateOrderStatus(context.Background(), "test_store_id", "test_order_id", &domain.OrderStatusRequest{Status: domain.OrderCancelled})
+	assert.Equal(t, domain.ErrOrderStatus, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
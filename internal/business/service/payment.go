@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ijlik/store-app/internal/adapter/payment"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+)
+
+// open payments reconciled per run of the reconciliation job
+const paymentReconcileBatch = 100
+
+const defaultPaymentCurrency = "IDR"
+
+// CreatePayment start the payment of a pending order of the user, the open
+// payment of the order is returned when there is one
+func (s *service) CreatePayment(ctx context.Context, userId string, orderId string) (*domain.Payment, errpkg.ErrorService) {
+	if userId == "" {
+		return nil, domain.ErrSignInRequired
+	}
+	if s.gateway == nil {
+		return nil, domain.ErrPaymentUnavailable
+	}
+
+	order, err := s.repo.GetOrderById(ctx, orderId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if order == nil || order.UserID != userId {
+		return nil, domain.ErrOrderNotFound
+	}
+	if order.Status != domain.OrderPending {
+		return nil, domain.ErrOrderNotPayable
+	}
+
+	open, err := s.repo.GetOpenPaymentByOrder(ctx, orderId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if open != nil {
+		intent, err := s.gateway.GetIntent(ctx, open.Reference)
+		if err != nil {
+			log.Println("get payment intent: ", err)
+			return nil, domain.ErrPaymentUnavailable
+		}
+		return PaymentRes(open, intent.ClientSecret), nil
+	}
+
+	currency := defaultPaymentCurrency
+	if s.config != nil && s.config.GetString("PAYMENT_CURRENCY") != "" {
+		currency = s.config.GetString("PAYMENT_CURRENCY")
+	}
+
+	intent, err := s.gateway.CreateIntent(ctx, payment.IntentRequest{
+		OrderID:  order.ID,
+		Amount:   order.Total,
+		Currency: currency,
+	})
+	if err != nil {
+		log.Println("create payment intent: ", err)
+		return nil, domain.ErrPaymentUnavailable
+	}
+
+	pay := &repository.Payment{
+		ID:        uuid.New().String(),
+		OrderID:   order.ID,
+		Provider:  s.gateway.Name(),
+		Reference: intent.ID,
+		Amount:    intent.Amount,
+		Currency:  intent.Currency,
+		Status:    domain.PaymentPending,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.CreatePayment(ctx, pay); err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return PaymentRes(pay, intent.ClientSecret), nil
+}
+
+// HandlePaymentWebhook apply an event of the provider with the intent read
+// back from the provider, an event delivered more than once is applied once.
+// Authorized payment is captured right away.
+func (s *service) HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) errpkg.ErrorService {
+	if s.gateway == nil {
+		return domain.ErrPaymentUnavailable
+	}
+
+	event, err := s.gateway.VerifyWebhook(payload, signature)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return domain.ErrInvalidSignature
+		}
+		return errpkg.DefaultServiceError(errpkg.ErrBadRequest, err.Error())
+	}
+
+	// the payload only tells which intent changed, its state is read back from
+	// the provider so a replayed or forged payload can not move a payment
+	intent, err := s.gateway.GetIntent(ctx, event.Intent.ID)
+	if err != nil && !errors.Is(err, payment.ErrIntentNotFound) {
+		log.Println("get payment intent: ", event.ID, err)
+		return domain.ErrPaymentUnavailable
+	}
+
+	var (
+		authorized *repository.Payment
+		captured   *repository.Payment
+		unpaid     *repository.Refund
+	)
+	err = s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		authorized, captured, unpaid = nil, nil, nil
+
+		created, err := repo.CreatePaymentEvent(ctx, &repository.PaymentEvent{
+			Provider:  s.gateway.Name(),
+			EventID:   event.ID,
+			Type:      event.Type,
+			Reference: event.Intent.ID,
+			Payload:   payload,
+		})
+		if err != nil {
+			return err
+		}
+		if !created {
+			return nil
+		}
+
+		pay, err := repo.GetPaymentByReference(ctx, s.gateway.Name(), event.Intent.ID)
+		if err != nil {
+			return err
+		}
+		// intent not started here or unknown to the provider, the event is
+		// kept for investigation
+		if pay == nil || intent == nil {
+			log.Println("payment event for unknown intent: ", event.ID, event.Intent.ID)
+			return nil
+		}
+
+		if unpaid, err = applyIntent(ctx, repo, pay, intent); err != nil {
+			return err
+		}
+		switch pay.Status {
+		case domain.PaymentAuthorized:
+			authorized = pay
+		case domain.PaymentCaptured:
+			captured = pay
+		}
+
+		return nil
+	})
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+
+	if authorized != nil {
+		return s.applyIntent(ctx, authorized.ID, intent)
+	}
+	if unpaid != nil {
+		return s.refundUnpaid(ctx, captured, unpaid)
+	}
+
+	return nil
+}
+
+// ReconcilePayments compare the open payments older than
+// PaymentReconcileDelay with the provider and apply the missed events,
+// every payment is reconciled on its own and the first error is returned
+func (s *service) ReconcilePayments(ctx context.Context, now time.Time) errpkg.ErrorService {
+	if s.gateway == nil {
+		return nil
+	}
+
+	payments, err := s.repo.ListOpenPayments(ctx, now.Add(-domain.PaymentReconcileDelay), paymentReconcileBatch)
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+
+	var errSvc errpkg.ErrorService
+	for _, pay := range payments {
+		if pay.Provider != s.gateway.Name() {
+			continue
+		}
+
+		intent, err := s.gateway.GetIntent(ctx, pay.Reference)
+		if err != nil {
+			log.Println("reconcile payment: ", pay.ID, err)
+			if errSvc == nil {
+				errSvc = domain.ErrPaymentUnavailable
+			}
+			continue
+		}
+
+		if err := s.applyIntent(ctx, pay.ID, intent); err != nil && errSvc == nil {
+			errSvc = err
+		}
+	}
+
+	return errSvc
+}
+
+// apply the state of an intent on the payment, an authorized intent is
+// captured first and a capture which does not pay its order is refunded
+func (s *service) applyIntent(ctx context.Context, paymentId string, intent *payment.Intent) errpkg.ErrorService {
+	if intent.Status == payment.IntentAuthorized {
+		captured, err := s.gateway.Capture(ctx, intent.ID, intent.Amount)
+		if err != nil {
+			log.Println("capture payment: ", paymentId, err)
+			return domain.ErrPaymentUnavailable
+		}
+		intent = captured
+	}
+
+	var (
+		pay    *repository.Payment
+		unpaid *repository.Refund
+	)
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		var err error
+		if pay, err = repo.GetPaymentById(ctx, paymentId); err != nil {
+			return err
+		}
+		if pay == nil {
+			return nil
+		}
+
+		unpaid, err = applyIntent(ctx, repo, pay, intent)
+		return err
+	})
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+
+	if unpaid != nil {
+		return s.refundUnpaid(ctx, pay, unpaid)
+	}
+
+	return nil
+}
+
+// move the payment to the status of the intent, a captured payment pay its
+// order. Status going backward is ignored so events can arrive in any order.
+// A capture arriving after the order expired or was cancelled, or short of
+// its total, reserve a refund of the whole payment which is returned for the
+// caller to send to the provider.
+func applyIntent(ctx context.Context, repo repository.StoreRepository, pay *repository.Payment, intent *payment.Intent) (*repository.Refund, error) {
+	var to string
+	switch intent.Status {
+	case payment.IntentAuthorized:
+		if pay.Status != domain.PaymentPending {
+			return nil, nil
+		}
+		to = domain.PaymentAuthorized
+	case payment.IntentCaptured:
+		if pay.Status != domain.PaymentPending && pay.Status != domain.PaymentAuthorized {
+			return nil, nil
+		}
+		to = domain.PaymentCaptured
+	case payment.IntentRequiresPayment:
+		// the customer never paid, the payment is closed with its order
+		if pay.Status != domain.PaymentPending {
+			return nil, nil
+		}
+		order, err := repo.GetOrderById(ctx, pay.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		if order != nil && order.Status == domain.OrderPending {
+			return nil, nil
+		}
+		to = domain.PaymentFailed
+	case payment.IntentFailed, payment.IntentCancelled:
+		if pay.Status != domain.PaymentPending && pay.Status != domain.PaymentAuthorized {
+			return nil, nil
+		}
+		to = domain.PaymentFailed
+	default:
+		return nil, nil
+	}
+
+	if err := repo.UpdatePaymentStatus(ctx, pay.ID, pay.Status, to, intent.Captured); err != nil {
+		return nil, err
+	}
+	pay.Status = to
+	pay.CapturedAmount = intent.Captured
+
+	if to != domain.PaymentCaptured {
+		return nil, nil
+	}
+
+	order, err := repo.GetOrderById(ctx, pay.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || order.Status != domain.OrderPending || pay.CapturedAmount < order.Total {
+		log.Println("captured payment does not pay its order: ", pay.ID, pay.OrderID)
+		return reserveUnpaidRefund(ctx, repo, pay, domain.UnpaidRefundReason)
+	}
+
+	return nil, transitionOrder(ctx, repo, order, domain.OrderPaid, "")
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ijlik/store-app/internal/adapter/payment"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var paymentColumns = []string{"id", "order_id", "provider", "reference", "amount", "captured_amount", "refunded_amount", "currency", "status", "created_at", "updated_at"}
+
+func TestHandlePaymentWebhookAuthorized(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	gateway := payment.NewFakeGateway("secret", false)
+	intent, err := gateway.CreateIntent(ctx, payment.IntentRequest{OrderID: "test_order_id", Amount: 36000, Currency: "IDR"})
+	assert.NoError(t, err)
+	payload, signature, err := gateway.Authorize(intent.ID)
+	assert.NoError(t, err)
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, gateway)
+
+	now := time.Now().UTC()
+	paymentRow := func(status string, captured float32) *sqlmock.Rows {
+		return sqlmock.NewRows(paymentColumns).
+			AddRow("test_payment_id", "test_order_id", "fake", intent.ID, 36000, captured, 0, "IDR", status, now, nil)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO payment_events").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT (.+) FROM payments WHERE provider = \\$1 AND reference = \\$2").WithArgs("fake", intent.ID).
+		WillReturnRows(paymentRow(domain.PaymentPending, 0))
+	mock.ExpectExec("UPDATE payments SET status = \\$3").WithArgs("test_payment_id", "pending", "authorized", float32(0)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM payments WHERE id = \\$1").WithArgs("test_payment_id").
+		WillReturnRows(paymentRow(domain.PaymentAuthorized, 0))
+	mock.ExpectExec("UPDATE payments SET status = \\$3").WithArgs("test_payment_id", "authorized", "captured", float32(36000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(orderColumns).
+			AddRow("test_order_id", "test_store_id", "test_user_id", "pending", 36000, 0, 36000, nil, now.Add(time.Minute), now, nil))
+	mock.ExpectExec("UPDATE orders SET status = \\$3").WithArgs("test_order_id", "pending", "paid").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	errSvc := svc.HandlePaymentWebhook(ctx, payload, signature)
+	assert.Nil(t, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	captured, err := gateway.GetIntent(ctx, intent.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, payment.IntentCaptured, captured.Status)
+}
+
+func TestHandlePaymentWebhookLateCapture(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	gateway := payment.NewFakeGateway("secret", false)
+	intent, err := gateway.CreateIntent(ctx, payment.IntentRequest{OrderID: "test_order_id", Amount: 36000, Currency: "IDR"})
+	assert.NoError(t, err)
+	payload, signature, err := gateway.Authorize(intent.ID)
+	assert.NoError(t, err)
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, gateway)
+
+	now := time.Now().UTC()
+	paymentRow := func(status string, captured float32) *sqlmock.Rows {
+		return sqlmock.NewRows(paymentColumns).
+			AddRow("test_payment_id", "test_order_id", "fake", intent.ID, 36000, captured, 0, "IDR", status, now, nil)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO payment_events").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT (.+) FROM payments WHERE provider = \\$1 AND reference = \\$2").WithArgs("fake", intent.ID).
+		WillReturnRows(paymentRow(domain.PaymentPending, 0))
+	mock.ExpectExec("UPDATE payments SET status = \\$3").WithArgs("test_payment_id", "pending", "authorized", float32(0)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// the order expired before the capture, the payment is refunded whole
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM payments WHERE id = \\$1").WithArgs("test_payment_id").
+		WillReturnRows(paymentRow(domain.PaymentAuthorized, 0))
+	mock.ExpectExec("UPDATE payments SET status = \\$3").WithArgs("test_payment_id", "authorized", "captured", float32(36000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(orderColumns).
+			AddRow("test_order_id", "test_store_id", "test_user_id", "cancelled", 36000, 0, 36000, nil, now.Add(-time.Minute), now, nil))
+	mock.ExpectExec("UPDATE payments SET refunded_amount = refunded_amount \\+ \\$2").WithArgs("test_payment_id", float32(36000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO refunds").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE refunds SET status = \\$3").WithArgs(sqlmock.AnyArg(), "pending", "succeeded", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_logs").WithArgs("refund", sqlmock.AnyArg(), "create", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	errSvc := svc.HandlePaymentWebhook(ctx, payload, signature)
+	assert.Nil(t, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	refunded, err := gateway.GetIntent(ctx, intent.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, payment.IntentCaptured, refunded.Status)
+	assert.Equal(t, float32(36000), refunded.Refunded)
+}
+
+func TestHandlePaymentWebhookDuplicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gateway := payment.NewFakeGateway("secret", false)
+	payload := []byte(`{"id":"evt_1","type":"payment.authorized","data":{"id":"pi_1","status":"authorized"}}`)
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, gateway)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO payment_events").WithArgs("fake", "evt_1", "payment.authorized", "pi_1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	errSvc := svc.HandlePaymentWebhook(context.Background(), payload, gateway.Sign(payload))
+	assert.Nil(t, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHandlePaymentWebhookForgedStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	gateway := payment.NewFakeGateway("secret", false)
+	intent, err := gateway.CreateIntent(ctx, payment.IntentRequest{OrderID: "test_order_id", Amount: 36000, Currency: "IDR"})
+	assert.NoError(t, err)
+	// signed but claiming a capture the provider never made
+	payload := []byte(`{"id":"evt_1","type":"payment.captured","data":{"id":"` + intent.ID + `","status":"captured","captured":36000}}`)
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, gateway)
+
+	now := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO payment_events").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT (.+) FROM payments WHERE provider = \\$1 AND reference = \\$2").WithArgs("fake", intent.ID).
+		WillReturnRows(sqlmock.NewRows(paymentColumns).
+			AddRow("test_payment_id", "test_order_id", "fake", intent.ID, 36000, 0, 0, "IDR", domain.PaymentPending, now, nil))
+	// the intent still requires payment, the order is left waiting for it
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(orderColumns).
+			AddRow("test_order_id", "test_store_id", "test_user_id", "pending", 36000, 0, 36000, nil, now.Add(time.Minute), now, nil))
+	mock.ExpectCommit()
+
+	errSvc := svc.HandlePaymentWebhook(ctx, payload, gateway.Sign(payload))
+	assert.Nil(t, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHandlePaymentWebhookInvalidSignature(t *testing.T) {
+	svc := NewStoreService(nil, nil, payment.NewFakeGateway("secret", false))
+
+	errSvc := svc.HandlePaymentWebhook(context.Background(), []byte(`{"id":"evt_1"}`), "deadbeef")
+	assert.Equal(t, domain.ErrInvalidSignature, errSvc)
+}
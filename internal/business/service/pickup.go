@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+)
+
+// UpdatePickupSettings create or replace the pickup settings of the store,
+// bookings already made keep their slot
+func (s *service) UpdatePickupSettings(ctx context.Context, storeId string, request *domain.PickupSettingsRequest) (*domain.PickupSettings, errpkg.ErrorService) {
+	store, err := s.repo.GetStoreById(ctx, storeId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if store == nil {
+		return nil, domain.ErrStoreNotFound
+	}
+
+	settings := &repository.PickupSettings{
+		StoreID:     store.ID,
+		SlotMinutes: request.SlotMinutes,
+		Capacity:    request.Capacity,
+		LeadMinutes: request.LeadMinutes,
+		DaysAhead:   request.DaysAhead,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	if err := s.repo.UpsertPickupSettings(ctx, settings); err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return PickupSettingsRes(settings), nil
+}
+
+func (s *service) GetPickupSettings(ctx context.Context, storeId string) (*domain.PickupSettings, errpkg.ErrorService) {
+	settings, err := s.repo.GetPickupSettings(ctx, storeId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if settings == nil {
+		return nil, domain.ErrPickupUnavailable
+	}
+
+	return PickupSettingsRes(settings), nil
+}
+
+// ListPickupSlots list the pickup slots the store offers from now, in the
+// time zone of the store hours, with their remaining places
+func (s *service) ListPickupSlots(ctx context.Context, storeId string) ([]domain.PickupSlot, errpkg.ErrorService) {
+	store, err := s.repo.GetStoreById(ctx, storeId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if store == nil {
+		return nil, domain.ErrStoreNotFound
+	}
+	settings, err := s.repo.GetPickupSettings(ctx, store.ID)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if settings == nil {
+		return nil, domain.ErrPickupUnavailable
+	}
+
+	slots := s.pickupSlots(store, settings)
+	if len(slots) == 0 {
+		return slots, nil
+	}
+
+	booked, err := s.repo.ListPickupSlots(ctx, store.ID, slots[0].StartsAt.UTC(), slots[len(slots)-1].EndsAt.UTC())
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	for _, count := range booked {
+		slot := domain.FindPickupSlot(slots, count.StartsAt)
+		if slot == nil {
+			continue
+		}
+		slot.Booked = count.Booked
+		slot.Available = slot.Capacity - count.Booked
+		if slot.Available < 0 {
+			slot.Available = 0
+		}
+	}
+
+	return slots, nil
+}
+
+// BookPickupSlot book a pickup slot of the store for an order of the user
+// placed on the store. An order already booked is moved to the new slot and
+// its previous slot is released.
+func (s *service) BookPickupSlot(ctx context.Context, userId string, storeId string, request *domain.PickupBookingRequest) (*domain.PickupBooking, errpkg.ErrorService) {
+	if userId == "" {
+		return nil, domain.ErrSignInRequired
+	}
+
+	var booking *repository.PickupBooking
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		store, err := repo.GetStoreById(ctx, storeId)
+		if err != nil {
+			return err
+		}
+		if store == nil {
+			return domain.ErrStoreNotFound
+		}
+		settings, err := repo.GetPickupSettings(ctx, store.ID)
+		if err != nil {
+			return err
+		}
+		if settings == nil {
+			return domain.ErrPickupUnavailable
+		}
+
+		order, err := repo.GetOrderById(ctx, request.OrderID)
+		if err != nil {
+			return err
+		}
+		if order == nil || order.UserID != userId || order.StoreID != store.ID {
+			return domain.ErrOrderNotFound
+		}
+		if !domain.PickupBookable(order.Status) {
+			return domain.ErrOrderStatus
+		}
+
+		slot := domain.FindPickupSlot(s.pickupSlots(store, settings), request.StartsAt)
+		if slot == nil {
+			return domain.ErrPickupSlotInvalid
+		}
+		startsAt := slot.StartsAt.UTC()
+
+		current, err := repo.GetPickupBookingByOrder(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			if current.StartsAt.Equal(startsAt) {
+				booking = current
+				return nil
+			}
+			if err := repo.ReleaseOrderPickup(ctx, order.ID); err != nil {
+				return err
+			}
+		}
+
+		reserved, err := repo.ReservePickupSlot(ctx, store.ID, startsAt, settings.Capacity)
+		if err != nil {
+			return err
+		}
+		if !reserved {
+			return domain.ErrPickupSlotFull
+		}
+
+		booking = &repository.PickupBooking{
+			ID:        uuid.New().String(),
+			StoreID:   store.ID,
+			OrderID:   order.ID,
+			UserID:    userId,
+			StartsAt:  startsAt,
+			EndsAt:    slot.EndsAt.UTC(),
+			Status:    domain.PickupBooked,
+			CreatedAt: time.Now().UTC(),
+		}
+
+		return repo.CreatePickupBooking(ctx, booking)
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return PickupBookingRes(booking), nil
+}
+
+// slots the store offers from now following its opening hours
+func (s *service) pickupSlots(store *repository.Store, settings *repository.PickupSettings) []domain.PickupSlot {
+	return domain.PickupSlots(*PickupSettingsRes(settings), store.OperationalTimeStart, store.OperationalTimeEnd, s.storeTime(time.Now().UTC()))
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var pickupSettingsColumns = []string{"store_id", "slot_minutes", "capacity", "lead_minutes", "days_ahead", "updated_at"}
+
+func TestBookPickupSlotMoveBooking(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	var (
+		now      = time.Now().UTC()
+		previous = now.Truncate(time.Hour).Add(24 * time.Hour)
+		startsAt = previous.Add(time.Hour)
+	)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM stores WHERE id = \\$1 LIMIT 1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "operational_time_start", "operational_time_end"}).AddRow("test_store_id", "Kopi Kenangan", 0, 0))
+	mock.ExpectQuery("SELECT (.+) FROM pickup_settings WHERE store_id = \\$1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows(pickupSettingsColumns).AddRow("test_store_id", 60, 5, 0, 2, now))
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(orderColumns).AddRow("test_order_id", "test_store_id", "test_user_id", "paid", 36000, 0, 36000, nil, nil, now, nil))
+	mock.ExpectQuery("SELECT (.+) FROM pickup_bookings WHERE order_id = \\$1 AND status = 'booked'").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "order_id", "user_id", "starts_at", "ends_at", "status", "created_at", "updated_at"}).
+			AddRow("test_booking_id", "test_store_id", "test_order_id", "test_user_id", previous, previous.Add(time.Hour), "booked", now, nil))
+	mock.ExpectExec("WITH released AS \\(UPDATE pickup_bookings SET status = 'cancelled'").WithArgs("test_order_id").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO pickup_slots").WithArgs("test_store_id", startsAt, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO pickup_bookings").
+		WithArgs(sqlmock.AnyArg(), "test_store_id", "test_order_id", "test_user_id", startsAt, startsAt.Add(time.Hour), "booked").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	booking, errSvc := svc.BookPickupSlot(context.Background(), "test_user_id", "test_store_id", &domain.PickupBookingRequest{OrderID: "test_order_id", StartsAt: startsAt})
+	assert.Nil(t, errSvc)
+	assert.Equal(t, startsAt, booking.StartsAt)
+	assert.Equal(t, domain.PickupBooked, booking.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookPickupSlotFull(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	var (
+		now      = time.Now().UTC()
+		startsAt = now.Truncate(time.Hour).Add(24 * time.Hour)
+	)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM stores WHERE id = \\$1 LIMIT 1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "operational_time_start", "operational_time_end"}).AddRow("test_store_id", "Kopi Kenangan", 0, 0))
+	mock.ExpectQuery("SELECT (.+) FROM pickup_settings WHERE store_id = \\$1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows(pickupSettingsColumns).AddRow("test_store_id", 60, 5, 0, 2, now))
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(orderColumns).AddRow("test_order_id", "test_store_id", "test_user_id", "pending", 36000, 0, 36000, nil, now.Add(time.Minute), now, nil))
+	mock.ExpectQuery("SELECT (.+) FROM pickup_bookings WHERE order_id = \\$1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT INTO pickup_slots").WithArgs("test_store_id", startsAt, 5).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	booking, errSvc := svc.BookPickupSlot(context.Background(), "test_user_id", "test_store_id", &domain.PickupBookingRequest{OrderID: "test_order_id", StartsAt: startsAt})
+	assert.Nil(t, booking)
+	assert.Equal(t, domain.ErrPickupSlotFull, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookPickupSlotNotOffered(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	now := time.Now().UTC()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM stores WHERE id = \\$1 LIMIT 1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "operational_time_start", "operational_time_end"}).AddRow("test_store_id", "Kopi Kenangan", 0, 0))
+	mock.ExpectQuery("SELECT (.+) FROM pickup_settings WHERE store_id = \\$1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows(pickupSettingsColumns).AddRow("test_store_id", 60, 5, 0, 2, now))
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(orderColumns).AddRow("test_order_id", "test_store_id", "test_user_id", "paid", 36000, 0, 36000, nil, nil, now, nil))
+	mock.ExpectRollback()
+
+	_, errSvc := svc.BookPickupSlot(context.Background(), "test_user_id", "test_store_id", &domain.PickupBookingRequest{OrderID: "test_order_id", StartsAt: now.Add(10 * 24 * time.Hour)})
+	assert.Equal(t, domain.ErrPickupSlotInvalid, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppagination "github.com/ijlik/store-app/pkg/http/pagination"
+)
+
+// max schedules handled by one run of the price job, the rest wait for the
+// next run
+const priceScheduleBatch = 100
+
+// ShowPriceHistory list the price changes of a product, newest first
+func (s *service) ShowPriceHistory(ctx context.Context, pagination *httppagination.Pagination, productId string) errpkg.ErrorService {
+	var (
+		g           sync.WaitGroup
+		int64Atomic atomic.Int64
+		arrayAtomic atomic.Value
+		errAtomic   atomic.Value
+		result      = []*domain.PriceHistory{}
+	)
+
+	product, err := s.repo.GetProductById(ctx, productId)
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+	if product == nil {
+		return domain.ErrProductNotFound
+	}
+
+	g.Add(1)
+	go func() {
+		defer g.Done()
+		histories, err := s.repo.ListPriceHistories(ctx, productId, pagination.Limit, pagination.Offset)
+		if err != nil {
+			errAtomic.Store(err)
+		} else {
+			arrayAtomic.Store(histories)
+		}
+	}()
+
+	g.Add(1)
+	go func() {
+		defer g.Done()
+		count, err := s.repo.CountPriceHistories(ctx, productId)
+		if err != nil {
+			errAtomic.Store(err)
+		} else {
+			int64Atomic.Store(count)
+		}
+	}()
+	g.Wait()
+
+	if err, ok := errAtomic.Load().(error); ok {
+		return repository.TranslateError(err)
+	}
+
+	if histories, ok := arrayAtomic.Load().([]*repository.PriceHistory); !ok {
+		return errpkg.DefaultServiceError(errpkg.ErrInternal, "")
+	} else {
+		for _, history := range histories {
+			result = append(result, PriceHistoryRes(history))
+		}
+	}
+
+	pagination.SetData(result, int64Atomic.Load())
+	return nil
+}
+
+// ShowPriceSchedules list pending and active schedules of a product
+func (s *service) ShowPriceSchedules(ctx context.Context, productId string) ([]*domain.PriceSchedule, errpkg.ErrorService) {
+	product, err := s.repo.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+
+	schedules, err := s.repo.ListOpenPriceSchedules(ctx, productId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	result := []*domain.PriceSchedule{}
+	for _, schedule := range schedules {
+		result = append(result, PriceScheduleRes(schedule))
+	}
+
+	return result, nil
+}
+
+func (s *service) CreatePriceSchedule(ctx context.Context, request *domain.PriceScheduleRequest, productId string) (*domain.PriceSchedule, errpkg.ErrorService) {
+	var schedule *repository.PriceSchedule
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		product, err := repo.GetProductById(ctx, productId)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return domain.ErrProductNotFound
+		}
+
+		open, err := repo.ListOpenPriceSchedules(ctx, productId)
+		if err != nil {
+			return err
+		}
+		period := domain.PricePeriod{From: request.EffectiveFrom, To: request.EffectiveTo}
+		for _, other := range open {
+			if period.Conflicts(pricePeriod(other)) {
+				return domain.ErrPriceScheduleConflict
+			}
+		}
+
+		schedule = &repository.PriceSchedule{
+			ID:            uuid.New().String(),
+			ProductID:     productId,
+			Price:         request.Price,
+			EffectiveFrom: request.EffectiveFrom,
+			Status:        domain.PriceSchedulePending,
+			CreatedAt:     time.Now().UTC(),
+		}
+		if request.EffectiveTo != nil {
+			schedule.EffectiveTo = sql.NullTime{Time: *request.EffectiveTo, Valid: true}
+		}
+
+		return repo.CreatePriceSchedule(ctx, schedule)
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return PriceScheduleRes(schedule), nil
+}
+
+// CancelPriceSchedule cancel a pending schedule, an active schedule is ended
+// right away
+func (s *service) CancelPriceSchedule(ctx context.Context, productId string, scheduleId string) errpkg.ErrorService {
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		schedule, err := repo.GetPriceScheduleById(ctx, scheduleId)
+		if err != nil {
+			return err
+		}
+		if schedule == nil || schedule.ProductID != productId {
+			return domain.ErrPriceScheduleNotFound
+		}
+
+		switch schedule.Status {
+		case domain.PriceSchedulePending:
+			return repo.UpdatePriceScheduleStatus(ctx, schedule.ID, domain.PriceScheduleCancelled, schedule.PreviousPrice)
+		case domain.PriceScheduleActive:
+			return endPriceSchedule(ctx, repo, schedule, domain.PriceScheduleCancelled)
+		}
+
+		return domain.ErrPriceScheduleClosed
+	})
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+
+	return nil
+}
+
+// ApplyPriceSchedules start and end the schedules due at now, each schedule
+// is applied on its own transaction so a failing one does not hold the
+// others, the first error is returned. Reads do not wait for this run,
+// resolvePriceSchedules give them the price from effective_from on.
+func (s *service) ApplyPriceSchedules(ctx context.Context, now time.Time) errpkg.ErrorService {
+	due, err := s.repo.ListDuePriceSchedules(ctx, now, priceScheduleBatch)
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+
+	var firstErr error
+	for _, d := range due {
+		id := d.ID
+		err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+			// status may have changed since listed
+			schedule, err := repo.GetPriceScheduleById(ctx, id)
+			if err != nil {
+				return err
+			}
+			if schedule == nil {
+				return nil
+			}
+
+			return applyPriceSchedule(ctx, repo, schedule, now)
+		})
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return repository.TranslateError(firstErr)
+}
+
+func applyPriceSchedule(ctx context.Context, repo repository.StoreRepository, schedule *repository.PriceSchedule, now time.Time) error {
+	ended := schedule.EffectiveTo.Valid && !now.Before(schedule.EffectiveTo.Time)
+
+	switch {
+	case schedule.Status == domain.PriceSchedulePending && ended:
+		// the whole period passed while the job was not running
+		return repo.UpdatePriceScheduleStatus(ctx, schedule.ID, domain.PriceScheduleDone, schedule.PreviousPrice)
+	case schedule.Status == domain.PriceSchedulePending && !now.Before(schedule.EffectiveFrom):
+		return startPriceSchedule(ctx, repo, schedule)
+	case schedule.Status == domain.PriceScheduleActive && ended:
+		return endPriceSchedule(ctx, repo, schedule, domain.PriceScheduleDone)
+	}
+
+	return nil
+}
+
+// resolvePriceSchedules set on the products the price and was price their
+// started schedules give at now, the price ApplyPriceSchedules is about to
+// write, so a scheduled price is shown and charged from effective_from on
+func resolvePriceSchedules(ctx context.Context, repo repository.StoreRepository, now time.Time, products ...*repository.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+
+	schedules, err := repo.ListStartedPriceSchedules(ctx, ids, now)
+	if err != nil {
+		return err
+	}
+
+	byProduct := make(map[string][]*repository.PriceSchedule, len(products))
+	for _, schedule := range schedules {
+		byProduct[schedule.ProductID] = append(byProduct[schedule.ProductID], schedule)
+	}
+	for _, product := range products {
+		scheduledPrice(product, byProduct[product.ID], now)
+	}
+
+	return nil
+}
+
+// scheduledPrice apply the schedules of a product, earliest first, on its
+// price the way applyPriceSchedule would write them at now
+func scheduledPrice(product *repository.Product, schedules []*repository.PriceSchedule, now time.Time) {
+	for _, schedule := range schedules {
+		ended := schedule.EffectiveTo.Valid && !now.Before(schedule.EffectiveTo.Time)
+
+		switch {
+		case schedule.Status == domain.PriceSchedulePending && ended:
+			// the whole period passed, the job close it without a change
+		case schedule.Status == domain.PriceSchedulePending && !now.Before(schedule.EffectiveFrom):
+			product.WasPrice = sql.NullFloat64{}
+			if schedule.EffectiveTo.Valid {
+				product.WasPrice = priceOf(product.Price)
+			}
+			product.Price = schedule.Price
+		case schedule.Status == domain.PriceScheduleActive && ended:
+			if product.Price == schedule.Price && schedule.PreviousPrice.Valid {
+				product.Price = float32(schedule.PreviousPrice.Float64)
+			}
+			product.WasPrice = sql.NullFloat64{}
+		}
+	}
+}
+
+// startPriceSchedule set the product price, a temporary schedule keep the
+// current price as was price to restore it at the end
+func startPriceSchedule(ctx context.Context, repo repository.StoreRepository, schedule *repository.PriceSchedule) error {
+	product, err := repo.GetProductById(ctx, schedule.ProductID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return repo.UpdatePriceScheduleStatus(ctx, schedule.ID, domain.PriceScheduleCancelled, schedule.PreviousPrice)
+	}
+
+	var (
+		previous = priceOf(product.Price)
+		status   = domain.PriceScheduleDone
+		was      sql.NullFloat64
+	)
+	if schedule.EffectiveTo.Valid {
+		status = domain.PriceScheduleActive
+		was = previous
+	}
+
+	if err := setProductPrice(ctx, repo, product, schedule.Price, was, schedule.ID); err != nil {
+		return err
+	}
+
+	return repo.UpdatePriceScheduleStatus(ctx, schedule.ID, status, previous)
+}
+
+// endPriceSchedule restore the price from before the schedule, a price
+// changed by hand while the schedule was running is kept
+func endPriceSchedule(ctx context.Context, repo repository.StoreRepository, schedule *repository.PriceSchedule, status string) error {
+	product, err := repo.GetProductById(ctx, schedule.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if product != nil {
+		price := product.Price
+		if price == schedule.Price && schedule.PreviousPrice.Valid {
+			price = float32(schedule.PreviousPrice.Float64)
+		}
+
+		if err := setProductPrice(ctx, repo, product, price, sql.NullFloat64{}, schedule.ID); err != nil {
+			return err
+		}
+	}
+
+	return repo.UpdatePriceScheduleStatus(ctx, schedule.ID, status, schedule.PreviousPrice)
+}
+
+// setProductPrice update price and was price of the product, the price change
+// is recorded on the price history and the audit log
+func setProductPrice(ctx context.Context, repo repository.StoreRepository, product *repository.Product, price float32, was sql.NullFloat64, scheduleId string) error {
+	columns := []repository.Column{{Name: "was_price", Value: was}}
+	if price != product.Price {
+		columns = append(columns, repository.Column{Name: "price", Value: price})
+	}
+
+	if err := repo.PatchProduct(ctx, product.ID, 0, columns); err != nil {
+		return err
+	}
+
+	if price == product.Price {
+		return nil
+	}
+	if err := recordPrice(ctx, repo, product.ID, price, priceOf(product.Price), scheduleId); err != nil {
+		return err
+	}
+
+	before := productAuditFields(product)
+	return recordAudit(ctx, repo, domain.AuditEntityProduct, product.ID, domain.AuditActionUpdate, before, patchedAuditFields(before, columns))
+}
+
+// recordPrice add a price change to the price history, previous is NULL for a
+// new product and scheduleId empty for a change made by hand
+func recordPrice(ctx context.Context, repo repository.StoreRepository, productId string, price float32, previous sql.NullFloat64, scheduleId string) error {
+	return repo.CreatePriceHistory(ctx, &repository.PriceHistory{
+		ProductID:     productId,
+		Price:         price,
+		PreviousPrice: previous,
+		ScheduleID:    nullString(scheduleId),
+	})
+}
+
+func priceOf(price float32) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: float64(price), Valid: true}
+}
+
+func pricePeriod(schedule *repository.PriceSchedule) domain.PricePeriod {
+	period := domain.PricePeriod{From: schedule.EffectiveFrom}
+	if schedule.EffectiveTo.Valid {
+		to := schedule.EffectiveTo.Time
+		period.To = &to
+	}
+
+	return period
+}
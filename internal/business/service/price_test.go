@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var priceScheduleColumns = []string{"id", "product_id", "price", "effective_from", "effective_to", "status", "previous_price", "created_at", "updated_at"}
+
+func TestApplyPriceSchedulesStart(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	ctx := context.Background()
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	from := now.Add(-time.Minute)
+	to := now.Add(24 * time.Hour)
+
+	listDuePriceSchedulesQueryMock := "SELECT (.+) FROM product_price_schedules WHERE \\(status = 'pending' AND effective_from <= \\$1\\) OR \\(status = 'active' AND effective_to <= \\$1\\)"
+	getPriceScheduleByIdQueryMock := "SELECT (.+) FROM product_price_schedules WHERE id = \\$1 LIMIT 1"
+	getProductByIdQueryMock := "SELECT (.+) FROM products WHERE id = \\$1 LIMIT 1"
+	patchProductQueryMock := "UPDATE products SET was_price = \\$3, price = \\$4, updated_at = CURRENT_TIMESTAMP, version = version \\+ 1 WHERE id = \\$1"
+	createPriceHistoryQueryMock := "INSERT INTO product_price_histories"
+	createAuditLogQueryMock := "INSERT INTO audit_logs"
+	updatePriceScheduleStatusQueryMock := "UPDATE product_price_schedules SET status = \\$2, previous_price = \\$3"
+
+	scheduleRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows(priceScheduleColumns).
+			AddRow("test_schedule_id", "test_product_id", 80, from, to, domain.PriceSchedulePending, nil, from, nil)
+	}
+
+	mock.ExpectQuery(listDuePriceSchedulesQueryMock).WithArgs(now, priceScheduleBatch).WillReturnRows(scheduleRow())
+	mock.ExpectBegin()
+	mock.ExpectQuery(getPriceScheduleByIdQueryMock).WithArgs("test_schedule_id").WillReturnRows(scheduleRow())
+	mock.ExpectQuery(getProductByIdQueryMock).WithArgs("test_product_id").WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version", "was_price"}).
+		AddRow("test_product_id", "test_store_id", "test_product_name", "test_product_url", 100, "test_product_description", nil, from, nil, 2, nil))
+	mock.ExpectExec(patchProductQueryMock).
+		WithArgs("test_product_id", 0, sql.NullFloat64{Float64: 100, Valid: true}, float32(80)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(createPriceHistoryQueryMock).
+		WithArgs("test_product_id", float32(80), sql.NullFloat64{Float64: 100, Valid: true}, sql.NullString{String: "test_schedule_id", Valid: true}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(createAuditLogQueryMock).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(updatePriceScheduleStatusQueryMock).
+		WithArgs("test_schedule_id", domain.PriceScheduleActive, sql.NullFloat64{Float64: 100, Valid: true}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	errSvc := svc.ApplyPriceSchedules(ctx, now)
+	assert.Nil(t, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplyPriceSchedulesEndKeepManualPrice(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	ctx := context.Background()
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	from := now.Add(-24 * time.Hour)
+	to := now.Add(-time.Minute)
+
+	scheduleRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows(priceScheduleColumns).
+			AddRow("test_schedule_id", "test_product_id", 80, from, to, domain.PriceScheduleActive, 100, from, now)
+	}
+
+	mock.ExpectQuery("SELECT (.+) FROM product_price_schedules WHERE \\(status").WillReturnRows(scheduleRow())
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM product_price_schedules WHERE id = \\$1").WillReturnRows(scheduleRow())
+	// price was changed by hand to 90 while the schedule was running
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1").WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version", "was_price"}).
+		AddRow("test_product_id", "test_store_id", "test_product_name", "test_product_url", 90, "test_product_description", nil, from, nil, 4, 100))
+	mock.ExpectExec("UPDATE products SET was_price = \\$3, updated_at").
+		WithArgs("test_product_id", 0, sql.NullFloat64{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE product_price_schedules SET status = \\$2").
+		WithArgs("test_schedule_id", domain.PriceScheduleDone, sql.NullFloat64{Float64: 100, Valid: true}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	errSvc := svc.ApplyPriceSchedules(ctx, now)
+	assert.Nil(t, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduledPriceChangesAtEffectiveFrom(t *testing.T) {
+	from := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	schedule := &repository.PriceSchedule{
+		ID:            "test_schedule_id",
+		ProductID:     "test_product_id",
+		Price:         80,
+		EffectiveFrom: from,
+		EffectiveTo:   sql.NullTime{Time: to, Valid: true},
+		Status:        domain.PriceSchedulePending,
+	}
+	store := &repository.Store{ID: "test_store_id"}
+	price := func(now time.Time) *domain.Product {
+		product := &repository.Product{ID: "test_product_id", StoreID: "test_store_id", Price: 100}
+		scheduledPrice(product, []*repository.PriceSchedule{schedule}, now)
+		return ProductRes(product, store)
+	}
+
+	res := price(from.Add(-time.Second))
+	assert.Equal(t, float32(100), res.Price)
+	assert.Nil(t, res.WasPrice)
+
+	// the price job has not run yet
+	res = price(from)
+	assert.Equal(t, float32(80), res.Price)
+	assert.Equal(t, float32(100), *res.WasPrice)
+
+	// the whole period passed before the price job ran
+	res = price(to)
+	assert.Equal(t, float32(100), res.Price)
+	assert.Nil(t, res.WasPrice)
+
+	// written by the price job, ended but not restored yet
+	schedule.Status = domain.PriceScheduleActive
+	schedule.PreviousPrice = sql.NullFloat64{Float64: 100, Valid: true}
+	product := &repository.Product{ID: "test_product_id", StoreID: "test_store_id", Price: 80, WasPrice: sql.NullFloat64{Float64: 100, Valid: true}}
+	scheduledPrice(product, []*repository.PriceSchedule{schedule}, to)
+	assert.Equal(t, float32(100), product.Price)
+	assert.False(t, product.WasPrice.Valid)
+}
+
+func TestGetProductByIdScheduledPrice(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	now := time.Now().UTC()
+	mock.ExpectQuery("SELECT (.+) FROM products WHERE id = \\$1 LIMIT 1").WithArgs("test_product_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version", "was_price"}).
+			AddRow("test_product_id", "test_store_id", "Kopi Susu", "kopi-susu", 100, "Es kopi susu", nil, now, nil, 1, nil))
+	mock.ExpectQuery("SELECT (.+) FROM product_price_schedules WHERE product_id = ANY\\(\\$1\\)").
+		WillReturnRows(sqlmock.NewRows(priceScheduleColumns).
+			AddRow("test_schedule_id", "test_product_id", 80, now.Add(-time.Minute), now.Add(time.Hour), "pending", nil, now, nil))
+	mock.ExpectQuery("SELECT (.+) FROM stores WHERE id = \\$1 LIMIT 1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version"}).AddRow("test_store_id", "Kopi Kenangan", 1))
+	mock.ExpectQuery("SELECT (.+) FROM promotions WHERE code IS NULL").WillReturnRows(sqlmock.NewRows(promotionColumns))
+
+	product, errSvc := svc.GetProductById(context.Background(), "test_product_id")
+	assert.Nil(t, errSvc)
+	assert.Equal(t, float32(80), product.Price)
+	assert.Equal(t, float32(100), *product.WasPrice)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
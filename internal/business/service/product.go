@@ -2,11 +2,13 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"github.com/google/uuid"
 	"github.com/ijlik/store-app/internal/adapter/repository"
 	"github.com/ijlik/store-app/internal/business/domain"
 	errpkg "github.com/ijlik/store-app/pkg/error"
 	httppagination "github.com/ijlik/store-app/pkg/http/pagination"
+	"github.com/ijlik/store-app/pkg/validation"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -53,12 +55,15 @@ func (s *service) ShowProducts(ctx context.Context, pagination *httppagination.P
 	g.Wait()
 
 	if err, ok := errAtomic.Load().(error); ok {
-		return errpkg.DefaultServiceError(errpkg.ErrInternal, err.Error())
+		return repository.TranslateError(err)
 	}
 
 	if products, ok := arrayAtomic.Load().([]*repository.Product); !ok {
 		return errpkg.DefaultServiceError(errpkg.ErrInternal, "")
 	} else {
+		if err := resolvePriceSchedules(ctx, s.repo, time.Now().UTC(), products...); err != nil {
+			return repository.TranslateError(err)
+		}
 		for _, product := range products {
 			store, err := s.repo.GetStoreById(ctx, product.StoreID)
 			if err == nil && store != nil {
@@ -67,131 +72,397 @@ func (s *service) ShowProducts(ctx context.Context, pagination *httppagination.P
 		}
 	}
 
+	if err := s.applySalePrices(ctx, result); err != nil {
+		return err
+	}
+
 	pagination.SetData(result, int64Atomic.Load())
 	return nil
 }
 
 func (s *service) CreateProduct(ctx context.Context, request *domain.ProductRequest) (*domain.Product, errpkg.ErrorService) {
-	product, err := s.repo.CreateProduct(ctx, &repository.Product{
-		ID:          uuid.New().String(),
-		Name:        request.Name,
-		Url:         request.Url,
-		Price:       request.Price,
-		StoreID:     request.StoreID,
-		Description: request.Description,
-		CreatedAt:   time.Now().UTC(),
+	var (
+		product *repository.Product
+		store   *repository.Store
+	)
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		var err error
+		store, err = repo.GetStoreById(ctx, request.StoreID)
+		if err != nil {
+			return err
+		}
+		if store == nil {
+			return errStoreIdNotFound()
+		}
+
+		url, err := allocateProductUrl(ctx, repo, request.Url, "")
+		if err != nil {
+			return err
+		}
+
+		product, err = repo.CreateProduct(ctx, &repository.Product{
+			ID:          uuid.New().String(),
+			Name:        request.Name,
+			Url:         url,
+			Price:       request.Price,
+			StoreID:     request.StoreID,
+			Description: request.Description,
+			Sku:         nullString(request.Sku),
+			Category:    nullString(request.Category),
+			Weight:      request.Weight,
+			CreatedAt:   time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := recordPrice(ctx, repo, product.ID, product.Price, sql.NullFloat64{}, ""); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repo, domain.AuditEntityProduct, product.ID, domain.AuditActionCreate, nil, productAuditFields(product))
 	})
 	if err != nil {
-		return nil, errpkg.DefaultServiceError(
-			errpkg.ErrInternal,
-			err.Error(),
-		)
+		return nil, repository.TranslateError(err)
 	}
 
-	store, err := s.repo.GetStoreById(ctx, request.StoreID)
+	return ProductRes(product, store), nil
+}
+
+// GetProductByUrl find product by its current url or a previous one, the
+// returned product carry the current url so the caller can redirect
+func (s *service) GetProductByUrl(ctx context.Context, url string) (*domain.Product, errpkg.ErrorService) {
+	product, err := s.repo.GetProductByUrl(ctx, url)
 	if err != nil {
-		return nil, errpkg.DefaultServiceError(
-			errpkg.ErrInternal,
-			err.Error(),
-		)
+		return nil, repository.TranslateError(err)
 	}
-	if store == nil {
-		return nil, errpkg.DefaultServiceError(
-			errpkg.ErrNotFound,
-			"store not found",
-		)
+
+	if product == nil {
+		history, err := s.repo.GetProductUrlHistory(ctx, url)
+		if err != nil {
+			return nil, repository.TranslateError(err)
+		}
+		if history == nil {
+			return nil, domain.ErrProductNotFound
+		}
+
+		product, err = s.repo.GetProductById(ctx, history.OwnerID)
+		if err != nil {
+			return nil, repository.TranslateError(err)
+		}
+		if product == nil {
+			return nil, domain.ErrProductNotFound
+		}
 	}
 
-	return ProductRes(product, store), nil
+	return s.productWithStore(ctx, product)
 }
 
-func (s *service) GetProductByUrl(ctx context.Context, url string) (*domain.Product, errpkg.ErrorService) {
-	product, err := s.repo.GetProductByUrl(ctx, url)
+func (s *service) GetProductById(ctx context.Context, id string) (*domain.Product, errpkg.ErrorService) {
+	product, err := s.repo.GetProductById(ctx, id)
 	if err != nil {
-		return nil, errpkg.DefaultServiceError(
-			errpkg.ErrInternal,
-			err.Error(),
-		)
+		return nil, repository.TranslateError(err)
 	}
 	if product == nil {
-		return nil, errpkg.DefaultServiceError(
-			errpkg.ErrNotFound,
-			"product not found",
-		)
+		return nil, domain.ErrProductNotFound
 	}
 
-	store, err := s.repo.GetStoreById(ctx, product.StoreID)
+	return s.productWithStore(ctx, product)
+}
+
+func (s *service) GetProductBySku(ctx context.Context, sku string) (*domain.Product, errpkg.ErrorService) {
+	product, err := s.repo.GetProductBySku(ctx, sku)
 	if err != nil {
-		return nil, errpkg.DefaultServiceError(
-			errpkg.ErrInternal,
-			err.Error(),
-		)
+		return nil, repository.TranslateError(err)
 	}
-	if store == nil {
-		return nil, errpkg.DefaultServiceError(
-			errpkg.ErrNotFound,
-			"store not found",
-		)
+	if product == nil {
+		return nil, domain.ErrProductNotFound
 	}
 
-	return ProductRes(product, store), nil
+	return s.productWithStore(ctx, product)
+}
+
+// GetProductsByIds return products in the order of the requested ids,
+// unknown ids are skipped
+func (s *service) GetProductsByIds(ctx context.Context, request *domain.ProductBatchGetRequest) ([]*domain.Product, errpkg.ErrorService) {
+	products, err := s.repo.ListProductByIds(ctx, request.IDs)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if err := resolvePriceSchedules(ctx, s.repo, time.Now().UTC(), products...); err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	var (
+		storeIds   []string
+		byId       = make(map[string]*repository.Product, len(products))
+		seenStores = make(map[string]bool)
+	)
+	for _, product := range products {
+		byId[product.ID] = product
+		if !seenStores[product.StoreID] {
+			seenStores[product.StoreID] = true
+			storeIds = append(storeIds, product.StoreID)
+		}
+	}
+
+	stores := make(map[string]*repository.Store, len(storeIds))
+	if len(storeIds) > 0 {
+		list, err := s.repo.ListStoreByIds(ctx, storeIds)
+		if err != nil {
+			return nil, repository.TranslateError(err)
+		}
+		for _, store := range list {
+			stores[store.ID] = store
+		}
+	}
+
+	result := []*domain.Product{}
+	for _, id := range request.IDs {
+		product, ok := byId[id]
+		if !ok {
+			continue
+		}
+		store, ok := stores[product.StoreID]
+		if !ok {
+			continue
+		}
+		result = append(result, ProductRes(product, store))
+	}
+
+	if err := s.applySalePrices(ctx, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
 func (s *service) UpdateProduct(ctx context.Context, request *domain.ProductRequest, id string) errpkg.ErrorService {
-	product, err := s.repo.GetProductById(ctx, id)
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		product, err := repo.GetProductById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return domain.ErrProductNotFound
+		}
+		if err := domain.CheckVersion(request.Version, product.Version); err != nil {
+			return err
+		}
+
+		if request.StoreID != product.StoreID {
+			store, err := repo.GetStoreById(ctx, request.StoreID)
+			if err != nil {
+				return err
+			}
+			if store == nil {
+				return errStoreIdNotFound()
+			}
+		}
+
+		url, err := renameProductUrl(ctx, repo, product, request.Name, request.Url)
+		if err != nil {
+			return err
+		}
+
+		updated := &repository.Product{
+			ID:          product.ID,
+			Name:        request.Name,
+			Url:         url,
+			Price:       request.Price,
+			StoreID:     request.StoreID,
+			Description: request.Description,
+			Sku:         nullString(request.Sku),
+			Category:    nullString(request.Category),
+			Weight:      request.Weight,
+			Version:     request.Version,
+		}
+		if err := repo.UpdateProduct(ctx, updated); err != nil {
+			return err
+		}
+		if updated.Price != product.Price {
+			if err := recordPrice(ctx, repo, id, updated.Price, priceOf(product.Price), ""); err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(ctx, repo, domain.AuditEntityProduct, id, domain.AuditActionUpdate, productAuditFields(product), productAuditFields(updated))
+	})
 	if err != nil {
-		return errpkg.DefaultServiceError(
-			errpkg.ErrInternal,
-			err.Error(),
-		)
+		return repository.TranslateError(err)
 	}
-	if product == nil {
-		return errpkg.DefaultServiceError(
-			errpkg.ErrNotFound,
-			"product not found",
-		)
-	}
-
-	err = s.repo.UpdateProduct(ctx, &repository.Product{
-		ID:          product.ID,
-		Name:        request.Name,
-		Url:         product.Url,
-		Price:       request.Price,
-		StoreID:     request.StoreID,
-		Description: request.Description,
+
+	return nil
+}
+
+// PatchProduct apply a merge patch, only the changed columns are updated
+func (s *service) PatchProduct(ctx context.Context, patch *domain.ProductPatch, id string) errpkg.ErrorService {
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		product, err := repo.GetProductById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return domain.ErrProductNotFound
+		}
+		if err := domain.CheckVersion(patch.Version, product.Version); err != nil {
+			return err
+		}
+
+		var columns []repository.Column
+		if patch.StoreID != nil && *patch.StoreID != product.StoreID {
+			store, err := repo.GetStoreById(ctx, *patch.StoreID)
+			if err != nil {
+				return err
+			}
+			if store == nil {
+				return errStoreIdNotFound()
+			}
+
+			columns = append(columns, repository.Column{Name: "store_id", Value: *patch.StoreID})
+		}
+		if patch.Name != nil {
+			url, err := renameProductUrl(ctx, repo, product, *patch.Name, *patch.Url)
+			if err != nil {
+				return err
+			}
+
+			columns = append(columns, repository.Column{Name: "name", Value: *patch.Name})
+			if url != product.Url {
+				columns = append(columns, repository.Column{Name: "url", Value: url})
+			}
+		}
+		if patch.Price != nil {
+			columns = append(columns, repository.Column{Name: "price", Value: *patch.Price})
+		}
+		if patch.Description != nil {
+			columns = append(columns, repository.Column{Name: "description", Value: *patch.Description})
+		}
+		if patch.Sku != nil {
+			columns = append(columns, repository.Column{Name: "sku", Value: nullString(*patch.Sku)})
+		}
+		if patch.Category != nil {
+			columns = append(columns, repository.Column{Name: "category", Value: nullString(*patch.Category)})
+		}
+		if patch.Weight != nil {
+			columns = append(columns, repository.Column{Name: "weight", Value: *patch.Weight})
+		}
+		if len(columns) == 0 {
+			return nil
+		}
+
+		if err := repo.PatchProduct(ctx, id, patch.Version, columns); err != nil {
+			return err
+		}
+		if patch.Price != nil && *patch.Price != product.Price {
+			if err := recordPrice(ctx, repo, id, *patch.Price, priceOf(product.Price), ""); err != nil {
+				return err
+			}
+		}
+
+		before := productAuditFields(product)
+		return recordAudit(ctx, repo, domain.AuditEntityProduct, id, domain.AuditActionUpdate, before, patchedAuditFields(before, columns))
 	})
 	if err != nil {
-		return errpkg.DefaultServiceError(
-			errpkg.ErrInternal,
-			err.Error(),
-		)
+		return repository.TranslateError(err)
 	}
 
 	return nil
 }
 
-func (s *service) DeleteProduct(ctx context.Context, id string) errpkg.ErrorService {
-	product, err := s.repo.GetProductById(ctx, id)
+func (s *service) DeleteProduct(ctx context.Context, id string, version int) errpkg.ErrorService {
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		product, err := repo.GetProductById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return domain.ErrProductNotFound
+		}
+		if err := domain.CheckVersion(version, product.Version); err != nil {
+			return err
+		}
+
+		if err := repo.DeleteProduct(ctx, id); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repo, domain.AuditEntityProduct, id, domain.AuditActionDelete, productAuditFields(product), nil)
+	})
 	if err != nil {
-		return errpkg.DefaultServiceError(
-			errpkg.ErrInternal,
-			err.Error(),
-		)
+		return repository.TranslateError(err)
 	}
-	if product == nil {
-		return errpkg.DefaultServiceError(
-			errpkg.ErrNotFound,
-			"product not found",
-		)
+
+	return nil
+}
+
+// productWithStore render a product read from the repository with its store,
+// scheduled and sale prices
+func (s *service) productWithStore(ctx context.Context, product *repository.Product) (*domain.Product, errpkg.ErrorService) {
+	if err := resolvePriceSchedules(ctx, s.repo, time.Now().UTC(), product); err != nil {
+		return nil, repository.TranslateError(err)
 	}
 
-	err = s.repo.DeleteProduct(ctx, id)
+	store, err := s.repo.GetStoreById(ctx, product.StoreID)
 	if err != nil {
-		return errpkg.DefaultServiceError(
-			errpkg.ErrInternal,
-			err.Error(),
-		)
+		return nil, repository.TranslateError(err)
+	}
+	if store == nil {
+		return nil, domain.ErrStoreNotFound
 	}
 
-	return nil
+	result := ProductRes(product, store)
+	if err := s.applySalePrices(ctx, []*domain.Product{result}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// empty string is stored as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// validation error of product request referring to a missing store
+func errStoreIdNotFound() errpkg.ErrorService {
+	return validation.New().
+		Check(false, "store_id", validation.ReasonNotFound, "store id not found", nil).
+		Error()
+}
+
+// allocate free url from base, urls owned by productId are not counted as taken
+func allocateProductUrl(ctx context.Context, repo repository.StoreRepository, base string, productId string) (string, error) {
+	slugs, err := repo.ListProductUrls(ctx, base, domain.SlugPattern(base))
+	if err != nil {
+		return "", err
+	}
+
+	return freeUrl(slugs, base, productId), nil
+}
+
+// renameProductUrl keep the url while the name is unchanged or the url is
+// still derived from base, otherwise allocate a new url and keep the current
+// one on the history for redirect. Name without usable letter get a random
+// base on every call and keep its url too.
+func renameProductUrl(ctx context.Context, repo repository.StoreRepository, product *repository.Product, name string, base string) (string, error) {
+	if product.Name == name || domain.RandomSlug(name) || domain.SlugOf(product.Url, base) {
+		return product.Url, nil
+	}
+
+	url, err := allocateProductUrl(ctx, repo, base, product.ID)
+	if err != nil {
+		return "", err
+	}
+
+	// url may come back from the history of this product
+	if err := repo.DeleteProductUrlHistory(ctx, product.ID, url); err != nil {
+		return "", err
+	}
+	if err := repo.CreateProductUrlHistory(ctx, product.ID, product.Url); err != nil {
+		return "", err
+	}
+
+	return url, nil
 }
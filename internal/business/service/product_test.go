@@ -6,6 +6,8 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/ijlik/store-app/internal/adapter/repository"
 	"github.com/ijlik/store-app/internal/business/domain"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	errpkg "github.com/ijlik/store-app/pkg/error"
 	httppagination "github.com/ijlik/store-app/pkg/http/pagination"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
@@ -39,9 +41,9 @@ func TestShowProducts(t *testing.T) {
 			},
 		},
 	}
-	listProductsQueryMock := "SELECT id, store_id, name, url, price, description, created_at, updated_at FROM products"
-	mock.ExpectQuery(listProductsQueryMock).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "created_at", "updated_at"}).
-		AddRow(expectedProductData[0].ID, expectedProductData[0].StoreID, expectedProductData[0].Name, expectedProductData[0].Url, expectedProductData[0].Price, expectedProductData[0].Description, expectedProductData[0].CreatedAt, expectedProductData[0].UpdatedAt))
+	listProductsQueryMock := "SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products"
+	mock.ExpectQuery(listProductsQueryMock).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version"}).
+		AddRow(expectedProductData[0].ID, expectedProductData[0].StoreID, expectedProductData[0].Name, expectedProductData[0].Url, expectedProductData[0].Price, expectedProductData[0].Description, expectedProductData[0].Sku, expectedProductData[0].CreatedAt, expectedProductData[0].UpdatedAt, expectedProductData[0].Version))
 
 	expectedCount := int64(10)
 	countProductsQueryMock := "SELECT count\\(\\*\\) FROM products"
@@ -62,9 +64,9 @@ func TestShowProducts(t *testing.T) {
 		},
 	}
 
-	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at FROM stores WHERE id = \\$1 LIMIT 1"
-	rows := sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at"}).
-		AddRow(expectedStoreData.ID, expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd, expectedStoreData.CreatedAt, nil)
+	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at, version, tax_region, latitude, longitude, address_street, address_district, address_city, address_province, address_postal_code, address_country FROM stores WHERE id = \\$1 LIMIT 1"
+	rows := sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at", "version"}).
+		AddRow(expectedStoreData.ID, expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd, expectedStoreData.CreatedAt, nil, expectedStoreData.Version)
 	mock.ExpectQuery(getStoreByIdQueryMock).WithArgs(expectedStoreData.ID).WillReturnRows(rows)
 
 	pagination := httppagination.Pagination{
@@ -120,9 +122,9 @@ func TestCreateProduct(t *testing.T) {
 		},
 	}
 
-	createProductQueryMock := "INSERT INTO products \\(store_id, name, url, price, description, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, CURRENT_TIMESTAMP\\) RETURNING id"
+	createProductQueryMock := "INSERT INTO products \\(store_id, name, url, price, description, sku, category, weight, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8, CURRENT_TIMESTAMP\\) RETURNING id"
 	mock.ExpectQuery(createProductQueryMock).
-		WithArgs(expectedProductData.StoreID, expectedProductData.Name, expectedProductData.Url, expectedProductData.Price, expectedProductData.Description).
+		WithArgs(expectedProductData.StoreID, expectedProductData.Name, expectedProductData.Url, expectedProductData.Price, expectedProductData.Description, expectedProductData.Sku, expectedProductData.Category, expectedProductData.Weight).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(expectedProductData.ID))
 
 	expectedStoreData := &repository.Store{
@@ -140,9 +142,9 @@ func TestCreateProduct(t *testing.T) {
 		},
 	}
 
-	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at FROM stores WHERE id = \\$1 LIMIT 1"
-	rows := sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at"}).
-		AddRow(expectedStoreData.ID, expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd, expectedStoreData.CreatedAt, nil)
+	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at, version, tax_region, latitude, longitude, address_street, address_district, address_city, address_province, address_postal_code, address_country FROM stores WHERE id = \\$1 LIMIT 1"
+	rows := sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at", "version"}).
+		AddRow(expectedStoreData.ID, expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd, expectedStoreData.CreatedAt, nil, expectedStoreData.Version)
 	mock.ExpectQuery(getStoreByIdQueryMock).WithArgs(expectedStoreData.ID).WillReturnRows(rows)
 
 	mockProductService.Mock.On("MockCreateProduct", request).Return(ProductRes(expectedProductData, expectedStoreData), nil)
@@ -191,9 +193,9 @@ func TestGetProductByUrl(t *testing.T) {
 		},
 	}
 
-	getProductByUrlQueryMock := "SELECT id, store_id, name, url, price, description, created_at, updated_at FROM products WHERE url = \\$1 LIMIT 1"
-	mock.ExpectQuery(getProductByUrlQueryMock).WithArgs(expectedProductData.Url).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "created_at", "updated_at"}).
-		AddRow(expectedProductData.ID, expectedProductData.StoreID, expectedProductData.Name, expectedProductData.Url, expectedProductData.Price, expectedProductData.Description, expectedProductData.CreatedAt, expectedProductData.UpdatedAt))
+	getProductByUrlQueryMock := "SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products WHERE url = \\$1 LIMIT 1"
+	mock.ExpectQuery(getProductByUrlQueryMock).WithArgs(expectedProductData.Url).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version"}).
+		AddRow(expectedProductData.ID, expectedProductData.StoreID, expectedProductData.Name, expectedProductData.Url, expectedProductData.Price, expectedProductData.Description, expectedProductData.Sku, expectedProductData.CreatedAt, expectedProductData.UpdatedAt, expectedProductData.Version))
 
 	expectedStoreData := &repository.Store{
 		ID:                   "test_store_id",
@@ -210,9 +212,9 @@ func TestGetProductByUrl(t *testing.T) {
 		},
 	}
 
-	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at FROM stores WHERE id = \\$1 LIMIT 1"
-	rows := sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at"}).
-		AddRow(expectedStoreData.ID, expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd, expectedStoreData.CreatedAt, nil)
+	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at, version, tax_region, latitude, longitude, address_street, address_district, address_city, address_province, address_postal_code, address_country FROM stores WHERE id = \\$1 LIMIT 1"
+	rows := sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at", "version"}).
+		AddRow(expectedStoreData.ID, expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd, expectedStoreData.CreatedAt, nil, expectedStoreData.Version)
 	mock.ExpectQuery(getStoreByIdQueryMock).WithArgs(expectedStoreData.ID).WillReturnRows(rows)
 
 	mockProductService.Mock.On("MockGetProductByUrl", url).Return(ProductRes(expectedProductData, expectedStoreData), nil)
@@ -268,9 +270,9 @@ func TestUpdateProduct(t *testing.T) {
 		},
 	}
 
-	updateProductByIdQueryMock := "UPDATE products SET store_id = \\$2, name = \\$3, url = \\$4, price = \\$5, description = \\$6, updated_at = CURRENT_TIMESTAMP WHERE id = \\$1"
+	updateProductByIdQueryMock := "UPDATE products SET store_id = \\$2, name = \\$3, url = \\$4, price = \\$5, description = \\$6, sku = \\$7, category = \\$8, weight = \\$9, updated_at = CURRENT_TIMESTAMP, version = version \\+ 1 WHERE id = \\$1 AND \\(\\$10 = 0 OR version = \\$10\\)"
 	mock.ExpectExec(updateProductByIdQueryMock).
-		WithArgs(expectedProductData.ID, expectedProductData.StoreID, expectedProductData.Name, expectedProductData.Url, expectedProductData.Price, expectedProductData.Description).
+		WithArgs(expectedProductData.ID, expectedProductData.StoreID, expectedProductData.Name, expectedProductData.Url, expectedProductData.Price, expectedProductData.Description, expectedProductData.Sku, expectedProductData.Category, expectedProductData.Weight, expectedProductData.Version).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	mockProductService.Mock.On("MockUpdateProduct", request, productId).Return(nil)
@@ -300,3 +302,83 @@ func TestDeleteProduct(t *testing.T) {
 	err = svc.productService.MockDeleteProduct(ctx, productId)
 	assert.NoError(t, err)
 }
+
+func TestCreateProductStoreNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	ctx := context.Background()
+	request := &domain.ProductRequest{
+		StoreID:     "test_store_id",
+		Name:        "test_product_name",
+		Url:         "test_product_url",
+		Price:       100,
+		Description: "test_product_description",
+	}
+
+	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at, version, tax_region, latitude, longitude, address_street, address_district, address_city, address_province, address_postal_code, address_country FROM stores WHERE id = \\$1 LIMIT 1"
+	mock.ExpectBegin()
+	mock.ExpectQuery(getStoreByIdQueryMock).WithArgs(request.StoreID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	product, errSvc := svc.CreateProduct(ctx, request)
+	assert.Nil(t, product)
+	assert.Equal(t, errpkg.ErrBadRequest, errSvc.GetCode())
+	assert.Equal(t, "store_id", errSvc.GetFields()[0].Field)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteProductVersionMismatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	ctx := context.Background()
+	getProductByIdQueryMock := "SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products WHERE id = \\$1 LIMIT 1"
+	mock.ExpectBegin()
+	mock.ExpectQuery(getProductByIdQueryMock).WithArgs("test_product_id").WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version"}).
+		AddRow("test_product_id", "test_store_id", "test_product_name", "test_product_url", 100, "test_product_description", nil, time.Now(), nil, 3))
+	mock.ExpectRollback()
+
+	errSvc := svc.DeleteProduct(ctx, "test_product_id", 2)
+	assert.Equal(t, errpkg.ErrPreconditionFailed, errSvc.GetCode())
+	assert.Equal(t, "VERSION_MISMATCH", errSvc.GetReason())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteProductAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	ctx := pkgcontext.SetContext(context.Background(), map[pkgcontext.ContextMetadata]any{
+		pkgcontext.USER_ID:    "test_user_id",
+		pkgcontext.REQUEST_ID: "test_request_id",
+	})
+	getProductByIdQueryMock := "SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products WHERE id = \\$1 LIMIT 1"
+	deleteProductQueryMock := "DELETE FROM products WHERE id = \\$1"
+	createAuditLogQueryMock := "INSERT INTO audit_logs"
+	changes := `{"category":{"from":null,"to":null},"description":{"from":"test_product_description","to":null},"name":{"from":"test_product_name","to":null},"price":{"from":100,"to":null},"sku":{"from":null,"to":null},"store_id":{"from":"test_store_id","to":null},"url":{"from":"test_product_url","to":null},"weight":{"from":0,"to":null}}`
+	mock.ExpectBegin()
+	mock.ExpectQuery(getProductByIdQueryMock).WithArgs("test_product_id").WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version"}).
+		AddRow("test_product_id", "test_store_id", "test_product_name", "test_product_url", 100, "test_product_description", nil, time.Now(), nil, 3))
+	mock.ExpectExec(deleteProductQueryMock).WithArgs("test_product_id").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(createAuditLogQueryMock).
+		WithArgs("product", "test_product_id", "delete", sql.NullString{String: "test_user_id", Valid: true}, sql.NullString{String: "test_request_id", Valid: true}, []byte(changes)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	errSvc := svc.DeleteProduct(ctx, "test_product_id", 3)
+	assert.Nil(t, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppagination "github.com/ijlik/store-app/pkg/http/pagination"
+	"github.com/ijlik/store-app/pkg/validation"
+)
+
+func (s *service) CreatePromotion(ctx context.Context, request *domain.PromotionRequest) (*domain.Promotion, errpkg.ErrorService) {
+	switch request.Scope {
+	case domain.PromotionScopeStore:
+		store, err := s.repo.GetStoreById(ctx, request.ScopeID)
+		if err != nil {
+			return nil, repository.TranslateError(err)
+		}
+		if store == nil {
+			return nil, errScopeIdNotFound()
+		}
+	case domain.PromotionScopeProduct:
+		product, err := s.repo.GetProductById(ctx, request.ScopeID)
+		if err != nil {
+			return nil, repository.TranslateError(err)
+		}
+		if product == nil {
+			return nil, errScopeIdNotFound()
+		}
+	}
+
+	promotion := &repository.Promotion{
+		ID:                uuid.New().String(),
+		Name:              request.Name,
+		Code:              nullString(request.Code),
+		DiscountType:      request.DiscountType,
+		DiscountValue:     request.DiscountValue,
+		Scope:             request.Scope,
+		ScopeID:           request.ScopeID,
+		StartsAt:          request.StartsAt,
+		UsageLimit:        request.UsageLimit,
+		UsageLimitPerUser: request.UsageLimitPerUser,
+		Stackable:         request.Stackable,
+		Priority:          request.Priority,
+		Active:            true,
+		CreatedAt:         time.Now().UTC(),
+	}
+	if request.EndsAt != nil {
+		promotion.EndsAt = sql.NullTime{Time: *request.EndsAt, Valid: true}
+	}
+
+	if err := s.repo.CreatePromotion(ctx, promotion); err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return PromotionRes(promotion), nil
+}
+
+func (s *service) GetPromotionById(ctx context.Context, id string) (*domain.Promotion, errpkg.ErrorService) {
+	promotion, err := s.repo.GetPromotionById(ctx, id)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if promotion == nil {
+		return nil, domain.ErrPromotionNotFound
+	}
+
+	return PromotionRes(promotion), nil
+}
+
+func (s *service) ShowPromotions(ctx context.Context, pagination *httppagination.Pagination) errpkg.ErrorService {
+	var (
+		g           sync.WaitGroup
+		int64Atomic atomic.Int64
+		arrayAtomic atomic.Value
+		errAtomic   atomic.Value
+		result      = []*domain.Promotion{}
+	)
+
+	g.Add(1)
+	go func() {
+		defer g.Done()
+		promotions, err := s.repo.ListPromotions(ctx, pagination.Limit, pagination.Offset)
+		if err != nil {
+			errAtomic.Store(err)
+		} else {
+			arrayAtomic.Store(promotions)
+		}
+	}()
+
+	g.Add(1)
+	go func() {
+		defer g.Done()
+		count, err := s.repo.CountPromotions(ctx)
+		if err != nil {
+			errAtomic.Store(err)
+		} else {
+			int64Atomic.Store(count)
+		}
+	}()
+	g.Wait()
+
+	if err, ok := errAtomic.Load().(error); ok {
+		return repository.TranslateError(err)
+	}
+
+	if promotions, ok := arrayAtomic.Load().([]*repository.Promotion); !ok {
+		return errpkg.DefaultServiceError(errpkg.ErrInternal, "")
+	} else {
+		for _, promotion := range promotions {
+			result = append(result, PromotionRes(promotion))
+		}
+	}
+
+	pagination.SetData(result, int64Atomic.Load())
+	return nil
+}
+
+// DeactivatePromotion stop a promotion, it is kept for its redemptions
+func (s *service) DeactivatePromotion(ctx context.Context, id string) errpkg.ErrorService {
+	promotion, err := s.repo.GetPromotionById(ctx, id)
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+	if promotion == nil {
+		return domain.ErrPromotionNotFound
+	}
+
+	if err := s.repo.DeactivatePromotion(ctx, id); err != nil {
+		return repository.TranslateError(err)
+	}
+
+	return nil
+}
+
+// ValidateCoupon evaluate the items with the coupon and the promotions
+// running without coupon. The limit per user is only checked for a signed in
+// user, the order checks it again when the coupon is redeemed.
+func (s *service) ValidateCoupon(ctx context.Context, request *domain.CouponValidateRequest) (*domain.CouponValidation, errpkg.ErrorService) {
+	now := time.Now().UTC()
+
+	promotion, err := availableCoupon(ctx, s.repo, request.Code, pkgcontext.GetString(ctx, pkgcontext.USER_ID), now)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	lines, errSvc := s.basketLines(ctx, request.Items)
+	if errSvc != nil {
+		return nil, errSvc
+	}
+
+	promotions, err := automaticPromotions(ctx, s.repo, lines, now)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	evaluation := domain.EvaluateBasket(lines, append(promotions, promotion), now)
+	if !evaluation.Applied(promotion.ID) {
+		return nil, domain.ErrCouponNotApplicable
+	}
+
+	return &domain.CouponValidation{
+		Promotion:  promotion,
+		Evaluation: evaluation,
+	}, nil
+}
+
+// load the coupon with code usable by the user at now, the limit per user
+// is only checked for a signed in user
+func availableCoupon(ctx context.Context, repo repository.StoreRepository, code string, userId string, now time.Time) (*domain.Promotion, error) {
+	coupon, err := repo.GetPromotionByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if coupon == nil || !coupon.Active {
+		return nil, domain.ErrCouponNotFound
+	}
+
+	promotion := PromotionRes(coupon)
+	if err := loadUserRedemptions(ctx, repo, []*domain.Promotion{promotion}, userId); err != nil {
+		return nil, err
+	}
+	if err := couponAvailable(promotion, now); err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+// set the redemptions of the user on the promotions limited per user
+func loadUserRedemptions(ctx context.Context, repo repository.StoreRepository, promotions []*domain.Promotion, userId string) error {
+	if userId == "" {
+		return nil
+	}
+
+	for _, promotion := range promotions {
+		if promotion.UsageLimitPerUser == 0 {
+			continue
+		}
+		used, err := repo.CountPromotionRedemptionsByUser(ctx, promotion.ID, userId)
+		if err != nil {
+			return err
+		}
+		promotion.UsedByUser = int(used)
+	}
+
+	return nil
+}
+
+// tell why the coupon can not be used at now
+func couponAvailable(promotion *domain.Promotion, now time.Time) errpkg.ErrorService {
+	if now.Before(promotion.StartsAt) || (promotion.EndsAt != nil && !now.Before(*promotion.EndsAt)) {
+		return domain.ErrCouponExpired
+	}
+	if !promotion.Available(now) {
+		return domain.ErrCouponUsageExceeded
+	}
+
+	return nil
+}
+
+// basketLines load the product of every item, unknown product is a
+// validation error of the item
+func (s *service) basketLines(ctx context.Context, items []domain.BasketItem) ([]domain.BasketLine, errpkg.ErrorService) {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	products, err := s.repo.ListProductByIds(ctx, ids)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if err := resolvePriceSchedules(ctx, s.repo, time.Now().UTC(), products...); err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	byId := make(map[string]*repository.Product, len(products))
+	for _, product := range products {
+		byId[product.ID] = product
+	}
+
+	var (
+		lines = make([]domain.BasketLine, 0, len(items))
+		v     = validation.New()
+	)
+	for i, item := range items {
+		product, ok := byId[item.ProductID]
+		v.Check(ok, fmt.Sprintf("items[%d].product_id", i), validation.ReasonNotFound, "product not found", nil)
+		if !ok {
+			continue
+		}
+		lines = append(lines, basketLine(product, item.Quantity))
+	}
+	if err := v.Error(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+func basketLine(product *repository.Product, quantity int) domain.BasketLine {
+	return domain.BasketLine{
+		ProductID: product.ID,
+		StoreID:   product.StoreID,
+		Category:  product.Category.String,
+		Price:     product.Price,
+		Quantity:  quantity,
+		Weight:    product.Weight,
+	}
+}
+
+// automaticPromotions load the promotions without coupon which may apply to
+// the lines at now
+func automaticPromotions(ctx context.Context, repo repository.StoreRepository, lines []domain.BasketLine, now time.Time) ([]*domain.Promotion, error) {
+	var (
+		scope = &repository.PromotionScope{}
+		seen  = make(map[string]bool)
+	)
+	for _, line := range lines {
+		if !seen["s:"+line.StoreID] {
+			seen["s:"+line.StoreID] = true
+			scope.StoreIDs = append(scope.StoreIDs, line.StoreID)
+		}
+		if line.Category != "" && !seen["c:"+line.Category] {
+			seen["c:"+line.Category] = true
+			scope.Categories = append(scope.Categories, line.Category)
+		}
+		scope.ProductIDs = append(scope.ProductIDs, line.ProductID)
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	promotions, err := repo.ListAutomaticPromotions(ctx, now, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.Promotion, 0, len(promotions))
+	for _, promotion := range promotions {
+		result = append(result, PromotionRes(promotion))
+	}
+
+	return result, nil
+}
+
+// applySalePrices set the sale price of the products discounted by a
+// promotion running without coupon
+func (s *service) applySalePrices(ctx context.Context, products []*domain.Product) errpkg.ErrorService {
+	now := time.Now().UTC()
+
+	lines := make([]domain.BasketLine, 0, len(products))
+	for _, product := range products {
+		lines = append(lines, productLine(product))
+	}
+
+	promotions, err := automaticPromotions(ctx, s.repo, lines, now)
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+	if len(promotions) == 0 {
+		return nil
+	}
+
+	for i, product := range products {
+		if price, ok := domain.SalePrice(lines[i], promotions, now); ok {
+			product.SalePrice = &price
+		}
+	}
+
+	return nil
+}
+
+func productLine(product *domain.Product) domain.BasketLine {
+	line := domain.BasketLine{
+		ProductID: product.ID,
+		Category:  product.Category,
+		Price:     product.Price,
+		Quantity:  1,
+	}
+	if product.Store != nil {
+		line.StoreID = product.Store.ID
+	}
+
+	return line
+}
+
+// validation error of promotion request referring to a missing store or
+// product
+func errScopeIdNotFound() errpkg.ErrorService {
+	return validation.New().
+		Check(false, "scope_id", validation.ReasonNotFound, "scope id not found", nil).
+		Error()
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var promotionColumns = []string{"id", "name", "code", "discount_type", "discount_value", "scope", "scope_id", "starts_at", "ends_at", "usage_limit", "usage_limit_per_user", "used", "stackable", "priority", "active", "created_at", "updated_at"}
+
+func TestValidateCoupon(t *testing.T) {
+	starts := time.Now().UTC().Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		couponScopeId string
+		ends          any
+		used          int
+		expectedErr   error
+	}{
+		{"applied with automatic promotion", "test_store_id", nil, 0, nil},
+		{"not applicable", "other_store_id", nil, 0, domain.ErrCouponNotApplicable},
+		{"expired", "test_store_id", starts.Add(time.Minute), 0, domain.ErrCouponExpired},
+		{"usage exceeded", "test_store_id", nil, 10, domain.ErrCouponUsageExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			dbx := sqlx.NewDb(db, "postgres")
+			svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+			getPromotionByCodeQueryMock := "SELECT (.+) FROM promotions WHERE code = \\$1 LIMIT 1"
+			listProductByIdsQueryMock := "SELECT (.+) FROM products WHERE id = ANY\\(\\$1\\)"
+			listAutomaticPromotionsQueryMock := "SELECT (.+) FROM promotions WHERE code IS NULL"
+
+			mock.ExpectQuery(getPromotionByCodeQueryMock).WithArgs("HEMAT10").
+				WillReturnRows(sqlmock.NewRows(promotionColumns).
+					AddRow("test_coupon_id", "Hemat", "HEMAT10", domain.DiscountFixed, 1000, domain.PromotionScopeStore, tt.couponScopeId, starts, tt.ends, 10, 0, tt.used, true, 0, true, starts, nil))
+			if tt.expectedErr == nil || tt.expectedErr == domain.ErrCouponNotApplicable {
+				mock.ExpectQuery(listProductByIdsQueryMock).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version", "was_price", "category"}).
+						AddRow("0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11", "test_store_id", "Kopi Susu", "kopi-susu", 18000, "Es kopi susu", nil, starts, nil, 1, nil, "coffee"))
+				mock.ExpectQuery("SELECT (.+) FROM product_price_schedules WHERE product_id = ANY\\(\\$1\\)").WillReturnRows(sqlmock.NewRows(priceScheduleColumns))
+				mock.ExpectQuery(listAutomaticPromotionsQueryMock).
+					WillReturnRows(sqlmock.NewRows(promotionColumns).
+						AddRow("test_promotion_id", "Weekend", nil, domain.DiscountPercentage, 5, domain.PromotionScopeCategory, "coffee", starts, nil, 0, 0, 0, true, 1, true, starts, nil))
+			}
+
+			request := &domain.CouponValidateRequest{
+				Code:  "HEMAT10",
+				Items: []domain.BasketItem{{ProductID: "0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11", Quantity: 2}},
+			}
+			result, errSvc := svc.ValidateCoupon(context.Background(), request)
+			assert.NoError(t, mock.ExpectationsWereMet())
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, errSvc)
+				return
+			}
+
+			assert.Nil(t, errSvc)
+			// 5% of 18000 then 1000 off the remaining 17100, per unit
+			assert.Equal(t, float32(16100), result.Evaluation.Lines[0].SalePrice)
+			assert.Equal(t, float32(32200), result.Evaluation.Total)
+			assert.True(t, result.Evaluation.Applied("test_coupon_id"))
+			assert.True(t, result.Evaluation.Applied("test_promotion_id"))
+		})
+	}
+}
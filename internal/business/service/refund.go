@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+)
+
+// RefundOrder give back the money of the whole order or of some lines. The
+// amount is reserved on the payment first so concurrent refunds never exceed
+// the captured amount, then the provider refund it. A refund the provider
+// reject is given back to the payment and recorded as failed. A payment
+// captured for an order that was never paid is given back whole, its order
+// and stock are left as they are.
+func (s *service) RefundOrder(ctx context.Context, storeId string, orderId string, request *domain.RefundRequest) (*domain.Refund, errpkg.ErrorService) {
+	if s.gateway == nil {
+		return nil, domain.ErrPaymentUnavailable
+	}
+
+	var (
+		refund *repository.Refund
+		lines  []*repository.RefundItem
+		pay    *repository.Payment
+		// product and quantity put back on the stock
+		restock map[string]int
+		unpaid  bool
+	)
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		lines, restock, unpaid = nil, make(map[string]int), false
+
+		order, err := repo.GetOrderById(ctx, orderId)
+		if err != nil {
+			return err
+		}
+		if order == nil || order.StoreID != storeId {
+			return domain.ErrOrderNotFound
+		}
+		if !domain.OrderRefundable(order.Status) && !domain.OrderUnpaid(order.Status) {
+			return domain.ErrOrderNotRefundable
+		}
+
+		if pay, err = capturedPayment(ctx, repo, order.ID); err != nil {
+			return err
+		}
+		if pay == nil {
+			return domain.ErrOrderNotRefundable
+		}
+
+		if domain.OrderUnpaid(order.Status) {
+			if len(request.Items) > 0 {
+				return domain.ErrOrderNotRefundable
+			}
+			unpaid = true
+			if refund, err = reserveUnpaidRefund(ctx, repo, pay, request.Reason); err != nil {
+				return err
+			}
+			if refund == nil {
+				return domain.ErrOrderNotRefundable
+			}
+			return nil
+		}
+
+		items, err := repo.ListOrderItems(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		refund = &repository.Refund{
+			ID:        uuid.New().String(),
+			OrderID:   order.ID,
+			PaymentID: pay.ID,
+			Reason:    request.Reason,
+			Restock:   request.Restock,
+			Status:    domain.RefundPending,
+			CreatedAt: time.Now().UTC(),
+		}
+
+		requested, err := refundQuantities(items, request.Items)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			quantity := requested[item.ID]
+			if quantity == 0 {
+				continue
+			}
+			line := &repository.RefundItem{
+				RefundID:    refund.ID,
+				OrderItemID: item.ID,
+				Quantity:    quantity,
+				Amount:      domain.RefundLineAmount(item.Total+item.TaxAdded, item.Quantity, item.RefundedQuantity, item.RefundedAmount, quantity),
+			}
+			refund.Amount += line.Amount
+			lines = append(lines, line)
+			restock[item.ProductID] += quantity
+		}
+		// a full refund take what is left on the payment so no cent stays behind
+		if len(request.Items) == 0 {
+			refund.Amount = pay.CapturedAmount - pay.RefundedAmount
+		}
+		refund.Amount = domain.RoundPrice(refund.Amount)
+		if refund.Amount <= 0 {
+			return domain.ErrOrderNotRefundable
+		}
+
+		reserved, err := repo.AddPaymentRefund(ctx, pay.ID, refund.Amount)
+		if err != nil {
+			return err
+		}
+		if !reserved {
+			return domain.ErrRefundExceedsCaptured
+		}
+
+		if err := repo.CreateRefund(ctx, refund); err != nil {
+			return err
+		}
+		for _, line := range lines {
+			added, err := repo.AddOrderItemRefund(ctx, line.OrderItemID, line.Quantity, line.Amount)
+			if err != nil {
+				return err
+			}
+			if !added {
+				return domain.ErrRefundQuantity
+			}
+			if err := repo.CreateRefundItem(ctx, line); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	if unpaid {
+		if errSvc := s.refundUnpaid(ctx, pay, refund); errSvc != nil {
+			return nil, errSvc
+		}
+		return RefundRes(refund, nil), nil
+	}
+
+	result, err := s.gateway.Refund(ctx, pay.Reference, refund.Amount, refund.ID)
+	if err != nil {
+		log.Println("refund payment: ", refund.ID, err)
+		if errSvc := s.failRefund(ctx, refund, lines); errSvc != nil {
+			return nil, errSvc
+		}
+		return nil, domain.ErrPaymentUnavailable
+	}
+
+	err = s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		if err := repo.UpdateRefundStatus(ctx, refund.ID, domain.RefundPending, domain.RefundSucceeded, nullString(result.ID)); err != nil {
+			return err
+		}
+
+		if refund.Restock {
+			for productId, quantity := range restock {
+				if err := repo.ReleaseStock(ctx, productId, quantity); err != nil {
+					return err
+				}
+			}
+		}
+
+		order, err := repo.GetOrderById(ctx, refund.OrderID)
+		if err != nil {
+			return err
+		}
+		items, err := repo.ListOrderItems(ctx, refund.OrderID)
+		if err != nil {
+			return err
+		}
+		to := domain.OrderRefunded
+		for _, item := range items {
+			if item.RefundedQuantity < item.Quantity {
+				to = domain.OrderPartiallyRefunded
+				break
+			}
+		}
+		if order.Status != to {
+			if err := transitionOrder(ctx, repo, order, to, refund.Reason); err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(ctx, repo, domain.AuditEntityRefund, refund.ID, domain.AuditActionCreate, nil, refundAuditFields(refund, domain.RefundSucceeded, lines))
+	})
+	if err != nil {
+		// the provider has refunded, the refund stays pending for the store
+		// to follow up
+		log.Println("record refund: ", refund.ID, err)
+		return nil, repository.TranslateError(err)
+	}
+	refund.Status = domain.RefundSucceeded
+	refund.Reference = nullString(result.ID)
+
+	return RefundRes(refund, lines), nil
+}
+
+// ListRefunds list the refunds of an order of the store, oldest first
+func (s *service) ListRefunds(ctx context.Context, storeId string, orderId string) ([]*domain.Refund, errpkg.ErrorService) {
+	order, err := s.repo.GetOrderById(ctx, orderId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	if order == nil || order.StoreID != storeId {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	refunds, err := s.repo.ListRefundsByOrder(ctx, orderId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+	items, err := s.repo.ListRefundItemsByOrder(ctx, orderId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	byRefund := make(map[string][]*repository.RefundItem, len(refunds))
+	for _, item := range items {
+		byRefund[item.RefundID] = append(byRefund[item.RefundID], item)
+	}
+
+	result := []*domain.Refund{}
+	for _, refund := range refunds {
+		result = append(result, RefundRes(refund, byRefund[refund.ID]))
+	}
+
+	return result, nil
+}
+
+// reserve a refund of what is left on a payment which does not pay its
+// order, nil when nothing is left. The provider is asked by refundUnpaid once
+// the transaction is committed.
+func reserveUnpaidRefund(ctx context.Context, repo repository.StoreRepository, pay *repository.Payment, reason string) (*repository.Refund, error) {
+	refund := &repository.Refund{
+		ID:        uuid.New().String(),
+		OrderID:   pay.OrderID,
+		PaymentID: pay.ID,
+		Amount:    domain.RoundPrice(pay.CapturedAmount - pay.RefundedAmount),
+		Reason:    reason,
+		Status:    domain.RefundPending,
+		CreatedAt: time.Now().UTC(),
+	}
+	if refund.Amount <= 0 {
+		return nil, nil
+	}
+
+	reserved, err := repo.AddPaymentRefund(ctx, pay.ID, refund.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if !reserved {
+		return nil, nil
+	}
+	if err := repo.CreateRefund(ctx, refund); err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// send a refund reserved by reserveUnpaidRefund to the provider, a refund the
+// provider reject is given back to the payment so the store can retry it
+// with RefundOrder
+func (s *service) refundUnpaid(ctx context.Context, pay *repository.Payment, refund *repository.Refund) errpkg.ErrorService {
+	result, err := s.gateway.Refund(ctx, pay.Reference, refund.Amount, refund.ID)
+	if err != nil {
+		log.Println("refund payment: ", refund.ID, err)
+		if errSvc := s.failRefund(ctx, refund, nil); errSvc != nil {
+			return errSvc
+		}
+		return domain.ErrPaymentUnavailable
+	}
+
+	err = s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		if err := repo.UpdateRefundStatus(ctx, refund.ID, domain.RefundPending, domain.RefundSucceeded, nullString(result.ID)); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repo, domain.AuditEntityRefund, refund.ID, domain.AuditActionCreate, nil, refundAuditFields(refund, domain.RefundSucceeded, nil))
+	})
+	if err != nil {
+		// the provider has refunded, the refund stays pending for the store
+		// to follow up
+		log.Println("record refund: ", refund.ID, err)
+		return repository.TranslateError(err)
+	}
+	refund.Status = domain.RefundSucceeded
+	refund.Reference = nullString(result.ID)
+
+	return nil
+}
+
+// give a refund the provider rejected back to the payment and its lines
+func (s *service) failRefund(ctx context.Context, refund *repository.Refund, lines []*repository.RefundItem) errpkg.ErrorService {
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		if err := repo.UpdateRefundStatus(ctx, refund.ID, domain.RefundPending, domain.RefundFailed, nullString("")); err != nil {
+			return err
+		}
+		if _, err := repo.AddPaymentRefund(ctx, refund.PaymentID, -refund.Amount); err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if _, err := repo.AddOrderItemRefund(ctx, line.OrderItemID, -line.Quantity, -line.Amount); err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(ctx, repo, domain.AuditEntityRefund, refund.ID, domain.AuditActionCreate, nil, refundAuditFields(refund, domain.RefundFailed, lines))
+	})
+	if err != nil {
+		log.Println("release failed refund: ", refund.ID, err)
+		return repository.TranslateError(err)
+	}
+
+	return nil
+}
+
+// the captured payment of an order, nil when it has none
+func capturedPayment(ctx context.Context, repo repository.StoreRepository, orderId string) (*repository.Payment, error) {
+	payments, err := repo.ListPaymentsByOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	for _, pay := range payments {
+		if pay.Status == domain.PaymentCaptured {
+			return pay, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// quantity to refund per order item, every quantity left when no item is
+// requested
+func refundQuantities(items []*repository.OrderItem, requested []domain.RefundItemRequest) (map[int64]int, error) {
+	quantities := make(map[int64]int, len(items))
+	if len(requested) == 0 {
+		for _, item := range items {
+			if left := item.Quantity - item.RefundedQuantity; left > 0 {
+				quantities[item.ID] = left
+			}
+		}
+		return quantities, nil
+	}
+
+	byId := make(map[int64]*repository.OrderItem, len(items))
+	for _, item := range items {
+		byId[item.ID] = item
+	}
+	for _, request := range requested {
+		item, ok := byId[request.OrderItemID]
+		if !ok {
+			return nil, domain.ErrOrderItemNotFound
+		}
+		if item.RefundedQuantity+request.Quantity > item.Quantity {
+			return nil, domain.ErrRefundQuantity
+		}
+		quantities[item.ID] = request.Quantity
+	}
+
+	return quantities, nil
+}
+
+func refundAuditFields(refund *repository.Refund, status string, lines []*repository.RefundItem) domain.AuditFields {
+	items := make([]domain.RefundItem, 0, len(lines))
+	for _, line := range lines {
+		items = append(items, domain.RefundItem{OrderItemID: line.OrderItemID, Quantity: line.Quantity, Amount: line.Amount})
+	}
+
+	return domain.AuditFields{
+		"order_id":   refund.OrderID,
+		"payment_id": refund.PaymentID,
+		"amount":     refund.Amount,
+		"reason":     refund.Reason,
+		"restock":    refund.Restock,
+		"status":     status,
+		"items":      items,
+	}
+}
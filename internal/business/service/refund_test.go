@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ijlik/store-app/internal/adapter/payment"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefundQuantities(t *testing.T) {
+	items := []*repository.OrderItem{
+		{ID: 1, Quantity: 2, RefundedQuantity: 1},
+		{ID: 2, Quantity: 3},
+		{ID: 3, Quantity: 1, RefundedQuantity: 1},
+	}
+
+	quantities, err := refundQuantities(items, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int{1: 1, 2: 3}, quantities)
+
+	quantities, err = refundQuantities(items, []domain.RefundItemRequest{{OrderItemID: 2, Quantity: 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int{2: 2}, quantities)
+
+	_, err = refundQuantities(items, []domain.RefundItemRequest{{OrderItemID: 1, Quantity: 2}})
+	assert.Equal(t, domain.ErrRefundQuantity, err)
+
+	_, err = refundQuantities(items, []domain.RefundItemRequest{{OrderItemID: 9, Quantity: 1}})
+	assert.Equal(t, domain.ErrOrderItemNotFound, err)
+}
+
+func TestRefundOrderPartial(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	gateway := payment.NewFakeGateway("secret", true)
+	intent, err := gateway.CreateIntent(ctx, payment.IntentRequest{OrderID: "test_order_id", Amount: 36000, Currency: "IDR"})
+	assert.NoError(t, err)
+	_, err = gateway.Capture(ctx, intent.ID, 36000)
+	assert.NoError(t, err)
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, gateway)
+
+	now := time.Now().UTC()
+	order := func(status string) *sqlmock.Rows {
+		return sqlmock.NewRows(orderColumns).
+			AddRow("test_order_id", "test_store_id", "test_user_id", status, 36000, 0, 36000, nil, nil, now, nil)
+	}
+	items := func(refunded int, refundedAmount float32) *sqlmock.Rows {
+		return sqlmock.NewRows(append(orderItemColumns, "refunded_quantity", "refunded_amount")).
+			AddRow(1, "test_order_id", "test_product_id", "Kopi Susu", nil, 18000, 18000, 2, 0, 36000, []byte(`[]`), refunded, refundedAmount)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").WillReturnRows(order(domain.OrderPaid))
+	mock.ExpectQuery("SELECT (.+) FROM payments WHERE order_id = \\$1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(paymentColumns).
+			AddRow("test_payment_id", "test_order_id", "fake", intent.ID, 36000, 36000, 0, "IDR", "captured", now, nil))
+	mock.ExpectQuery("SELECT (.+) FROM order_items WHERE order_id = \\$1").WithArgs("test_order_id").WillReturnRows(items(0, 0))
+	mock.ExpectExec("UPDATE payments SET refunded_amount = refunded_amount \\+ \\$2").WithArgs("test_payment_id", float32(18000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO refunds").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE order_items SET refunded_quantity").WithArgs(int64(1), 1, float32(18000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO refund_items").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE refunds SET status = \\$3").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE products SET stock = stock \\+ \\$2").WithArgs("test_product_id", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").WillReturnRows(order(domain.OrderPaid))
+	mock.ExpectQuery("SELECT (.+) FROM order_items WHERE order_id = \\$1").WithArgs("test_order_id").WillReturnRows(items(1, 18000))
+	mock.ExpectExec("UPDATE orders SET status = \\$3").WithArgs("test_order_id", "paid", "partially_refunded").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_logs").WithArgs("order", "test_order_id", "update", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO audit_logs").WithArgs("refund", sqlmock.AnyArg(), "create", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	refund, errSvc := svc.RefundOrder(ctx, "test_store_id", "test_order_id", &domain.RefundRequest{
+		Reason:  "wrong item",
+		Restock: true,
+		Items:   []domain.RefundItemRequest{{OrderItemID: 1, Quantity: 1}},
+	})
+	assert.Nil(t, errSvc)
+	assert.Equal(t, float32(18000), refund.Amount)
+	assert.Equal(t, domain.RefundSucceeded, refund.Status)
+	assert.Equal(t, []domain.RefundItem{{OrderItemID: 1, Quantity: 1, Amount: 18000}}, refund.Items)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	refunded, err := gateway.GetIntent(ctx, intent.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(18000), refunded.Refunded)
+}
+
+func TestRefundOrderExceedsCaptured(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, payment.NewFakeGateway("secret", false))
+
+	now := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(orderColumns).
+			AddRow("test_order_id", "test_store_id", "test_user_id", "completed", 36000, 0, 36000, nil, nil, now, nil))
+	mock.ExpectQuery("SELECT (.+) FROM payments WHERE order_id = \\$1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(paymentColumns).
+			AddRow("test_payment_id", "test_order_id", "fake", "pi_1", 36000, 36000, 30000, "IDR", "captured", now, nil))
+	mock.ExpectQuery("SELECT (.+) FROM order_items WHERE order_id = \\$1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(orderItemColumns).
+			AddRow(1, "test_order_id", "test_product_id", "Kopi Susu", nil, 18000, 18000, 2, 0, 36000, []byte(`[]`)))
+	mock.ExpectExec("UPDATE payments SET refunded_amount = refunded_amount \\+ \\$2").WithArgs("test_payment_id", float32(18000)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	_, errSvc := svc.RefundOrder(context.Background(), "test_store_id", "test_order_id", &domain.RefundRequest{
+		Reason: "late delivery",
+		Items:  []domain.RefundItemRequest{{OrderItemID: 1, Quantity: 1}},
+	})
+	assert.Equal(t, domain.ErrRefundExceedsCaptured, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefundOrderUnpaid(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	gateway := payment.NewFakeGateway("secret", true)
+	intent, err := gateway.CreateIntent(ctx, payment.IntentRequest{OrderID: "test_order_id", Amount: 36000, Currency: "IDR"})
+	assert.NoError(t, err)
+	_, err = gateway.Capture(ctx, intent.ID, 36000)
+	assert.NoError(t, err)
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, gateway)
+
+	// captured after the order was cancelled and the automatic refund failed
+	now := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = \\$1 LIMIT 1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(orderColumns).
+			AddRow("test_order_id", "test_store_id", "test_user_id", "cancelled", 36000, 0, 36000, nil, nil, now, nil))
+	mock.ExpectQuery("SELECT (.+) FROM payments WHERE order_id = \\$1").WithArgs("test_order_id").
+		WillReturnRows(sqlmock.NewRows(paymentColumns).
+			AddRow("test_payment_id", "test_order_id", "fake", intent.ID, 36000, 36000, 0, "IDR", "captured", now, nil))
+	mock.ExpectExec("UPDATE payments SET refunded_amount = refunded_amount \\+ \\$2").WithArgs("test_payment_id", float32(36000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO refunds").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE refunds SET status = \\$3").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_logs").WithArgs("refund", sqlmock.AnyArg(), "create", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	refund, errSvc := svc.RefundOrder(ctx, "test_store_id", "test_order_id", &domain.RefundRequest{Reason: "order was cancelled"})
+	assert.Nil(t, errSvc)
+	assert.Equal(t, float32(36000), refund.Amount)
+	assert.Equal(t, domain.RefundSucceeded, refund.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	refunded, err := gateway.GetIntent(ctx, intent.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(36000), refunded.Refunded)
+}
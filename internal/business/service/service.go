@@ -3,21 +3,25 @@ package service
 import (
 	configdata "github.com/ijlik/store-app/pkg/config/data"
 	// business package
+	"github.com/ijlik/store-app/internal/adapter/payment"
 	"github.com/ijlik/store-app/internal/adapter/repository"
 	"github.com/ijlik/store-app/internal/business/port"
 )
 
 type service struct {
-	repo   repository.StoreRepository
-	config configdata.Config
+	repo    repository.StoreRepository
+	config  configdata.Config
+	gateway payment.PaymentGateway
 }
 
 func NewStoreService(
 	repo repository.StoreRepository,
 	config configdata.Config,
+	gateway payment.PaymentGateway,
 ) port.StoreDomainService {
 	return &service{
 		repo,
 		config,
+		gateway,
 	}
 }
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"github.com/google/uuid"
 	"github.com/ijlik/store-app/internal/adapter/repository"
 	"github.com/ijlik/store-app/internal/business/domain"
@@ -13,79 +14,266 @@ import (
 )
 
 func (s *service) CreateStore(ctx context.Context, request *domain.StoreRequest) (*domain.Store, errpkg.ErrorService) {
-	store, err := s.repo.CreateStore(ctx, &repository.Store{
-		ID:                   uuid.New().String(),
-		Name:                 request.Name,
-		Url:                  request.Url,
-		Address:              request.Address,
-		Phone:                request.Phone,
-		OperationalTimeStart: request.OperationalTimeStart,
-		OperationalTimeEnd:   request.OperationalTimeEnd,
-		CreatedAt:            time.Now().UTC(),
+	var (
+		store               *repository.Store
+		latitude, longitude = nullLocation(request.Location)
+		address             = requestAddress(request)
+	)
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		url, err := allocateStoreUrl(ctx, repo, request.Url, "")
+		if err != nil {
+			return err
+		}
+
+		store, err = repo.CreateStore(ctx, &repository.Store{
+			ID:                   uuid.New().String(),
+			Name:                 request.Name,
+			Url:                  url,
+			Address:              request.Address,
+			Phone:                request.Phone,
+			OperationalTimeStart: request.OperationalTimeStart,
+			OperationalTimeEnd:   request.OperationalTimeEnd,
+			TaxRegion:            nullString(request.TaxRegion),
+			Latitude:             latitude,
+			Longitude:            longitude,
+			AddressStreet:        address.Street,
+			AddressDistrict:      address.District,
+			AddressCity:          address.City,
+			AddressProvince:      address.Province,
+			AddressPostalCode:    address.PostalCode,
+			AddressCountry:       address.Country,
+			CreatedAt:            time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repo, domain.AuditEntityStore, store.ID, domain.AuditActionCreate, nil, storeAuditFields(store))
 	})
 	if err != nil {
-		return nil, errpkg.DefaultServiceError(
-			errpkg.ErrInternal,
-			err.Error(),
-		)
-	}
-	return &domain.Store{
-		ID:                   store.ID,
-		Name:                 store.Name,
-		Url:                  store.Url,
-		Address:              store.Address,
-		Phone:                store.Phone,
-		OperationalTimeStart: store.OperationalTimeStart,
-		OperationalTimeEnd:   store.OperationalTimeEnd,
-		CreatedAt:            store.CreatedAt,
-	}, nil
+		return nil, repository.TranslateError(err)
+	}
+	return StoreRes(store), nil
 }
 
 func (s *service) GetStoreById(ctx context.Context, id string) (*domain.Store, errpkg.ErrorService) {
 	store, err := s.repo.GetStoreById(ctx, id)
 	if err != nil {
-		return nil, errpkg.DefaultServiceError(
-			errpkg.ErrInternal,
-			err.Error(),
-		)
+		return nil, repository.TranslateError(err)
 	}
 	if store == nil {
-		return nil, errpkg.DefaultServiceError(
-			errpkg.ErrNotFound,
-			"store not found",
-		)
-	}
-	return &domain.Store{
-		ID:                   store.ID,
-		Name:                 store.Name,
-		Url:                  store.Url,
-		Address:              store.Address,
-		Phone:                store.Phone,
-		OperationalTimeStart: store.OperationalTimeStart,
-		OperationalTimeEnd:   store.OperationalTimeEnd,
-		CreatedAt:            store.CreatedAt,
-	}, nil
+		return nil, domain.ErrStoreNotFound
+	}
+	return StoreRes(store), nil
 }
 
 func (s *service) UpdateStore(ctx context.Context, request *domain.StoreRequest, id string) errpkg.ErrorService {
-	err := s.repo.UpdateStore(ctx, &repository.Store{
-		ID:                   id,
-		Name:                 request.Name,
-		Url:                  request.Url,
-		Address:              request.Address,
-		Phone:                request.Phone,
-		OperationalTimeStart: request.OperationalTimeStart,
-		OperationalTimeEnd:   request.OperationalTimeEnd,
+	latitude, longitude := nullLocation(request.Location)
+	address := requestAddress(request)
+
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		store, err := repo.GetStoreById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if store == nil {
+			return domain.ErrStoreNotFound
+		}
+		if err := domain.CheckVersion(request.Version, store.Version); err != nil {
+			return err
+		}
+
+		url, err := renameStoreUrl(ctx, repo, store, request.Name, request.Url)
+		if err != nil {
+			return err
+		}
+
+		updated := &repository.Store{
+			ID:                   id,
+			Name:                 request.Name,
+			Url:                  url,
+			Address:              request.Address,
+			Phone:                request.Phone,
+			OperationalTimeStart: request.OperationalTimeStart,
+			OperationalTimeEnd:   request.OperationalTimeEnd,
+			TaxRegion:            nullString(request.TaxRegion),
+			Latitude:             latitude,
+			Longitude:            longitude,
+			AddressStreet:        address.Street,
+			AddressDistrict:      address.District,
+			AddressCity:          address.City,
+			AddressProvince:      address.Province,
+			AddressPostalCode:    address.PostalCode,
+			AddressCountry:       address.Country,
+			Version:              request.Version,
+		}
+		if err := repo.UpdateStore(ctx, updated); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, repo, domain.AuditEntityStore, id, domain.AuditActionUpdate, storeAuditFields(store), storeAuditFields(updated))
+	})
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+	return nil
+}
+
+// PatchStore apply a merge patch, only the changed columns are updated
+func (s *service) PatchStore(ctx context.Context, patch *domain.StorePatch, id string) errpkg.ErrorService {
+	err := s.repo.WithTx(ctx, func(ctx context.Context, repo repository.StoreRepository) error {
+		store, err := repo.GetStoreById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if store == nil {
+			return domain.ErrStoreNotFound
+		}
+		if err := domain.CheckVersion(patch.Version, store.Version); err != nil {
+			return err
+		}
+
+		var columns []repository.Column
+		if patch.Name != nil {
+			url, err := renameStoreUrl(ctx, repo, store, *patch.Name, *patch.Url)
+			if err != nil {
+				return err
+			}
+
+			columns = append(columns, repository.Column{Name: "name", Value: *patch.Name})
+			if url != store.Url {
+				columns = append(columns, repository.Column{Name: "url", Value: url})
+			}
+		}
+		if patch.PostalAddress != nil {
+			address := patch.PostalAddress.Apply(storeAddress(store))
+			columns = append(columns, addressColumns(address.Format(), address)...)
+		} else if patch.Address != nil {
+			// the line is kept as given, the parts are parsed from it
+			address := domain.ParseAddress(*patch.Address)
+			address.Country = store.AddressCountry
+			columns = append(columns, addressColumns(*patch.Address, address)...)
+		}
+		if patch.Phone != nil {
+			columns = append(columns, repository.Column{Name: "phone", Value: *patch.Phone})
+		}
+		if patch.OperationalTimeStart != nil {
+			columns = append(columns, repository.Column{Name: "operational_time_start", Value: *patch.OperationalTimeStart})
+		}
+		if patch.OperationalTimeEnd != nil {
+			columns = append(columns, repository.Column{Name: "operational_time_end", Value: *patch.OperationalTimeEnd})
+		}
+		if patch.TaxRegion != nil {
+			columns = append(columns, repository.Column{Name: "tax_region", Value: nullString(*patch.TaxRegion)})
+		}
+		if patch.Location != nil {
+			var location *domain.GeoPoint
+			if patch.Location.Valid {
+				location = &patch.Location.GeoPoint
+			}
+			latitude, longitude := nullLocation(location)
+			columns = append(columns,
+				repository.Column{Name: "latitude", Value: latitude},
+				repository.Column{Name: "longitude", Value: longitude},
+			)
+		}
+		if len(columns) == 0 {
+			return nil
+		}
+
+		if err := repo.PatchStore(ctx, id, patch.Version, columns); err != nil {
+			return err
+		}
+
+		before := storeAuditFields(store)
+		return recordAudit(ctx, repo, domain.AuditEntityStore, id, domain.AuditActionUpdate, before, patchedAuditFields(before, columns))
 	})
 	if err != nil {
-		return errpkg.DefaultServiceError(
-			errpkg.ErrInternal,
-			err.Error(),
-		)
+		return repository.TranslateError(err)
 	}
 	return nil
 }
 
+// GetStoreByUrl find store by its current url or a previous one, the
+// returned store carry the current url so the caller can redirect
+func (s *service) GetStoreByUrl(ctx context.Context, url string) (*domain.Store, errpkg.ErrorService) {
+	store, err := s.repo.GetStoreByUrl(ctx, url)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	if store == nil {
+		history, err := s.repo.GetStoreUrlHistory(ctx, url)
+		if err != nil {
+			return nil, repository.TranslateError(err)
+		}
+		if history == nil {
+			return nil, domain.ErrStoreNotFound
+		}
+
+		store, err = s.repo.GetStoreById(ctx, history.OwnerID)
+		if err != nil {
+			return nil, repository.TranslateError(err)
+		}
+		if store == nil {
+			return nil, domain.ErrStoreNotFound
+		}
+	}
+
+	return StoreRes(store), nil
+}
+
+// ShowStores list the stores by name, city and province of the query filter
+// the stores regardless of case
+func (s *service) ShowStores(ctx context.Context, pagination *httppagination.Pagination, query *domain.HttpStoreQuery) errpkg.ErrorService {
+	var (
+		g           sync.WaitGroup
+		int64Atomic atomic.Int64
+		arrayAtomic atomic.Value
+		errAtomic   atomic.Value
+		result      = []*domain.Store{}
+	)
+
+	g.Add(1)
+	go func() {
+		defer g.Done()
+		stores, err := s.repo.ListStores(ctx, query.City, query.Province, pagination.Limit, pagination.Offset)
+		if err != nil {
+			errAtomic.Store(err)
+		} else {
+			arrayAtomic.Store(stores)
+		}
+	}()
+
+	g.Add(1)
+	go func() {
+		defer g.Done()
+		count, err := s.repo.CountStores(ctx, query.City, query.Province)
+		if err != nil {
+			errAtomic.Store(err)
+		} else {
+			int64Atomic.Store(count)
+		}
+	}()
+	g.Wait()
+
+	if err, ok := errAtomic.Load().(error); ok {
+		return repository.TranslateError(err)
+	}
+
+	if stores, ok := arrayAtomic.Load().([]*repository.Store); !ok {
+		return errpkg.DefaultServiceError(errpkg.ErrInternal, "")
+	} else {
+		for _, store := range stores {
+			result = append(result, StoreRes(store))
+		}
+	}
+
+	pagination.SetData(result, int64Atomic.Load())
+	return nil
+}
+
 func (s *service) ShowStoreProducts(ctx context.Context, pagination *httppagination.Pagination, searchAndFilter *domain.SearchAndFilterProduct, id string) errpkg.ErrorService {
 	var (
 		g           sync.WaitGroup
@@ -105,16 +293,10 @@ func (s *service) ShowStoreProducts(ctx context.Context, pagination *httppaginat
 
 	store, err := s.repo.GetStoreById(ctx, id)
 	if err != nil {
-		return errpkg.DefaultServiceError(
-			errpkg.ErrInternal,
-			err.Error(),
-		)
+		return repository.TranslateError(err)
 	}
 	if store == nil {
-		return errpkg.DefaultServiceError(
-			errpkg.ErrNotFound,
-			"store not found",
-		)
+		return domain.ErrStoreNotFound
 	}
 
 	g.Add(1)
@@ -141,17 +323,132 @@ func (s *service) ShowStoreProducts(ctx context.Context, pagination *httppaginat
 	g.Wait()
 
 	if err, ok := errAtomic.Load().(error); ok {
-		return errpkg.DefaultServiceError(errpkg.ErrInternal, err.Error())
+		return repository.TranslateError(err)
 	}
 
 	if products, ok := arrayAtomic.Load().([]*repository.Product); !ok {
 		return errpkg.DefaultServiceError(errpkg.ErrInternal, "")
 	} else {
+		if err := resolvePriceSchedules(ctx, s.repo, time.Now().UTC(), products...); err != nil {
+			return repository.TranslateError(err)
+		}
 		for _, product := range products {
 			result = append(result, ProductRes(product, store))
 		}
 	}
 
+	if err := s.applySalePrices(ctx, result); err != nil {
+		return err
+	}
+
 	pagination.SetData(result, int64Atomic.Load())
 	return nil
 }
+
+// allocate free url from base, urls owned by storeId are not counted as taken
+func allocateStoreUrl(ctx context.Context, repo repository.StoreRepository, base string, storeId string) (string, error) {
+	slugs, err := repo.ListStoreUrls(ctx, base, domain.SlugPattern(base))
+	if err != nil {
+		return "", err
+	}
+
+	return freeUrl(slugs, base, storeId), nil
+}
+
+// first free url from base, slugs of owner can be reused by the owner
+func freeUrl(slugs []*repository.Slug, base string, owner string) string {
+	var taken []string
+	for _, slug := range slugs {
+		if slug.OwnerID != owner {
+			taken = append(taken, slug.Url)
+		}
+	}
+
+	return domain.NextSlug(base, taken)
+}
+
+// renameStoreUrl keep the url while the name is unchanged or the url is
+// still derived from base, otherwise allocate a new url and keep the current
+// one on the history for redirect. Name without usable letter get a random
+// base on every call and keep its url too.
+func renameStoreUrl(ctx context.Context, repo repository.StoreRepository, store *repository.Store, name string, base string) (string, error) {
+	if store.Name == name || domain.RandomSlug(name) || domain.SlugOf(store.Url, base) {
+		return store.Url, nil
+	}
+
+	url, err := allocateStoreUrl(ctx, repo, base, store.ID)
+	if err != nil {
+		return "", err
+	}
+
+	// url may come back from the history of this store
+	if err := repo.DeleteStoreUrlHistory(ctx, store.ID, url); err != nil {
+		return "", err
+	}
+	if err := repo.CreateStoreUrlHistory(ctx, store.ID, store.Url); err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
+
+// postal address of the request, parsed from the single line when the
+// request did not give it
+func requestAddress(request *domain.StoreRequest) domain.Address {
+	if request.PostalAddress != nil {
+		return *request.PostalAddress
+	}
+
+	return domain.ParseAddress(request.Address)
+}
+
+// columns of the store address, line is the single line address
+func addressColumns(line string, address domain.Address) []repository.Column {
+	return []repository.Column{
+		{Name: "address", Value: line},
+		{Name: "address_street", Value: address.Street},
+		{Name: "address_district", Value: address.District},
+		{Name: "address_city", Value: address.City},
+		{Name: "address_province", Value: address.Province},
+		{Name: "address_postal_code", Value: address.PostalCode},
+		{Name: "address_country", Value: address.Country},
+	}
+}
+
+// columns of the store location, null when the location is not set
+func nullLocation(location *domain.GeoPoint) (sql.NullFloat64, sql.NullFloat64) {
+	if location == nil {
+		return sql.NullFloat64{}, sql.NullFloat64{}
+	}
+
+	return sql.NullFloat64{Float64: location.Lat, Valid: true}, sql.NullFloat64{Float64: location.Lng, Valid: true}
+}
+
+// ListNearbyStores list the stores within the radius of the point of the
+// query, nearest first with their distance
+func (s *service) ListNearbyStores(ctx context.Context, query *domain.HttpNearbyStoreQuery) ([]*domain.NearbyStore, errpkg.ErrorService) {
+	point := query.Point()
+	latDelta, lngDelta := domain.BoundingBox(point, query.RadiusKm)
+
+	stores, err := s.repo.ListNearbyStores(ctx, &repository.NearbySearch{
+		Lat:      point.Lat,
+		Lng:      point.Lng,
+		RadiusKm: query.RadiusKm,
+		LatDelta: latDelta,
+		LngDelta: lngDelta,
+		Limit:    query.Limit,
+	})
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	res := make([]*domain.NearbyStore, 0, len(stores))
+	for _, store := range stores {
+		res = append(res, &domain.NearbyStore{
+			Store:      *StoreRes(&store.Store),
+			DistanceKm: domain.RoundDistanceKm(store.DistanceKm),
+		})
+	}
+
+	return res, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ijlik/store-app/internal/adapter/repository"
 	"github.com/ijlik/store-app/internal/business/domain"
 	httppagination "github.com/ijlik/store-app/pkg/http/pagination"
+	"github.com/ijlik/store-app/pkg/mergepatch"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	mocktest "github.com/stretchr/testify/mock"
@@ -49,9 +50,9 @@ func TestCreateStore(t *testing.T) {
 		},
 	}
 
-	createStoreQueryMock := "INSERT INTO stores \\(name, url, address, phone, operational_time_start, operational_time_end, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, CURRENT_TIMESTAMP\\) RETURNING id"
+	createStoreQueryMock := "INSERT INTO stores \\(name, url, address, phone, operational_time_start, operational_time_end, tax_region, latitude, longitude, address_street, address_district, address_city, address_province, address_postal_code, address_country, created_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8, \\$9, \\$10, \\$11, \\$12, \\$13, \\$14, \\$15, CURRENT_TIMESTAMP\\) RETURNING id"
 	mock.ExpectQuery(createStoreQueryMock).
-		WithArgs(expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd).
+		WithArgs(expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd, expectedStoreData.TaxRegion, expectedStoreData.Latitude, expectedStoreData.Longitude, expectedStoreData.AddressStreet, expectedStoreData.AddressDistrict, expectedStoreData.AddressCity, expectedStoreData.AddressProvince, expectedStoreData.AddressPostalCode, expectedStoreData.AddressCountry).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(expectedStoreData.ID))
 
 	mockStoreService.Mock.On("MockCreateStore", request).Return(StoreRes(expectedStoreData), nil)
@@ -96,9 +97,9 @@ func TestGetStoreById(t *testing.T) {
 		},
 	}
 
-	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at FROM stores WHERE id = \\$1 LIMIT 1"
-	rows := sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at"}).
-		AddRow(expectedStoreData.ID, expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd, expectedStoreData.CreatedAt, nil)
+	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at, version, tax_region, latitude, longitude, address_street, address_district, address_city, address_province, address_postal_code, address_country FROM stores WHERE id = \\$1 LIMIT 1"
+	rows := sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at", "version"}).
+		AddRow(expectedStoreData.ID, expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd, expectedStoreData.CreatedAt, nil, expectedStoreData.Version)
 	mock.ExpectQuery(getStoreByIdQueryMock).WithArgs(expectedStoreData.ID).WillReturnRows(rows)
 
 	mockStoreService.Mock.On("MockGetStoreById", expectedStoreData.ID).Return(StoreRes(expectedStoreData), nil)
@@ -115,6 +116,25 @@ func TestGetStoreById(t *testing.T) {
 	assert.Equal(t, expectedResponse.OperationalTimeEnd, store.OperationalTimeEnd)
 }
 
+func TestGetStoreByIdVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM stores WHERE id = \\$1 LIMIT 1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at", "version"}).
+			AddRow("test_store_id", "Kopi Kenangan", "kopi-kenangan", "Jl. Sudirman 1, Jakarta", "+6281234567890", 8, 22, time.Now(), nil, 3))
+
+	// the version is the entity tag of the store, zero is never accepted by If-Match
+	store, errSvc := svc.GetStoreById(context.Background(), "test_store_id")
+	assert.Nil(t, errSvc)
+	assert.Equal(t, 3, store.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpdateStore(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -150,9 +170,9 @@ func TestUpdateStore(t *testing.T) {
 		},
 	}
 
-	updateStoreQueryMock := "UPDATE stores SET name = \\$2, url = \\$3, address = \\$4, phone = \\$5, operational_time_start = \\$6, operational_time_end = \\$7, updated_at = CURRENT_TIMESTAMP WHERE id = \\$1"
+	updateStoreQueryMock := "UPDATE stores SET name = \\$2, url = \\$3, address = \\$4, phone = \\$5, operational_time_start = \\$6, operational_time_end = \\$7, tax_region = \\$8, latitude = \\$9, longitude = \\$10, address_street = \\$11, address_district = \\$12, address_city = \\$13, address_province = \\$14, address_postal_code = \\$15, address_country = \\$16, updated_at = CURRENT_TIMESTAMP, version = version \\+ 1 WHERE id = \\$1 AND \\(\\$17 = 0 OR version = \\$17\\)"
 	mock.ExpectExec(updateStoreQueryMock).
-		WithArgs(expectedStoreData.ID, expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd).
+		WithArgs(expectedStoreData.ID, expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd, expectedStoreData.TaxRegion, expectedStoreData.Latitude, expectedStoreData.Longitude, expectedStoreData.AddressStreet, expectedStoreData.AddressDistrict, expectedStoreData.AddressCity, expectedStoreData.AddressProvince, expectedStoreData.AddressPostalCode, expectedStoreData.AddressCountry, expectedStoreData.Version).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	mockStoreService.Mock.On("MockUpdateStore", request, expectedStoreData.ID).Return(nil)
@@ -189,9 +209,9 @@ func TestShowStoreProducts(t *testing.T) {
 			},
 		},
 	}
-	listProductsQueryMock := "SELECT id, store_id, name, url, price, description, created_at, updated_at FROM products"
-	mock.ExpectQuery(listProductsQueryMock).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "created_at", "updated_at"}).
-		AddRow(expectedProductData[0].ID, expectedProductData[0].StoreID, expectedProductData[0].Name, expectedProductData[0].Url, expectedProductData[0].Price, expectedProductData[0].Description, expectedProductData[0].CreatedAt, expectedProductData[0].UpdatedAt))
+	listProductsQueryMock := "SELECT id, store_id, name, url, price, description, sku, created_at, updated_at, version, was_price, category, stock, weight FROM products"
+	mock.ExpectQuery(listProductsQueryMock).WillReturnRows(sqlmock.NewRows([]string{"id", "store_id", "name", "url", "price", "description", "sku", "created_at", "updated_at", "version"}).
+		AddRow(expectedProductData[0].ID, expectedProductData[0].StoreID, expectedProductData[0].Name, expectedProductData[0].Url, expectedProductData[0].Price, expectedProductData[0].Description, expectedProductData[0].Sku, expectedProductData[0].CreatedAt, expectedProductData[0].UpdatedAt, expectedProductData[0].Version))
 
 	expectedCount := int64(8)
 	countProductsQueryMock := "SELECT count\\(\\*\\) FROM products"
@@ -212,9 +232,9 @@ func TestShowStoreProducts(t *testing.T) {
 		},
 	}
 
-	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at FROM stores WHERE id = \\$1 LIMIT 1"
-	rows := sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at"}).
-		AddRow(expectedStoreData.ID, expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd, expectedStoreData.CreatedAt, nil)
+	getStoreByIdQueryMock := "SELECT id, name, url, address, phone, operational_time_start, operational_time_end, created_at, updated_at, version, tax_region, latitude, longitude, address_street, address_district, address_city, address_province, address_postal_code, address_country FROM stores WHERE id = \\$1 LIMIT 1"
+	rows := sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at", "version"}).
+		AddRow(expectedStoreData.ID, expectedStoreData.Name, expectedStoreData.Url, expectedStoreData.Address, expectedStoreData.Phone, expectedStoreData.OperationalTimeStart, expectedStoreData.OperationalTimeEnd, expectedStoreData.CreatedAt, nil, expectedStoreData.Version)
 	mock.ExpectQuery(getStoreByIdQueryMock).WithArgs(expectedStoreData.ID).WillReturnRows(rows)
 
 	pagination := httppagination.Pagination{
@@ -236,3 +256,108 @@ func TestShowStoreProducts(t *testing.T) {
 	assert.Equal(t, expectedCount, count)
 	assert.Equal(t, []*domain.Product{ProductRes(expectedProductData[0], expectedStoreData)}, products)
 }
+
+func TestListNearbyStores(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	now := time.Now()
+	lat, lng := -6.1754, 106.8272
+	query := &domain.HttpNearbyStoreQuery{Lat: &lat, Lng: &lng}
+	assert.Nil(t, query.Validate())
+
+	mock.ExpectQuery("SELECT (.+) AS distance_km FROM stores").WithArgs(lat, lng, 5.0, sqlmock.AnyArg(), sqlmock.AnyArg(), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at", "version", "tax_region", "latitude", "longitude", "distance_km"}).
+			AddRow("test_store_1", "Kopi Kenangan", "kopi-kenangan", "Jakarta", "+6281234567890", 8, 22, now, nil, 1, nil, -6.1950, 106.8230, 2.23117).
+			AddRow("test_store_2", "Roti O", "roti-o", "Jakarta", "+6281234567891", 7, 21, now, nil, 1, nil, -6.2088, 106.8456, 4.2066))
+
+	stores, errSvc := svc.ListNearbyStores(context.Background(), query)
+	assert.Nil(t, errSvc)
+	assert.Len(t, stores, 2)
+	assert.Equal(t, "test_store_1", stores[0].ID)
+	assert.Equal(t, 2.23, stores[0].DistanceKm)
+	assert.Equal(t, &domain.GeoPoint{Lat: -6.1950, Lng: 106.8230}, stores[0].Location)
+	assert.Equal(t, 4.21, stores[1].DistanceKm)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShowStores(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	query := &domain.HttpStoreQuery{City: "bandung", Province: "jawa barat"}
+	assert.Nil(t, query.Validate())
+	pagination := &httppagination.Pagination{Limit: 10, Offset: 0}
+
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectQuery("SELECT (.+) FROM stores WHERE (.+) ORDER BY name, id").WithArgs("bandung", "jawa barat", 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at", "version", "address_street", "address_city", "address_province"}).
+			AddRow("test_store_id", "Kopi Kenangan", "kopi-kenangan", "Jl. Braga 10, Bandung, Jawa Barat", "+6281234567890", 8, 22, time.Now(), nil, 1, "Jl. Braga 10", "Bandung", "Jawa Barat"))
+	mock.ExpectQuery("SELECT COUNT\\(id\\) FROM stores").WithArgs("bandung", "jawa barat").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	errSvc := svc.ShowStores(context.Background(), pagination, query)
+	assert.Nil(t, errSvc)
+	stores := pagination.Data.([]*domain.Store)
+	assert.Len(t, stores, 1)
+	assert.Equal(t, domain.Address{Street: "Jl. Braga 10", City: "Bandung", Province: "Jawa Barat"}, stores[0].PostalAddress)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchStorePostalAddress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dbx := sqlx.NewDb(db, "postgres")
+	svc := NewStoreService(repository.NewStoreRepo(dbx), nil, nil)
+
+	doc, err := mergepatch.Parse([]byte(`{"postal_address":{"district":"Menteng","province":"DKI Jakarta","postal_code":"10310","country":"id"}}`))
+	assert.NoError(t, err)
+	patch, errPatch := domain.NewStorePatch(doc)
+	assert.Nil(t, errPatch)
+
+	changes := `{"address":{"from":"Jl. Sudirman 1, Jakarta","to":"Jl. Sudirman 1, Menteng, Jakarta, DKI Jakarta 10310"},"address_country":{"from":"","to":"ID"},"address_district":{"from":"","to":"Menteng"},"address_postal_code":{"from":"","to":"10310"},"address_province":{"from":"","to":"DKI Jakarta"}}`
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM stores WHERE id = \\$1 LIMIT 1").WithArgs("test_store_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "url", "address", "phone", "operational_time_start", "operational_time_end", "created_at", "updated_at", "version", "address_street", "address_city"}).
+			AddRow("test_store_id", "Kopi Kenangan", "kopi-kenangan", "Jl. Sudirman 1, Jakarta", "+6281234567890", 8, 22, time.Now(), nil, 2, "Jl. Sudirman 1", "Jakarta"))
+	mock.ExpectExec("UPDATE stores SET address = \\$3, address_street = \\$4, address_district = \\$5, address_city = \\$6, address_province = \\$7, address_postal_code = \\$8, address_country = \\$9, updated_at = CURRENT_TIMESTAMP").
+		WithArgs("test_store_id", 0, "Jl. Sudirman 1, Menteng, Jakarta, DKI Jakarta 10310", "Jl. Sudirman 1", "Menteng", "Jakarta", "DKI Jakarta", "10310", "ID").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_logs").
+		WithArgs("store", "test_store_id", "update", sqlmock.AnyArg(), sqlmock.AnyArg(), []byte(changes)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	errSvc := svc.PatchStore(context.Background(), patch, "test_store_id")
+	assert.Nil(t, errSvc)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRenameStoreUrlKeepUrl(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewStoreRepo(sqlx.NewDb(db, "postgres"))
+	store := &repository.Store{ID: "test_store_id", Name: "咖啡店", Url: "x7k2m9qa"}
+
+	// the random base of a name without usable letter never match the url
+	url, err := renameStoreUrl(context.Background(), repo, store, "咖啡店", domain.CreateSlug("咖啡店"))
+	assert.NoError(t, err)
+	assert.Equal(t, "x7k2m9qa", url)
+
+	url, err = renameStoreUrl(context.Background(), repo, store, "茶館", domain.CreateSlug("茶館"))
+	assert.NoError(t, err)
+	assert.Equal(t, "x7k2m9qa", url)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ijlik/store-app/internal/adapter/repository"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+)
+
+func (s *service) CreateTaxRule(ctx context.Context, request *domain.TaxRuleRequest) (*domain.TaxRule, errpkg.ErrorService) {
+	if request.StoreID != "" {
+		store, err := s.repo.GetStoreById(ctx, request.StoreID)
+		if err != nil {
+			return nil, repository.TranslateError(err)
+		}
+		if store == nil {
+			return nil, errStoreIdNotFound()
+		}
+	}
+
+	rule := &repository.TaxRule{
+		ID:        uuid.New().String(),
+		Name:      request.Name,
+		StoreID:   nullString(request.StoreID),
+		Region:    nullString(request.Region),
+		Category:  nullString(request.Category),
+		Rate:      request.Rate,
+		Inclusive: request.Inclusive,
+		Rounding:  request.Rounding,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.CreateTaxRule(ctx, rule); err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return TaxRuleRes(rule), nil
+}
+
+// ListTaxRules list every tax rule oldest first, only the rules of the store
+// when storeId is not empty
+func (s *service) ListTaxRules(ctx context.Context, storeId string) ([]*domain.TaxRule, errpkg.ErrorService) {
+	rules, err := s.repo.ListTaxRules(ctx, storeId)
+	if err != nil {
+		return nil, repository.TranslateError(err)
+	}
+
+	return TaxRulesRes(rules), nil
+}
+
+func (s *service) DeleteTaxRule(ctx context.Context, id string) errpkg.ErrorService {
+	deleted, err := s.repo.DeleteTaxRule(ctx, id)
+	if err != nil {
+		return repository.TranslateError(err)
+	}
+	if !deleted {
+		return domain.ErrTaxRuleNotFound
+	}
+
+	return nil
+}
+
+// calculate the tax of lines sold by the store with the rules of the store,
+// of its region and the default rules
+func storeTax(ctx context.Context, repo repository.StoreRepository, store *repository.Store, lines []domain.TaxLine) (*domain.TaxBreakdown, error) {
+	rules, err := repo.ListTaxRulesForStore(ctx, store.ID, store.TaxRegion.String)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.CalculateTax(TaxRulesRes(rules), store.ID, store.TaxRegion.String, lines), nil
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ijlik/store-app/internal/business/domain"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+)
+
+const CartTokenHeader = "X-Cart-Token"
+
+// owner of the cart of the request, signed in user and token of the
+// anonymous cart. Token longer than any issued token is ignored.
+func cartOwner(c *gin.Context) domain.CartOwner {
+	token := c.GetHeader(CartTokenHeader)
+	if len(token) > domain.MaxCartTokenLength {
+		token = ""
+	}
+
+	return domain.CartOwner{
+		UserID: pkgcontext.GetString(c.Request.Context(), pkgcontext.USER_ID),
+		Token:  token,
+	}
+}
+
+func (rh *requestHandler) ShowCart(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	cart, err := rh.service.GetCart(ctx, cartOwner(c))
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(cart)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) AddCartItem(c *gin.Context) {
+	ctx := c.Request.Context()
+	var request domain.CartItemRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	cart, err := rh.service.AddCartItem(ctx, cartOwner(c), &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(cart)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) UpdateCartItem(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpCartItemParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	var request domain.CartQuantityRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	cart, err := rh.service.UpdateCartItem(ctx, cartOwner(c), params.ProductID, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(cart)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) RemoveCartItem(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpCartItemParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	cart, err := rh.service.RemoveCartItem(ctx, cartOwner(c), params.ProductID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(cart)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) ClearCart(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	err := rh.service.ClearCart(ctx, cartOwner(c))
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(nil)
+	c.JSON(response.HttpCode, response)
+}
+
+// RevalidateCart accept the current prices of the lines
+func (rh *requestHandler) RevalidateCart(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	cart, err := rh.service.RevalidateCart(ctx, cartOwner(c))
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(cart)
+	c.JSON(response.HttpCode, response)
+}
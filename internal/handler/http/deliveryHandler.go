@@ -0,0 +1,108 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+)
+
+func (rh *requestHandler) CreateDeliveryZone(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	var request domain.DeliveryZoneRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	zone, err := rh.service.CreateDeliveryZone(ctx, params.ID, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(zone)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) ListDeliveryZones(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	zones, err := rh.service.ListDeliveryZones(ctx, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(zones)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) DeleteDeliveryZone(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpDeliveryZoneParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	err := rh.service.DeleteDeliveryZone(ctx, params.StoreID, params.ZoneID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(nil)
+	c.JSON(response.HttpCode, response)
+}
+
+// QuoteDelivery price the delivery of the items to a location, the store
+// must have a location and a zone covering the location
+func (rh *requestHandler) QuoteDelivery(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	var request domain.DeliveryQuoteRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	quote, err := rh.service.QuoteDelivery(ctx, params.ID, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(quote)
+	c.JSON(response.HttpCode, response)
+}
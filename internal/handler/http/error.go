@@ -0,0 +1,21 @@
+package http
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+)
+
+// renderError write the service error with the http status of its code,
+// message of 5xx error is replaced by the generic one and logged with its
+// cause
+func renderError(c *gin.Context, err errpkg.ErrorService) {
+	if errpkg.GetHttpStatus(err.GetCode()) >= 500 {
+		log.Printf("%s %s: %s, cause: %v", c.Request.Method, c.Request.URL.Path, err.Error(), err.GetCause())
+		err = errpkg.NewServiceError(err.GetCode(), "", errpkg.WithReason(err.GetReason()))
+	}
+
+	httppkg.BuildServiceErrorResponse(c, err)
+}
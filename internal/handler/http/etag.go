@@ -0,0 +1,65 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ijlik/store-app/internal/business/domain"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+)
+
+// ifMatch return the version expected by If-Match header, * give zero
+// version which skip the check. Missing header is rendered as 428 and
+// unknown entity tag as 412.
+func ifMatch(c *gin.Context) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader(httppkg.HeaderIfMatch))
+	if header == "" {
+		renderError(c, domain.ErrMissingIfMatch)
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	version, ok := httppkg.ParseETag(header)
+	if !ok {
+		renderError(c, domain.ErrVersionMismatch)
+		return 0, false
+	}
+
+	return version, true
+}
+
+// productETag tag the product with its version and the version of the
+// embedded store, so an edit of the store also change the product tag. The
+// price follows the started price schedules and the sale price the running
+// promotions on read, their values in cents are added so the tag change when
+// a schedule or a promotion start or end.
+func productETag(product *domain.Product) string {
+	var embedded []int
+	if product.Store != nil {
+		embedded = append(embedded, product.Store.Version)
+	}
+	embedded = append(embedded, int(math.Round(float64(product.Price)*100)))
+	if product.SalePrice != nil {
+		embedded = append(embedded, int(math.Round(float64(*product.SalePrice)*100)))
+	}
+
+	return httppkg.ETag(product.Version, embedded...)
+}
+
+// renderWithETag write data with etag, or 304 without body when the client
+// already has this representation
+func renderWithETag(c *gin.Context, etag string, data interface{}) {
+	c.Header(httppkg.HeaderETag, etag)
+
+	if httppkg.IfNoneMatch(c.GetHeader(httppkg.HeaderIfNoneMatch), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(data)
+	c.JSON(response.HttpCode, response)
+}
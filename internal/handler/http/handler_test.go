@@ -0,0 +1,902 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httpmiddleware "github.com/ijlik/store-app/pkg/http/middleware"
+	httppagination "github.com/ijlik/store-app/pkg/http/pagination"
+	"github.com/ijlik/store-app/pkg/mergepatch"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeService return err on every call, or a fixed result when err is nil
+type fakeService struct {
+	err       errpkg.ErrorService
+	price     float32
+	salePrice float32
+}
+
+func (f *fakeService) CreateStore(ctx context.Context, request *domain.StoreRequest) (*domain.Store, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Store{ID: "store-id", Name: request.Name}, nil
+}
+
+func (f *fakeService) GetStoreById(ctx context.Context, id string) (*domain.Store, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Store{ID: id, Version: 3}, nil
+}
+
+func (f *fakeService) GetStoreByUrl(ctx context.Context, url string) (*domain.Store, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Store{ID: "store-id", Url: "kopi-kenangan"}, nil
+}
+
+func (f *fakeService) ListNearbyStores(ctx context.Context, query *domain.HttpNearbyStoreQuery) ([]*domain.NearbyStore, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*domain.NearbyStore{}, nil
+}
+
+func (f *fakeService) ShowStores(ctx context.Context, pagination *httppagination.Pagination, query *domain.HttpStoreQuery) errpkg.ErrorService {
+	if f.err != nil {
+		return f.err
+	}
+	pagination.SetData([]*domain.Store{}, 0)
+	return nil
+}
+
+func (f *fakeService) UpdateStore(ctx context.Context, request *domain.StoreRequest, id string) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) PatchStore(ctx context.Context, patch *domain.StorePatch, id string) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) ShowStoreProducts(ctx context.Context, pagination *httppagination.Pagination, searchAndFilter *domain.SearchAndFilterProduct, id string) errpkg.ErrorService {
+	if f.err != nil {
+		return f.err
+	}
+	pagination.SetData([]*domain.Product{}, 0)
+	return nil
+}
+
+func (f *fakeService) ShowStoreHistory(ctx context.Context, pagination *httppagination.Pagination, id string) errpkg.ErrorService {
+	if f.err != nil {
+		return f.err
+	}
+	pagination.SetData([]*domain.AuditLog{}, 0)
+	return nil
+}
+
+func (f *fakeService) ShowProducts(ctx context.Context, pagination *httppagination.Pagination, searchAndFilter *domain.SearchAndFilterProduct) errpkg.ErrorService {
+	if f.err != nil {
+		return f.err
+	}
+	pagination.SetData([]*domain.Product{}, 0)
+	return nil
+}
+
+func (f *fakeService) CreateProduct(ctx context.Context, request *domain.ProductRequest) (*domain.Product, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Product{ID: "product-id", Name: request.Name}, nil
+}
+
+func (f *fakeService) GetProductByUrl(ctx context.Context, url string) (*domain.Product, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Product{ID: "product-id", Url: "kopi-susu"}, nil
+}
+
+func (f *fakeService) GetProductById(ctx context.Context, id string) (*domain.Product, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	product := &domain.Product{ID: id, Url: "kopi-susu", Price: 18000, Version: 2, Store: &domain.Store{ID: "store-id", Version: 3}}
+	if f.price > 0 {
+		product.Price = f.price
+	}
+	if f.salePrice > 0 {
+		product.SalePrice = &f.salePrice
+	}
+	return product, nil
+}
+
+func (f *fakeService) GetProductBySku(ctx context.Context, sku string) (*domain.Product, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Product{ID: "product-id", Url: "kopi-susu", Sku: sku}, nil
+}
+
+func (f *fakeService) GetProductsByIds(ctx context.Context, request *domain.ProductBatchGetRequest) ([]*domain.Product, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*domain.Product{}, nil
+}
+
+func (f *fakeService) UpdateProduct(ctx context.Context, request *domain.ProductRequest, id string) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) PatchProduct(ctx context.Context, patch *domain.ProductPatch, id string) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) DeleteProduct(ctx context.Context, id string, version int) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) ShowProductHistory(ctx context.Context, pagination *httppagination.Pagination, id string) errpkg.ErrorService {
+	if f.err != nil {
+		return f.err
+	}
+	pagination.SetData([]*domain.AuditLog{}, 0)
+	return nil
+}
+
+func (f *fakeService) ShowPriceHistory(ctx context.Context, pagination *httppagination.Pagination, productId string) errpkg.ErrorService {
+	if f.err != nil {
+		return f.err
+	}
+	pagination.SetData([]*domain.PriceHistory{}, 0)
+	return nil
+}
+
+func (f *fakeService) ShowPriceSchedules(ctx context.Context, productId string) ([]*domain.PriceSchedule, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*domain.PriceSchedule{}, nil
+}
+
+func (f *fakeService) CreatePriceSchedule(ctx context.Context, request *domain.PriceScheduleRequest, productId string) (*domain.PriceSchedule, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.PriceSchedule{ID: "schedule-id", Price: request.Price, EffectiveFrom: request.EffectiveFrom, Status: domain.PriceSchedulePending}, nil
+}
+
+func (f *fakeService) CancelPriceSchedule(ctx context.Context, productId string, scheduleId string) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) ApplyPriceSchedules(ctx context.Context, now time.Time) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) CreatePromotion(ctx context.Context, request *domain.PromotionRequest) (*domain.Promotion, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Promotion{ID: "promotion-id", Name: request.Name, Code: request.Code}, nil
+}
+
+func (f *fakeService) GetPromotionById(ctx context.Context, id string) (*domain.Promotion, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Promotion{ID: id}, nil
+}
+
+func (f *fakeService) ShowPromotions(ctx context.Context, pagination *httppagination.Pagination) errpkg.ErrorService {
+	if f.err != nil {
+		return f.err
+	}
+	pagination.SetData([]*domain.Promotion{}, 0)
+	return nil
+}
+
+func (f *fakeService) DeactivatePromotion(ctx context.Context, id string) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) ValidateCoupon(ctx context.Context, request *domain.CouponValidateRequest) (*domain.CouponValidation, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.CouponValidation{Promotion: &domain.Promotion{ID: "promotion-id", Code: request.Code}, Evaluation: &domain.BasketEvaluation{}}, nil
+}
+
+func (f *fakeService) GetCart(ctx context.Context, owner domain.CartOwner) (*domain.Cart, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Cart{ID: "cart-id", Token: owner.Token, Stores: []domain.CartStore{}}, nil
+}
+
+func (f *fakeService) AddCartItem(ctx context.Context, owner domain.CartOwner, request *domain.CartItemRequest) (*domain.Cart, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Cart{ID: "cart-id", Token: "new-token", ItemCount: request.Quantity}, nil
+}
+
+func (f *fakeService) UpdateCartItem(ctx context.Context, owner domain.CartOwner, productId string, request *domain.CartQuantityRequest) (*domain.Cart, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Cart{ID: "cart-id", ItemCount: request.Quantity}, nil
+}
+
+func (f *fakeService) RemoveCartItem(ctx context.Context, owner domain.CartOwner, productId string) (*domain.Cart, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Cart{ID: "cart-id"}, nil
+}
+
+func (f *fakeService) ClearCart(ctx context.Context, owner domain.CartOwner) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) RevalidateCart(ctx context.Context, owner domain.CartOwner) (*domain.Cart, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Cart{ID: "cart-id"}, nil
+}
+
+func (f *fakeService) ExpireCarts(ctx context.Context, now time.Time) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) Checkout(ctx context.Context, owner domain.CartOwner, request *domain.CheckoutRequest) (*domain.Order, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Order{ID: "order-id", StoreID: request.StoreID, Status: domain.OrderPending}, nil
+}
+
+func (f *fakeService) GetOrder(ctx context.Context, userId string, id string) (*domain.Order, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Order{ID: id, Status: domain.OrderPending}, nil
+}
+
+func (f *fakeService) ShowOrders(ctx context.Context, pagination *httppagination.Pagination, userId string, status string) errpkg.ErrorService {
+	if f.err != nil {
+		return f.err
+	}
+	pagination.SetData([]*domain.Order{}, 0)
+	return nil
+}
+
+func (f *fakeService) CancelOrder(ctx context.Context, userId string, id string, request *domain.OrderCancelRequest) (*domain.Order, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Order{ID: id, Status: domain.OrderCancelled}, nil
+}
+
+func (f *fakeService) GetStoreOrder(ctx context.Context, storeId string, id string) (*domain.Order, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Order{ID: id, StoreID: storeId, Status: domain.OrderPaid}, nil
+}
+
+func (f *fakeService) ShowStoreOrders(ctx context.Context, pagination *httppagination.Pagination, storeId string, status string) errpkg.ErrorService {
+	if f.err != nil {
+		return f.err
+	}
+	pagination.SetData([]*domain.Order{}, 0)
+	return nil
+}
+
+func (f *fakeService) UpdateOrderStatus(ctx context.Context, storeId string, id string, request *domain.OrderStatusRequest) (*domain.Order, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Order{ID: id, StoreID: storeId, Status: request.Status}, nil
+}
+
+func (f *fakeService) CancelExpiredOrders(ctx context.Context, now time.Time) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) SetProductStock(ctx context.Context, id string, request *domain.ProductStockRequest) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) CreatePayment(ctx context.Context, userId string, orderId string) (*domain.Payment, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Payment{ID: "payment-id", OrderID: orderId, Status: domain.PaymentPending, ClientSecret: "secret"}, nil
+}
+
+func (f *fakeService) HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) ReconcilePayments(ctx context.Context, now time.Time) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) RefundOrder(ctx context.Context, storeId string, orderId string, request *domain.RefundRequest) (*domain.Refund, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Refund{ID: "refund-id", OrderID: orderId, Reason: request.Reason, Status: domain.RefundSucceeded}, nil
+}
+
+func (f *fakeService) ListRefunds(ctx context.Context, storeId string, orderId string) ([]*domain.Refund, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*domain.Refund{}, nil
+}
+
+func (f *fakeService) CreateTaxRule(ctx context.Context, request *domain.TaxRuleRequest) (*domain.TaxRule, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.TaxRule{ID: "tax-rule-id", Name: request.Name, Rate: request.Rate, Rounding: request.Rounding}, nil
+}
+
+func (f *fakeService) ListTaxRules(ctx context.Context, storeId string) ([]*domain.TaxRule, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*domain.TaxRule{}, nil
+}
+
+func (f *fakeService) DeleteTaxRule(ctx context.Context, id string) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) CreateDeliveryZone(ctx context.Context, storeId string, request *domain.DeliveryZoneRequest) (*domain.DeliveryZone, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.DeliveryZone{ID: "zone-id", StoreID: storeId, Name: request.Name, Type: request.Type, RadiusKm: request.RadiusKm, Rates: request.Rates}, nil
+}
+
+func (f *fakeService) ListDeliveryZones(ctx context.Context, storeId string) ([]*domain.DeliveryZone, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*domain.DeliveryZone{}, nil
+}
+
+func (f *fakeService) DeleteDeliveryZone(ctx context.Context, storeId string, id string) errpkg.ErrorService {
+	return f.err
+}
+
+func (f *fakeService) QuoteDelivery(ctx context.Context, storeId string, request *domain.DeliveryQuoteRequest) (*domain.DeliveryQuote, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.DeliveryQuote{ZoneID: "zone-id", Fee: 10000}, nil
+}
+
+func (f *fakeService) UpdatePickupSettings(ctx context.Context, storeId string, request *domain.PickupSettingsRequest) (*domain.PickupSettings, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.PickupSettings{StoreID: storeId, SlotMinutes: request.SlotMinutes, Capacity: request.Capacity, DaysAhead: request.DaysAhead}, nil
+}
+
+func (f *fakeService) GetPickupSettings(ctx context.Context, storeId string) (*domain.PickupSettings, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.PickupSettings{StoreID: storeId, SlotMinutes: 30, Capacity: 5, DaysAhead: 1}, nil
+}
+
+func (f *fakeService) ListPickupSlots(ctx context.Context, storeId string) ([]domain.PickupSlot, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []domain.PickupSlot{}, nil
+}
+
+func (f *fakeService) BookPickupSlot(ctx context.Context, userId string, storeId string, request *domain.PickupBookingRequest) (*domain.PickupBooking, errpkg.ErrorService) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.PickupBooking{ID: "booking-id", StoreID: storeId, OrderID: request.OrderID, StartsAt: request.StartsAt, Status: domain.PickupBooked}, nil
+}
+
+// signedInService fail like the service when the request has no user and
+// record the user of the customer flows
+type signedInService struct {
+	*fakeService
+	users []string
+}
+
+func (s *signedInService) user(userId string) errpkg.ErrorService {
+	s.users = append(s.users, userId)
+	if userId == "" {
+		return domain.ErrSignInRequired
+	}
+	return nil
+}
+
+func (s *signedInService) Checkout(ctx context.Context, owner domain.CartOwner, request *domain.CheckoutRequest) (*domain.Order, errpkg.ErrorService) {
+	if err := s.user(owner.UserID); err != nil {
+		return nil, err
+	}
+	return s.fakeService.Checkout(ctx, owner, request)
+}
+
+func (s *signedInService) ShowOrders(ctx context.Context, pagination *httppagination.Pagination, userId string, status string) errpkg.ErrorService {
+	if err := s.user(userId); err != nil {
+		return err
+	}
+	return s.fakeService.ShowOrders(ctx, pagination, userId, status)
+}
+
+func (s *signedInService) CancelOrder(ctx context.Context, userId string, id string, request *domain.OrderCancelRequest) (*domain.Order, errpkg.ErrorService) {
+	if err := s.user(userId); err != nil {
+		return nil, err
+	}
+	return s.fakeService.CancelOrder(ctx, userId, id, request)
+}
+
+func (s *signedInService) CreatePayment(ctx context.Context, userId string, orderId string) (*domain.Payment, errpkg.ErrorService) {
+	if err := s.user(userId); err != nil {
+		return nil, err
+	}
+	return s.fakeService.CreatePayment(ctx, userId, orderId)
+}
+
+func (s *signedInService) BookPickupSlot(ctx context.Context, userId string, storeId string, request *domain.PickupBookingRequest) (*domain.PickupBooking, errpkg.ErrorService) {
+	if err := s.user(userId); err != nil {
+		return nil, err
+	}
+	return s.fakeService.BookPickupSlot(ctx, userId, storeId, request)
+}
+
+func newTestRouter(service *fakeService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	// the store order routes require a staff token
+	router.Use(func(c *gin.Context) {
+		httpmiddleware.SetTokenData(c, map[string]interface{}{"user_id": "staff-1", "role": staffRole})
+	})
+	routeHandler(router, requestHandler{service: service})
+
+	return router
+}
+
+const (
+	validStoreBody   = `{"name":"Kopi Kenangan","address":"Jakarta","phone":"+6281234567890","operational_time_start":8,"operational_time_end":20}`
+	validProductBody = `{"name":"Kopi Susu","price":18000,"description":"Es kopi susu","store_id":"0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11"}`
+	validPromoBody   = `{"name":"Weekend","code":"hemat10","discount_type":"percentage","discount_value":10,"scope":"category","scope_id":"coffee","starts_at":"2024-01-01T00:00:00Z"}`
+	validCouponBody  = `{"code":"HEMAT10","items":[{"product_id":"0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11","quantity":2}]}`
+)
+
+type endpoint struct {
+	name   string
+	method string
+	path   string
+	body   string
+}
+
+var endpoints = []endpoint{
+	{"create store", http.MethodPost, "/store", validStoreBody},
+	{"show store", http.MethodGet, "/store/store-id", ""},
+	{"show store by url", http.MethodGet, "/store/by-url/kopi-kenangan", ""},
+	{"update store", http.MethodPut, "/store/store-id", validStoreBody},
+	{"patch store", http.MethodPatch, "/store/store-id", `{"phone":"+6281234567890"}`},
+	{"show store products", http.MethodGet, "/store/store-id/products", ""},
+	{"show store history", http.MethodGet, "/store/store-id/history?limit=5&page=2", ""},
+	{"list products", http.MethodGet, "/product", ""},
+	{"create product", http.MethodPost, "/product", validProductBody},
+	{"show product", http.MethodGet, "/product/kopi-susu", ""},
+	{"show product by id", http.MethodGet, "/product/id/product-id", ""},
+	{"show product by sku", http.MethodGet, "/product/sku/KS-001", ""},
+	{"batch get products", http.MethodPost, "/product/batch-get", `{"ids":["0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11"]}`},
+	{"update product", http.MethodPut, "/product/product-id", validProductBody},
+	{"patch product", http.MethodPatch, "/product/product-id", `{"price":20000}`},
+	{"delete product", http.MethodDelete, "/product/product-id", ""},
+	{"show product history", http.MethodGet, "/product/0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11/history", ""},
+	{"show price history", http.MethodGet, "/product/0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11/price-history", ""},
+	{"show price schedules", http.MethodGet, "/product/0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11/price-schedules", ""},
+	{"create price schedule", http.MethodPost, "/product/product-id/price-schedules", `{"price":15000,"effective_from":"2099-01-01T00:00:00Z","effective_to":"2099-01-08T00:00:00Z"}`},
+	{"cancel price schedule", http.MethodDelete, "/product/product-id/price-schedules/schedule-id", ""},
+	{"create promotion", http.MethodPost, "/promotion", validPromoBody},
+	{"list promotions", http.MethodGet, "/promotion?limit=5", ""},
+	{"show promotion", http.MethodGet, "/promotion/promotion-id", ""},
+	{"deactivate promotion", http.MethodDelete, "/promotion/promotion-id", ""},
+	{"validate coupon", http.MethodPost, "/coupon/validate", validCouponBody},
+	{"show cart", http.MethodGet, "/cart", ""},
+	{"clear cart", http.MethodDelete, "/cart", ""},
+	{"add cart item", http.MethodPost, "/cart/items", `{"product_id":"0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11","quantity":2}`},
+	{"update cart item", http.MethodPut, "/cart/items/0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11", `{"quantity":3}`},
+	{"remove cart item", http.MethodDelete, "/cart/items/0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11", ""},
+	{"revalidate cart", http.MethodPost, "/cart/revalidate", ""},
+	{"checkout", http.MethodPost, "/checkout", `{"store_id":"0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11"}`},
+	{"list orders", http.MethodGet, "/order?status=pending", ""},
+	{"show order", http.MethodGet, "/order/order-id", ""},
+	{"cancel order", http.MethodPost, "/order/order-id/cancel", ""},
+	{"list store orders", http.MethodGet, "/store/store-id/orders", ""},
+	{"show store order", http.MethodGet, "/store/store-id/orders/order-id", ""},
+	{"update order status", http.MethodPost, "/store/store-id/orders/order-id/status", `{"status":"preparing"}`},
+	{"set product stock", http.MethodPut, "/product/product-id/stock", `{"stock":10}`},
+	{"create payment", http.MethodPost, "/order/order-id/payment", ""},
+	{"payment webhook", http.MethodPost, "/payment/webhook", `{"id":"evt_1","type":"payment.authorized"}`},
+	{"refund order", http.MethodPost, "/store/store-id/orders/order-id/refunds", `{"reason":"wrong item","restock":true,"items":[{"order_item_id":1,"quantity":1}]}`},
+	{"list refunds", http.MethodGet, "/store/store-id/orders/order-id/refunds", ""},
+	{"create tax rule", http.MethodPost, "/tax-rule", `{"name":"PPN","region":"ID-JK","rate":11,"rounding":"total"}`},
+	{"list tax rules", http.MethodGet, "/tax-rule?store_id=0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11", ""},
+	{"delete tax rule", http.MethodDelete, "/tax-rule/tax-rule-id", ""},
+	{"list nearby stores", http.MethodGet, "/store/nearby?lat=-6.1754&lng=106.8272&radiusKm=3", ""},
+	{"list stores", http.MethodGet, "/store?city=Bandung&province=Jawa%20Barat", ""},
+	{"create store with postal address", http.MethodPost, "/store", `{"name":"Kopi","postal_address":{"street":"Jl. Braga 10","city":"Bandung","province":"Jawa Barat","postal_code":"40111","country":"ID"},"phone":"+6281234567890"}`},
+	{"patch store postal address", http.MethodPatch, "/store/store-id", `{"postal_address":{"city":"Bandung"}}`},
+	{"create delivery zone", http.MethodPost, "/store/store-id/delivery-zones", `{"name":"Jakarta","type":"radius","radius_km":5,"rates":[{"max_distance_km":3,"fee":10000},{"fee":15000}]}`},
+	{"list delivery zones", http.MethodGet, "/store/store-id/delivery-zones", ""},
+	{"delete delivery zone", http.MethodDelete, "/store/store-id/delivery-zones/zone-id", ""},
+	{"quote delivery", http.MethodPost, "/store/store-id/delivery/quote", `{"location":{"lat":-6.2,"lng":106.8},"items":[{"product_id":"0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11","quantity":2}]}`},
+	{"update pickup settings", http.MethodPut, "/store/store-id/pickup-settings", `{"slot_minutes":30,"capacity":5}`},
+	{"show pickup settings", http.MethodGet, "/store/store-id/pickup-settings", ""},
+	{"list pickup slots", http.MethodGet, "/store/store-id/pickup-slots", ""},
+	{"book pickup slot", http.MethodPost, "/store/store-id/pickup-slots/book", `{"order_id":"0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11","starts_at":"2024-01-01T09:00:00+07:00"}`},
+}
+
+func serve(router *gin.Engine, e endpoint) *httptest.ResponseRecorder {
+	var req *http.Request
+	if e.body != "" {
+		req = httptest.NewRequest(e.method, e.path, strings.NewReader(e.body))
+	} else {
+		req = httptest.NewRequest(e.method, e.path, nil)
+	}
+	switch e.method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		req.Header.Set("If-Match", `"1"`)
+	}
+	if e.method == http.MethodPatch {
+		req.Header.Set("Content-Type", mergepatch.ContentType)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestHandlerServiceErrorStatus(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             errpkg.ErrorService
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{"success", nil, http.StatusOK, "Success"},
+		{"not found", domain.ErrStoreNotFound, http.StatusNotFound, "store not found"},
+		{"bad request", errpkg.DefaultServiceError(errpkg.ErrBadRequest, "invalid value format"), http.StatusBadRequest, "invalid value format"},
+		{"conflict", errpkg.DefaultServiceError(errpkg.ErrConflict, "store with the same name already exists"), http.StatusConflict, "store with the same name already exists"},
+		{"retryable", errpkg.DefaultServiceError(errpkg.ErrRetryable, "pq: could not serialize access"), http.StatusServiceUnavailable, "Temporary Failure, Please Retry"},
+		{"internal", errpkg.DefaultServiceError(errpkg.ErrInternal, "pq: relation \"stores\" does not exist"), http.StatusInternalServerError, "Internal Error"},
+	}
+
+	for _, tt := range tests {
+		router := newTestRouter(&fakeService{err: tt.err})
+		for _, e := range endpoints {
+			t.Run(tt.name+"/"+e.name, func(t *testing.T) {
+				w := serve(router, e)
+				assert.Equal(t, tt.expectedStatus, w.Code)
+
+				var resp map[string]interface{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.expectedMessage, resp["message"])
+				assert.Equal(t, errpkg.GetCode(errCode(tt.err)), resp["code"])
+			})
+		}
+	}
+}
+
+func errCode(err errpkg.ErrorService) errpkg.ErrCode {
+	if err == nil {
+		return 0
+	}
+	return err.GetCode()
+}
+
+func TestHandlerRequestErrorStatus(t *testing.T) {
+	router := newTestRouter(&fakeService{})
+
+	tests := []struct {
+		endpoint       endpoint
+		expectedStatus int
+		expectedField  string
+	}{
+		{endpoint{"create store invalid json", http.MethodPost, "/store", `{"name":`}, http.StatusBadRequest, ""},
+		{endpoint{"create store missing name", http.MethodPost, "/store", `{"address":"Jakarta","phone":"+6281234567890"}`}, http.StatusBadRequest, "name"},
+		{endpoint{"update store invalid hour", http.MethodPut, "/store/store-id", `{"name":"Kopi","address":"Jakarta","phone":"+6281234567890","operational_time_start":25}`}, http.StatusBadRequest, "operational_time_start"},
+		{endpoint{"create product invalid json", http.MethodPost, "/product", `[]`}, http.StatusBadRequest, ""},
+		{endpoint{"create product missing store", http.MethodPost, "/product", `{"name":"Kopi Susu","price":18000,"description":"Es kopi susu"}`}, http.StatusBadRequest, "store_id"},
+		{endpoint{"update product missing description", http.MethodPut, "/product/product-id", `{"name":"Kopi Susu","price":18000,"store_id":"0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11"}`}, http.StatusBadRequest, "description"},
+		{endpoint{"batch get products empty ids", http.MethodPost, "/product/batch-get", `{"ids":[]}`}, http.StatusBadRequest, "ids"},
+		{endpoint{"batch get products invalid id", http.MethodPost, "/product/batch-get", `{"ids":["abc"]}`}, http.StatusBadRequest, "ids[0]"},
+		{endpoint{"patch store not object", http.MethodPatch, "/store/store-id", `[]`}, http.StatusBadRequest, ""},
+		{endpoint{"patch store null name", http.MethodPatch, "/store/store-id", `{"name":null}`}, http.StatusBadRequest, "name"},
+		{endpoint{"patch product invalid price", http.MethodPatch, "/product/product-id", `{"price":"free"}`}, http.StatusBadRequest, "price"},
+		{endpoint{"patch product unknown field", http.MethodPatch, "/product/product-id", `{"stock":1}`}, http.StatusBadRequest, "stock"},
+		{endpoint{"list products invalid query", http.MethodGet, "/product?limit=abc", ""}, http.StatusBadRequest, ""},
+		{endpoint{"create promotion invalid discount type", http.MethodPost, "/promotion", `{"name":"Weekend","discount_type":"free","discount_value":10,"scope":"category","scope_id":"coffee","starts_at":"2024-01-01T00:00:00Z"}`}, http.StatusBadRequest, "discount_type"},
+		{endpoint{"add cart item invalid product", http.MethodPost, "/cart/items", `{"product_id":"abc","quantity":1}`}, http.StatusBadRequest, "product_id"},
+		{endpoint{"update cart item zero quantity", http.MethodPut, "/cart/items/0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11", `{"quantity":0}`}, http.StatusBadRequest, "quantity"},
+		{endpoint{"validate coupon invalid quantity", http.MethodPost, "/coupon/validate", `{"code":"HEMAT10","items":[{"product_id":"0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11","quantity":0}]}`}, http.StatusBadRequest, "items[0].quantity"},
+		{endpoint{"checkout missing store", http.MethodPost, "/checkout", `{}`}, http.StatusBadRequest, "store_id"},
+		{endpoint{"list orders invalid status", http.MethodGet, "/order?status=lost", ""}, http.StatusBadRequest, "status"},
+		{endpoint{"update order invalid status", http.MethodPost, "/store/store-id/orders/order-id/status", `{"status":"shipped"}`}, http.StatusBadRequest, "status"},
+		{endpoint{"update order paid status", http.MethodPost, "/store/store-id/orders/order-id/status", `{"status":"paid"}`}, http.StatusBadRequest, "status"},
+		{endpoint{"refund order missing reason", http.MethodPost, "/store/store-id/orders/order-id/refunds", `{}`}, http.StatusBadRequest, "reason"},
+		{endpoint{"refund order invalid quantity", http.MethodPost, "/store/store-id/orders/order-id/refunds", `{"reason":"wrong item","items":[{"order_item_id":1,"quantity":0}]}`}, http.StatusBadRequest, "items[0].quantity"},
+		{endpoint{"create tax rule invalid rate", http.MethodPost, "/tax-rule", `{"name":"PPN","rate":150}`}, http.StatusBadRequest, "rate"},
+		{endpoint{"list tax rules invalid store", http.MethodGet, "/tax-rule?store_id=abc", ""}, http.StatusBadRequest, "store_id"},
+		{endpoint{"create delivery zone invalid type", http.MethodPost, "/store/store-id/delivery-zones", `{"name":"Jakarta","type":"circle","rates":[{"fee":10000}]}`}, http.StatusBadRequest, "type"},
+		{endpoint{"create delivery zone small polygon", http.MethodPost, "/store/store-id/delivery-zones", `{"name":"Jakarta","type":"polygon","polygon":[{"lat":-6.2,"lng":106.8}],"rates":[{"fee":10000}]}`}, http.StatusBadRequest, "polygon"},
+		{endpoint{"quote delivery invalid location", http.MethodPost, "/store/store-id/delivery/quote", `{"location":{"lat":-95,"lng":106.8},"items":[{"product_id":"0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11","quantity":1}]}`}, http.StatusBadRequest, "location.lat"},
+		{endpoint{"update pickup settings invalid capacity", http.MethodPut, "/store/store-id/pickup-settings", `{"slot_minutes":30,"capacity":0}`}, http.StatusBadRequest, "capacity"},
+		{endpoint{"book pickup slot missing starts at", http.MethodPost, "/store/store-id/pickup-slots/book", `{"order_id":"0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11"}`}, http.StatusBadRequest, "starts_at"},
+		{endpoint{"list nearby stores missing lng", http.MethodGet, "/store/nearby?lat=-6.1754", ""}, http.StatusBadRequest, "lng"},
+		{endpoint{"list nearby stores invalid lat", http.MethodGet, "/store/nearby?lat=91&lng=106.8272", ""}, http.StatusBadRequest, "lat"},
+		{endpoint{"list nearby stores radius too large", http.MethodGet, "/store/nearby?lat=-6.1754&lng=106.8272&radiusKm=500", ""}, http.StatusBadRequest, "radiusKm"},
+		{endpoint{"list nearby stores invalid query", http.MethodGet, "/store/nearby?lat=north", ""}, http.StatusBadRequest, ""},
+		{endpoint{"list stores invalid query", http.MethodGet, "/store?limit=abc", ""}, http.StatusBadRequest, ""},
+		{endpoint{"create store invalid country", http.MethodPost, "/store", `{"name":"Kopi","postal_address":{"street":"Jl. Braga 10","city":"Bandung","province":"Jawa Barat","country":"IDN"},"phone":"+6281234567890"}`}, http.StatusBadRequest, "postal_address.country"},
+		{endpoint{"patch store unknown address part", http.MethodPatch, "/store/store-id", `{"postal_address":{"zip":"40111"}}`}, http.StatusBadRequest, "postal_address.zip"},
+		{endpoint{"set product negative stock", http.MethodPut, "/product/product-id/stock", `{"stock":-1}`}, http.StatusBadRequest, "stock"},
+		{endpoint{"product history by url", http.MethodGet, "/product/kopi-susu/history", ""}, http.StatusBadRequest, "id"},
+		{endpoint{"price history by url", http.MethodGet, "/product/kopi-susu/price-history", ""}, http.StatusBadRequest, "id"},
+		{endpoint{"price schedules by url", http.MethodGet, "/product/kopi-susu/price-schedules", ""}, http.StatusBadRequest, "id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint.name, func(t *testing.T) {
+			w := serve(router, tt.endpoint)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var resp struct {
+				Errors []errpkg.FieldError `json:"errors"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			if tt.expectedField != "" {
+				assert.Equal(t, tt.expectedField, resp.Errors[0].Field)
+			}
+		})
+	}
+}
+
+func TestShowStoreByUrlRedirect(t *testing.T) {
+	router := newTestRouter(&fakeService{})
+
+	w := serve(router, endpoint{"old url", http.MethodGet, "/store/by-url/kopi-lama", ""})
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/store/by-url/kopi-kenangan", w.Header().Get("Location"))
+}
+
+func TestShowProductRedirect(t *testing.T) {
+	router := newTestRouter(&fakeService{})
+
+	w := serve(router, endpoint{"old url", http.MethodGet, "/product/kopi-susu-1675209600", ""})
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/product/kopi-susu", w.Header().Get("Location"))
+}
+
+func TestShowStoreETag(t *testing.T) {
+	router := newTestRouter(&fakeService{})
+
+	w := serve(router, endpoint{"show store", http.MethodGet, "/store/store-id", ""})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	req := httptest.NewRequest(http.MethodGet, "/store/store-id", nil)
+	req.Header.Set("If-None-Match", `"3"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Empty(t, w.Body.String())
+}
+
+func TestShowProductETag(t *testing.T) {
+	router := newTestRouter(&fakeService{})
+
+	w := serve(router, endpoint{"show product by id", http.MethodGet, "/product/id/product-id", ""})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2.3.1800000"`, w.Header().Get("ETag"))
+
+	// store edited since the client fetched the product
+	req := httptest.NewRequest(http.MethodGet, "/product/id/product-id", nil)
+	req.Header.Set("If-None-Match", `"2.2.1800000"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/product/id/product-id", nil)
+	req.Header.Set("If-None-Match", `"2.3.1800000"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// a price schedule started since the client fetched the product
+	router = newTestRouter(&fakeService{price: 16000})
+	req = httptest.NewRequest(http.MethodGet, "/product/id/product-id", nil)
+	req.Header.Set("If-None-Match", `"2.3.1800000"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2.3.1600000"`, w.Header().Get("ETag"))
+
+	// a promotion started since the client fetched the product
+	router = newTestRouter(&fakeService{salePrice: 15000})
+	w = serve(router, endpoint{"show product by id", http.MethodGet, "/product/id/product-id", ""})
+	assert.Equal(t, `"2.3.1800000.1500000"`, w.Header().Get("ETag"))
+
+	req = httptest.NewRequest(http.MethodGet, "/product/id/product-id", nil)
+	req.Header.Set("If-None-Match", `"2.3.1800000"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the product tag is accepted by If-Match
+	req = httptest.NewRequest(http.MethodDelete, "/product/product-id", nil)
+	req.Header.Set("If-Match", `"2.3.1800000"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestIfMatchRequired(t *testing.T) {
+	router := newTestRouter(&fakeService{})
+
+	tests := []struct {
+		name           string
+		ifMatch        string
+		expectedStatus int
+	}{
+		{"missing", "", http.StatusPreconditionRequired},
+		{"weak", `W/"1"`, http.StatusPreconditionFailed},
+		{"any", "*", http.StatusOK},
+		{"version", `"1"`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/product/product-id", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestMergePatchContentType(t *testing.T) {
+	router := newTestRouter(&fakeService{})
+
+	tests := []struct {
+		name           string
+		contentType    string
+		expectedStatus int
+	}{
+		{"missing", "", http.StatusUnsupportedMediaType},
+		{"json", "application/json", http.StatusUnsupportedMediaType},
+		{"merge patch", mergepatch.ContentType, http.StatusOK},
+		{"merge patch with charset", mergepatch.ContentType + "; charset=utf-8", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/product/product-id", strings.NewReader(`{"price":20000}`))
+			req.Header.Set("If-Match", `"1"`)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestSignedInCustomerFlows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const secret = "test-secret"
+	token, err := httpmiddleware.SignToken(secret, map[string]interface{}{"user_id": "user-1"})
+	assert.NoError(t, err)
+
+	flows := []endpoint{
+		{"checkout", http.MethodPost, "/checkout", `{"store_id":"0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11"}`},
+		{"list orders", http.MethodGet, "/order?status=pending", ""},
+		{"cancel order", http.MethodPost, "/order/order-id/cancel", ""},
+		{"create payment", http.MethodPost, "/order/order-id/payment", ""},
+		{"book pickup slot", http.MethodPost, "/store/store-id/pickup-slots/book", `{"order_id":"0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11","starts_at":"2024-01-01T09:00:00+07:00"}`},
+	}
+
+	for _, e := range flows {
+		t.Run(e.name, func(t *testing.T) {
+			service := &signedInService{fakeService: &fakeService{}}
+			router := gin.New()
+			router.Use(httpmiddleware.WithAuth(secret), httpmiddleware.WithUser("user_id"))
+			routeHandler(router, requestHandler{service: service})
+
+			// anonymous request
+			w := serve(router, e)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+			req := httptest.NewRequest(e.method, e.path, strings.NewReader(e.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, []string{"", "user-1"}, service.users)
+		})
+	}
+}
+
+func TestStoreOrderRoutesRequireStaff(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const secret = "test-secret"
+	customer, err := httpmiddleware.SignToken(secret, map[string]interface{}{"user_id": "user-1"})
+	assert.NoError(t, err)
+	staff, err := httpmiddleware.SignToken(secret, map[string]interface{}{"user_id": "staff-1", "role": staffRole})
+	assert.NoError(t, err)
+
+	routes := []endpoint{
+		{"list store orders", http.MethodGet, "/store/store-id/orders", ""},
+		{"show store order", http.MethodGet, "/store/store-id/orders/order-id", ""},
+		{"update order status", http.MethodPost, "/store/store-id/orders/order-id/status", `{"status":"preparing"}`},
+		{"refund order", http.MethodPost, "/store/store-id/orders/order-id/refunds", `{"reason":"wrong item"}`},
+		{"list refunds", http.MethodGet, "/store/store-id/orders/order-id/refunds", ""},
+	}
+
+	router := gin.New()
+	router.Use(httpmiddleware.WithAuth(secret))
+	routeHandler(router, requestHandler{service: &fakeService{}})
+
+	for _, e := range routes {
+		t.Run(e.name, func(t *testing.T) {
+			w := serve(router, e)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+			for token, expected := range map[string]int{customer: http.StatusForbidden, staff: http.StatusOK} {
+				req := httptest.NewRequest(e.method, e.path, strings.NewReader(e.body))
+				req.Header.Set("Authorization", "Bearer "+token)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				assert.Equal(t, expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestRouteSegmentsReserved(t *testing.T) {
+	router := newTestRouter(&fakeService{})
+
+	assert.ElementsMatch(t, []string{"nearby", "by-url", "id", "sku", "batch-get"}, routeSegments(router.Routes(), "/store/", "/product/"))
+	assert.Equal(t, "batch-get-1", domain.CreateSlug("Batch Get"))
+	assert.Equal(t, "nearby-1", domain.CreateSlug("Nearby"))
+	assert.Equal(t, "history", domain.CreateSlug("History"))
+}
@@ -5,8 +5,15 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	configdata "github.com/ijlik/store-app/pkg/config/data"
+	errpkg "github.com/ijlik/store-app/pkg/error"
 	httppkg "github.com/ijlik/store-app/pkg/http"
+	httpmiddleware "github.com/ijlik/store-app/pkg/http/middleware"
+	"github.com/ijlik/store-app/pkg/mergepatch"
+	"io"
+	"mime"
+	"strings"
 
+	"github.com/ijlik/store-app/internal/business/domain"
 	"github.com/ijlik/store-app/internal/business/port"
 )
 
@@ -37,20 +44,118 @@ func HandlerHttp(
 	httppkg.Serve(router, addr)
 }
 
+// staffRole is the role token claim of the store staff, the order routes of a
+// store move orders and money so they require it
+const staffRole = "staff"
+
 func routeHandler(router *gin.Engine, rh requestHandler) {
+	staff := httpmiddleware.RequireRole("role", staffRole)
+
 	storeRoute := router.Group("/store")
 	storeRoute.POST("", rh.CreateStore)
+	storeRoute.GET("", rh.ListStores)
+	storeRoute.GET("/nearby", rh.ListNearbyStores)
 	storeRoute.GET("/:id", rh.ShowStore)
+	storeRoute.GET("/by-url/:url", rh.ShowStoreByUrl)
 	storeRoute.PUT("/:id", rh.UpdateStore)
+	storeRoute.PATCH("/:id", rh.PatchStore)
 	storeRoute.GET("/:id/products", rh.ShowStoreProducts)
+	storeRoute.GET("/:id/history", rh.ShowStoreHistory)
+	storeRoute.GET("/:id/orders", staff, rh.ListStoreOrders)
+	storeRoute.GET("/:id/orders/:orderId", staff, rh.ShowStoreOrder)
+	storeRoute.POST("/:id/orders/:orderId/status", staff, rh.UpdateOrderStatus)
+	storeRoute.GET("/:id/orders/:orderId/refunds", staff, rh.ListRefunds)
+	storeRoute.POST("/:id/orders/:orderId/refunds", staff, rh.RefundOrder)
+	storeRoute.POST("/:id/delivery-zones", rh.CreateDeliveryZone)
+	storeRoute.GET("/:id/delivery-zones", rh.ListDeliveryZones)
+	storeRoute.DELETE("/:id/delivery-zones/:zoneId", rh.DeleteDeliveryZone)
+	storeRoute.POST("/:id/delivery/quote", rh.QuoteDelivery)
+	storeRoute.PUT("/:id/pickup-settings", rh.UpdatePickupSettings)
+	storeRoute.GET("/:id/pickup-settings", rh.ShowPickupSettings)
+	storeRoute.GET("/:id/pickup-slots", rh.ListPickupSlots)
+	storeRoute.POST("/:id/pickup-slots/book", rh.BookPickupSlot)
 
 	productRoute := router.Group("/product")
 	productRoute.GET("", rh.ListProducts)
 	productRoute.POST("", rh.CreateProduct)
 	productRoute.GET("/:url", rh.ShowProduct)
+	productRoute.GET("/id/:id", rh.ShowProductById)
+	productRoute.GET("/sku/:sku", rh.ShowProductBySku)
+	productRoute.POST("/batch-get", rh.BatchGetProducts)
 	productRoute.PUT("/:id", rh.UpdateProduct)
+	productRoute.PATCH("/:id", rh.PatchProduct)
 	productRoute.DELETE("/:id", rh.DeleteProduct)
+	// gin require a wildcard to keep one name at the same position across
+	// routes of a method, so the GET routes nested under a product name the
+	// product id segment url like ShowProduct. Their handlers bind it with
+	// HttpProductHistoryParams, which validate it as an id.
+	productRoute.GET("/:url/history", rh.ShowProductHistory)
+	productRoute.GET("/:url/price-history", rh.ShowPriceHistory)
+	productRoute.GET("/:url/price-schedules", rh.ShowPriceSchedules)
+	productRoute.POST("/:id/price-schedules", rh.CreatePriceSchedule)
+	productRoute.DELETE("/:id/price-schedules/:scheduleId", rh.CancelPriceSchedule)
+	productRoute.PUT("/:id/stock", rh.SetProductStock)
+
+	promotionRoute := router.Group("/promotion")
+	promotionRoute.POST("", rh.CreatePromotion)
+	promotionRoute.GET("", rh.ListPromotions)
+	promotionRoute.GET("/:id", rh.ShowPromotion)
+	promotionRoute.DELETE("/:id", rh.DeactivatePromotion)
+
+	couponRoute := router.Group("/coupon")
+	couponRoute.POST("/validate", rh.ValidateCoupon)
+
+	cartRoute := router.Group("/cart")
+	cartRoute.GET("", rh.ShowCart)
+	cartRoute.DELETE("", rh.ClearCart)
+	cartRoute.POST("/items", rh.AddCartItem)
+	cartRoute.PUT("/items/:productId", rh.UpdateCartItem)
+	cartRoute.DELETE("/items/:productId", rh.RemoveCartItem)
+	cartRoute.POST("/revalidate", rh.RevalidateCart)
+
+	router.POST("/checkout", rh.Checkout)
+
+	orderRoute := router.Group("/order")
+	orderRoute.GET("", rh.ListOrders)
+	orderRoute.GET("/:id", rh.ShowOrder)
+	orderRoute.POST("/:id/cancel", rh.CancelOrder)
+	orderRoute.POST("/:id/payment", rh.CreatePayment)
+
+	paymentRoute := router.Group("/payment")
+	paymentRoute.POST("/webhook", rh.PaymentWebhook)
+
+	taxRuleRoute := router.Group("/tax-rule")
+	taxRuleRoute.POST("", rh.CreateTaxRule)
+	taxRuleRoute.GET("", rh.ListTaxRules)
+	taxRuleRoute.DELETE("/:id", rh.DeleteTaxRule)
+
+	// a store or product url must not shadow a static route next to it
+	domain.ReserveSlugs(routeSegments(router.Routes(), "/store/", "/product/")...)
+}
+
+// static first segments of the routes under the prefixes, example id of
+// /product/id/:id
+func routeSegments(routes gin.RoutesInfo, prefixes ...string) []string {
+	var (
+		segments []string
+		seen     = make(map[string]bool)
+	)
+	for _, route := range routes {
+		for _, prefix := range prefixes {
+			if !strings.HasPrefix(route.Path, prefix) {
+				continue
+			}
 
+			segment := strings.SplitN(strings.TrimPrefix(route.Path, prefix), "/", 2)[0]
+			if segment == "" || segment[0] == ':' || segment[0] == '*' || seen[segment] {
+				continue
+			}
+			seen[segment] = true
+			segments = append(segments, segment)
+		}
+	}
+
+	return segments
 }
 
 func decodeRequest(c *gin.Context, i interface{}) error {
@@ -61,3 +166,24 @@ func decodeRequest(c *gin.Context, i interface{}) error {
 
 	return nil
 }
+
+// decodeMergePatch read the request body as a JSON merge patch. Other
+// media type is rendered as 415 and a malformed body as 400.
+func decodeMergePatch(c *gin.Context) (mergepatch.Document, bool) {
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil || mediaType != mergepatch.ContentType {
+		renderError(c, domain.ErrMergePatchMediaType)
+		return nil, false
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err == nil {
+		var doc mergepatch.Document
+		if doc, err = mergepatch.Parse(data); err == nil {
+			return doc, true
+		}
+	}
+	httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+
+	return nil, false
+}
@@ -0,0 +1,279 @@
+package http
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ijlik/store-app/internal/business/domain"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+	httppagination "github.com/ijlik/store-app/pkg/http/pagination"
+)
+
+// Checkout place an order with the cart lines of a store
+func (rh *requestHandler) Checkout(c *gin.Context) {
+	ctx := c.Request.Context()
+	var request domain.CheckoutRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	order, err := rh.service.Checkout(ctx, cartOwner(c), &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(order)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) ListOrders(c *gin.Context) {
+	var (
+		query      = domain.HttpOrderQuery{}
+		pagination *httppagination.Pagination
+	)
+
+	if errQuery := c.ShouldBindQuery(&query); errQuery != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	err := query.Validate()
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+	pagination = httppagination.NewPaginate(query.Limit, query.Page)
+
+	ctx := c.Request.Context()
+	err = rh.service.ShowOrders(ctx, pagination, pkgcontext.GetString(ctx, pkgcontext.USER_ID), query.Status)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	pagination.BuildPaginationResponse(c)
+}
+
+func (rh *requestHandler) ShowOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpOrderIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	order, err := rh.service.GetOrder(ctx, pkgcontext.GetString(ctx, pkgcontext.USER_ID), params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(order)
+	c.JSON(response.HttpCode, response)
+}
+
+// CancelOrder cancel an order which has not been paid, the body is optional
+func (rh *requestHandler) CancelOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpOrderIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	var request domain.OrderCancelRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil && errDecode != io.EOF {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	order, err := rh.service.CancelOrder(ctx, pkgcontext.GetString(ctx, pkgcontext.USER_ID), params.ID, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(order)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) ListStoreOrders(c *gin.Context) {
+	var (
+		query      = domain.HttpOrderQuery{}
+		pagination *httppagination.Pagination
+	)
+
+	var params = domain.HttpStoreIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	if errQuery := c.ShouldBindQuery(&query); errQuery != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	err := query.Validate()
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+	pagination = httppagination.NewPaginate(query.Limit, query.Page)
+
+	err = rh.service.ShowStoreOrders(c.Request.Context(), pagination, params.ID, query.Status)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	pagination.BuildPaginationResponse(c)
+}
+
+func (rh *requestHandler) ShowStoreOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreOrderParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	order, err := rh.service.GetStoreOrder(ctx, params.StoreID, params.OrderID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(order)
+	c.JSON(response.HttpCode, response)
+}
+
+// UpdateOrderStatus move an order of the store to the next status
+func (rh *requestHandler) UpdateOrderStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreOrderParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	var request domain.OrderStatusRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	order, err := rh.service.UpdateOrderStatus(ctx, params.StoreID, params.OrderID, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(order)
+	c.JSON(response.HttpCode, response)
+}
+
+// SetProductStock set the stock of a product, null stop tracking it
+func (rh *requestHandler) SetProductStock(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpProductIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	var request domain.ProductStockRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	err := rh.service.SetProductStock(ctx, params.ID, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(nil)
+	c.JSON(response.HttpCode, response)
+}
+
+// RefundOrder give back the money of an order, fully or by line
+func (rh *requestHandler) RefundOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreOrderParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	var request domain.RefundRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	refund, err := rh.service.RefundOrder(ctx, params.StoreID, params.OrderID, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(refund)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) ListRefunds(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreOrderParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	refunds, err := rh.service.ListRefunds(ctx, params.StoreID, params.OrderID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(refunds)
+	c.JSON(response.HttpCode, response)
+}
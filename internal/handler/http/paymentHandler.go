@@ -0,0 +1,53 @@
+package http
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ijlik/store-app/internal/business/domain"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+)
+
+const PaymentSignatureHeader = "X-Payment-Signature"
+
+// CreatePayment start the payment of a pending order, the client secret is
+// handed to the provider client to complete the payment
+func (rh *requestHandler) CreatePayment(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpOrderIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	payment, err := rh.service.CreatePayment(ctx, pkgcontext.GetString(ctx, pkgcontext.USER_ID), params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(payment)
+	c.JSON(response.HttpCode, response)
+}
+
+// PaymentWebhook receive the events of the payment provider, the signature
+// is computed over the raw body
+func (rh *requestHandler) PaymentWebhook(c *gin.Context) {
+	payload, errRead := io.ReadAll(c.Request.Body)
+	if errRead != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errRead.Error())
+		return
+	}
+
+	err := rh.service.HandlePaymentWebhook(c.Request.Context(), payload, c.GetHeader(PaymentSignatureHeader))
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(nil)
+	c.JSON(response.HttpCode, response)
+}
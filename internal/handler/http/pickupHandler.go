@@ -0,0 +1,111 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ijlik/store-app/internal/business/domain"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+)
+
+func (rh *requestHandler) UpdatePickupSettings(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	var request domain.PickupSettingsRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	settings, err := rh.service.UpdatePickupSettings(ctx, params.ID, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(settings)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) ShowPickupSettings(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	settings, err := rh.service.GetPickupSettings(ctx, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(settings)
+	c.JSON(response.HttpCode, response)
+}
+
+// ListPickupSlots list the pickup slots the store offers from now with
+// their remaining places
+func (rh *requestHandler) ListPickupSlots(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	slots, err := rh.service.ListPickupSlots(ctx, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(slots)
+	c.JSON(response.HttpCode, response)
+}
+
+// BookPickupSlot book a slot for an order of the signed in user, booking
+// again move the order to the new slot
+func (rh *requestHandler) BookPickupSlot(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	var request domain.PickupBookingRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	booking, err := rh.service.BookPickupSlot(ctx, pkgcontext.GetString(ctx, pkgcontext.USER_ID), params.ID, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(booking)
+	c.JSON(response.HttpCode, response)
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+	httppagination "github.com/ijlik/store-app/pkg/http/pagination"
+)
+
+func (rh *requestHandler) ShowPriceHistory(c *gin.Context) {
+	var (
+		query      = domain.HttpHistoryQuery{}
+		pagination *httppagination.Pagination
+	)
+
+	var params = domain.HttpProductHistoryParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+	if err := params.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	if errQuery := c.ShouldBindQuery(&query); errQuery != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	err := query.Validate()
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+	pagination = httppagination.NewPaginate(query.Limit, query.Page)
+
+	err = rh.service.ShowPriceHistory(c.Request.Context(), pagination, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	pagination.BuildPaginationResponse(c)
+}
+
+func (rh *requestHandler) ShowPriceSchedules(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpProductHistoryParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+	if err := params.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	schedules, err := rh.service.ShowPriceSchedules(ctx, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(schedules)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) CreatePriceSchedule(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpProductIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	var request domain.PriceScheduleRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(time.Now().UTC()); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	schedule, err := rh.service.CreatePriceSchedule(ctx, &request, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(schedule)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) CancelPriceSchedule(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpPriceScheduleParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	err := rh.service.CancelPriceSchedule(ctx, params.ProductID, params.ScheduleID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(nil)
+	c.JSON(response.HttpCode, response)
+}
@@ -1,6 +1,9 @@
 package http
 
 import (
+	"net/http"
+	"net/url"
+
 	"github.com/gin-gonic/gin"
 	"github.com/ijlik/store-app/internal/business/domain"
 	errpkg "github.com/ijlik/store-app/pkg/error"
@@ -21,14 +24,14 @@ func (rh *requestHandler) ListProducts(c *gin.Context) {
 
 	err := sf.Validate()
 	if err != nil {
-		httppkg.BuildErrorResponse(c, err.GetCode(), err.Error())
+		renderError(c, err)
 		return
 	}
 	pagination = httppagination.NewPaginate(sf.Limit, sf.Page)
 
 	err = rh.service.ShowProducts(c.Request.Context(), pagination, &sf)
 	if err != nil {
-		httppkg.BuildErrorResponse(c, err.GetCode(), err.Error())
+		renderError(c, err)
 		return
 	}
 
@@ -39,19 +42,18 @@ func (rh *requestHandler) CreateProduct(c *gin.Context) {
 	ctx := c.Request.Context()
 	var request domain.ProductRequest
 
-	err := decodeRequest(c, &request)
-	if err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
 		return
 	}
 	if err := request.Validate(); err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+		renderError(c, err)
 		return
 	}
 
 	product, err := rh.service.CreateProduct(ctx, &request)
 	if err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+		renderError(c, err)
 		return
 	}
 
@@ -70,11 +72,112 @@ func (rh *requestHandler) ShowProduct(c *gin.Context) {
 
 	product, err := rh.service.GetProductByUrl(ctx, params.Url)
 	if err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+		renderError(c, err)
 		return
 	}
 
-	response := httppkg.DefaultSuccessResponse(product)
+	// previous url of a renamed product
+	if product.Url != params.Url {
+		c.Redirect(http.StatusMovedPermanently, "/product/"+url.PathEscape(product.Url))
+		return
+	}
+
+	renderWithETag(c, productETag(product), product)
+}
+
+func (rh *requestHandler) ShowProductById(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpProductIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	product, err := rh.service.GetProductById(ctx, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	renderWithETag(c, productETag(product), product)
+}
+
+func (rh *requestHandler) ShowProductBySku(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpProductSkuParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	product, err := rh.service.GetProductBySku(ctx, params.Sku)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	renderWithETag(c, productETag(product), product)
+}
+
+func (rh *requestHandler) BatchGetProducts(c *gin.Context) {
+	ctx := c.Request.Context()
+	var request domain.ProductBatchGetRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	products, err := rh.service.GetProductsByIds(ctx, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(products)
+	c.JSON(response.HttpCode, response)
+}
+
+// PatchProduct accept a JSON merge patch, RFC 7386
+func (rh *requestHandler) PatchProduct(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpProductIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	doc, ok := decodeMergePatch(c)
+	if !ok {
+		return
+	}
+	patch, err := domain.NewProductPatch(doc)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	patch.Version = version
+
+	err = rh.service.PatchProduct(ctx, patch, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(nil)
 	c.JSON(response.HttpCode, response)
 }
 
@@ -87,21 +190,27 @@ func (rh *requestHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
 	var request domain.ProductRequest
 
-	err := decodeRequest(c, &request)
-	if err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
 		return
 	}
 	if err := request.Validate(); err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+		renderError(c, err)
 		return
 	}
 
-	err = rh.service.UpdateProduct(ctx, &request, params.ID)
+	request.Version = version
+
+	err := rh.service.UpdateProduct(ctx, &request, params.ID)
 	if err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+		renderError(c, err)
 		return
 	}
 
@@ -118,12 +227,55 @@ func (rh *requestHandler) DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	err := rh.service.DeleteProduct(ctx, params.ID)
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	err := rh.service.DeleteProduct(ctx, params.ID, version)
 	if err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+		renderError(c, err)
 		return
 	}
 
 	response := httppkg.DefaultSuccessResponse(nil)
 	c.JSON(response.HttpCode, response)
 }
+
+func (rh *requestHandler) ShowProductHistory(c *gin.Context) {
+	var (
+		query      = domain.HttpHistoryQuery{}
+		pagination *httppagination.Pagination
+	)
+
+	var params = domain.HttpProductHistoryParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+	if err := params.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	if errQuery := c.ShouldBindQuery(&query); errQuery != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	err := query.Validate()
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+	pagination = httppagination.NewPaginate(query.Limit, query.Page)
+
+	err = rh.service.ShowProductHistory(c.Request.Context(), pagination, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	pagination.BuildPaginationResponse(c)
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+	httppagination "github.com/ijlik/store-app/pkg/http/pagination"
+)
+
+func (rh *requestHandler) CreatePromotion(c *gin.Context) {
+	ctx := c.Request.Context()
+	var request domain.PromotionRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	promotion, err := rh.service.CreatePromotion(ctx, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(promotion)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) ListPromotions(c *gin.Context) {
+	var (
+		query      = domain.HttpPromotionQuery{}
+		pagination *httppagination.Pagination
+	)
+
+	if errQuery := c.ShouldBindQuery(&query); errQuery != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	err := query.Validate()
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+	pagination = httppagination.NewPaginate(query.Limit, query.Page)
+
+	err = rh.service.ShowPromotions(c.Request.Context(), pagination)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	pagination.BuildPaginationResponse(c)
+}
+
+func (rh *requestHandler) ShowPromotion(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpPromotionIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	promotion, err := rh.service.GetPromotionById(ctx, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(promotion)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) DeactivatePromotion(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpPromotionIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	err := rh.service.DeactivatePromotion(ctx, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(nil)
+	c.JSON(response.HttpCode, response)
+}
+
+// ValidateCoupon price the items with the coupon without redeeming it
+func (rh *requestHandler) ValidateCoupon(c *gin.Context) {
+	ctx := c.Request.Context()
+	var request domain.CouponValidateRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	validation, err := rh.service.ValidateCoupon(ctx, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(validation)
+	c.JSON(response.HttpCode, response)
+}
@@ -1,6 +1,9 @@
 package http
 
 import (
+	"net/http"
+	"net/url"
+
 	"github.com/gin-gonic/gin"
 	"github.com/ijlik/store-app/internal/business/domain"
 	errpkg "github.com/ijlik/store-app/pkg/error"
@@ -12,19 +15,18 @@ func (rh *requestHandler) CreateStore(c *gin.Context) {
 	ctx := c.Request.Context()
 	var request domain.StoreRequest
 
-	err := decodeRequest(c, &request)
-	if err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
 		return
 	}
 	if err := request.Validate(); err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+		renderError(c, err)
 		return
 	}
 
 	store, err := rh.service.CreateStore(ctx, &request)
 	if err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+		renderError(c, err)
 		return
 	}
 
@@ -43,11 +45,125 @@ func (rh *requestHandler) ShowStore(c *gin.Context) {
 
 	store, err := rh.service.GetStoreById(ctx, params.ID)
 	if err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+		renderError(c, err)
 		return
 	}
 
-	response := httppkg.DefaultSuccessResponse(store)
+	renderWithETag(c, httppkg.ETag(store.Version), store)
+}
+
+func (rh *requestHandler) ShowStoreByUrl(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreUrlParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	store, err := rh.service.GetStoreByUrl(ctx, params.Url)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	// previous url of a renamed store
+	if store.Url != params.Url {
+		c.Redirect(http.StatusMovedPermanently, "/store/by-url/"+url.PathEscape(store.Url))
+		return
+	}
+
+	renderWithETag(c, httppkg.ETag(store.Version), store)
+}
+
+// ListStores list the stores by name, filtered on city and province
+func (rh *requestHandler) ListStores(c *gin.Context) {
+	var (
+		query      = domain.HttpStoreQuery{}
+		pagination *httppagination.Pagination
+	)
+
+	if errQuery := c.ShouldBindQuery(&query); errQuery != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	err := query.Validate()
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+	pagination = httppagination.NewPaginate(query.Limit, query.Page)
+
+	ctx := c.Request.Context()
+	err = rh.service.ShowStores(ctx, pagination, &query)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	pagination.BuildPaginationResponse(c)
+}
+
+// ListNearbyStores list the stores around lat and lng within radiusKm,
+// nearest first with their distance
+func (rh *requestHandler) ListNearbyStores(c *gin.Context) {
+	ctx := c.Request.Context()
+	var query = domain.HttpNearbyStoreQuery{}
+
+	if errQuery := c.ShouldBindQuery(&query); errQuery != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+	if err := query.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	stores, err := rh.service.ListNearbyStores(ctx, &query)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(stores)
+	c.JSON(response.HttpCode, response)
+}
+
+// PatchStore accept a JSON merge patch, RFC 7386
+func (rh *requestHandler) PatchStore(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpStoreIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	doc, ok := decodeMergePatch(c)
+	if !ok {
+		return
+	}
+	patch, err := domain.NewStorePatch(doc)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	patch.Version = version
+
+	err = rh.service.PatchStore(ctx, patch, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(nil)
 	c.JSON(response.HttpCode, response)
 }
 
@@ -60,21 +176,27 @@ func (rh *requestHandler) UpdateStore(c *gin.Context) {
 		return
 	}
 
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
 	var request domain.StoreRequest
 
-	err := decodeRequest(c, &request)
-	if err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
 		return
 	}
 	if err := request.Validate(); err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+		renderError(c, err)
 		return
 	}
 
-	err = rh.service.UpdateStore(ctx, &request, params.ID)
+	request.Version = version
+
+	err := rh.service.UpdateStore(ctx, &request, params.ID)
 	if err != nil {
-		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, err.Error())
+		renderError(c, err)
 		return
 	}
 
@@ -102,14 +224,48 @@ func (rh *requestHandler) ShowStoreProducts(c *gin.Context) {
 
 	err := sf.Validate()
 	if err != nil {
-		httppkg.BuildErrorResponse(c, err.GetCode(), err.Error())
+		renderError(c, err)
 		return
 	}
 	pagination = httppagination.NewPaginate(sf.Limit, sf.Page)
 
 	err = rh.service.ShowStoreProducts(c.Request.Context(), pagination, &sf, params.ID)
 	if err != nil {
-		httppkg.BuildErrorResponse(c, err.GetCode(), err.Error())
+		renderError(c, err)
+		return
+	}
+
+	pagination.BuildPaginationResponse(c)
+}
+
+func (rh *requestHandler) ShowStoreHistory(c *gin.Context) {
+	var (
+		query      = domain.HttpHistoryQuery{}
+		pagination *httppagination.Pagination
+	)
+
+	var params = domain.HttpStoreIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	if errQuery := c.ShouldBindQuery(&query); errQuery != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	err := query.Validate()
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+	pagination = httppagination.NewPaginate(query.Limit, query.Page)
+
+	err = rh.service.ShowStoreHistory(c.Request.Context(), pagination, params.ID)
+	if err != nil {
+		renderError(c, err)
 		return
 	}
 
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ijlik/store-app/internal/business/domain"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+)
+
+func (rh *requestHandler) CreateTaxRule(c *gin.Context) {
+	ctx := c.Request.Context()
+	var request domain.TaxRuleRequest
+
+	if errDecode := decodeRequest(c, &request); errDecode != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, errDecode.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	rule, err := rh.service.CreateTaxRule(ctx, &request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(rule)
+	c.JSON(response.HttpCode, response)
+}
+
+// ListTaxRules list every tax rule, store_id query keep the rules of the
+// store
+func (rh *requestHandler) ListTaxRules(c *gin.Context) {
+	ctx := c.Request.Context()
+	var query = domain.HttpTaxRuleQuery{}
+
+	if errQuery := c.ShouldBindQuery(&query); errQuery != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+	if err := query.Validate(); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	rules, err := rh.service.ListTaxRules(ctx, query.StoreID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(rules)
+	c.JSON(response.HttpCode, response)
+}
+
+func (rh *requestHandler) DeleteTaxRule(c *gin.Context) {
+	ctx := c.Request.Context()
+	var params = domain.HttpTaxRuleIdParams{}
+
+	if errJson := c.ShouldBindUri(&params); errJson != nil {
+		httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "")
+		return
+	}
+
+	err := rh.service.DeleteTaxRule(ctx, params.ID)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := httppkg.DefaultSuccessResponse(nil)
+	c.JSON(response.HttpCode, response)
+}
@@ -0,0 +1,166 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-co-op/gocron"
+	"github.com/ijlik/store-app/internal/business/port"
+	configdata "github.com/ijlik/store-app/pkg/config/data"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+)
+
+// default interval of the jobs in seconds
+const (
+	defaultPriceInterval       = 60
+	defaultCartExpiryInterval  = 3600
+	defaultOrderExpiryInterval = 60
+	defaultReconcileInterval   = 300
+)
+
+// TenantSource list the configured tenants, it is called on every run so a
+// tenant added to the config is picked up without restart
+type TenantSource func() []string
+
+// TenantContext derive the context of a job run on the tenant set on it,
+// e.g. attaching the connection pool of the tenant
+type TenantContext func(ctx context.Context) (context.Context, error)
+
+// HandlerScheduler start the background jobs, the caller stop the returned
+// scheduler on shutdown. Jobs run on the default database and on every
+// tenant of tenants, resolve is run once per tenant and run.
+func HandlerScheduler(
+	config configdata.Config,
+	service port.StoreDomainService,
+	tenants TenantSource,
+	resolve TenantContext,
+) *gocron.Scheduler {
+	s := gocron.NewScheduler(time.UTC)
+
+	// a slow run is not started again before it ends
+	if _, err := s.Every(interval(config, "PRICE_SCHEDULE_INTERVAL", defaultPriceInterval)).Seconds().SingletonMode().Do(func() {
+		applyPriceSchedules(service, Tenants(tenants()), resolve)
+	}); err != nil {
+		log.Println("scheduler specify jobFunc: ", err)
+	}
+
+	if _, err := s.Every(interval(config, "CART_EXPIRY_INTERVAL", defaultCartExpiryInterval)).Seconds().SingletonMode().Do(func() {
+		expireCarts(service, Tenants(tenants()), resolve)
+	}); err != nil {
+		log.Println("scheduler specify jobFunc: ", err)
+	}
+
+	if _, err := s.Every(interval(config, "ORDER_EXPIRY_INTERVAL", defaultOrderExpiryInterval)).Seconds().SingletonMode().Do(func() {
+		cancelExpiredOrders(service, Tenants(tenants()), resolve)
+	}); err != nil {
+		log.Println("scheduler specify jobFunc: ", err)
+	}
+
+	if _, err := s.Every(interval(config, "PAYMENT_RECONCILE_INTERVAL", defaultReconcileInterval)).Seconds().SingletonMode().Do(func() {
+		reconcilePayments(service, Tenants(tenants()), resolve)
+	}); err != nil {
+		log.Println("scheduler specify jobFunc: ", err)
+	}
+
+	s.StartAsync()
+
+	return s
+}
+
+func applyPriceSchedules(service port.StoreDomainService, tenants []string, resolve TenantContext) {
+	now := time.Now().UTC()
+	for _, tenant := range tenants {
+		ctx, err := tenantContext(resolve, tenant)
+		if err != nil {
+			log.Println("FAILED TO RESOLVE TENANT: ", tenant, err)
+			continue
+		}
+
+		if err := service.ApplyPriceSchedules(ctx, now); err != nil {
+			log.Println("FAILED TO APPLY PRICE SCHEDULES: ", tenant, err)
+		}
+	}
+}
+
+func expireCarts(service port.StoreDomainService, tenants []string, resolve TenantContext) {
+	now := time.Now().UTC()
+	for _, tenant := range tenants {
+		ctx, err := tenantContext(resolve, tenant)
+		if err != nil {
+			log.Println("FAILED TO RESOLVE TENANT: ", tenant, err)
+			continue
+		}
+
+		if err := service.ExpireCarts(ctx, now); err != nil {
+			log.Println("FAILED TO EXPIRE CARTS: ", tenant, err)
+		}
+	}
+}
+
+func cancelExpiredOrders(service port.StoreDomainService, tenants []string, resolve TenantContext) {
+	now := time.Now().UTC()
+	for _, tenant := range tenants {
+		ctx, err := tenantContext(resolve, tenant)
+		if err != nil {
+			log.Println("FAILED TO RESOLVE TENANT: ", tenant, err)
+			continue
+		}
+
+		if err := service.CancelExpiredOrders(ctx, now); err != nil {
+			log.Println("FAILED TO CANCEL EXPIRED ORDERS: ", tenant, err)
+		}
+	}
+}
+
+func reconcilePayments(service port.StoreDomainService, tenants []string, resolve TenantContext) {
+	now := time.Now().UTC()
+	for _, tenant := range tenants {
+		ctx, err := tenantContext(resolve, tenant)
+		if err != nil {
+			log.Println("FAILED TO RESOLVE TENANT: ", tenant, err)
+			continue
+		}
+
+		if err := service.ReconcilePayments(ctx, now); err != nil {
+			log.Println("FAILED TO RECONCILE PAYMENTS: ", tenant, err)
+		}
+	}
+}
+
+// context of a job run on tenant
+func tenantContext(resolve TenantContext, tenant string) (context.Context, error) {
+	ctx := pkgcontext.SetContext(context.Background(), map[pkgcontext.ContextMetadata]any{
+		pkgcontext.TENANT_ID: tenant,
+	})
+
+	return resolve(ctx)
+}
+
+// job interval in seconds read from key, def when not set
+func interval(config configdata.Config, key string, def int) int {
+	if seconds := config.GetInt(key); seconds > 0 {
+		return seconds
+	}
+
+	return def
+}
+
+// Tenants return the tenants a job runs on, the default database first then
+// every configured tenant once
+func Tenants(configured []string) []string {
+	var (
+		tenants = []string{""}
+		seen    = map[string]bool{"": true}
+	)
+	for _, tenant := range configured {
+		tenant = strings.TrimSpace(tenant)
+		if !seen[tenant] {
+			seen[tenant] = true
+			tenants = append(tenants, tenant)
+		}
+	}
+
+	return tenants
+}
@@ -0,0 +1,13 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenants(t *testing.T) {
+	assert.Equal(t, []string{""}, Tenants(nil))
+	assert.Equal(t, []string{""}, Tenants([]string{""}))
+	assert.Equal(t, []string{"", "acme", "globex"}, Tenants([]string{"acme", " globex", "acme", ""}))
+}
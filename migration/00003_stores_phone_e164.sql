@@ -0,0 +1,25 @@
+-- +goose Up
+-- E.164 phone number is a plus sign followed by up to 15 digits
+ALTER TABLE stores ALTER COLUMN phone TYPE VARCHAR(16);
+
+-- best effort normalisation of the existing numbers so they pass the E.164
+-- rule on the next update. Separators are removed like
+-- validation.NormalizePhone, 00 is the international prefix and a leading 0
+-- or 62 is a local Indonesian number. Numbers still not E.164 are left as is.
+UPDATE stores SET phone = normalized.phone
+FROM (
+    SELECT id, CASE
+        WHEN digits LIKE '+%' THEN digits
+        WHEN digits LIKE '00%' THEN '+' || substr(digits, 3)
+        WHEN digits LIKE '0%' THEN '+62' || substr(digits, 2)
+        WHEN digits LIKE '62%' THEN '+' || digits
+        ELSE digits END AS phone
+    FROM (SELECT id, regexp_replace(btrim(phone), '[\s().-]', '', 'g') AS digits FROM stores) separated
+) normalized
+WHERE stores.id = normalized.id
+    AND normalized.phone ~ '^\+[1-9][0-9]{6,14}$'
+    AND stores.phone <> normalized.phone;
+
+-- +goose Down
+-- a 16 characters number lose its plus sign to fit back
+ALTER TABLE stores ALTER COLUMN phone TYPE VARCHAR(15) USING CASE WHEN length(phone) > 15 THEN ltrim(phone, '+') ELSE phone END;
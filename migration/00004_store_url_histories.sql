@@ -0,0 +1,14 @@
+-- +goose Up
+-- previous url of renamed store, kept to redirect old links to the current url
+CREATE TABLE IF NOT EXISTS store_url_histories (
+    url VARCHAR(100) NOT NULL,
+    store_id uuid NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (url),
+    FOREIGN KEY (store_id) REFERENCES stores (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS store_url_histories_store_id_idx ON store_url_histories (store_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS store_url_histories;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- previous url of renamed product, kept to redirect old links to the current url
+CREATE TABLE IF NOT EXISTS product_url_histories (
+    url VARCHAR(100) NOT NULL,
+    product_id uuid NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (url),
+    FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS product_url_histories_product_id_idx ON product_url_histories (product_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS product_url_histories;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- stock keeping unit given by the merchant, optional but unique when set
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64) NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_sku_key ON products (sku);
+
+-- +goose Down
+DROP INDEX IF EXISTS products_sku_key;
+ALTER TABLE products DROP COLUMN IF EXISTS sku;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- row version used as ETag, incremented on every update
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE products DROP COLUMN IF EXISTS version;
+ALTER TABLE stores DROP COLUMN IF EXISTS version;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- append only change history of stores and products, entity_id has no
+-- foreign key so the history outlives deleted rows
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL NOT NULL,
+    entity VARCHAR(20) NOT NULL,
+    entity_id uuid NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    actor VARCHAR(128) NULL,
+    request_id VARCHAR(128) NULL,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS audit_logs_entity_idx ON audit_logs (entity, entity_id, id DESC);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+DROP FUNCTION IF EXISTS audit_logs_append_only();
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,40 @@
+-- +goose Up
+-- price before the running temporary price schedule, shown as "was" price
+ALTER TABLE products ADD COLUMN IF NOT EXISTS was_price FLOAT NULL;
+
+CREATE TABLE IF NOT EXISTS product_price_histories (
+    id BIGSERIAL NOT NULL,
+    product_id uuid NOT NULL,
+    price FLOAT NOT NULL,
+    previous_price FLOAT NULL,
+    schedule_id uuid NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS product_price_histories_product_id_idx ON product_price_histories (product_id, id DESC);
+
+CREATE TABLE IF NOT EXISTS product_price_schedules (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    product_id uuid NOT NULL,
+    price FLOAT NOT NULL,
+    effective_from TIMESTAMP NOT NULL,
+    effective_to TIMESTAMP NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    previous_price FLOAT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE,
+    CONSTRAINT product_price_schedules_period_check CHECK (effective_to IS NULL OR effective_to > effective_from),
+    CONSTRAINT product_price_schedules_status_check CHECK (status IN ('pending', 'active', 'done', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS product_price_schedules_product_id_idx ON product_price_schedules (product_id, effective_from);
+CREATE INDEX IF NOT EXISTS product_price_schedules_due_idx ON product_price_schedules (status, effective_from, effective_to);
+
+-- +goose Down
+DROP TABLE IF EXISTS product_price_schedules;
+DROP TABLE IF EXISTS product_price_histories;
+ALTER TABLE products DROP COLUMN IF EXISTS was_price;
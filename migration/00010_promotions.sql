@@ -0,0 +1,53 @@
+-- +goose Up
+-- lower cased category, promotions can be scoped to a category
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category VARCHAR(50) NULL;
+
+CREATE INDEX IF NOT EXISTS products_category_idx ON products (category);
+
+-- promotion without code apply automatically, with code it is a coupon.
+-- zero usage limit is unlimited, used count the redemptions
+CREATE TABLE IF NOT EXISTS promotions (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    code VARCHAR(32) NULL,
+    discount_type VARCHAR(10) NOT NULL,
+    discount_value FLOAT NOT NULL,
+    scope VARCHAR(10) NOT NULL,
+    scope_id VARCHAR(50) NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NULL,
+    usage_limit INT NOT NULL DEFAULT 0,
+    usage_limit_per_user INT NOT NULL DEFAULT 0,
+    used INT NOT NULL DEFAULT 0,
+    stackable BOOLEAN NOT NULL DEFAULT FALSE,
+    priority INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT promotions_discount_type_check CHECK (discount_type IN ('percentage', 'fixed')),
+    CONSTRAINT promotions_discount_value_check CHECK (discount_value > 0 AND (discount_type <> 'percentage' OR discount_value <= 100)),
+    CONSTRAINT promotions_scope_check CHECK (scope IN ('store', 'category', 'product')),
+    CONSTRAINT promotions_period_check CHECK (ends_at IS NULL OR ends_at > starts_at),
+    CONSTRAINT promotions_usage_check CHECK (usage_limit >= 0 AND usage_limit_per_user >= 0 AND used >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS promotions_code_key ON promotions (code) WHERE code IS NOT NULL;
+CREATE INDEX IF NOT EXISTS promotions_scope_idx ON promotions (scope, scope_id) WHERE active;
+
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id BIGSERIAL NOT NULL,
+    promotion_id uuid NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (promotion_id) REFERENCES promotions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS promotion_redemptions_user_idx ON promotion_redemptions (promotion_id, user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotions;
+DROP INDEX IF EXISTS products_category_idx;
+ALTER TABLE products DROP COLUMN IF EXISTS category;
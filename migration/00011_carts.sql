@@ -0,0 +1,36 @@
+-- +goose Up
+-- cart of an anonymous visitor is found by its token, cart of a signed in
+-- user by the user id. Stale carts are deleted once expires_at has passed
+CREATE TABLE IF NOT EXISTS carts (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    token VARCHAR(64) NULL,
+    user_id VARCHAR(64) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT carts_owner_check CHECK (token IS NOT NULL OR user_id IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS carts_token_key ON carts (token) WHERE token IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS carts_user_id_key ON carts (user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS carts_expires_at_idx ON carts (expires_at);
+
+-- price is the product price when the line was last written, it is compared
+-- with the current price to tell the buyer about a change
+CREATE TABLE IF NOT EXISTS cart_items (
+    cart_id uuid NOT NULL,
+    product_id uuid NOT NULL,
+    quantity INT NOT NULL,
+    price FLOAT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    PRIMARY KEY (cart_id, product_id),
+    FOREIGN KEY (cart_id) REFERENCES carts (id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE,
+    CONSTRAINT cart_items_quantity_check CHECK (quantity > 0)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS cart_items;
+DROP TABLE IF EXISTS carts;
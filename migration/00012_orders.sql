@@ -0,0 +1,61 @@
+-- +goose Up
+-- stock NULL is not tracked, checkout reserve stock by decrementing it and a
+-- cancelled order put it back
+ALTER TABLE products ADD COLUMN IF NOT EXISTS stock INT NULL;
+ALTER TABLE products ADD CONSTRAINT products_stock_check CHECK (stock IS NULL OR stock >= 0);
+
+-- order of one store, pending order not paid before expires_at is cancelled
+CREATE TABLE IF NOT EXISTS orders (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    store_id uuid NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    subtotal FLOAT NOT NULL,
+    discount FLOAT NOT NULL DEFAULT 0,
+    total FLOAT NOT NULL,
+    coupon_code VARCHAR(32) NULL,
+    expires_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (store_id) REFERENCES stores (id),
+    CONSTRAINT orders_status_check CHECK (status IN ('pending', 'paid', 'preparing', 'ready', 'completed', 'cancelled', 'refunded'))
+);
+
+CREATE INDEX IF NOT EXISTS orders_user_idx ON orders (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS orders_store_idx ON orders (store_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS orders_pending_idx ON orders (expires_at) WHERE status = 'pending';
+
+-- snapshot of the product at purchase time, product_id has no foreign key so
+-- the line survives the product
+CREATE TABLE IF NOT EXISTS order_items (
+    id BIGSERIAL NOT NULL,
+    order_id uuid NOT NULL,
+    product_id uuid NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    sku VARCHAR(64) NULL,
+    unit_price FLOAT NOT NULL,
+    sale_price FLOAT NOT NULL,
+    quantity INT NOT NULL,
+    discount FLOAT NOT NULL DEFAULT 0,
+    total FLOAT NOT NULL,
+    promotions JSONB NOT NULL DEFAULT '[]',
+    PRIMARY KEY (id),
+    FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT order_items_quantity_check CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS order_items_order_idx ON order_items (order_id, id);
+
+-- redemption of a cancelled order is released
+ALTER TABLE promotion_redemptions ADD COLUMN IF NOT EXISTS order_id uuid NULL;
+
+CREATE INDEX IF NOT EXISTS promotion_redemptions_order_idx ON promotion_redemptions (order_id) WHERE order_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS promotion_redemptions_order_idx;
+ALTER TABLE promotion_redemptions DROP COLUMN IF EXISTS order_id;
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_stock_check;
+ALTER TABLE products DROP COLUMN IF EXISTS stock;
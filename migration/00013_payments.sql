@@ -0,0 +1,37 @@
+-- +goose Up
+-- payment of an order on a provider, reference is the id of the intent on
+-- the provider. An order has at most one open payment.
+CREATE TABLE IF NOT EXISTS payments (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    order_id uuid NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    reference VARCHAR(128) NOT NULL,
+    amount FLOAT NOT NULL,
+    captured_amount FLOAT NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT payments_status_check CHECK (status IN ('pending', 'authorized', 'captured', 'failed'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS payments_reference_key ON payments (provider, reference);
+CREATE UNIQUE INDEX IF NOT EXISTS payments_open_order_key ON payments (order_id) WHERE status IN ('pending', 'authorized');
+CREATE INDEX IF NOT EXISTS payments_open_idx ON payments (created_at) WHERE status IN ('pending', 'authorized');
+
+-- webhook events already handled, a redelivered event is skipped
+CREATE TABLE IF NOT EXISTS payment_events (
+    provider VARCHAR(32) NOT NULL,
+    event_id VARCHAR(128) NOT NULL,
+    type VARCHAR(64) NOT NULL,
+    reference VARCHAR(128) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, event_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS payment_events;
+DROP TABLE IF EXISTS payments;
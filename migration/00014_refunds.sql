@@ -0,0 +1,56 @@
+-- +goose Up
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check CHECK (status IN ('pending', 'paid', 'preparing', 'ready', 'completed', 'cancelled', 'partially_refunded', 'refunded'));
+
+-- refunded amount never exceed the captured amount, the tolerance absorb
+-- float rounding of line amounts
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS refunded_amount FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE payments ADD CONSTRAINT payments_refunded_amount_check CHECK (refunded_amount <= captured_amount + 0.005);
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS refunded_quantity INT NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS refunded_amount FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD CONSTRAINT order_items_refunded_quantity_check CHECK (refunded_quantity <= quantity);
+
+-- refund of a payment, pending until the provider accepts it. reference is
+-- the id of the refund on the provider.
+CREATE TABLE IF NOT EXISTS refunds (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    order_id uuid NOT NULL,
+    payment_id uuid NOT NULL,
+    amount FLOAT NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    restock BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(16) NOT NULL,
+    reference VARCHAR(128) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    FOREIGN KEY (payment_id) REFERENCES payments (id),
+    CONSTRAINT refunds_amount_check CHECK (amount > 0),
+    CONSTRAINT refunds_status_check CHECK (status IN ('pending', 'succeeded', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS refunds_order_idx ON refunds (order_id, created_at);
+
+CREATE TABLE IF NOT EXISTS refund_items (
+    refund_id uuid NOT NULL,
+    order_item_id BIGINT NOT NULL,
+    quantity INT NOT NULL,
+    amount FLOAT NOT NULL,
+    PRIMARY KEY (refund_id, order_item_id),
+    FOREIGN KEY (refund_id) REFERENCES refunds (id) ON DELETE CASCADE,
+    FOREIGN KEY (order_item_id) REFERENCES order_items (id) ON DELETE CASCADE,
+    CONSTRAINT refund_items_quantity_check CHECK (quantity > 0)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS refund_items;
+DROP TABLE IF EXISTS refunds;
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_refunded_quantity_check;
+ALTER TABLE order_items DROP COLUMN IF EXISTS refunded_amount;
+ALTER TABLE order_items DROP COLUMN IF EXISTS refunded_quantity;
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_refunded_amount_check;
+ALTER TABLE payments DROP COLUMN IF EXISTS refunded_amount;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check CHECK (status IN ('pending', 'paid', 'preparing', 'ready', 'completed', 'cancelled', 'refunded'));
@@ -0,0 +1,44 @@
+-- +goose Up
+-- region of the store matched by tax rules of a region, e.g. ID-JK
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS tax_region VARCHAR(50) NULL;
+
+-- tax rule of a store, of every store of a region or of every store when
+-- both are NULL. category NULL apply on every category. rate is a percent,
+-- inclusive rate is already part of the price.
+CREATE TABLE IF NOT EXISTS tax_rules (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    store_id uuid NULL,
+    region VARCHAR(50) NULL,
+    category VARCHAR(50) NULL,
+    rate FLOAT NOT NULL,
+    inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    rounding VARCHAR(8) NOT NULL DEFAULT 'line',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (store_id) REFERENCES stores (id) ON DELETE CASCADE,
+    CONSTRAINT tax_rules_rate_check CHECK (rate >= 0 AND rate <= 100),
+    CONSTRAINT tax_rules_rounding_check CHECK (rounding IN ('line', 'total')),
+    CONSTRAINT tax_rules_scope_check CHECK (store_id IS NULL OR region IS NULL)
+);
+
+CREATE INDEX IF NOT EXISTS tax_rules_store_idx ON tax_rules (store_id);
+CREATE INDEX IF NOT EXISTS tax_rules_region_idx ON tax_rules (region) WHERE store_id IS NULL;
+
+-- tax snapshot of the order, total include tax_added while tax_included is
+-- already inside the line totals. taxes is the breakdown per rule.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_included FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_added FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS taxes JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_included FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_added FLOAT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_added;
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_included;
+ALTER TABLE orders DROP COLUMN IF EXISTS taxes;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_added;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_included;
+DROP TABLE IF EXISTS tax_rules;
+ALTER TABLE stores DROP COLUMN IF EXISTS tax_region;
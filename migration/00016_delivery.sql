@@ -0,0 +1,77 @@
+-- +goose Up
+-- shipping weight in grams
+ALTER TABLE products ADD COLUMN IF NOT EXISTS weight INT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD CONSTRAINT products_weight_check CHECK (weight >= 0);
+
+-- area a store deliver to, radius_km from the store location or polygon of
+-- [{"lat":..,"lng":..}] points. rates is the rate table of the zone, the
+-- cheapest matching rate is charged.
+CREATE TABLE IF NOT EXISTS delivery_zones (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    store_id uuid NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(8) NOT NULL,
+    radius_km FLOAT NULL,
+    polygon JSONB NULL,
+    rates JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (store_id) REFERENCES stores (id) ON DELETE CASCADE,
+    CONSTRAINT delivery_zones_type_check CHECK (
+        (type = 'radius' AND radius_km > 0) OR
+        (type = 'polygon' AND polygon IS NOT NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS delivery_zones_store_idx ON delivery_zones (store_id, created_at);
+
+-- pickup slots of a store are generated from its opening hours, every slot
+-- takes at most capacity bookings
+CREATE TABLE IF NOT EXISTS pickup_settings (
+    store_id uuid NOT NULL,
+    slot_minutes INT NOT NULL,
+    capacity INT NOT NULL,
+    lead_minutes INT NOT NULL DEFAULT 0,
+    days_ahead INT NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (store_id),
+    FOREIGN KEY (store_id) REFERENCES stores (id) ON DELETE CASCADE,
+    CONSTRAINT pickup_settings_check CHECK (slot_minutes > 0 AND capacity > 0 AND lead_minutes >= 0 AND days_ahead > 0)
+);
+
+-- booked count of a slot, the row is locked by the conditional upsert so the
+-- capacity is never exceeded
+CREATE TABLE IF NOT EXISTS pickup_slots (
+    store_id uuid NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    booked INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (store_id, starts_at),
+    FOREIGN KEY (store_id) REFERENCES stores (id) ON DELETE CASCADE,
+    CONSTRAINT pickup_slots_booked_check CHECK (booked >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS pickup_bookings (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    store_id uuid NOT NULL,
+    order_id uuid NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (store_id) REFERENCES stores (id) ON DELETE CASCADE,
+    FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT pickup_bookings_status_check CHECK (status IN ('booked', 'cancelled'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS pickup_bookings_order_key ON pickup_bookings (order_id) WHERE status = 'booked';
+
+-- +goose Down
+DROP TABLE IF EXISTS pickup_bookings;
+DROP TABLE IF EXISTS pickup_slots;
+DROP TABLE IF EXISTS pickup_settings;
+DROP TABLE IF EXISTS delivery_zones;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_weight_check;
+ALTER TABLE products DROP COLUMN IF EXISTS weight;
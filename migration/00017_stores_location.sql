@@ -0,0 +1,19 @@
+-- +goose Up
+-- location of the store, origin of radius zones, of delivery distance and of
+-- the nearby search
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION NULL;
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION NULL;
+ALTER TABLE stores ADD CONSTRAINT stores_location_check CHECK (
+    (latitude IS NULL AND longitude IS NULL) OR
+    (latitude BETWEEN -90 AND 90 AND longitude BETWEEN -180 AND 180)
+);
+
+-- nearby search filter the stores on a box around the point before the
+-- exact distance, stores without location are never searched
+CREATE INDEX IF NOT EXISTS stores_location_idx ON stores (latitude, longitude) WHERE latitude IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS stores_location_idx;
+ALTER TABLE stores DROP CONSTRAINT IF EXISTS stores_location_check;
+ALTER TABLE stores DROP COLUMN IF EXISTS longitude;
+ALTER TABLE stores DROP COLUMN IF EXISTS latitude;
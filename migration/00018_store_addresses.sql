@@ -0,0 +1,52 @@
+-- +goose Up
+-- postal address of the store, address keep the formatted single line
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS address_street VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS address_district VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS address_city VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS address_province VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS address_postal_code VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS address_country VARCHAR(2) NOT NULL DEFAULT '';
+
+-- best effort split of the existing lines, same rules as domain.ParseAddress:
+-- a trailing five digits number is the postal code, the last three comma
+-- separated parts are district, city and province and the rest is the
+-- street. Shorter lines fill street, city and province first. The country
+-- is unknown and left empty.
+WITH parsed AS (
+    SELECT id, substring(line FROM '\m(\d{5})$') AS postal_code,
+        array_remove(regexp_split_to_array(regexp_replace(line, '[\s,]*\m\d{5}$', ''), '\s*,\s*'), '') AS parts
+    FROM (SELECT id, btrim(address) AS line FROM stores) lines
+), split AS (
+    SELECT id, postal_code, parts, coalesce(array_length(parts, 1), 0) AS n FROM parsed
+)
+UPDATE stores SET
+    address_street = left(CASE
+        WHEN split.n >= 4 THEN array_to_string(split.parts[1:split.n - 3], ', ')
+        WHEN split.n >= 1 THEN split.parts[1]
+        ELSE '' END, 255),
+    address_district = left(CASE WHEN split.n >= 4 THEN split.parts[split.n - 2] ELSE '' END, 100),
+    address_city = left(CASE
+        WHEN split.n >= 4 THEN split.parts[split.n - 1]
+        WHEN split.n >= 2 THEN split.parts[2]
+        ELSE '' END, 100),
+    address_province = left(CASE
+        WHEN split.n >= 4 THEN split.parts[split.n]
+        WHEN split.n = 3 THEN split.parts[3]
+        ELSE '' END, 100),
+    address_postal_code = coalesce(split.postal_code, '')
+FROM split
+WHERE stores.id = split.id;
+
+-- store listing filter on city and province regardless of case
+CREATE INDEX IF NOT EXISTS stores_address_city_idx ON stores (lower(address_city));
+CREATE INDEX IF NOT EXISTS stores_address_province_idx ON stores (lower(address_province));
+
+-- +goose Down
+DROP INDEX IF EXISTS stores_address_province_idx;
+DROP INDEX IF EXISTS stores_address_city_idx;
+ALTER TABLE stores DROP COLUMN IF EXISTS address_country;
+ALTER TABLE stores DROP COLUMN IF EXISTS address_postal_code;
+ALTER TABLE stores DROP COLUMN IF EXISTS address_province;
+ALTER TABLE stores DROP COLUMN IF EXISTS address_city;
+ALTER TABLE stores DROP COLUMN IF EXISTS address_district;
+ALTER TABLE stores DROP COLUMN IF EXISTS address_street;
@@ -6,6 +6,7 @@ import (
 	"github.com/go-co-op/gocron"
 	"github.com/hashicorp/vault/api"
 	"log"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -35,6 +36,7 @@ type Config interface {
 	GetInt(key string) int
 	GetArray(key string) []string
 	GetMap(key string) map[string]string
+	GetKeys(prefix string) []string
 }
 
 type ConfigData struct {
@@ -115,6 +117,20 @@ func (cd *ConfigData) GetMap(key string) map[string]string {
 	return data
 }
 
+// get the keys starting with prefix sorted
+// example GetKeys("DB_") return ["DB_HOST","DB_NAME"]
+func (cd *ConfigData) GetKeys(prefix string) []string {
+	var keys []string
+	for key := range cd.Data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 // for reload purpose
 func (cd *ConfigData) Reload(fn ConfigFunc, cc *ClientConfig) {
 	s := gocron.NewScheduler(time.UTC)
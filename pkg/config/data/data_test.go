@@ -0,0 +1,18 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetKeys(t *testing.T) {
+	config := &ConfigData{Data: map[string]interface{}{
+		"DB_TENANT_GLOBEX": `{"host":"globex"}`,
+		"DB_HOST":          "127.0.0.1",
+		"DB_TENANT_ACME":   `{"host":"acme"}`,
+	}}
+
+	assert.Equal(t, []string{"DB_TENANT_ACME", "DB_TENANT_GLOBEX"}, config.GetKeys("DB_TENANT_"))
+	assert.Empty(t, config.GetKeys("PAYMENT_"))
+}
@@ -2,6 +2,7 @@ package context
 
 import (
 	"context"
+	"fmt"
 )
 
 type ContextMetadata int
@@ -13,6 +14,8 @@ const (
 	PHONE
 	EMAIL
 	STATUS
+	TENANT_ID
+	REQUEST_ID
 )
 
 func SetContext(ctx context.Context, list map[ContextMetadata]any) context.Context {
@@ -22,3 +25,13 @@ func SetContext(ctx context.Context, list map[ContextMetadata]any) context.Conte
 
 	return ctx
 }
+
+// get metadata as string, empty when the key is not set
+func GetString(ctx context.Context, key ContextMetadata) string {
+	val := ctx.Value(key)
+	if val == nil {
+		return ""
+	}
+
+	return fmt.Sprint(val)
+}
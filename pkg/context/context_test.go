@@ -19,3 +19,12 @@ func TestSetContextMetadata(t *testing.T) {
 	assert.Equal(t, ctxVal.Value(USER_ID), "1")
 	assert.Equal(t, ctxVal.Value(AUTH), "token")
 }
+
+func TestGetStringContextMetadata(t *testing.T) {
+	ctx := SetContext(context.Background(), map[ContextMetadata]any{
+		TENANT_ID: "acme",
+	})
+
+	assert.Equal(t, "acme", GetString(ctx, TENANT_ID))
+	assert.Equal(t, "", GetString(ctx, USER_ID))
+}
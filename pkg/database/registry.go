@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	httpmiddlewaresdk "github.com/ijlik/store-app/pkg/http/middleware"
+	"github.com/jmoiron/sqlx"
+)
+
+// Opener open a connection pool for the given database data
+type Opener func(data httpmiddlewaresdk.DatabaseData) (*sqlx.DB, error)
+
+func DSN(data httpmiddlewaresdk.DatabaseData) string {
+	return fmt.Sprintf("host=%s port=%d user=%s "+
+		"password=%s dbname=%s sslmode=disable TimeZone=UTC",
+		data.Host, data.Port, data.UserName, data.Password, data.Database)
+}
+
+// open postgres connection pool
+func Open(data httpmiddlewaresdk.DatabaseData) (*sqlx.DB, error) {
+	db, err := sql.Open("postgres", DSN(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlx.NewDb(db, "postgres"), nil
+}
+
+type pool struct {
+	once sync.Once
+	db   *sqlx.DB
+	err  error
+}
+
+// Registry lazily open and cache one connection pool per tenant, database
+// data of the tenant is fetched using SecretData on first use
+type Registry struct {
+	mutex  sync.Mutex
+	def    *sqlx.DB
+	secret httpmiddlewaresdk.SecretData
+	open   Opener
+	pools  map[string]*pool
+}
+
+func NewRegistry(
+	def *sqlx.DB,
+	secret httpmiddlewaresdk.SecretData,
+	open Opener,
+) *Registry {
+	if open == nil {
+		open = Open
+	}
+
+	return &Registry{
+		def:    def,
+		secret: secret,
+		open:   open,
+		pools:  make(map[string]*pool),
+	}
+}
+
+// get connection pool of the tenant, empty tenant use the default pool
+func (r *Registry) Get(ctx context.Context, tenant string) (*sqlx.DB, error) {
+	if tenant == "" {
+		return r.def, nil
+	}
+	if r.secret == nil {
+		return nil, fmt.Errorf("tenant %s: secret data not configured", tenant)
+	}
+
+	r.mutex.Lock()
+	p, ok := r.pools[tenant]
+	if !ok {
+		p = &pool{}
+		r.pools[tenant] = p
+	}
+	r.mutex.Unlock()
+
+	p.once.Do(func() {
+		data, err := r.secret(ctx, tenant)
+		if err != nil {
+			p.err = err
+			return
+		}
+
+		p.db, p.err = r.open(data)
+	})
+
+	if p.err != nil {
+		// forget failed pool so the next request can retry
+		r.mutex.Lock()
+		if r.pools[tenant] == p {
+			delete(r.pools, tenant)
+		}
+		r.mutex.Unlock()
+
+		return nil, fmt.Errorf("tenant %s: %w", tenant, p.err)
+	}
+
+	return p.db, nil
+}
+
+// close all tenant pools, the default pool is owned by the caller
+func (r *Registry) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var firstErr error
+	for tenant, p := range r.pools {
+		if p.db != nil {
+			if err := p.db.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		delete(r.pools, tenant)
+	}
+
+	return firstErr
+}
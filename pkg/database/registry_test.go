@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	httpmiddlewaresdk "github.com/ijlik/store-app/pkg/http/middleware"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryGet(t *testing.T) {
+	def, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defaultDb := sqlx.NewDb(def, "postgres")
+	defer defaultDb.Close()
+
+	var (
+		secretCalls int
+		openCalls   int
+	)
+	secret := func(ctx context.Context, id string) (httpmiddlewaresdk.DatabaseData, error) {
+		secretCalls++
+		if id == "unknown" {
+			return httpmiddlewaresdk.DatabaseData{}, errors.New("not found")
+		}
+		return httpmiddlewaresdk.DatabaseData{Database: id}, nil
+	}
+	open := func(data httpmiddlewaresdk.DatabaseData) (*sqlx.DB, error) {
+		openCalls++
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, err
+		}
+		return sqlx.NewDb(db, "postgres"), nil
+	}
+
+	registry := NewRegistry(defaultDb, secret, open)
+	defer registry.Close()
+	ctx := context.Background()
+
+	result, err := registry.Get(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultDb, result)
+
+	first, err := registry.Get(ctx, "acme")
+	assert.NoError(t, err)
+	second, err := registry.Get(ctx, "acme")
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.NotSame(t, defaultDb, first)
+	assert.Equal(t, 1, secretCalls)
+	assert.Equal(t, 1, openCalls)
+
+	_, err = registry.Get(ctx, "unknown")
+	assert.Error(t, err)
+	_, err = registry.Get(ctx, "unknown")
+	assert.Error(t, err)
+	assert.Equal(t, 3, secretCalls)
+}
+
+func TestDSN(t *testing.T) {
+	dsn := DSN(httpmiddlewaresdk.DatabaseData{
+		UserName: "user",
+		Password: "secret",
+		Host:     "127.0.0.1",
+		Port:     5432,
+		Database: "store_app",
+	})
+	assert.Equal(t, "host=127.0.0.1 port=5432 user=user password=secret dbname=store_app sslmode=disable TimeZone=UTC", dsn)
+}
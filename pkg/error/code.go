@@ -22,6 +22,15 @@ func GetCode(code ErrCode) string {
 	return val
 }
 
+func GetReason(code ErrCode) string {
+	val, ok := mapReason[code]
+	if !ok {
+		return "SUCCESS"
+	}
+
+	return val
+}
+
 func GetMessage(code ErrCode) string {
 	val, ok := mapText[code]
 	if !ok {
@@ -46,6 +55,12 @@ const (
 	ErrTokenAlreadyUsed
 	ErrMaxUserReached
 	ErrAccessLimited
+	ErrConflict
+	ErrRetryable
+	ErrPreconditionFailed
+	ErrPreconditionRequired
+	ErrUnprocessableEntity
+	ErrUnsupportedMediaType
 )
 
 var mapCode = map[ErrCode]string{
@@ -63,6 +78,12 @@ var mapCode = map[ErrCode]string{
 	ErrTokenAlreadyUsed:     "11",
 	ErrMaxUserReached:       "12",
 	ErrAccessLimited:        "13",
+	ErrConflict:             "14",
+	ErrRetryable:            "15",
+	ErrPreconditionFailed:   "16",
+	ErrPreconditionRequired: "17",
+	ErrUnprocessableEntity:  "18",
+	ErrUnsupportedMediaType: "19",
 }
 
 var mapHttpStatus = map[ErrCode]int{
@@ -80,6 +101,12 @@ var mapHttpStatus = map[ErrCode]int{
 	ErrTokenAlreadyUsed:     http.StatusUnprocessableEntity,
 	ErrMaxUserReached:       http.StatusUnprocessableEntity,
 	ErrAccessLimited:        http.StatusForbidden,
+	ErrConflict:             http.StatusConflict,
+	ErrRetryable:            http.StatusServiceUnavailable,
+	ErrPreconditionFailed:   http.StatusPreconditionFailed,
+	ErrPreconditionRequired: http.StatusPreconditionRequired,
+	ErrUnprocessableEntity:  http.StatusUnprocessableEntity,
+	ErrUnsupportedMediaType: http.StatusUnsupportedMediaType,
 }
 
 var mapText = map[ErrCode]string{
@@ -97,4 +124,33 @@ var mapText = map[ErrCode]string{
 	ErrTokenAlreadyUsed:     "Token Already Use",
 	ErrMaxUserReached:       "Maximum 5 Users",
 	ErrAccessLimited:        "Access limited",
+	ErrConflict:             "Conflict",
+	ErrRetryable:            "Temporary Failure, Please Retry",
+	ErrPreconditionFailed:   "Precondition Failed",
+	ErrPreconditionRequired: "Precondition Required",
+	ErrUnprocessableEntity:  "Unprocessable Entity",
+	ErrUnsupportedMediaType: "Unsupported Media Type",
+}
+
+var mapReason = map[ErrCode]string{
+	ErrBadRequest:           "BAD_REQUEST",
+	ErrInternal:             "INTERNAL_ERROR",
+	ErrInvalidToken:         "INVALID_TOKEN",
+	ErrNotFound:             "NOT_FOUND",
+	ErrAlreadyRegistered:    "ALREADY_REGISTERED",
+	ErrEmptyPassword:        "EMPTY_PASSWORD",
+	ErrFailedToSendDeeplink: "FAILED_TO_SEND_DEEPLINK",
+	ErrTemporaryBlocked:     "TEMPORARY_BLOCKED",
+	ErrUnauthorize:          "UNAUTHORIZED",
+	ErrInvalidPassword:      "INVALID_PASSWORD",
+	ErrReloginNeeded:        "RELOGIN_NEEDED",
+	ErrTokenAlreadyUsed:     "TOKEN_ALREADY_USED",
+	ErrMaxUserReached:       "MAX_USER_REACHED",
+	ErrAccessLimited:        "ACCESS_LIMITED",
+	ErrConflict:             "CONFLICT",
+	ErrRetryable:            "RETRYABLE",
+	ErrPreconditionFailed:   "PRECONDITION_FAILED",
+	ErrPreconditionRequired: "PRECONDITION_REQUIRED",
+	ErrUnprocessableEntity:  "UNPROCESSABLE_ENTITY",
+	ErrUnsupportedMediaType: "UNSUPPORTED_MEDIA_TYPE",
 }
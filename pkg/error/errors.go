@@ -3,11 +3,75 @@ package error
 type ErrorService interface {
 	Error() string
 	GetCode() ErrCode
+	GetCause() error
+	GetReason() string
+	GetFields() []FieldError
+	GetMetadata() map[string]any
+	Unwrap() error
+}
+
+// reason of error carrying failing fields
+const ReasonValidationFailed = "VALIDATION_FAILED"
+
+// FieldError describe a failing field of the request, Params is used to
+// render the localised message of the reason
+type FieldError struct {
+	Field   string         `json:"field"`
+	Reason  string         `json:"reason"`
+	Message string         `json:"message"`
+	Params  map[string]any `json:"-"`
 }
 
 type errorService struct {
-	Code ErrCode
-	Msg  string
+	Code     ErrCode
+	Msg      string
+	Reason   string
+	Fields   []FieldError
+	Metadata map[string]any
+	Cause    error
+}
+
+type Option func(e *errorService)
+
+// set original error, available through errors.Is and errors.As
+func WithCause(cause error) Option {
+	return func(e *errorService) {
+		e.Cause = cause
+	}
+}
+
+// set machine readable reason, example STORE_NOT_FOUND
+func WithReason(reason string) Option {
+	return func(e *errorService) {
+		e.Reason = reason
+	}
+}
+
+// append failing field detail
+func WithField(field, reason, msg string) Option {
+	return func(e *errorService) {
+		e.Fields = append(e.Fields, FieldError{
+			Field:   field,
+			Reason:  reason,
+			Message: msg,
+		})
+	}
+}
+
+// append failing field details
+func WithFields(fields ...FieldError) Option {
+	return func(e *errorService) {
+		e.Fields = append(e.Fields, fields...)
+	}
+}
+
+func WithMetadata(key string, val any) Option {
+	return func(e *errorService) {
+		if e.Metadata == nil {
+			e.Metadata = make(map[string]any)
+		}
+		e.Metadata[key] = val
+	}
 }
 
 func (e *errorService) Error() string {
@@ -18,9 +82,59 @@ func (e *errorService) GetCode() ErrCode {
 	return e.Code
 }
 
-func DefaultServiceError(code ErrCode, msg string) ErrorService {
-	return &errorService{
+// original error for logging purpose, must not be shown to the user
+func (e *errorService) GetCause() error {
+	return e.Cause
+}
+
+// reason set on the error, or the default reason of the code
+func (e *errorService) GetReason() string {
+	if e.Reason != "" {
+		return e.Reason
+	}
+
+	return GetReason(e.Code)
+}
+
+func (e *errorService) GetFields() []FieldError {
+	return e.Fields
+}
+
+func (e *errorService) GetMetadata() map[string]any {
+	return e.Metadata
+}
+
+func (e *errorService) Unwrap() error {
+	return e.Cause
+}
+
+// Is match service error with the same code, and the same reason when the
+// target has one, so errors.Is(err, ErrStoreNotFound) work on wrapped error
+func (e *errorService) Is(target error) bool {
+	t, ok := target.(*errorService)
+	if !ok {
+		return false
+	}
+
+	return e.Code == t.Code && (t.Reason == "" || e.GetReason() == t.Reason)
+}
+
+func NewServiceError(code ErrCode, msg string, opts ...Option) ErrorService {
+	e := &errorService{
 		Code: code,
 		Msg:  msg,
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+func DefaultServiceError(code ErrCode, msg string) ErrorService {
+	return NewServiceError(code, msg)
+}
+
+func WrapServiceError(code ErrCode, msg string, cause error) ErrorService {
+	return NewServiceError(code, msg, WithCause(cause))
 }
@@ -0,0 +1,53 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceErrorWrap(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := NewServiceError(
+		ErrInternal,
+		"Internal Error",
+		WithCause(cause),
+		WithMetadata("table", "stores"),
+	)
+
+	assert.True(t, errors.Is(err, cause))
+	assert.Equal(t, cause, errors.Unwrap(err))
+	assert.Equal(t, "stores", err.GetMetadata()["table"])
+	assert.Equal(t, "INTERNAL_ERROR", err.GetReason())
+
+	var errService ErrorService
+	assert.True(t, errors.As(fmt.Errorf("create store: %w", err), &errService))
+	assert.Equal(t, ErrInternal, errService.GetCode())
+}
+
+func TestServiceErrorIs(t *testing.T) {
+	errStoreNotFound := NewServiceError(ErrNotFound, "store not found", WithReason("STORE_NOT_FOUND"))
+
+	assert.True(t, errors.Is(fmt.Errorf("get store: %w", errStoreNotFound), errStoreNotFound))
+	assert.True(t, errors.Is(errStoreNotFound, DefaultServiceError(ErrNotFound, "")))
+	assert.False(t, errors.Is(DefaultServiceError(ErrNotFound, "product not found"), errStoreNotFound))
+	assert.False(t, errors.Is(errStoreNotFound, DefaultServiceError(ErrBadRequest, "")))
+}
+
+func TestServiceErrorFields(t *testing.T) {
+	err := NewServiceError(
+		ErrBadRequest,
+		"invalid request",
+		WithReason("VALIDATION_FAILED"),
+		WithField("name", "REQUIRED", "missing name"),
+		WithFields(FieldError{Field: "phone", Reason: "INVALID_FORMAT", Message: "invalid phone"}),
+	)
+
+	assert.Equal(t, "VALIDATION_FAILED", err.GetReason())
+	assert.Equal(t, []FieldError{
+		{Field: "name", Reason: "REQUIRED", Message: "missing name"},
+		{Field: "phone", Reason: "INVALID_FORMAT", Message: "invalid phone"},
+	}, err.GetFields())
+}
@@ -3,13 +3,19 @@ package http
 import (
 	"github.com/gin-gonic/gin"
 	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/i18n"
 )
 
 type DefaultResponse struct {
-	Code     string      `json:"code"`
-	Message  string      `json:"message"`
-	Data     interface{} `json:"data,omitempty"`
-	HttpCode int         `json:"-"`
+	Code     string              `json:"code"`
+	Message  string              `json:"message"`
+	Reason   string              `json:"reason,omitempty"`
+	Errors   []errpkg.FieldError `json:"errors,omitempty"`
+	Data     interface{}         `json:"data,omitempty"`
+	HttpCode int                 `json:"-"`
+	ErrCode  errpkg.ErrCode      `json:"-"`
+	Title    string              `json:"-"`
+	Language string              `json:"-"`
 }
 
 func DefaultSuccessResponse(data interface{}) DefaultResponse {
@@ -21,23 +27,59 @@ func DefaultSuccessResponse(data interface{}) DefaultResponse {
 	}
 }
 
-func DefaultResponseErrorWithMessage(code errpkg.ErrCode, msg string) DefaultResponse {
+func DefaultResponseErrorWithMessage(code errpkg.ErrCode, msg string, fields ...errpkg.FieldError) DefaultResponse {
+	return LocalizedResponseErrorWithMessage(i18n.DefaultLanguage, code, msg, fields...)
+}
+
+// LocalizedResponseErrorWithMessage build error response with field messages
+// and empty msg rendered on lang
+func LocalizedResponseErrorWithMessage(lang string, code errpkg.ErrCode, msg string, fields ...errpkg.FieldError) DefaultResponse {
+	var errors []errpkg.FieldError
+	for _, field := range fields {
+		field.Message = fieldMessage(lang, field)
+		errors = append(errors, field)
+	}
+
+	title := codeMessage(lang, code)
 	if msg == "" {
-		msg = errpkg.GetMessage(code)
+		msg = title
 	}
 
 	return DefaultResponse{
 		Code:     errpkg.GetCode(code),
 		Message:  msg,
+		Errors:   errors,
 		HttpCode: errpkg.GetHttpStatus(code),
+		ErrCode:  code,
+		Title:    title,
+		Language: lang,
 	}
 }
 
-func BuildErrorResponse(c *gin.Context, code errpkg.ErrCode, msg string) {
-	errResponse := DefaultResponseErrorWithMessage(code, msg)
-	c.Header("Content-Type", "application/json")
-	c.JSON(errResponse.HttpCode, errResponse)
-	c.Abort()
+func DefaultResponseServiceError(err errpkg.ErrorService) DefaultResponse {
+	return LocalizedResponseServiceError(i18n.DefaultLanguage, err)
+}
+
+func LocalizedResponseServiceError(lang string, err errpkg.ErrorService) DefaultResponse {
+	resp := LocalizedResponseErrorWithMessage(lang, err.GetCode(), serviceErrorMessage(lang, err), err.GetFields()...)
+	resp.Reason = err.GetReason()
+
+	// single failing field is described by its own message
+	if resp.Reason == errpkg.ReasonValidationFailed && len(resp.Errors) == 1 {
+		resp.Message = resp.Errors[0].Message
+	}
+
+	return resp
+}
+
+// BuildErrorResponse write the error as {code,message} json, or as problem
+// document when the request Accept application/problem+json
+func BuildErrorResponse(c *gin.Context, code errpkg.ErrCode, msg string, fields ...errpkg.FieldError) {
+	writeErrorResponse(c, LocalizedResponseErrorWithMessage(language(c), code, msg, fields...))
+}
+
+func BuildServiceErrorResponse(c *gin.Context, err errpkg.ErrorService) {
+	writeErrorResponse(c, LocalizedResponseServiceError(language(c), err))
 }
 
 func BuildSuccessResponse(data interface{}, c *gin.Context) {
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultResponseErrorWithMessage(t *testing.T) {
+	resp := DefaultResponseErrorWithMessage(errpkg.ErrBadRequest, "", errpkg.FieldError{
+		Field:   "name",
+		Reason:  "REQUIRED",
+		Message: "missing name",
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.HttpCode)
+
+	body, err := json.Marshal(resp)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"code":"01","message":"Bad Request","errors":[{"field":"name","reason":"REQUIRED","message":"missing name"}]}`, string(body))
+
+	body, err = json.Marshal(DefaultResponseErrorWithMessage(errpkg.ErrNotFound, "store not found"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"code":"03","message":"store not found"}`, string(body))
+}
+
+func TestDefaultResponseServiceError(t *testing.T) {
+	resp := DefaultResponseServiceError(errpkg.NewServiceError(
+		errpkg.ErrNotFound,
+		"store not found",
+		errpkg.WithReason("STORE_NOT_FOUND"),
+	))
+
+	body, err := json.Marshal(resp)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"code":"03","message":"store not found","reason":"STORE_NOT_FOUND"}`, string(body))
+	assert.Equal(t, http.StatusNotFound, resp.HttpCode)
+}
+
+func TestLocalizedResponseServiceError(t *testing.T) {
+	validationErr := errpkg.NewServiceError(
+		errpkg.ErrBadRequest,
+		"missing name",
+		errpkg.WithReason(errpkg.ReasonValidationFailed),
+		errpkg.WithField("name", "REQUIRED", "missing name"),
+	)
+
+	resp := LocalizedResponseServiceError("id", validationErr)
+	assert.Equal(t, "nama wajib diisi", resp.Message)
+	assert.Equal(t, "nama wajib diisi", resp.Errors[0].Message)
+	assert.Equal(t, "Permintaan Tidak Valid", resp.Title)
+
+	resp = LocalizedResponseServiceError("en", validationErr)
+	assert.Equal(t, "missing name", resp.Message)
+
+	multiFieldErr := errpkg.NewServiceError(
+		errpkg.ErrBadRequest,
+		"missing name",
+		errpkg.WithReason(errpkg.ReasonValidationFailed),
+		errpkg.WithField("name", "REQUIRED", "missing name"),
+		errpkg.WithFields(errpkg.FieldError{Field: "operational_time_end", Reason: "OUT_OF_RANGE", Params: map[string]any{"min": 0, "max": 23}}),
+	)
+	resp = LocalizedResponseServiceError("id-ID", multiFieldErr)
+	assert.Equal(t, "2 isian tidak valid", resp.Message)
+	assert.Equal(t, "jam tutup harus di antara 0 dan 23", resp.Errors[1].Message)
+
+	resp = LocalizedResponseServiceError("id", errpkg.NewServiceError(errpkg.ErrNotFound, "store not found", errpkg.WithReason("STORE_NOT_FOUND")))
+	assert.Equal(t, "toko tidak ditemukan", resp.Message)
+
+	// message without own reason is kept, empty message use the code text
+	resp = LocalizedResponseServiceError("id", errpkg.DefaultServiceError(errpkg.ErrBadRequest, "invalid tenant"))
+	assert.Equal(t, "invalid tenant", resp.Message)
+	resp = LocalizedResponseServiceError("id", errpkg.DefaultServiceError(errpkg.ErrInternal, ""))
+	assert.Equal(t, "Terjadi Kesalahan Internal", resp.Message)
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// ETag return the strong entity tag of a resource version, example "3".
+// Versions of the resources embedded in the body follow the version, example
+// "3.7", so the tag change when any of them change.
+func ETag(version int, embedded ...int) string {
+	parts := make([]string, 0, len(embedded)+1)
+	parts = append(parts, strconv.Itoa(version))
+	for _, v := range embedded {
+		parts = append(parts, strconv.Itoa(v))
+	}
+
+	return strconv.Quote(strings.Join(parts, "."))
+}
+
+// ParseETag return the version of a strong entity tag written by ETag, the
+// versions of embedded resources are ignored. Weak tag never match on
+// If-Match
+func ParseETag(tag string) (int, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	parts := strings.Split(tag[1:len(tag)-1], ".")
+	for _, part := range parts[1:] {
+		if _, err := strconv.Atoi(part); err != nil {
+			return 0, false
+		}
+	}
+
+	version, err := strconv.Atoi(parts[0])
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// IfNoneMatch report whether If-None-Match header contains etag using the
+// weak comparison, * match any etag
+func IfNoneMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	assert.Equal(t, `"3"`, ETag(3))
+
+	version, ok := ParseETag(` "3" `)
+	assert.True(t, ok)
+	assert.Equal(t, 3, version)
+
+	assert.Equal(t, `"3.7"`, ETag(3, 7))
+	version, ok = ParseETag(ETag(3, 7))
+	assert.True(t, ok)
+	assert.Equal(t, 3, version)
+
+	for _, tag := range []string{"", "3", `W/"3"`, `"abc"`, `"0"`, `"`, `"3."`, `"3.x"`, `".3"`} {
+		_, ok := ParseETag(tag)
+		assert.False(t, ok, tag)
+	}
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	assert.True(t, IfNoneMatch(`"3"`, ETag(3)))
+	assert.True(t, IfNoneMatch(`"1", W/"3"`, ETag(3)))
+	assert.True(t, IfNoneMatch("*", ETag(3)))
+	assert.False(t, IfNoneMatch(`"2"`, ETag(3)))
+	assert.False(t, IfNoneMatch(`"3.7"`, ETag(3, 8)))
+	assert.False(t, IfNoneMatch("", ETag(3)))
+}
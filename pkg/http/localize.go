@@ -0,0 +1,66 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/ijlik/store-app/pkg/i18n"
+)
+
+// language of the response negotiated from Accept-Language
+func language(c *gin.Context) string {
+	return i18n.Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// localised text of the error code, default to the text of pkg/error
+func codeMessage(lang string, code errpkg.ErrCode) string {
+	if msg, ok := i18n.Translate(lang, errpkg.GetReason(code), nil); ok {
+		return msg
+	}
+
+	return errpkg.GetMessage(code)
+}
+
+// localised message of the failing field, the field label is passed to the
+// message as {field}
+func fieldMessage(lang string, field errpkg.FieldError) string {
+	params := map[string]any{}
+	for key, val := range field.Params {
+		params[key] = val
+	}
+
+	label, ok := i18n.Translate(lang, "field."+field.Field, nil)
+	if !ok {
+		label = strings.ReplaceAll(field.Field, "_", " ")
+	}
+	params["field"] = label
+
+	if msg, ok := i18n.Translate(lang, "validation."+field.Reason, params); ok {
+		return msg
+	}
+
+	return field.Message
+}
+
+// localised message of the service error, the message is translated when
+// the error has its own reason or no message
+func serviceErrorMessage(lang string, err errpkg.ErrorService) string {
+	msg := err.Error()
+	reason := err.GetReason()
+	if msg != "" && reason == errpkg.GetReason(err.GetCode()) {
+		return msg
+	}
+
+	params := map[string]any{}
+	for key, val := range err.GetMetadata() {
+		params[key] = val
+	}
+	params["count"] = len(err.GetFields())
+
+	if translated, ok := i18n.Translate(lang, reason, params); ok {
+		return translated
+	}
+
+	return msg
+}
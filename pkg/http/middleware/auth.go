@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+)
+
+const bearer = "Bearer "
+
+var (
+	errMalformedToken = errors.New("malformed token")
+	errTokenSignature = errors.New("invalid token signature")
+	errTokenExpired   = errors.New("token is expired or not valid yet")
+)
+
+// WithAuth verify the bearer token of the Authorization header and store its
+// claims for WithTenant and WithUser. The token is a JSON web token signed
+// with HS256 using secret. Request without token is anonymous, a token that
+// does not verify or has expired is rejected with 401.
+func WithAuth(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := strings.TrimSpace(c.GetHeader(authorization))
+		if header == "" {
+			c.Next()
+			return
+		}
+		if !strings.HasPrefix(header, bearer) {
+			httppkg.BuildErrorResponse(c, errpkg.ErrInvalidToken, errMalformedToken.Error())
+			return
+		}
+
+		claims, err := VerifyToken(secret, strings.TrimSpace(strings.TrimPrefix(header, bearer)), time.Now())
+		if err != nil {
+			httppkg.BuildErrorResponse(c, errpkg.ErrInvalidToken, err.Error())
+			return
+		}
+
+		SetTokenData(c, claims)
+		c.Next()
+	}
+}
+
+// VerifyToken check the HS256 signature of token and its exp and nbf claims
+// at now, then return the claims
+func VerifyToken(secret string, token string, now time.Time) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errMalformedToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errMalformedToken
+	}
+	// the algorithm is fixed so a token can not pick a weaker one
+	if header.Alg != "HS256" {
+		return nil, errTokenSignature
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errMalformedToken
+	}
+	if !hmac.Equal(signature, sign(secret, parts[0]+"."+parts[1])) {
+		return nil, errTokenSignature
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil || claims == nil {
+		return nil, errMalformedToken
+	}
+
+	exp, err := dateClaim(claims, "exp")
+	if err != nil {
+		return nil, err
+	}
+	nbf, err := dateClaim(claims, "nbf")
+	if err != nil {
+		return nil, err
+	}
+	if (!exp.IsZero() && !now.Before(exp)) || (!nbf.IsZero() && now.Before(nbf)) {
+		return nil, errTokenExpired
+	}
+
+	return claims, nil
+}
+
+// SignToken return a HS256 JSON web token of claims, used by tools and tests
+// issuing tokens for WithAuth
+func SignToken(secret string, claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sign(secret, unsigned)), nil
+}
+
+func sign(secret string, unsigned string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+
+	return mac.Sum(nil)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
+
+// date claim written in seconds since epoch, zero time when the claim is
+// missing
+func dateClaim(claims map[string]interface{}, name string) (time.Time, error) {
+	value, ok := claims[name]
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, errMalformedToken
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, errMalformedToken
+	}
+
+	return time.Unix(int64(seconds), 0), nil
+}
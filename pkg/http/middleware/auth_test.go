@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "test-secret"
+
+func newToken(t *testing.T, secret string, claims map[string]interface{}) string {
+	token, err := SignToken(secret, claims)
+	assert.NoError(t, err)
+
+	return token
+}
+
+func TestWithAuth(t *testing.T) {
+	now := time.Now()
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	valid := newToken(t, testSecret, map[string]interface{}{"user_id": "user-1", "tenant_id": "acme", "exp": now.Add(time.Hour).Unix()})
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+		expectedUser   string
+		expectedTenant string
+	}{
+		{"no token", "", http.StatusOK, "", ""},
+		{"valid", "Bearer " + valid, http.StatusOK, "user-1", "acme"},
+		{"not bearer", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, "", ""},
+		{"malformed", "Bearer abc", http.StatusUnauthorized, "", ""},
+		{"other secret", "Bearer " + newToken(t, "other-secret", map[string]interface{}{"user_id": "user-1"}), http.StatusUnauthorized, "", ""},
+		{"expired", "Bearer " + newToken(t, testSecret, map[string]interface{}{"user_id": "user-1", "exp": now.Add(-time.Minute).Unix()}), http.StatusUnauthorized, "", ""},
+		{"not valid yet", "Bearer " + newToken(t, testSecret, map[string]interface{}{"user_id": "user-1", "nbf": now.Add(time.Hour).Unix()}), http.StatusUnauthorized, "", ""},
+		{"exp not a number", "Bearer " + newToken(t, testSecret, map[string]interface{}{"user_id": "user-1", "exp": "never"}), http.StatusUnauthorized, "", ""},
+		{"alg none", "Bearer " + noneHeader + "." + strings.Split(valid, ".")[1] + ".", http.StatusUnauthorized, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			var user, tenant string
+			router := gin.New()
+			router.Use(
+				WithAuth(testSecret),
+				WithTenant(TenantFromClaim("tenant_id")),
+				WithUser("user_id"),
+			)
+			router.GET("/", func(c *gin.Context) {
+				user = pkgcontext.GetString(c.Request.Context(), pkgcontext.USER_ID)
+				tenant = pkgcontext.GetString(c.Request.Context(), pkgcontext.TENANT_ID)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedUser, user)
+			assert.Equal(t, tt.expectedTenant, tenant)
+		})
+	}
+}
@@ -26,8 +26,9 @@ func WithAllowedCORS() gin.HandlerFunc {
 		if origin := c.Request.Header.Get("Origin"); origin != "" {
 			c.Header("Access-Control-Allow-Origin", origin)
 			c.Header("Access-Control-Allow-Credentials", "true")
-			c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Menu-Slug, X-Origin-Path, X-Request-Id")
-			c.Header("Access-Control-Allow-Methods", "POST, HEAD, PATCH, OPTIONS, GET, PUT")
+			c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Menu-Slug, X-Origin-Path, X-Request-Id, X-Tenant-Id, X-Cart-Token, If-Match, If-None-Match")
+			c.Header("Access-Control-Expose-Headers", "ETag, X-Request-Id")
+			c.Header("Access-Control-Allow-Methods", "POST, HEAD, PATCH, OPTIONS, GET, PUT, DELETE")
 
 			if c.Request.Method == "OPTIONS" {
 				c.AbortWithStatus(204)
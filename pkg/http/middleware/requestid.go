@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+)
+
+const RequestIdHeader = "X-Request-Id"
+
+// WithRequestId keep the X-Request-Id of the request or generate a new one,
+// the id is set on the request context and on the response header
+func WithRequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIdHeader)
+		if id == "" || len(id) > 128 {
+			id = uuid.New().String()
+		}
+
+		ctx := pkgcontext.SetContext(c.Request.Context(), map[pkgcontext.ContextMetadata]any{
+			pkgcontext.REQUEST_ID: id,
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(RequestIdHeader, id)
+		c.Next()
+	}
+}
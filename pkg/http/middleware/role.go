@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+)
+
+// RequireRole let through only the request which token claim hold role,
+// request without token is rejected with 401 and a token of another role
+// with 403. It must run after the auth middleware storing the claims.
+func RequireRole(claim string, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data, ok := c.Get(tokenData)
+		if !ok {
+			httppkg.BuildErrorResponse(c, errpkg.ErrUnauthorize, "sign in to continue")
+			return
+		}
+
+		claims, _ := data.(map[string]interface{})
+		if value, _ := claims[claim].(string); value != role {
+			httppkg.BuildErrorResponse(c, errpkg.ErrAccessLimited, "the token does not have the "+role+" role")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name           string
+		claims         map[string]interface{}
+		expectedStatus int
+	}{
+		{"role", map[string]interface{}{"role": "staff"}, http.StatusOK},
+		{"other role", map[string]interface{}{"role": "customer"}, http.StatusForbidden},
+		{"missing claim", map[string]interface{}{"user_id": "user-1"}, http.StatusForbidden},
+		{"not string", map[string]interface{}{"role": 1}, http.StatusForbidden},
+		{"no token", nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.claims != nil {
+					SetTokenData(c, tt.claims)
+				}
+			})
+			router.Use(RequireRole("role", "staff"))
+			router.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
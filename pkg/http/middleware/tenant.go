@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	httppkg "github.com/ijlik/store-app/pkg/http"
+)
+
+const TenantHeader = "X-Tenant-Id"
+
+var tenantPattern = regexp.MustCompile("^[a-z0-9][a-z0-9_-]{0,62}$")
+
+// TenantResolver returns the tenant id of the request, or empty string when
+// the request does not carry one
+type TenantResolver func(c *gin.Context) string
+
+// resolve tenant from a request header, example X-Tenant-Id: acme
+func TenantFromHeader(header string) TenantResolver {
+	return func(c *gin.Context) string {
+		return c.GetHeader(header)
+	}
+}
+
+// resolve tenant from the first label of the host when it is a sub domain
+// of baseDomain, example acme.store.id with base domain store.id
+func TenantFromHost(baseDomain string) TenantResolver {
+	suffix := "." + strings.TrimPrefix(strings.ToLower(baseDomain), ".")
+
+	return func(c *gin.Context) string {
+		host := strings.ToLower(c.Request.Host)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		if baseDomain == "" || !strings.HasSuffix(host, suffix) {
+			return ""
+		}
+
+		sub := strings.TrimSuffix(host, suffix)
+		if strings.Contains(sub, ".") {
+			return ""
+		}
+
+		return sub
+	}
+}
+
+// resolve tenant from the token claims stored by the auth middleware,
+// claims must be stored as map[string]interface{} on tokenData key
+func TenantFromClaim(claim string) TenantResolver {
+	return func(c *gin.Context) string {
+		data, ok := c.Get(tokenData)
+		if !ok {
+			return ""
+		}
+
+		claims, ok := data.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+
+		val, ok := claims[claim].(string)
+		if !ok {
+			return ""
+		}
+
+		return val
+	}
+}
+
+// store token claims for the next handlers
+func SetTokenData(c *gin.Context, claims map[string]interface{}) {
+	c.Set(tokenData, claims)
+}
+
+// WithTenant set the tenant id on the request context. The tenant of the
+// verified token claim wins, fallbacks such as header and host are only used
+// by request without the claim, and a fallback naming another tenant than
+// the claim is rejected. Request without tenant will use the default
+// database.
+func WithTenant(claim TenantResolver, fallbacks ...TenantResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant := normalizeTenant(claim(c))
+		for _, resolve := range fallbacks {
+			val := normalizeTenant(resolve(c))
+			if val == "" {
+				continue
+			}
+			if tenant == "" {
+				tenant = val
+				break
+			}
+			if val != tenant {
+				httppkg.BuildErrorResponse(c, errpkg.ErrAccessLimited, "tenant does not match the token")
+				return
+			}
+		}
+
+		if tenant == "" {
+			c.Next()
+			return
+		}
+
+		if !tenantPattern.MatchString(tenant) {
+			httppkg.BuildErrorResponse(c, errpkg.ErrBadRequest, "invalid tenant")
+			return
+		}
+
+		ctx := pkgcontext.SetContext(c.Request.Context(), map[pkgcontext.ContextMetadata]any{
+			pkgcontext.TENANT_ID: tenant,
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// TenantContext derive the context of the tenant set on it, e.g. attaching
+// the connection pool of the tenant
+type TenantContext func(ctx context.Context) (context.Context, error)
+
+// WithTenantContext run resolve once on the request context set by
+// WithTenant, the request is rejected with 503 when the tenant can not be
+// resolved
+func WithTenantContext(resolve TenantContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, err := resolve(c.Request.Context())
+		if err != nil {
+			log.Println("resolve tenant: ", err)
+			httppkg.BuildErrorResponse(c, errpkg.ErrRetryable, "tenant is unavailable, retry later")
+			return
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func normalizeTenant(tenant string) string {
+	return strings.ToLower(strings.TrimSpace(tenant))
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTenantRouter(claim string, resolvers ...TenantResolver) (*gin.Engine, *string) {
+	gin.SetMode(gin.TestMode)
+
+	var tenant string
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if claim != "" {
+			SetTokenData(c, map[string]interface{}{"tenant_id": claim})
+		}
+	})
+	router.Use(WithTenant(TenantFromClaim("tenant_id"), resolvers...))
+	router.GET("/", func(c *gin.Context) {
+		tenant = pkgcontext.GetString(c.Request.Context(), pkgcontext.TENANT_ID)
+		c.Status(http.StatusOK)
+	})
+
+	return router, &tenant
+}
+
+func TestWithTenant(t *testing.T) {
+	tests := []struct {
+		name      string
+		claim     string
+		resolvers []TenantResolver
+		host      string
+		header    string
+		status    int
+		expected  string
+	}{
+		{"header", "", []TenantResolver{TenantFromHeader(TenantHeader)}, "api.store.id", "Acme", http.StatusOK, "acme"},
+		{"host", "", []TenantResolver{TenantFromHeader(TenantHeader), TenantFromHost("store.id")}, "acme.store.id:8080", "", http.StatusOK, "acme"},
+		{"nested host", "", []TenantResolver{TenantFromHost("store.id")}, "a.acme.store.id", "", http.StatusOK, ""},
+		{"claim", "claim", []TenantResolver{TenantFromHeader(TenantHeader)}, "store.id", "", http.StatusOK, "claim"},
+		{"claim and same header", "acme", []TenantResolver{TenantFromHeader(TenantHeader)}, "store.id", "ACME", http.StatusOK, "acme"},
+		{"claim and other header", "acme", []TenantResolver{TenantFromHeader(TenantHeader)}, "store.id", "globex", http.StatusForbidden, ""},
+		{"claim and other host", "acme", []TenantResolver{TenantFromHost("store.id")}, "globex.store.id", "", http.StatusForbidden, ""},
+		{"none", "", []TenantResolver{TenantFromHeader(TenantHeader)}, "store.id", "", http.StatusOK, ""},
+		{"invalid", "", []TenantResolver{TenantFromHeader(TenantHeader)}, "store.id", "../etc", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, tenant := newTenantRouter(tt.claim, tt.resolvers...)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Host = tt.host
+			if tt.header != "" {
+				req.Header.Set(TenantHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.expected, *tenant)
+		})
+	}
+}
+
+func TestWithTenantContext(t *testing.T) {
+	type key struct{}
+
+	tests := []struct {
+		name     string
+		err      error
+		status   int
+		expected string
+	}{
+		{"resolved", nil, http.StatusOK, "acme"},
+		{"unavailable", errors.New("tenant acme: connection refused"), http.StatusServiceUnavailable, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			var (
+				calls    int
+				resolved string
+			)
+			router := gin.New()
+			router.Use(WithTenant(TenantFromHeader(TenantHeader)))
+			router.Use(WithTenantContext(func(ctx context.Context) (context.Context, error) {
+				calls++
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return context.WithValue(ctx, key{}, pkgcontext.GetString(ctx, pkgcontext.TENANT_ID)), nil
+			}))
+			router.GET("/", func(c *gin.Context) {
+				resolved, _ = c.Request.Context().Value(key{}).(string)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(TenantHeader, "acme")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, 1, calls)
+			assert.Equal(t, tt.expected, resolved)
+		})
+	}
+}
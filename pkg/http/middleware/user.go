@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+)
+
+// WithUser set the user id on the request context from the token claims
+// stored by the auth middleware, request without the claim is anonymous
+func WithUser(claim string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data, ok := c.Get(tokenData)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		claims, ok := data.(map[string]interface{})
+		if !ok {
+			c.Next()
+			return
+		}
+
+		userId, ok := claims[claim].(string)
+		if !ok || userId == "" {
+			c.Next()
+			return
+		}
+
+		ctx := pkgcontext.SetContext(c.Request.Context(), map[pkgcontext.ContextMetadata]any{
+			pkgcontext.USER_ID: userId,
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   map[string]interface{}
+		expected string
+	}{
+		{"claim", map[string]interface{}{"user_id": "user-1"}, "user-1"},
+		{"missing claim", map[string]interface{}{"tenant_id": "acme"}, ""},
+		{"not string", map[string]interface{}{"user_id": 1}, ""},
+		{"no token", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			var user string
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.claims != nil {
+					SetTokenData(c, tt.claims)
+				}
+			})
+			router.Use(WithUser("user_id"))
+			router.GET("/", func(c *gin.Context) {
+				user = pkgcontext.GetString(c.Request.Context(), pkgcontext.USER_ID)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expected, user)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+)
+
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeProblem = "application/problem+json"
+)
+
+// base of the problem type uri, the type of a problem is the base followed by
+// the reason of the error code, example /problems/not-found
+var ProblemTypeBaseURI = "/problems/"
+
+// ProblemDetail is RFC 7807 problem document, code, reason and errors are
+// extension members
+type ProblemDetail struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     string              `json:"code"`
+	Reason   string              `json:"reason,omitempty"`
+	Errors   []errpkg.FieldError `json:"errors,omitempty"`
+}
+
+func ProblemType(code errpkg.ErrCode) string {
+	reason := strings.ToLower(errpkg.GetReason(code))
+	return ProblemTypeBaseURI + strings.ReplaceAll(reason, "_", "-")
+}
+
+func NewProblemDetail(resp DefaultResponse, requestId string) ProblemDetail {
+	problem := ProblemDetail{
+		Type:   ProblemType(resp.ErrCode),
+		Title:  resp.Title,
+		Status: resp.HttpCode,
+		Detail: resp.Message,
+		Code:   resp.Code,
+		Reason: resp.Reason,
+		Errors: resp.Errors,
+	}
+	if requestId != "" {
+		problem.Instance = "urn:request:" + requestId
+	}
+
+	return problem
+}
+
+// WantsProblem report whether the Accept header prefer problem document over
+// plain json, the default response shape is kept when both are equal or
+// none is listed
+func WantsProblem(accept string) bool {
+	var problemQ, jsonQ float64
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if val, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(val, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case ContentTypeProblem:
+			if q > problemQ {
+				problemQ = q
+			}
+		case ContentTypeJSON:
+			if q > jsonQ {
+				jsonQ = q
+			}
+		}
+	}
+
+	return problemQ > 0 && problemQ > jsonQ
+}
+
+func writeErrorResponse(c *gin.Context, resp DefaultResponse) {
+	c.Header("Vary", "Accept, Accept-Language")
+	if resp.Language != "" {
+		c.Header("Content-Language", resp.Language)
+	}
+	if WantsProblem(c.GetHeader("Accept")) {
+		requestId := pkgcontext.GetString(c.Request.Context(), pkgcontext.REQUEST_ID)
+		c.Header("Content-Type", ContentTypeProblem)
+		c.JSON(resp.HttpCode, NewProblemDetail(resp, requestId))
+		c.Abort()
+		return
+	}
+
+	c.Header("Content-Type", ContentTypeJSON)
+	c.JSON(resp.HttpCode, resp)
+	c.Abort()
+}
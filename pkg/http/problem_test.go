@@ -0,0 +1,82 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	pkgcontext "github.com/ijlik/store-app/pkg/context"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWantsProblem(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/problem+json", true},
+		{"application/json, application/problem+json", false},
+		{"application/json;q=0.5, application/problem+json", true},
+		{"application/problem+json;q=0.2, application/json;q=0.9", false},
+		{"text/html, application/problem+json;q=0.8", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, WantsProblem(tt.accept), tt.accept)
+	}
+}
+
+func TestBuildServiceErrorResponseProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/store/:id", func(c *gin.Context) {
+		ctx := pkgcontext.SetContext(c.Request.Context(), map[pkgcontext.ContextMetadata]any{
+			pkgcontext.REQUEST_ID: "req-1",
+		})
+		c.Request = c.Request.WithContext(ctx)
+		BuildServiceErrorResponse(c, errpkg.NewServiceError(
+			errpkg.ErrBadRequest,
+			"missing name",
+			errpkg.WithReason("VALIDATION_FAILED"),
+			errpkg.WithField("name", "REQUIRED", "missing name"),
+		))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/store/1", nil)
+	req.Header.Set("Accept", ContentTypeProblem)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ContentTypeProblem, w.Header().Get("Content-Type"))
+
+	var problem ProblemDetail
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, ProblemDetail{
+		Type:     "/problems/bad-request",
+		Title:    "Bad Request",
+		Status:   http.StatusBadRequest,
+		Detail:   "missing name",
+		Instance: "urn:request:req-1",
+		Code:     "01",
+		Reason:   "VALIDATION_FAILED",
+		Errors: []errpkg.FieldError{
+			{Field: "name", Reason: "REQUIRED", Message: "missing name"},
+		},
+	}, problem)
+
+	req = httptest.NewRequest(http.MethodGet, "/store/1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), ContentTypeJSON)
+	assert.JSONEq(t, `{"code":"01","message":"missing name","reason":"VALIDATION_FAILED","errors":[{"field":"name","reason":"REQUIRED","message":"missing name"}]}`, w.Body.String())
+}
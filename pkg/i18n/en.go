@@ -0,0 +1,66 @@
+package i18n
+
+// en only list messages which are not the default text of pkg/error
+var en = Catalog{
+	"STORE_NOT_FOUND":               "store not found",
+	"PRODUCT_NOT_FOUND":             "product not found",
+	"STORE_ALREADY_EXISTS":          "store with the same name already exists",
+	"PRODUCT_ALREADY_EXISTS":        "product with the same name already exists",
+	"PRODUCT_SKU_ALREADY_EXISTS":    "product with the same sku already exists",
+	"ALREADY_EXISTS":                "resource already exists",
+	"REFERENCE_NOT_FOUND":           "referenced resource does not exist",
+	"INVALID_VALUE":                 "invalid value",
+	"MISSING_VALUE":                 "missing {field}",
+	"VALUE_TOO_LONG":                "value too long",
+	"INVALID_FORMAT":                "invalid value format",
+	"VALIDATION_FAILED":             "{count} fields are invalid",
+	"PRICE_SCHEDULE_NOT_FOUND":      "price schedule not found",
+	"PRICE_SCHEDULE_CONFLICT":       "price schedule overlaps another schedule of the product",
+	"PRICE_SCHEDULE_CLOSED":         "price schedule has already ended",
+	"PROMOTION_NOT_FOUND":           "promotion not found",
+	"PROMOTION_CODE_ALREADY_EXISTS": "promotion with the same code already exists",
+	"COUPON_NOT_FOUND":              "coupon not found",
+	"COUPON_EXPIRED":                "coupon is not valid at this time",
+	"COUPON_USAGE_EXCEEDED":         "coupon usage limit has been reached",
+	"COUPON_NOT_APPLICABLE":         "coupon does not apply to the items",
+	"CART_NOT_FOUND":                "cart not found",
+	"CART_ITEM_NOT_FOUND":           "product is not in the cart",
+	"CART_FULL":                     "cart has reached the maximum number of products",
+	"CART_EMPTY":                    "cart has no product of the store",
+	"CART_PRICE_CHANGED":            "price of products in the cart has changed, review the cart and retry",
+	"SIGN_IN_REQUIRED":              "sign in to continue",
+	"STORE_CLOSED":                  "store is closed at this time",
+	"OUT_OF_STOCK":                  "product is out of stock",
+	"ORDER_NOT_FOUND":               "order not found",
+	"INVALID_STATUS_TRANSITION":     "order can not move to this status",
+	"ORDER_NOT_PAYABLE":             "order is not waiting for payment",
+	"INVALID_SIGNATURE":             "invalid webhook signature",
+	"PAYMENT_UNAVAILABLE":           "payment provider is unavailable, retry later",
+	"ORDER_NOT_REFUNDABLE":          "order has no captured payment left to refund",
+	"ORDER_ITEM_NOT_FOUND":          "order item not found",
+	"REFUND_QUANTITY_EXCEEDED":      "refund quantity exceeds the quantity left on the order item",
+	"REFUND_EXCEEDS_CAPTURED":       "refund exceeds the captured amount left on the payment",
+	"TAX_RULE_NOT_FOUND":            "tax rule not found",
+	"DELIVERY_ZONE_NOT_FOUND":       "delivery zone not found",
+	"DELIVERY_UNAVAILABLE":          "store does not deliver to this location",
+	"PICKUP_UNAVAILABLE":            "store does not offer pickup",
+	"PICKUP_SLOT_INVALID":           "pickup slot is not offered",
+	"PICKUP_SLOT_FULL":              "pickup slot is fully booked",
+	"PICKUP_ALREADY_BOOKED":         "order already has a pickup slot booked",
+
+	"validation.REQUIRED":      "missing {field}",
+	"validation.OUT_OF_RANGE":  "{field} must be between {min} and {max}",
+	"validation.MIN":           "{field} must be at least {min}",
+	"validation.MAX":           "{field} must be at most {max}",
+	"validation.MAX_LENGTH":    "{field} must be at most {max} characters",
+	"validation.INVALID_PHONE": "{field} must be a phone number in E.164 format, example +6281234567890",
+	"validation.INVALID_UUID":  "{field} must be a valid id",
+	"validation.NOT_FOUND":     "{field} not found",
+	"validation.INVALID_TYPE":  "{field} has an invalid type",
+	"validation.UNKNOWN_FIELD": "{field} is not a known field",
+	"validation.AFTER":         "{field} must be after {after}",
+	"validation.ONE_OF":        "{field} must be one of {values}",
+
+	"validation.INVALID_POSTAL_CODE": "{field} must be a valid postal code",
+	"validation.INVALID_COUNTRY":     "{field} must be a two letters country code, example ID",
+}
@@ -0,0 +1,112 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const DefaultLanguage = "en"
+
+// Catalog map message key to message template, parameter is written as
+// {name} on the template
+type Catalog map[string]string
+
+var catalogs = map[string]Catalog{
+	"en": en,
+	"id": id,
+}
+
+// Supported report whether the language, or its base language, has a catalog
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	if !ok {
+		_, ok = catalogs[base(lang)]
+	}
+
+	return ok
+}
+
+// Negotiate pick the supported language with the highest quality from an
+// Accept-Language header, DefaultLanguage is returned when none is supported
+// example "id-ID,id;q=0.9,en;q=0.8" return "id-id"
+func Negotiate(acceptLanguage string) string {
+	type tag struct {
+		lang string
+		q    float64
+	}
+
+	var tags []tag
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		lang, param, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if lang == "" || lang == "*" {
+			continue
+		}
+
+		q := 1.0
+		if param = strings.TrimSpace(param); strings.HasPrefix(param, "q=") {
+			parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		tags = append(tags, tag{lang, q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	for _, t := range tags {
+		if Supported(t.lang) {
+			return t.lang
+		}
+	}
+
+	return DefaultLanguage
+}
+
+// Translate render the message of key on lang, falling back to the base
+// language then DefaultLanguage. Return false when no catalog has the key.
+func Translate(lang, key string, params map[string]any) (string, bool) {
+	for _, l := range []string{lang, base(lang), DefaultLanguage} {
+		catalog, ok := catalogs[l]
+		if !ok {
+			continue
+		}
+
+		if msg, ok := catalog[key]; ok {
+			return render(msg, params), true
+		}
+	}
+
+	return "", false
+}
+
+func render(msg string, params map[string]any) string {
+	if len(params) == 0 {
+		return msg
+	}
+
+	pairs := make([]string, 0, len(params)*2)
+	for key, val := range params {
+		pairs = append(pairs, "{"+key+"}", fmt.Sprint(val))
+	}
+
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+func base(lang string) string {
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		return lang[:i]
+	}
+
+	return lang
+}
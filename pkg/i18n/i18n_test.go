@@ -0,0 +1,45 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{"", "en"},
+		{"*", "en"},
+		{"id", "id"},
+		{"id-ID,id;q=0.9,en;q=0.8", "id-id"},
+		{"fr-FR,fr;q=0.9", "en"},
+		{"fr;q=0.9,en;q=0.5,id;q=0.8", "id"},
+		{"id;q=0,en", "en"},
+		{"en-GB;q=0.4,ms;q=0.9", "en-gb"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Negotiate(tt.acceptLanguage), tt.acceptLanguage)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	msg, ok := Translate("id-id", "validation.OUT_OF_RANGE", map[string]any{"field": "jam buka", "min": 0, "max": 23})
+	assert.True(t, ok)
+	assert.Equal(t, "jam buka harus di antara 0 dan 23", msg)
+
+	msg, ok = Translate("en", "validation.REQUIRED", map[string]any{"field": "name"})
+	assert.True(t, ok)
+	assert.Equal(t, "missing name", msg)
+
+	// fallback to default language
+	msg, ok = Translate("fr", "STORE_NOT_FOUND", nil)
+	assert.True(t, ok)
+	assert.Equal(t, "store not found", msg)
+
+	_, ok = Translate("id", "UNKNOWN_REASON", nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,143 @@
+package i18n
+
+var id = Catalog{
+	"BAD_REQUEST":             "Permintaan Tidak Valid",
+	"INTERNAL_ERROR":          "Terjadi Kesalahan Internal",
+	"INVALID_TOKEN":           "Token Tidak Valid",
+	"NOT_FOUND":               "Tidak Ditemukan",
+	"ALREADY_REGISTERED":      "Pengguna Sudah Terdaftar, Silakan Masuk",
+	"EMPTY_PASSWORD":          "Pengguna Sudah Terdaftar, Silakan Atur Kata Sandi",
+	"FAILED_TO_SEND_DEEPLINK": "Gagal Mengirim DeepLink",
+	"TEMPORARY_BLOCKED":       "Pengguna Diblokir Sementara",
+	"UNAUTHORIZED":            "Tidak Memiliki Akses",
+	"INVALID_PASSWORD":        "Kata sandi tidak valid",
+	"RELOGIN_NEEDED":          "Pengguna Sudah Keluar, Silakan Masuk Kembali",
+	"TOKEN_ALREADY_USED":      "Token Sudah Digunakan",
+	"MAX_USER_REACHED":        "Maksimal 5 Pengguna",
+	"ACCESS_LIMITED":          "Akses dibatasi",
+	"CONFLICT":                "Data Bertentangan",
+	"RETRYABLE":               "Gagal Sementara, Silakan Coba Lagi",
+	"PRECONDITION_FAILED":     "Prasyarat Tidak Terpenuhi",
+	"PRECONDITION_REQUIRED":   "Prasyarat Diperlukan",
+	"UNPROCESSABLE_ENTITY":    "Permintaan Tidak Dapat Diproses",
+
+	"STORE_NOT_FOUND":               "toko tidak ditemukan",
+	"PRODUCT_NOT_FOUND":             "produk tidak ditemukan",
+	"STORE_ALREADY_EXISTS":          "toko dengan nama yang sama sudah ada",
+	"PRODUCT_ALREADY_EXISTS":        "produk dengan nama yang sama sudah ada",
+	"PRODUCT_SKU_ALREADY_EXISTS":    "produk dengan sku yang sama sudah ada",
+	"ALREADY_EXISTS":                "data sudah ada",
+	"REFERENCE_NOT_FOUND":           "data yang dirujuk tidak ditemukan",
+	"INVALID_VALUE":                 "nilai tidak valid",
+	"MISSING_VALUE":                 "{field} wajib diisi",
+	"VALUE_TOO_LONG":                "nilai terlalu panjang",
+	"INVALID_FORMAT":                "format nilai tidak valid",
+	"VALIDATION_FAILED":             "{count} isian tidak valid",
+	"PRICE_SCHEDULE_NOT_FOUND":      "jadwal harga tidak ditemukan",
+	"PRICE_SCHEDULE_CONFLICT":       "jadwal harga bertabrakan dengan jadwal lain dari produk",
+	"PRICE_SCHEDULE_CLOSED":         "jadwal harga sudah berakhir",
+	"PROMOTION_NOT_FOUND":           "promosi tidak ditemukan",
+	"PROMOTION_CODE_ALREADY_EXISTS": "promosi dengan kode yang sama sudah ada",
+	"COUPON_NOT_FOUND":              "kupon tidak ditemukan",
+	"COUPON_EXPIRED":                "kupon tidak berlaku saat ini",
+	"COUPON_USAGE_EXCEEDED":         "batas pemakaian kupon sudah tercapai",
+	"COUPON_NOT_APPLICABLE":         "kupon tidak berlaku untuk barang yang dipilih",
+	"CART_NOT_FOUND":                "keranjang tidak ditemukan",
+	"CART_ITEM_NOT_FOUND":           "produk tidak ada di keranjang",
+	"CART_FULL":                     "keranjang sudah mencapai jumlah produk maksimal",
+	"CART_EMPTY":                    "keranjang tidak berisi produk dari toko ini",
+	"CART_PRICE_CHANGED":            "harga produk di keranjang berubah, periksa keranjang lalu coba lagi",
+	"SIGN_IN_REQUIRED":              "silakan masuk untuk melanjutkan",
+	"STORE_CLOSED":                  "toko sedang tutup",
+	"OUT_OF_STOCK":                  "stok produk habis",
+	"ORDER_NOT_FOUND":               "pesanan tidak ditemukan",
+	"INVALID_STATUS_TRANSITION":     "status pesanan tidak dapat diubah ke status ini",
+	"ORDER_NOT_PAYABLE":             "pesanan tidak sedang menunggu pembayaran",
+	"INVALID_SIGNATURE":             "tanda tangan webhook tidak valid",
+	"PAYMENT_UNAVAILABLE":           "penyedia pembayaran sedang tidak tersedia, coba lagi nanti",
+	"ORDER_NOT_REFUNDABLE":          "pesanan tidak memiliki pembayaran yang dapat dikembalikan",
+	"ORDER_ITEM_NOT_FOUND":          "item pesanan tidak ditemukan",
+	"REFUND_QUANTITY_EXCEEDED":      "jumlah pengembalian melebihi sisa jumlah item pesanan",
+	"REFUND_EXCEEDS_CAPTURED":       "pengembalian melebihi sisa dana yang telah dibayar",
+	"TAX_RULE_NOT_FOUND":            "aturan pajak tidak ditemukan",
+	"DELIVERY_ZONE_NOT_FOUND":       "zona pengiriman tidak ditemukan",
+	"DELIVERY_UNAVAILABLE":          "toko tidak melayani pengiriman ke lokasi ini",
+	"PICKUP_UNAVAILABLE":            "toko tidak melayani pengambilan di toko",
+	"PICKUP_SLOT_INVALID":           "slot pengambilan tidak tersedia",
+	"PICKUP_SLOT_FULL":              "slot pengambilan sudah penuh",
+	"PICKUP_ALREADY_BOOKED":         "pesanan sudah memiliki slot pengambilan",
+
+	"validation.REQUIRED":      "{field} wajib diisi",
+	"validation.OUT_OF_RANGE":  "{field} harus di antara {min} dan {max}",
+	"validation.MIN":           "{field} minimal {min}",
+	"validation.MAX":           "{field} maksimal {max}",
+	"validation.MAX_LENGTH":    "{field} maksimal {max} karakter",
+	"validation.INVALID_PHONE": "{field} harus berformat E.164, contoh +6281234567890",
+	"validation.INVALID_UUID":  "{field} harus berupa id yang valid",
+	"validation.NOT_FOUND":     "{field} tidak ditemukan",
+	"validation.INVALID_TYPE":  "tipe {field} tidak valid",
+	"validation.UNKNOWN_FIELD": "{field} tidak dikenal",
+	"validation.AFTER":         "{field} harus setelah {after}",
+	"validation.ONE_OF":        "{field} harus salah satu dari {values}",
+
+	"validation.INVALID_POSTAL_CODE": "{field} harus berupa kode pos yang valid",
+	"validation.INVALID_COUNTRY":     "{field} harus berupa kode negara dua huruf, contoh ID",
+
+	"field.name":                   "nama",
+	"field.address":                "alamat",
+	"field.phone":                  "nomor telepon",
+	"field.operational_time_start": "jam buka",
+	"field.operational_time_end":   "jam tutup",
+	"field.price":                  "harga",
+	"field.description":            "deskripsi",
+	"field.store_id":               "id toko",
+	"field.sku":                    "sku",
+	"field.ids":                    "daftar id",
+	"field.effective_from":         "berlaku mulai",
+	"field.effective_to":           "berlaku sampai",
+	"field.category":               "kategori",
+	"field.code":                   "kode",
+	"field.discount_type":          "jenis diskon",
+	"field.discount_value":         "nilai diskon",
+	"field.scope":                  "cakupan",
+	"field.scope_id":               "id cakupan",
+	"field.starts_at":              "mulai",
+	"field.ends_at":                "berakhir",
+	"field.usage_limit":            "batas pemakaian",
+	"field.usage_limit_per_user":   "batas pemakaian per pengguna",
+	"field.items":                  "daftar barang",
+	"field.product_id":             "id produk",
+	"field.quantity":               "jumlah",
+	"field.coupon_code":            "kode kupon",
+	"field.status":                 "status",
+	"field.reason":                 "alasan",
+	"field.stock":                  "stok",
+	"field.tax_region":             "wilayah pajak",
+	"field.region":                 "wilayah",
+	"field.rate":                   "tarif",
+	"field.rounding":               "pembulatan",
+	"field.location":               "lokasi",
+	"field.weight":                 "berat",
+	"field.type":                   "jenis",
+	"field.radius_km":              "radius km",
+	"field.polygon":                "poligon",
+	"field.rates":                  "tarif",
+	"field.slot_minutes":           "durasi slot",
+	"field.capacity":               "kapasitas",
+	"field.lead_minutes":           "jeda pemesanan",
+	"field.days_ahead":             "jumlah hari",
+	"field.order_id":               "id pesanan",
+	"field.lat":                    "lintang",
+	"field.lng":                    "bujur",
+	"field.radiusKm":               "radius km",
+	"field.city":                   "kota",
+	"field.province":               "provinsi",
+
+	"field.postal_address":             "alamat pos",
+	"field.postal_address.street":      "jalan",
+	"field.postal_address.district":    "kecamatan",
+	"field.postal_address.city":        "kota",
+	"field.postal_address.province":    "provinsi",
+	"field.postal_address.postal_code": "kode pos",
+	"field.postal_address.country":     "negara",
+}
@@ -0,0 +1,60 @@
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// ContentType is the media type of a JSON merge patch, RFC 7386
+const ContentType = "application/merge-patch+json"
+
+var ErrNotObject = errors.New("merge patch must be a JSON object")
+
+// Document is a JSON merge patch of a flat resource. A member set to null
+// remove the value, a missing member keep the current value.
+type Document map[string]json.RawMessage
+
+// Parse decode data into a document, the patch must be a JSON object
+func Parse(data []byte) (Document, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return nil, ErrNotObject
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// Has report whether the patch change the field, including to null
+func (d Document) Has(field string) bool {
+	_, ok := d[field]
+	return ok
+}
+
+// IsNull report whether the patch remove the field
+func (d Document) IsNull(field string) bool {
+	raw, ok := d[field]
+	return ok && bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// Decode unmarshal the value of field into v
+func (d Document) Decode(field string, v any) error {
+	return json.Unmarshal(d[field], v)
+}
+
+// Fields return the patched fields sorted by name
+func (d Document) Fields() []string {
+	fields := make([]string, 0, len(d))
+	for field := range d {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
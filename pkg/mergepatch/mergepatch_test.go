@@ -0,0 +1,36 @@
+package mergepatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(` {"price": 18000, "sku": null, "name": "Kopi"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "price", "sku"}, doc.Fields())
+
+	assert.True(t, doc.Has("sku"))
+	assert.True(t, doc.IsNull("sku"))
+	assert.False(t, doc.IsNull("price"))
+	assert.False(t, doc.Has("description"))
+	assert.False(t, doc.IsNull("description"))
+
+	var price float32
+	assert.NoError(t, doc.Decode("price", &price))
+	assert.Equal(t, float32(18000), price)
+
+	var name int
+	assert.Error(t, doc.Decode("name", &name))
+}
+
+func TestParseNotObject(t *testing.T) {
+	for _, data := range []string{"", "null", "[]", `"name"`} {
+		_, err := Parse([]byte(data))
+		assert.ErrorIs(t, err, ErrNotObject, data)
+	}
+
+	_, err := Parse([]byte(`{"name":`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+package slug
+
+import (
+	"crypto/rand"
+	"math/big"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// default max length, the size of url columns
+const MaxLength = 100
+
+// length of the random slug used when nothing usable remains from the input
+const RandomLength = 8
+
+const randomAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+var separator = regexp.MustCompile("[^a-z0-9]+")
+
+type Option func(s *Slugger)
+
+// max length in bytes of the slug, the slug is cut on a word boundary when
+// possible
+func WithMaxLength(n int) Option {
+	return func(s *Slugger) {
+		s.maxLength = n
+	}
+}
+
+// slugs used by the routes, example new or edit, a reserved slug get -1
+// suffix which is never produced by counter suffixes starting from 2
+func WithReserved(words ...string) Option {
+	return func(s *Slugger) {
+		for _, word := range words {
+			s.reserved[word] = true
+		}
+	}
+}
+
+// generator of fallback slug, default is a random lowercase alphanumeric
+func WithRandom(random func() string) Option {
+	return func(s *Slugger) {
+		s.random = random
+	}
+}
+
+// Slugger build url friendly slug from any unicode text
+type Slugger struct {
+	maxLength int
+	reserved  map[string]bool
+	random    func() string
+}
+
+func New(opts ...Option) *Slugger {
+	s := &Slugger{
+		maxLength: MaxLength,
+		reserved:  make(map[string]bool),
+		random:    Random,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Make normalise, transliterate and truncate input. Input without any
+// usable letter, example a Chinese name, get a random slug.
+func (s *Slugger) Make(input string) string {
+	slug := Truncate(separator.ReplaceAllString(Transliterate(input), "-"), s.maxLength)
+	if slug == "" {
+		return s.random()
+	}
+
+	if s.reserved[slug] {
+		slug = Truncate(slug, s.maxLength-2) + "-1"
+	}
+
+	return slug
+}
+
+// Fallback report whether input has no usable letter, so Make give it a
+// random slug which change on every call
+func (s *Slugger) Fallback(input string) bool {
+	return Truncate(separator.ReplaceAllString(Transliterate(input), "-"), s.maxLength) == ""
+}
+
+// Transliterate lower case input and write it with ascii letters, letters
+// of supported scripts are replaced using the tables and other letters lose
+// their marks. Letters without transliteration are kept as is.
+func Transliterate(input string) string {
+	var b strings.Builder
+	for _, r := range norm.NFC.String(strings.ToLower(input)) {
+		if t, ok := transliteration[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+
+		for _, d := range norm.NFKD.String(string(r)) {
+			if unicode.Is(unicode.Mn, d) {
+				continue
+			}
+			if t, ok := transliteration[d]; ok {
+				b.WriteString(t)
+				continue
+			}
+			b.WriteRune(d)
+		}
+	}
+
+	return b.String()
+}
+
+// Truncate cut slug to at most n bytes, on the last separator when there is
+// one, trailing separator is removed
+func Truncate(slug string, n int) string {
+	slug = strings.Trim(slug, "-")
+	if len(slug) <= n {
+		return slug
+	}
+	if n <= 0 {
+		return ""
+	}
+
+	cut := slug[:n]
+	if slug[n] != '-' {
+		if i := strings.LastIndexByte(cut, '-'); i > 0 {
+			cut = cut[:i]
+		}
+	}
+
+	return strings.Trim(cut, "-")
+}
+
+// Random return a random lowercase alphanumeric slug of RandomLength
+func Random() string {
+	max := big.NewInt(int64(len(randomAlphabet)))
+	b := make([]byte, RandomLength)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = randomAlphabet[n.Int64()]
+	}
+
+	return string(b)
+}
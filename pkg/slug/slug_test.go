@@ -0,0 +1,74 @@
+package slug
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMake(t *testing.T) {
+	s := New()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Kopi Kenangan – Jakarta", "kopi-kenangan-jakarta"},
+		{"Café Ümlaut", "cafe-umlaut"},
+		{"Straße", "strasse"},
+		{"Ｋｏｐｉ　Ｓｕｓｕ", "kopi-susu"},
+		{"Кофейня Пушкин", "kofeynya-pushkin"},
+		{"Καφές", "kafes"},
+		{"مقهى", "mqha"},
+		{"Tea & Coffee", "tea-and-coffee"},
+		{"  Es Teh, Manis!  ", "es-teh-manis"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, s.Make(tt.input))
+		})
+	}
+}
+
+func TestMakeRandomFallback(t *testing.T) {
+	s := New()
+
+	for _, input := range []string{"", "咖啡店", "!!!"} {
+		slug := s.Make(input)
+		assert.Regexp(t, regexp.MustCompile("^[a-z0-9]{8}$"), slug)
+		assert.True(t, s.Fallback(input))
+	}
+	assert.False(t, s.Fallback("Kopi 咖啡"))
+
+	assert.Equal(t, "fixed", New(WithRandom(func() string { return "fixed" })).Make("咖啡店"))
+}
+
+func TestMakeReserved(t *testing.T) {
+	s := New(WithReserved("new", "by-url"))
+
+	assert.Equal(t, "new-1", s.Make("New"))
+	assert.Equal(t, "by-url-1", s.Make("By URL"))
+	assert.Equal(t, "new-york", s.Make("New York"))
+}
+
+func TestMakeMaxLength(t *testing.T) {
+	s := New(WithMaxLength(12))
+
+	assert.Equal(t, "kopi", s.Make("Kopi Kenangan Jakarta"))
+	assert.Equal(t, "abcdefghijkl", s.Make("abcdefghijklmnop"))
+
+	long := New().Make(strings.Repeat("kopi ", 30))
+	assert.LessOrEqual(t, len(long), MaxLength)
+	assert.False(t, strings.HasSuffix(long, "-"))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "kopi-susu", Truncate("kopi-susu", 9))
+	assert.Equal(t, "kopi", Truncate("kopi-susu", 8))
+	assert.Equal(t, "kopi", Truncate("kopi-susu", 5))
+	assert.Equal(t, "kop", Truncate("kopi-susu", 3))
+	assert.Equal(t, "", Truncate("kopi-susu", 0))
+}
@@ -0,0 +1,123 @@
+package slug
+
+// transliteration of lower case letters, letters decomposing to an ascii
+// letter and a mark, example é, are handled by the normalisation
+var transliteration = map[rune]string{
+	// latin
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+	'ø': "o",
+	'đ': "d",
+	'ð': "d",
+	'ł': "l",
+	'þ': "th",
+	'ı': "i",
+	'ħ': "h",
+	'ŋ': "ng",
+	'&': "-and-",
+
+	// cyrillic
+	'а': "a",
+	'б': "b",
+	'в': "v",
+	'г': "g",
+	'ґ': "g",
+	'д': "d",
+	'е': "e",
+	'ё': "yo",
+	'є': "ye",
+	'ж': "zh",
+	'з': "z",
+	'и': "i",
+	'і': "i",
+	'ї': "yi",
+	'й': "y",
+	'к': "k",
+	'л': "l",
+	'м': "m",
+	'н': "n",
+	'о': "o",
+	'п': "p",
+	'р': "r",
+	'с': "s",
+	'т': "t",
+	'у': "u",
+	'ф': "f",
+	'х': "kh",
+	'ц': "ts",
+	'ч': "ch",
+	'ш': "sh",
+	'щ': "shch",
+	'ъ': "",
+	'ы': "y",
+	'ь': "",
+	'э': "e",
+	'ю': "yu",
+	'я': "ya",
+
+	// greek
+	'α': "a",
+	'β': "v",
+	'γ': "g",
+	'δ': "d",
+	'ε': "e",
+	'ζ': "z",
+	'η': "i",
+	'θ': "th",
+	'ι': "i",
+	'κ': "k",
+	'λ': "l",
+	'μ': "m",
+	'ν': "n",
+	'ξ': "x",
+	'ο': "o",
+	'π': "p",
+	'ρ': "r",
+	'σ': "s",
+	'ς': "s",
+	'τ': "t",
+	'υ': "y",
+	'φ': "f",
+	'χ': "ch",
+	'ψ': "ps",
+	'ω': "o",
+
+	// arabic, short vowels are not written
+	'ء': "",
+	'ا': "a",
+	'أ': "a",
+	'إ': "i",
+	'آ': "aa",
+	'ب': "b",
+	'ت': "t",
+	'ث': "th",
+	'ج': "j",
+	'ح': "h",
+	'خ': "kh",
+	'د': "d",
+	'ذ': "dh",
+	'ر': "r",
+	'ز': "z",
+	'س': "s",
+	'ش': "sh",
+	'ص': "s",
+	'ض': "d",
+	'ط': "t",
+	'ظ': "z",
+	'ع': "",
+	'غ': "gh",
+	'ف': "f",
+	'ق': "q",
+	'ك': "k",
+	'ل': "l",
+	'م': "m",
+	'ن': "n",
+	'ه': "h",
+	'ة': "h",
+	'و': "w",
+	'ي': "y",
+	'ى': "a",
+	'ئ': "y",
+	'ؤ': "w",
+}
@@ -0,0 +1,241 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	errpkg "github.com/ijlik/store-app/pkg/error"
+)
+
+// field validation reason, message of each reason is on the i18n catalog
+// with validation. prefix
+const (
+	ReasonRequired     = "REQUIRED"
+	ReasonMaxLength    = "MAX_LENGTH"
+	ReasonMin          = "MIN"
+	ReasonMax          = "MAX"
+	ReasonOutOfRange   = "OUT_OF_RANGE"
+	ReasonInvalidPhone = "INVALID_PHONE"
+	ReasonInvalidUUID  = "INVALID_UUID"
+	ReasonNotFound     = "NOT_FOUND"
+	ReasonInvalidType  = "INVALID_TYPE"
+	ReasonUnknownField = "UNKNOWN_FIELD"
+	ReasonAfter        = "AFTER"
+	ReasonOneOf        = "ONE_OF"
+
+	ReasonInvalidPostalCode = "INVALID_POSTAL_CODE"
+	ReasonInvalidCountry    = "INVALID_COUNTRY"
+)
+
+// E.164, plus sign followed by up to 15 digits
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// letters and digits, inner space or dash allowed, 3 to 10 characters
+var postalCodePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 -]{1,8}[A-Za-z0-9]$`)
+
+// ISO 3166-1 alpha-2, upper case
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Validator collect every failing field, a field is checked by the next
+// rules only while it has not failed
+type Validator struct {
+	fields []errpkg.FieldError
+	failed map[string]bool
+}
+
+func New() *Validator {
+	return &Validator{
+		failed: make(map[string]bool),
+	}
+}
+
+// Check add a failing field when ok is false, used for custom rule
+func (v *Validator) Check(ok bool, field, reason, msg string, params map[string]any) *Validator {
+	if ok || v.failed[field] {
+		return v
+	}
+
+	v.failed[field] = true
+	v.fields = append(v.fields, errpkg.FieldError{
+		Field:   field,
+		Reason:  reason,
+		Message: msg,
+		Params:  params,
+	})
+
+	return v
+}
+
+func (v *Validator) Required(field, value string) *Validator {
+	return v.Check(
+		strings.TrimSpace(value) != "",
+		field,
+		ReasonRequired,
+		fmt.Sprintf("missing %s", label(field)),
+		nil,
+	)
+}
+
+// MaxLength check the number of characters, not bytes, to follow VARCHAR(n)
+func (v *Validator) MaxLength(field, value string, max int) *Validator {
+	return v.Check(
+		utf8.RuneCountInString(value) <= max,
+		field,
+		ReasonMaxLength,
+		fmt.Sprintf("%s must be at most %d characters", label(field), max),
+		map[string]any{"max": max},
+	)
+}
+
+func (v *Validator) Min(field string, value, min float64) *Validator {
+	return v.Check(
+		value >= min,
+		field,
+		ReasonMin,
+		fmt.Sprintf("%s must be at least %s", label(field), number(min)),
+		map[string]any{"min": number(min)},
+	)
+}
+
+func (v *Validator) Max(field string, value, max float64) *Validator {
+	return v.Check(
+		value <= max,
+		field,
+		ReasonMax,
+		fmt.Sprintf("%s must be at most %s", label(field), number(max)),
+		map[string]any{"max": number(max)},
+	)
+}
+
+func (v *Validator) Range(field string, value, min, max float64) *Validator {
+	return v.Check(
+		value >= min && value <= max,
+		field,
+		ReasonOutOfRange,
+		fmt.Sprintf("%s must be between %s and %s", label(field), number(min), number(max)),
+		map[string]any{"min": number(min), "max": number(max)},
+	)
+}
+
+// Phone check the phone number is on E.164 format, example +6281234567890
+func (v *Validator) Phone(field, value string) *Validator {
+	return v.Check(
+		phonePattern.MatchString(value),
+		field,
+		ReasonInvalidPhone,
+		fmt.Sprintf("%s must be a phone number in E.164 format", label(field)),
+		nil,
+	)
+}
+
+// PostalCode check the postal code has only letters, digits and inner space
+// or dash, example 10310 or SW1A 1AA
+func (v *Validator) PostalCode(field, value string) *Validator {
+	return v.Check(
+		postalCodePattern.MatchString(value),
+		field,
+		ReasonInvalidPostalCode,
+		fmt.Sprintf("%s must be a valid postal code", label(field)),
+		nil,
+	)
+}
+
+// Country check the country is an ISO 3166-1 alpha-2 code, example ID
+func (v *Validator) Country(field, value string) *Validator {
+	return v.Check(
+		countryPattern.MatchString(value),
+		field,
+		ReasonInvalidCountry,
+		fmt.Sprintf("%s must be a two letters country code", label(field)),
+		nil,
+	)
+}
+
+func (v *Validator) UUID(field, value string) *Validator {
+	_, err := uuid.Parse(value)
+	return v.Check(
+		err == nil,
+		field,
+		ReasonInvalidUUID,
+		fmt.Sprintf("%s must be a valid id", label(field)),
+		nil,
+	)
+}
+
+// After check value is later than after
+func (v *Validator) After(field string, value, after time.Time) *Validator {
+	return v.Check(
+		value.After(after),
+		field,
+		ReasonAfter,
+		fmt.Sprintf("%s must be after %s", label(field), after.Format(time.RFC3339)),
+		map[string]any{"after": after.Format(time.RFC3339)},
+	)
+}
+
+// OneOf check value is one of values
+func (v *Validator) OneOf(field, value string, values ...string) *Validator {
+	ok := false
+	for _, val := range values {
+		if val == value {
+			ok = true
+			break
+		}
+	}
+
+	return v.Check(
+		ok,
+		field,
+		ReasonOneOf,
+		fmt.Sprintf("%s must be one of %s", label(field), strings.Join(values, ", ")),
+		map[string]any{"values": strings.Join(values, ", ")},
+	)
+}
+
+func (v *Validator) Valid() bool {
+	return len(v.fields) == 0
+}
+
+// Error return nil when every rule pass, otherwise a bad request error
+// listing every failing field
+func (v *Validator) Error() errpkg.ErrorService {
+	if v.Valid() {
+		return nil
+	}
+
+	msg := v.fields[0].Message
+	if len(v.fields) > 1 {
+		msg = fmt.Sprintf("%d fields are invalid", len(v.fields))
+	}
+
+	return errpkg.NewServiceError(
+		errpkg.ErrBadRequest,
+		msg,
+		errpkg.WithReason(errpkg.ReasonValidationFailed),
+		errpkg.WithFields(v.fields...),
+	)
+}
+
+// NormalizePhone remove space, dash, dot and parentheses from phone number
+func NormalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+}
+
+func number(val float64) string {
+	return strconv.FormatFloat(val, 'f', -1, 64)
+}
+
+func label(field string) string {
+	return strings.ReplaceAll(field, "_", " ")
+}
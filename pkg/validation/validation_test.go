@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"testing"
+
+	errpkg "github.com/ijlik/store-app/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorCollectEveryField(t *testing.T) {
+	err := New().
+		Required("name", "").
+		MaxLength("name", "", 5).
+		MaxLength("address", "Jalan Sudirman", 5).
+		Phone("phone", "081234567890").
+		Range("hour", 24, 0, 23).
+		Min("price", -1, 0).
+		Max("stock", 10, 5).
+		UUID("store_id", "store-id").
+		Error()
+
+	assert.Equal(t, errpkg.ErrBadRequest, err.GetCode())
+	assert.Equal(t, errpkg.ReasonValidationFailed, err.GetReason())
+	assert.Equal(t, "7 fields are invalid", err.Error())
+	assert.Equal(t, []errpkg.FieldError{
+		{Field: "name", Reason: ReasonRequired, Message: "missing name"},
+		{Field: "address", Reason: ReasonMaxLength, Message: "address must be at most 5 characters", Params: map[string]any{"max": 5}},
+		{Field: "phone", Reason: ReasonInvalidPhone, Message: "phone must be a phone number in E.164 format"},
+		{Field: "hour", Reason: ReasonOutOfRange, Message: "hour must be between 0 and 23", Params: map[string]any{"min": "0", "max": "23"}},
+		{Field: "price", Reason: ReasonMin, Message: "price must be at least 0", Params: map[string]any{"min": "0"}},
+		{Field: "stock", Reason: ReasonMax, Message: "stock must be at most 5", Params: map[string]any{"max": "5"}},
+		{Field: "store_id", Reason: ReasonInvalidUUID, Message: "store id must be a valid id"},
+	}, err.GetFields())
+}
+
+func TestValidatorValid(t *testing.T) {
+	v := New().
+		Required("name", "Kopi").
+		MaxLength("name", "Kafé", 4).
+		Phone("phone", "+6281234567890").
+		Range("hour", 23, 0, 23).
+		UUID("store_id", "0b7e3c8e-8f53-4a53-9f3c-5a3c1f2f6d11")
+
+	assert.True(t, v.Valid())
+	assert.Nil(t, v.Error())
+}
+
+func TestValidatorSingleField(t *testing.T) {
+	err := New().Required("name", " ").Error()
+	assert.Equal(t, "missing name", err.Error())
+}
+
+func TestNormalizePhone(t *testing.T) {
+	assert.Equal(t, "+6281234567890", NormalizePhone(" +62 812-3456.7890 "))
+	assert.Equal(t, "+12025550123", NormalizePhone("+1 (202) 555-0123"))
+}
+
+func TestValidatorPostalCodeCountry(t *testing.T) {
+	assert.True(t, New().
+		PostalCode("postal_code", "10310").
+		PostalCode("postal_code", "SW1A 1AA").
+		Country("country", "ID").
+		Valid())
+
+	err := New().
+		PostalCode("postal_code", "10-").
+		Country("country", "id").
+		Error()
+	assert.Equal(t, []errpkg.FieldError{
+		{Field: "postal_code", Reason: ReasonInvalidPostalCode, Message: "postal code must be a valid postal code"},
+		{Field: "country", Reason: ReasonInvalidCountry, Message: "country must be a two letters country code"},
+	}, err.GetFields())
+}